@@ -0,0 +1,138 @@
+package agent_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	agent "github.com/greenplum-db/gpdb/gp/agent"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// dialAgent opens a new TLS connection to the agent, trusting the CA
+// currently on disk, and returns the common name the agent presented
+func dialAgent(t *testing.T, creds *utils.GpCredentials, port int) (idl.AgentClient, string) {
+	t.Helper()
+
+	clientCreds, err := creds.LoadClientCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", port),
+		grpc.WithTransportCredentials(clientCreds),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := idl.NewAgentClient(conn)
+	var p peer.Peer
+	_, err = client.Status(context.Background(), &idl.StatusAgentRequest{}, grpc.Peer(&p))
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return client, p.AuthInfo.(credentials.TLSInfo).State.PeerCertificates[0].Subject.CommonName
+}
+
+func TestReloadCredentials(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("serves rotated certificates to new connections once reloaded", func(t *testing.T) {
+		dir := t.TempDir()
+		creds := testutils.CreateCertificates(t, dir, "first")
+		port := freePort(t)
+
+		platform := &testutils.MockPlatform{RetStatus: &idl.ServiceStatus{Status: "running", Pid: 1234}}
+		agent.SetPlatform(platform)
+		defer agent.ResetPlatform()
+
+		agentServer := agent.New(agent.Config{
+			Port:        port,
+			ServiceName: constants.DefaultServiceName,
+			Credentials: creds,
+		})
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- agentServer.Start()
+		}()
+		defer agentServer.Shutdown()
+
+		client, name := dialAgent(t, creds, port)
+		if name != "first" {
+			t.Fatalf("got %s, want first", name)
+		}
+
+		testutils.CreateCertificates(t, dir, "second")
+		reply, err := client.ReloadCredentials(context.Background(), &idl.ReloadCredentialsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if len(reply.Certificates) != 2 || reply.Certificates[0].Subject != "CN=second" {
+			t.Fatalf("unexpected certificates %+v", reply.Certificates)
+		}
+
+		// the existing connection is not affected
+		status, err := client.Status(context.Background(), &idl.StatusAgentRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if len(status.Certificates) != 2 || status.Certificates[0].Subject != "CN=second" {
+			t.Fatalf("unexpected certificates in status %+v", status.Certificates)
+		}
+
+		_, name = dialAgent(t, creds, port)
+		if name != "second" {
+			t.Fatalf("got %s, want second", name)
+		}
+
+		select {
+		case err := <-errChan:
+			t.Fatalf("unexpected error: %#v", err)
+		default:
+		}
+	})
+
+	t.Run("errors out when the agent is not serving reloadable credentials", func(t *testing.T) {
+		agentServer := agent.New(agent.Config{
+			Port:        constants.DefaultAgentPort,
+			ServiceName: constants.DefaultServiceName,
+			Credentials: &testutils.MockCredentials{},
+		})
+
+		_, err := agentServer.ReloadCredentials(context.Background(), &idl.ReloadCredentialsRequest{})
+		expected := "the agent credentials can not be reloaded"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+// PushFile writes the file sent by the hub. The contents go to a temporary file
+// in the target directory, which replaces the target only once the size and
+// checksum are verified, so the target is never left partially written.
+func (s *Server) PushFile(stream idl.Agent_PushFileServer) error {
+	request, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("could not receive file header: %w", err)
+	}
+
+	header := request.GetHeader()
+	if header == nil {
+		return errors.New("expected the file header as the first message")
+	}
+
+	if !filepath.IsAbs(header.Path) {
+		return fmt.Errorf("file path %s is not absolute", header.Path)
+	}
+
+	size, checksum, err := writeFileAtomically(header, stream)
+	if err != nil {
+		return fmt.Errorf("could not write file %s: %w", header.Path, err)
+	}
+	gplog.Info("Wrote file %s (%d bytes)", header.Path, size)
+
+	return stream.SendAndClose(&idl.PushFileReply{Size: size, Sha256: checksum})
+}
+
+func writeFileAtomically(header *idl.FileHeader, stream idl.Agent_PushFileServer) (size int64, checksum string, err error) {
+	dir, base := filepath.Split(header.Path)
+	temp, err := os.CreateTemp(dir, fmt.Sprintf(".%s.*", base))
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if err != nil {
+			temp.Close()
+			os.Remove(temp.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	writer := io.MultiWriter(temp, hash)
+	for {
+		var request *idl.PushFileRequest
+		request, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("could not receive file contents: %w", err)
+		}
+
+		if request.GetHeader() != nil {
+			err = errors.New("received more than one file header")
+			return 0, "", err
+		}
+
+		var n int
+		n, err = writer.Write(request.GetChunk())
+		if err != nil {
+			return 0, "", err
+		}
+		size += int64(n)
+	}
+
+	checksum = hex.EncodeToString(hash.Sum(nil))
+	if size != header.Size {
+		err = fmt.Errorf("received %d bytes, expected %d", size, header.Size)
+		return 0, "", err
+	}
+	if checksum != header.Sha256 {
+		err = fmt.Errorf("checksum mismatch: got %s, expected %s", checksum, header.Sha256)
+		return 0, "", err
+	}
+
+	err = temp.Chmod(os.FileMode(header.Mode).Perm())
+	if err != nil {
+		return 0, "", err
+	}
+
+	err = temp.Sync()
+	if err != nil {
+		return 0, "", err
+	}
+
+	err = temp.Close()
+	if err != nil {
+		return 0, "", err
+	}
+
+	err = os.Rename(temp.Name(), header.Path)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, checksum, nil
+}
@@ -0,0 +1,171 @@
+package agent_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	agent "github.com/greenplum-db/gpdb/gp/agent"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc"
+)
+
+// mockPushFileStream replays the requests to the agent and records its reply
+type mockPushFileStream struct {
+	grpc.ServerStream
+	Requests []*idl.PushFileRequest
+	Reply    *idl.PushFileReply
+}
+
+func (m *mockPushFileStream) Recv() (*idl.PushFileRequest, error) {
+	if len(m.Requests) == 0 {
+		return nil, io.EOF
+	}
+
+	request := m.Requests[0]
+	m.Requests = m.Requests[1:]
+
+	return request, nil
+}
+
+func (m *mockPushFileStream) SendAndClose(reply *idl.PushFileReply) error {
+	m.Reply = reply
+
+	return nil
+}
+
+func fileRequests(path string, mode uint32, chunks ...string) []*idl.PushFileRequest {
+	contents := strings.Join(chunks, "")
+	sum := sha256.Sum256([]byte(contents))
+
+	requests := []*idl.PushFileRequest{{Request: &idl.PushFileRequest_Header{Header: &idl.FileHeader{
+		Path:   path,
+		Mode:   mode,
+		Size:   int64(len(contents)),
+		Sha256: hex.EncodeToString(sum[:]),
+	}}}}
+	for _, chunk := range chunks {
+		requests = append(requests, &idl.PushFileRequest{Request: &idl.PushFileRequest_Chunk{Chunk: []byte(chunk)}})
+	}
+
+	return requests
+}
+
+func TestPushFile(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("writes the file with the requested mode", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		requests := fileRequests(path, 0600, "first ", "second")
+		stream := &mockPushFileStream{Requests: requests}
+
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.PushFile(stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if string(contents) != "first second" {
+			t.Fatalf("got %q, want %q", contents, "first second")
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Fatalf("got mode %o, want %o", info.Mode().Perm(), 0600)
+		}
+
+		header := requests[0].GetHeader()
+		if stream.Reply.Size != header.Size || stream.Reply.Sha256 != header.Sha256 {
+			t.Fatalf("got %+v, want the size and checksum of %+v", stream.Reply, header)
+		}
+	})
+
+	t.Run("keeps the existing file when the checksum does not match", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "gp.conf")
+		err := os.WriteFile(path, []byte("existing"), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		requests := fileRequests(path, 0644, "new contents")
+		requests[1] = &idl.PushFileRequest{Request: &idl.PushFileRequest_Chunk{Chunk: []byte("bad contents")}}
+		stream := &mockPushFileStream{Requests: requests}
+
+		agentServer := agent.New(agent.Config{})
+		err = agentServer.PushFile(stream)
+		expected := "checksum mismatch"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if string(contents) != "existing" {
+			t.Fatalf("got %q, want %q", contents, "existing")
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected the temporary file to be removed, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("errors out when fewer bytes are received than expected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		requests := fileRequests(path, 0644, "first ", "second")
+		stream := &mockPushFileStream{Requests: requests[:2]}
+
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.PushFile(stream)
+		expected := "received 6 bytes, expected 12"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s not to exist, got %v", path, err)
+		}
+	})
+
+	t.Run("errors out when the first message is not the header", func(t *testing.T) {
+		stream := &mockPushFileStream{Requests: []*idl.PushFileRequest{
+			{Request: &idl.PushFileRequest_Chunk{Chunk: []byte("contents")}},
+		}}
+
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.PushFile(stream)
+		expected := "expected the file header as the first message"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("errors out when the path is not absolute", func(t *testing.T) {
+		stream := &mockPushFileStream{Requests: fileRequests("gp.conf", 0644, "contents")}
+
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.PushFile(stream)
+		expected := "file path gp.conf is not absolute"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
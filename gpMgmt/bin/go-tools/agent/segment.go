@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+var stopModes = map[idl.StopMode]string{
+	idl.StopMode_SMART:     utils.StopModeSmart,
+	idl.StopMode_FAST:      utils.StopModeFast,
+	idl.StopMode_IMMEDIATE: utils.StopModeImmediate,
+}
+
+func (s *Server) StartSegment(ctx context.Context, in *idl.StartSegmentRequest) (*idl.StartSegmentReply, error) {
+	gpRole := utils.GpRoleExecute
+	if in.Standby {
+		gpRole = utils.GpRoleDispatch
+	}
+	if in.UtilityMode {
+		gpRole = utils.GpRoleUtility
+	}
+
+	args := utils.PgCtlStartArgs(in.DataDir, int(in.Port), gpRole, int(in.Timeout))
+	output, err := execCommand(utils.PgCtlPath(s.GpHome), args...).CombinedOutput()
+	if err != nil {
+		return &idl.StartSegmentReply{}, fmt.Errorf("could not start segment %d with data directory %s: %w, Command Output: %s", in.ContentId, in.DataDir, err, string(output))
+	}
+	gplog.Info("Started segment %d with data directory %s on port %d", in.ContentId, in.DataDir, in.Port)
+
+	return &idl.StartSegmentReply{}, nil
+}
+
+func (s *Server) StopSegment(ctx context.Context, in *idl.StopSegmentRequest) (*idl.StopSegmentReply, error) {
+	mode, ok := stopModes[in.Mode]
+	if !ok {
+		return &idl.StopSegmentReply{}, fmt.Errorf("invalid stop mode %s", in.Mode)
+	}
+
+	args := utils.PgCtlStopArgs(in.DataDir, mode, int(in.Timeout))
+	output, err := execCommand(utils.PgCtlPath(s.GpHome), args...).CombinedOutput()
+	if err != nil {
+		// told apart so that the hub can ignore it when stopping is a precaution
+		if checkPostmaster(in.DataDir) != nil {
+			return &idl.StopSegmentReply{}, grpcStatus.Errorf(codes.FailedPrecondition, "segment %d with data directory %s is not running", in.ContentId, in.DataDir)
+		}
+
+		return &idl.StopSegmentReply{}, fmt.Errorf("could not stop segment %d with data directory %s: %w, Command Output: %s", in.ContentId, in.DataDir, err, string(output))
+	}
+	gplog.Info("Stopped segment %d with data directory %s using %s mode", in.ContentId, in.DataDir, mode)
+
+	return &idl.StopSegmentReply{}, nil
+}
+
+// RecoverSegment rebuilds the data directory of a down segment from its acting
+// primary, with pg_rewind or with pg_basebackup for a full recovery, streaming
+// their output. The segment is left stopped.
+func (s *Server) RecoverSegment(in *idl.RecoverSegmentRequest, stream idl.Agent_RecoverSegmentServer) error {
+	if in.NewMirror {
+		// Only a data directory created here is removed when the copy fails
+		entries, err := os.ReadDir(in.DataDir)
+		if err == nil && len(entries) > 0 {
+			return fmt.Errorf("could not create mirror segment %d: data directory %s already exists", in.ContentId, in.DataDir)
+		}
+	}
+
+	var cmd *exec.Cmd
+	if in.Full || in.NewMirror {
+		args := utils.PgBasebackupArgs(in.DataDir, in.SourceHost, int(in.SourcePort), int(in.Dbid), in.NewMirror)
+		cmd = execCommand(utils.PgBasebackupPath(s.GpHome), args...)
+	} else {
+		// As with gprecoverseg, a segment shut down as a mirror needs no
+		// rewind and catches up through replication once started
+		_, err := os.Stat(filepath.Join(in.DataDir, "standby.signal"))
+		if err == nil {
+			gplog.Info("Segment %d with data directory %s is a mirror, skipping pg_rewind", in.ContentId, in.DataDir)
+			return stream.Send(&idl.RecoverSegmentReply{Output: "standby.signal found, skipping pg_rewind"})
+		}
+
+		args := utils.PgRewindArgs(in.DataDir, in.SourceHost, int(in.SourcePort))
+		cmd = execCommand(utils.PgRewindPath(s.GpHome), args...)
+		cmd.Env = append(os.Environ(), "PGOPTIONS=-c gp_role=utility")
+	}
+
+	gplog.Info("Recovering segment %d with data directory %s from %s:%d", in.ContentId, in.DataDir, in.SourceHost, in.SourcePort)
+	output, err := streamOutput(cmd, func(line string) {
+		err := stream.Send(&idl.RecoverSegmentReply{Output: line})
+		if err != nil {
+			gplog.Debug("could not send recovery output to hub: %v", err)
+		}
+	})
+	if err != nil {
+		if in.NewMirror {
+			_ = os.RemoveAll(in.DataDir)
+		}
+		return fmt.Errorf("could not recover segment %d with data directory %s: %w, Command Output: %s", in.ContentId, in.DataDir, err, output)
+	}
+	gplog.Info("Recovered segment %d with data directory %s", in.ContentId, in.DataDir)
+
+	return nil
+}
+
+// InitSegment creates the data directory of a new primary segment with initdb,
+// or as a copy of the coordinator when it is given, and configures it. The
+// data directory is removed when it can not be configured.
+func (s *Server) InitSegment(ctx context.Context, in *idl.InitSegmentRequest) (*idl.InitSegmentReply, error) {
+	currentUser, err := user.Current()
+	if err != nil {
+		return &idl.InitSegmentReply{}, fmt.Errorf("could not get current user: %w", err)
+	}
+
+	var cmd *exec.Cmd
+	if in.CoordinatorHost != "" {
+		// As gpexpand does, the copy carries the catalog of the cluster over
+		// to the new segment, which is then given its own port, content ID
+		// and dbid
+		args := utils.PgBasebackupCoordinatorArgs(in.DataDir, in.CoordinatorHost, int(in.CoordinatorPort), int(in.Dbid))
+		cmd = execCommand(utils.PgBasebackupPath(s.GpHome), args...)
+	} else {
+		args := utils.InitdbArgs(in.DataDir, in.Encoding, in.Locale)
+		cmd = execCommand(utils.InitdbPath(s.GpHome), args...)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return &idl.InitSegmentReply{}, fmt.Errorf("could not initialize segment %d with data directory %s: %w, Command Output: %s", in.ContentId, in.DataDir, err, string(output))
+	}
+
+	err = utils.WriteSegmentConfig(in.DataDir, int(in.Port), int(in.ContentId), int(in.Dbid), currentUser.Username, in.HbaHostnames)
+	if err != nil {
+		_ = os.RemoveAll(in.DataDir)
+		return &idl.InitSegmentReply{}, fmt.Errorf("could not configure segment %d with data directory %s: %w", in.ContentId, in.DataDir, err)
+	}
+	gplog.Info("Initialized segment %d with data directory %s", in.ContentId, in.DataDir)
+
+	return &idl.InitSegmentReply{}, nil
+}
+
+// RemoveSegment stops the segment, if it runs, and removes its data directory
+func (s *Server) RemoveSegment(ctx context.Context, in *idl.RemoveSegmentRequest) (*idl.RemoveSegmentReply, error) {
+	args := utils.PgCtlStopArgs(in.DataDir, utils.StopModeImmediate, 0)
+	output, err := execCommand(utils.PgCtlPath(s.GpHome), args...).CombinedOutput()
+	if err != nil {
+		gplog.Debug("Could not stop segment %d with data directory %s, it may not be running: %s, Command Output: %s", in.ContentId, in.DataDir, err, string(output))
+	}
+
+	err = os.RemoveAll(in.DataDir)
+	if err != nil {
+		return &idl.RemoveSegmentReply{}, fmt.Errorf("could not remove data directory %s of segment %d: %w", in.DataDir, in.ContentId, err)
+	}
+	gplog.Info("Removed segment %d with data directory %s", in.ContentId, in.DataDir)
+
+	return &idl.RemoveSegmentReply{}, nil
+}
+
+// streamOutput runs the command, passing every line of its combined output to
+// send as it comes, and returns the last lines of the output
+func streamOutput(cmd *exec.Cmd, send func(line string)) (string, error) {
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	err := cmd.Start()
+	if err != nil {
+		return "", err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		done <- err
+	}()
+
+	const kept = 10
+	lines := make([]string, 0, kept)
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		send(line)
+		if len(lines) == kept {
+			lines = lines[1:]
+		}
+		lines = append(lines, line)
+	}
+	// drain the output should the scanner give up on an overlong line
+	_, _ = io.Copy(io.Discard, reader)
+
+	return strings.Join(lines, "\n"), <-done
+}
+
+// scanProgressLines splits the output into lines ending with a newline or a
+// carriage return, which the progress reports of pg_basebackup end with
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
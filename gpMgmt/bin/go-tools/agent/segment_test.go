@@ -0,0 +1,562 @@
+package agent_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/agent"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// RecoveryProgress prints progress the way pg_basebackup does, along with the
+// options passed through the environment
+func RecoveryProgress() {
+	fmt.Printf("PGOPTIONS=%s\n", os.Getenv("PGOPTIONS"))
+	fmt.Print("10/20 kB (50%), 0/1 tablespace\r20/20 kB (100%), 1/1 tablespace\r\n")
+	fmt.Fprintln(os.Stderr, "done")
+}
+
+// RecoveryFailure fails the way pg_rewind does when the source is unreachable
+func RecoveryFailure() {
+	fmt.Fprintln(os.Stderr, "pg_rewind: error: could not connect to server")
+	os.Exit(1)
+}
+
+func init() {
+	exectest.RegisterMains(
+		RecoveryProgress,
+		RecoveryFailure,
+	)
+}
+
+// mockRecoverSegmentStream records the output sent by the agent
+type mockRecoverSegmentStream struct {
+	grpc.ServerStream
+	Output []string
+}
+
+func (m *mockRecoverSegmentStream) Send(reply *idl.RecoverSegmentReply) error {
+	m.Output = append(m.Output, reply.Output)
+
+	return nil
+}
+
+func TestStartSegment(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	agentServer := agent.New(agent.Config{GpHome: "/usr/local/gpdb"})
+
+	t.Run("starts the segment using pg_ctl", func(t *testing.T) {
+		var calledUtility string
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledUtility = utility
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		_, err := agentServer.StartSegment(context.Background(), &idl.StartSegmentRequest{
+			DataDir:   "/data/primary/gpseg0",
+			Port:      6000,
+			ContentId: 0,
+			Timeout:   600,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedUtility := "/usr/local/gpdb/bin/pg_ctl"
+		if calledUtility != expectedUtility {
+			t.Fatalf("got %q, want %q", calledUtility, expectedUtility)
+		}
+
+		expectedArgs := []string{"-D", "/data/primary/gpseg0", "-l", "/data/primary/gpseg0/log/startup.log", "-w", "-t", "600", "-o", "-p 6000 -c gp_role=execute", "start"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+	})
+
+	t.Run("starts the segment in utility mode", func(t *testing.T) {
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		_, err := agentServer.StartSegment(context.Background(), &idl.StartSegmentRequest{
+			DataDir:     "/data/primary/gpseg0",
+			Port:        6000,
+			UtilityMode: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedArgs := []string{"-D", "/data/primary/gpseg0", "-l", "/data/primary/gpseg0/log/startup.log", "-w", "-o", "-p 6000 -c gp_role=utility", "start"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+	})
+
+	t.Run("starts the standby coordinator in dispatch mode", func(t *testing.T) {
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		_, err := agentServer.StartSegment(context.Background(), &idl.StartSegmentRequest{
+			DataDir:   "/data/standby",
+			Port:      5432,
+			ContentId: -1,
+			Standby:   true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedArgs := []string{"-D", "/data/standby", "-l", "/data/standby/log/startup.log", "-w", "-o", "-p 5432 -c gp_role=dispatch", "start"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+	})
+
+	t.Run("errors out when pg_ctl fails", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer agent.ResetExecCommand()
+
+		_, err := agentServer.StartSegment(context.Background(), &idl.StartSegmentRequest{
+			DataDir:   "/data/primary/gpseg0",
+			Port:      6000,
+			ContentId: 0,
+		})
+		expectedErr := "could not start segment 0 with data directory /data/primary/gpseg0: exit status 1"
+		if !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+}
+
+func TestStopSegment(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	agentServer := agent.New(agent.Config{GpHome: "/usr/local/gpdb"})
+
+	t.Run("stops the segment using the requested mode", func(t *testing.T) {
+		cases := map[idl.StopMode]string{
+			idl.StopMode_SMART:     "smart",
+			idl.StopMode_FAST:      "fast",
+			idl.StopMode_IMMEDIATE: "immediate",
+		}
+
+		for mode, modeArg := range cases {
+			var calledArgs []string
+			agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+				calledArgs = args
+			}))
+
+			_, err := agentServer.StopSegment(context.Background(), &idl.StopSegmentRequest{
+				DataDir: "/data/mirror/gpseg1",
+				Mode:    mode,
+			})
+			agent.ResetExecCommand()
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+
+			expectedArgs := []string{"-D", "/data/mirror/gpseg1", "-m", modeArg, "-w", "stop"}
+			if !reflect.DeepEqual(calledArgs, expectedArgs) {
+				t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+			}
+		}
+	})
+
+	t.Run("errors out when given an invalid stop mode", func(t *testing.T) {
+		_, err := agentServer.StopSegment(context.Background(), &idl.StopSegmentRequest{
+			DataDir: "/data/mirror/gpseg1",
+			Mode:    idl.StopMode(10),
+		})
+		expectedErr := "invalid stop mode 10"
+		if err.Error() != expectedErr {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("errors out when pg_ctl fails", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer agent.ResetExecCommand()
+		agent.SetCheckPostmaster(func(dataDir string) error {
+			return nil
+		})
+		defer agent.ResetCheckPostmaster()
+
+		_, err := agentServer.StopSegment(context.Background(), &idl.StopSegmentRequest{
+			DataDir:   "/data/mirror/gpseg1",
+			ContentId: 1,
+		})
+		expectedErr := "could not stop segment 1 with data directory /data/mirror/gpseg1: exit status 1"
+		if !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("tells apart the segments that are not running", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer agent.ResetExecCommand()
+
+		_, err := agentServer.StopSegment(context.Background(), &idl.StopSegmentRequest{
+			DataDir:   t.TempDir(),
+			ContentId: 1,
+		})
+		if grpcStatus.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("got %v, want a %s error", err, codes.FailedPrecondition)
+		}
+	})
+}
+
+func TestRecoverSegment(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	agentServer := agent.New(agent.Config{GpHome: "/usr/local/gpdb"})
+
+	t.Run("rewinds the segment using pg_rewind and streams its output", func(t *testing.T) {
+		var calledUtility string
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(RecoveryProgress, func(utility string, args ...string) {
+			calledUtility = utility
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		stream := &mockRecoverSegmentStream{}
+		err := agentServer.RecoverSegment(&idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg0",
+			ContentId:  0,
+			Dbid:       4,
+			SourceHost: "sdw1",
+			SourcePort: 6000,
+		}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedUtility := "/usr/local/gpdb/bin/pg_rewind"
+		if calledUtility != expectedUtility {
+			t.Fatalf("got %q, want %q", calledUtility, expectedUtility)
+		}
+		expectedArgs := []string{"--write-recovery-conf", "--slot=internal_wal_replication_slot", "--source-server=host=sdw1 port=6000 dbname=template1", "--target-pgdata=/data/mirror/gpseg0", "--progress"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+
+		expectedOutput := []string{"PGOPTIONS=-c gp_role=utility", "10/20 kB (50%), 0/1 tablespace", "20/20 kB (100%), 1/1 tablespace", "done"}
+		if !reflect.DeepEqual(stream.Output, expectedOutput) {
+			t.Fatalf("got %q, want %q", stream.Output, expectedOutput)
+		}
+	})
+
+	t.Run("copies the segment using pg_basebackup for a full recovery", func(t *testing.T) {
+		var calledUtility string
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledUtility = utility
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		err := agentServer.RecoverSegment(&idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg0",
+			ContentId:  0,
+			Dbid:       4,
+			SourceHost: "sdw1",
+			SourcePort: 6000,
+			Full:       true,
+		}, &mockRecoverSegmentStream{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedUtility := "/usr/local/gpdb/bin/pg_basebackup"
+		if calledUtility != expectedUtility {
+			t.Fatalf("got %q, want %q", calledUtility, expectedUtility)
+		}
+		expectedArgs := []string{"-c", "fast", "-D", "/data/mirror/gpseg0", "-h", "sdw1", "-p", "6000", "--slot", "internal_wal_replication_slot", "--wal-method", "stream", "--force-overwrite", "--write-recovery-conf", "--target-gp-dbid", "4", "-E", "./db_dumps", "-E", "./promote", "-E", "./db_analyze", "--progress", "--verbose"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+	})
+
+	t.Run("skips pg_rewind when the segment was shut down as a mirror", func(t *testing.T) {
+		dataDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dataDir, "standby.signal"), []byte{}, 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		called := false
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			called = true
+		}))
+		defer agent.ResetExecCommand()
+
+		stream := &mockRecoverSegmentStream{}
+		err = agentServer.RecoverSegment(&idl.RecoverSegmentRequest{DataDir: dataDir, SourceHost: "sdw1", SourcePort: 6000}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if called {
+			t.Fatalf("expected pg_rewind not to be run")
+		}
+		expectedOutput := []string{"standby.signal found, skipping pg_rewind"}
+		if !reflect.DeepEqual(stream.Output, expectedOutput) {
+			t.Fatalf("got %q, want %q", stream.Output, expectedOutput)
+		}
+	})
+
+	t.Run("copies a new mirror creating its replication slot", func(t *testing.T) {
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		dataDir := filepath.Join(t.TempDir(), "gpseg0")
+		err := agentServer.RecoverSegment(&idl.RecoverSegmentRequest{
+			DataDir:    dataDir,
+			ContentId:  0,
+			Dbid:       4,
+			SourceHost: "sdw1",
+			SourcePort: 6000,
+			NewMirror:  true,
+		}, &mockRecoverSegmentStream{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedArgs := []string{"-c", "fast", "-D", dataDir, "-h", "sdw1", "-p", "6000", "--create-slot", "--slot", "internal_wal_replication_slot", "--wal-method", "stream", "--force-overwrite", "--write-recovery-conf", "--target-gp-dbid", "4", "-E", "./db_dumps", "-E", "./promote", "-E", "./db_analyze", "--progress", "--verbose"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+	})
+
+	t.Run("errors out when the data directory of a new mirror exists", func(t *testing.T) {
+		dataDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dataDir, "PG_VERSION"), []byte("12\n"), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		called := false
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			called = true
+		}))
+		defer agent.ResetExecCommand()
+
+		err = agentServer.RecoverSegment(&idl.RecoverSegmentRequest{DataDir: dataDir, NewMirror: true}, &mockRecoverSegmentStream{})
+
+		expectedErr := fmt.Sprintf("could not create mirror segment 0: data directory %s already exists", dataDir)
+		if err == nil || err.Error() != expectedErr {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+		if called {
+			t.Fatalf("expected pg_basebackup not to be run")
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, "PG_VERSION")); err != nil {
+			t.Fatalf("expected the data directory to be left in place, got %v", err)
+		}
+	})
+
+	t.Run("removes the data directory of a new mirror when the copy fails", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(RecoveryFailure))
+		defer agent.ResetExecCommand()
+
+		dataDir := t.TempDir()
+		err := agentServer.RecoverSegment(&idl.RecoverSegmentRequest{DataDir: dataDir, NewMirror: true}, &mockRecoverSegmentStream{})
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+			t.Fatalf("expected the data directory to be removed, got %v", err)
+		}
+	})
+
+	t.Run("errors out with the output of the recovery when it fails", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(RecoveryFailure))
+		defer agent.ResetExecCommand()
+
+		err := agentServer.RecoverSegment(&idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg1",
+			ContentId:  1,
+			SourceHost: "sdw2",
+			SourcePort: 6001,
+		}, &mockRecoverSegmentStream{})
+
+		expectedErr := "could not recover segment 1 with data directory /data/mirror/gpseg1: exit status 1, Command Output: pg_rewind: error: could not connect to server"
+		if err == nil || err.Error() != expectedErr {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+}
+
+func TestInitSegment(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	agentServer := agent.New(agent.Config{GpHome: "/usr/local/gpdb"})
+
+	t.Run("creates the data directory using initdb and configures it", func(t *testing.T) {
+		var calledUtility string
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledUtility = utility
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		dataDir := t.TempDir()
+		_, err := agentServer.InitSegment(context.Background(), &idl.InitSegmentRequest{
+			DataDir:      dataDir,
+			Port:         6000,
+			ContentId:    0,
+			Dbid:         2,
+			Locale:       "en_US.utf8",
+			Encoding:     "UTF-8",
+			HbaHostnames: []string{"cdw", "sdw1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedUtility := "/usr/local/gpdb/bin/initdb"
+		if calledUtility != expectedUtility {
+			t.Fatalf("got %q, want %q", calledUtility, expectedUtility)
+		}
+		expectedArgs := []string{"-D", dataDir, "-E", "UTF-8", "--locale=en_US.utf8", "--data-checksums"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+
+		contents, err := os.ReadFile(filepath.Join(dataDir, "postgresql.conf"))
+		if err != nil || !strings.Contains(string(contents), "gp_contentid=0") {
+			t.Fatalf("got %q (%v), want the segment to be configured", contents, err)
+		}
+	})
+
+	t.Run("copies the data directory of the coordinator and configures it", func(t *testing.T) {
+		var calledUtility string
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledUtility = utility
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		dataDir := t.TempDir()
+		_, err := agentServer.InitSegment(context.Background(), &idl.InitSegmentRequest{
+			DataDir:         dataDir,
+			Port:            6000,
+			ContentId:       2,
+			Dbid:            6,
+			HbaHostnames:    []string{"cdw", "sdw3"},
+			CoordinatorHost: "cdw",
+			CoordinatorPort: 5432,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedUtility := "/usr/local/gpdb/bin/pg_basebackup"
+		if calledUtility != expectedUtility {
+			t.Fatalf("got %q, want %q", calledUtility, expectedUtility)
+		}
+		expectedArgs := utils.PgBasebackupCoordinatorArgs(dataDir, "cdw", 5432, 6)
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+
+		contents, err := os.ReadFile(filepath.Join(dataDir, "postgresql.conf"))
+		if err != nil || !strings.Contains(string(contents), "port=6000\nlisten_addresses='*'\ngp_contentid=2\n") {
+			t.Fatalf("got %q (%v), want the port and content ID of the segment", contents, err)
+		}
+	})
+
+	t.Run("errors out when initdb fails", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer agent.ResetExecCommand()
+
+		_, err := agentServer.InitSegment(context.Background(), &idl.InitSegmentRequest{DataDir: "/data/primary/gpseg0"})
+
+		expectedErr := "could not initialize segment 0 with data directory /data/primary/gpseg0"
+		if err == nil || !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("errors out when the data directory can not be configured", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(exectest.Success))
+		defer agent.ResetExecCommand()
+
+		dataDir := filepath.Join(t.TempDir(), "gpseg0")
+		_, err := agentServer.InitSegment(context.Background(), &idl.InitSegmentRequest{DataDir: dataDir})
+
+		expectedErr := fmt.Sprintf("could not configure segment 0 with data directory %s", dataDir)
+		if err == nil || !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+}
+
+func TestRemoveSegment(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	agentServer := agent.New(agent.Config{GpHome: "/usr/local/gpdb"})
+
+	t.Run("stops the segment and removes its data directory", func(t *testing.T) {
+		var calledArgs []string
+		agent.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calledArgs = args
+		}))
+		defer agent.ResetExecCommand()
+
+		dataDir := t.TempDir()
+		_, err := agentServer.RemoveSegment(context.Background(), &idl.RemoveSegmentRequest{DataDir: dataDir})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedArgs := []string{"-D", dataDir, "-m", "immediate", "-w", "stop"}
+		if !reflect.DeepEqual(calledArgs, expectedArgs) {
+			t.Fatalf("got %+v, want %+v", calledArgs, expectedArgs)
+		}
+		if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+			t.Fatalf("expected the data directory to be removed, got %v", err)
+		}
+	})
+
+	t.Run("removes the data directory of a segment that does not run", func(t *testing.T) {
+		agent.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer agent.ResetExecCommand()
+
+		dataDir := t.TempDir()
+		_, err := agentServer.RemoveSegment(context.Background(), &idl.RemoveSegmentRequest{DataDir: dataDir})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+			t.Fatalf("expected the data directory to be removed, got %v", err)
+		}
+	})
+}
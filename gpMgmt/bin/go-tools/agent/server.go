@@ -2,23 +2,42 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"os/exec"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/audit"
+	"github.com/greenplum-db/gpdb/gp/constants"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/metrics"
+	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
 	"github.com/greenplum-db/gpdb/gp/utils"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 var (
-	platform = utils.GetPlatform()
+	platform    = utils.GetPlatform()
+	execCommand = exec.Command
 )
 
+// HealthService reports whether the agent accepts requests
+const HealthService = "idl.Agent"
+
 type Config struct {
 	Port        int
 	ServiceName string
+	GpHome      string
+	LogDir      string
+	ConfigFile  string // reported by its checksum in the status of the agent
+	MetricsPort int    // the agent exports no metrics when 0
 
 	Credentials utils.Credentials
 }
@@ -26,9 +45,14 @@ type Config struct {
 type Server struct {
 	*Config
 
-	mutex      sync.Mutex
-	grpcServer *grpc.Server
-	listener   net.Listener
+	mutex       sync.Mutex
+	grpcServer  *grpc.Server
+	listener    net.Listener
+	certWatcher *utils.CertificateWatcher
+	health      *health.Server
+
+	connections    utils.ConnectionCounter
+	configChecksum string
 }
 
 func New(conf Config) *Server {
@@ -37,6 +61,12 @@ func New(conf Config) *Server {
 	}
 }
 
+// isAuditedAgentRPC returns whether the RPC can change the state of the agent
+// or of its segments
+func isAuditedAgentRPC(method string) bool {
+	return method != "/idl.Agent/Status" && method != "/idl.Agent/WatchSegments" && !strings.HasPrefix(method, "/grpc.reflection.") && !strings.HasPrefix(method, "/grpc.health.")
+}
+
 func (s *Server) Stop(ctx context.Context, in *idl.StopAgentRequest) (*idl.StopAgentReply, error) {
 	s.Shutdown()
 	return &idl.StopAgentReply{}, nil
@@ -48,30 +78,57 @@ func (s *Server) Start() error {
 		return fmt.Errorf("could not listen on port %d: %w", s.Port, err)
 	}
 
-	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		// handle stuff here if needed
-		return handler(ctx, req)
+	credentials, certWatcher, err := utils.LoadWatchedServerCredentials(s.Credentials, utils.AgentRole)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	var configChecksum string
+	if s.ConfigFile != "" {
+		configChecksum, err = utils.FileChecksum(s.ConfigFile)
+		if err != nil {
+			gplog.Warn("%s", err)
+		}
 	}
 
-	credentials, err := s.Credentials.LoadServerCredentials()
+	serverMetrics := metrics.New(s.MetricsPort, string(utils.AgentRole))
+	err = serverMetrics.Start()
 	if err != nil {
 		listener.Close()
 		return err
 	}
+	defer serverMetrics.Stop()
 
+	auditLogger := audit.NewLogger(s.LogDir, string(utils.AgentRole), isAuditedAgentRPC, nil)
 	grpcServer := grpc.NewServer(
 		grpc.Creds(credentials),
-		grpc.UnaryInterceptor(interceptor),
+		grpc.StatsHandler(&s.connections),
+		grpc.ChainUnaryInterceptor(serverMetrics.UnaryInterceptor, auditLogger.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(serverMetrics.StreamInterceptor, auditLogger.StreamInterceptor),
 	)
 
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(HealthService, healthpb.HealthCheckResponse_SERVING)
+
 	s.mutex.Lock()
 	s.grpcServer = grpcServer
 	s.listener = listener
+	s.certWatcher = certWatcher
+	s.health = healthServer
+	s.configChecksum = configChecksum
 	s.mutex.Unlock()
 
 	idl.RegisterAgentServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
 
+	if certWatcher != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go certWatcher.Watch(constants.CertificateCheckInterval*time.Second, done)
+	}
+
 	err = grpcServer.Serve(listener)
 	if err != nil {
 		return fmt.Errorf("failed to serve: %w", err)
@@ -84,6 +141,9 @@ func (s *Server) Shutdown() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if s.health != nil {
+		s.health.Shutdown()
+	}
 	if s.grpcServer != nil {
 		s.grpcServer.Stop()
 	}
@@ -95,7 +155,55 @@ func (s *Server) Status(ctx context.Context, in *idl.StatusAgentRequest) (*idl.S
 		return &idl.StatusAgentReply{}, fmt.Errorf("could not get agent status: %w", err)
 	}
 
-	return &idl.StatusAgentReply{Status: status.Status, Uptime: status.Uptime, Pid: uint32(status.Pid)}, nil
+	s.mutex.Lock()
+	configChecksum := s.configChecksum
+	s.mutex.Unlock()
+
+	return &idl.StatusAgentReply{
+		Status:         status.Status,
+		Uptime:         status.Uptime,
+		Pid:            uint32(status.Pid),
+		Certificates:   s.certificates(),
+		StartTime:      status.StartTime,
+		MemoryRss:      status.MemoryRss,
+		CpuTime:        status.CpuTime,
+		Connections:    s.connections.Count(),
+		Version:        constants.Version,
+		ConfigChecksum: configChecksum,
+		ActiveState:    status.ActiveState,
+		SubState:       status.SubState,
+		Result:         status.Result,
+	}, nil
+}
+
+// ReloadCredentials picks up rotated certificates for the new connections
+func (s *Server) ReloadCredentials(ctx context.Context, in *idl.ReloadCredentialsRequest) (*idl.ReloadCredentialsReply, error) {
+	s.mutex.Lock()
+	certWatcher := s.certWatcher
+	s.mutex.Unlock()
+
+	if certWatcher == nil {
+		return &idl.ReloadCredentialsReply{}, errors.New("the agent credentials can not be reloaded")
+	}
+
+	err := certWatcher.Reload()
+	if err != nil {
+		return &idl.ReloadCredentialsReply{}, fmt.Errorf("could not reload agent credentials: %w", err)
+	}
+	gplog.Info("Reloaded the agent credentials")
+
+	return &idl.ReloadCredentialsReply{Certificates: certWatcher.Certificates()}, nil
+}
+
+func (s *Server) certificates() []*idl.Certificate {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.certWatcher == nil {
+		return nil
+	}
+
+	return s.certWatcher.Certificates()
 }
 
 func (s *Server) GetStatus() (*idl.ServiceStatus, error) {
@@ -105,6 +213,7 @@ func (s *Server) GetStatus() (*idl.ServiceStatus, error) {
 	}
 
 	status := platform.ParseServiceStatusMessage(message)
+	utils.AddProcessUsage(platform, &status)
 
 	return &status, nil
 }
@@ -116,3 +225,11 @@ func SetPlatform(p utils.Platform) {
 func ResetPlatform() {
 	platform = utils.GetPlatform()
 }
+
+func SetExecCommand(command exectest.Command) {
+	execCommand = command
+}
+
+func ResetExecCommand() {
+	execCommand = exec.Command
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	checkPostmaster = checkPostmasterFunc
+	diskUsage       = diskUsageFunc
+)
+
+// segmentState is what was last reported of a watched segment
+type segmentState struct {
+	checked  bool
+	down     bool
+	diskFull bool
+}
+
+// WatchSegments checks the segments at every interval and streams an event
+// whenever a segment goes down or up, or its disk fills up or is freed. It
+// only returns once the hub cancels the call or the stream fails.
+func (s *Server) WatchSegments(in *idl.WatchSegmentsRequest, stream idl.Agent_WatchSegmentsServer) error {
+	if in.Interval <= 0 {
+		return fmt.Errorf("invalid interval %d", in.Interval)
+	}
+
+	interval := time.Duration(in.Interval) * time.Second
+	gplog.Info("Watching %d segments every %s", len(in.Segments), interval)
+	defer gplog.Info("Stopped watching segments")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	states := make([]segmentState, len(in.Segments))
+	for {
+		for i, seg := range in.Segments {
+			for _, event := range checkSegment(seg, in.DiskUsageThreshold, &states[i], time.Now()) {
+				err := stream.Send(event)
+				if err != nil {
+					return fmt.Errorf("could not send segment event: %w", err)
+				}
+			}
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSegment returns the events of the segment since its previous check.
+// Nothing is reported of a segment found up with enough disk space on its
+// first check.
+func checkSegment(seg *idl.WatchedSegment, threshold int32, state *segmentState, now time.Time) []*idl.SegmentEvent {
+	events := make([]*idl.SegmentEvent, 0)
+	newEvent := func(eventType idl.SegmentEventType, format string, args ...interface{}) *idl.SegmentEvent {
+		return &idl.SegmentEvent{
+			Type:    eventType,
+			Segment: seg,
+			Time:    timestamppb.New(now),
+			Message: fmt.Sprintf(format, args...),
+		}
+	}
+
+	err := checkPostmaster(seg.DataDir)
+	down := err != nil
+	if down != state.down || (down && !state.checked) {
+		if down {
+			gplog.Warn("Segment %d with data directory %s is down: %s", seg.ContentId, seg.DataDir, err)
+			events = append(events, newEvent(idl.SegmentEventType_SEGMENT_DOWN, "segment %d with data directory %s is down: %s", seg.ContentId, seg.DataDir, err))
+		} else {
+			gplog.Info("Segment %d with data directory %s is up", seg.ContentId, seg.DataDir)
+			events = append(events, newEvent(idl.SegmentEventType_SEGMENT_UP, "segment %d with data directory %s is up", seg.ContentId, seg.DataDir))
+		}
+		state.down = down
+	}
+
+	if threshold > 0 {
+		usage, err := diskUsage(seg.DataDir)
+		if err != nil {
+			gplog.Debug("Could not get the disk usage of data directory %s: %s", seg.DataDir, err)
+		} else {
+			full := usage >= float64(threshold)
+			if full != state.diskFull || (full && !state.checked) {
+				var event *idl.SegmentEvent
+				if full {
+					gplog.Warn("Disk of data directory %s is %.1f%% full", seg.DataDir, usage)
+					event = newEvent(idl.SegmentEventType_DISK_NEARLY_FULL, "disk of data directory %s is %.1f%% full", seg.DataDir, usage)
+				} else {
+					gplog.Info("Disk of data directory %s is %.1f%% full", seg.DataDir, usage)
+					event = newEvent(idl.SegmentEventType_DISK_OK, "disk of data directory %s is %.1f%% full", seg.DataDir, usage)
+				}
+				event.DiskUsage = usage
+				events = append(events, event)
+				state.diskFull = full
+			}
+		}
+	}
+
+	state.checked = true
+
+	return events
+}
+
+// checkPostmasterFunc returns an error unless the process of the postmaster.pid
+// file of the data directory is running
+func checkPostmasterFunc(dataDir string) error {
+	file, err := os.Open(filepath.Join(dataDir, "postmaster.pid"))
+	if err != nil {
+		return fmt.Errorf("could not read postmaster pid: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return errors.New("postmaster pid file is empty")
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	if err != nil || pid <= 0 {
+		return fmt.Errorf("invalid postmaster pid %q", scanner.Text())
+	}
+
+	// signal 0 only checks that the process exists, which it does when owned
+	// by another user
+	err = syscall.Kill(pid, 0)
+	if err != nil && !errors.Is(err, syscall.EPERM) {
+		return fmt.Errorf("postmaster with pid %d is not running: %w", pid, err)
+	}
+
+	return nil
+}
+
+// diskUsageFunc returns the percentage of the file system of the directory
+// used, from statfs as df computes it
+func diskUsageFunc(dir string) (float64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(dir, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	used := uint64(stat.Blocks) - uint64(stat.Bfree)
+	total := used + uint64(stat.Bavail)
+	if total == 0 {
+		return 0, nil
+	}
+
+	return float64(used) * 100 / float64(total), nil
+}
+
+func SetCheckPostmaster(check func(dataDir string) error) {
+	checkPostmaster = check
+}
+
+func ResetCheckPostmaster() {
+	checkPostmaster = checkPostmasterFunc
+}
+
+func SetDiskUsage(usage func(dir string) (float64, error)) {
+	diskUsage = usage
+}
+
+func ResetDiskUsage() {
+	diskUsage = diskUsageFunc
+}
@@ -0,0 +1,164 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/agent"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc"
+)
+
+// mockWatchSegmentsStream records the events sent by the agent and cancels
+// the call once it got the expected number of them
+type mockWatchSegmentsStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	cancel   func()
+	expected int
+	Events   []*idl.SegmentEvent
+}
+
+func newMockWatchSegmentsStream(expected int) *mockWatchSegmentsStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	if expected == 0 {
+		cancel()
+	}
+
+	return &mockWatchSegmentsStream{ctx: ctx, cancel: cancel, expected: expected}
+}
+
+func (m *mockWatchSegmentsStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockWatchSegmentsStream) Send(event *idl.SegmentEvent) error {
+	m.Events = append(m.Events, event)
+	if len(m.Events) >= m.expected {
+		m.cancel()
+	}
+
+	return nil
+}
+
+// eventSummaries returns the type and data directory of the events
+func eventSummaries(events []*idl.SegmentEvent) []string {
+	summaries := make([]string, 0, len(events))
+	for _, event := range events {
+		summaries = append(summaries, fmt.Sprintf("%s %s", event.Type, event.Segment.DataDir))
+	}
+
+	return summaries
+}
+
+func TestWatchSegments(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	segments := []*idl.WatchedSegment{
+		{DataDir: "/data/primary/gpseg0", ContentId: 0, Dbid: 2, Port: 6000},
+		{DataDir: "/data/primary/gpseg1", ContentId: 1, Dbid: 3, Port: 6001},
+	}
+
+	t.Run("reports the segments that are down or nearly full on the first check", func(t *testing.T) {
+		agent.SetCheckPostmaster(func(dataDir string) error {
+			if dataDir == "/data/primary/gpseg1" {
+				return errors.New("postmaster with pid 123 is not running")
+			}
+			return nil
+		})
+		defer agent.ResetCheckPostmaster()
+		agent.SetDiskUsage(func(dir string) (float64, error) {
+			if dir == "/data/primary/gpseg0" {
+				return 95.5, nil
+			}
+			return 40, nil
+		})
+		defer agent.ResetDiskUsage()
+
+		stream := newMockWatchSegmentsStream(2)
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.WatchSegments(&idl.WatchSegmentsRequest{Segments: segments, Interval: 1, DiskUsageThreshold: 90}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"DISK_NEARLY_FULL /data/primary/gpseg0", "SEGMENT_DOWN /data/primary/gpseg1"}
+		if !reflect.DeepEqual(eventSummaries(stream.Events), expected) {
+			t.Fatalf("got %+v, want %+v", eventSummaries(stream.Events), expected)
+		}
+		if stream.Events[0].DiskUsage != 95.5 || stream.Events[0].Time == nil {
+			t.Fatalf("got %+v, want the disk usage and time of the event", stream.Events[0])
+		}
+		expectedMessage := "segment 1 with data directory /data/primary/gpseg1 is down: postmaster with pid 123 is not running"
+		if stream.Events[1].Message != expectedMessage {
+			t.Fatalf("got %q, want %q", stream.Events[1].Message, expectedMessage)
+		}
+	})
+
+	t.Run("reports the segments whose state changed since the previous check", func(t *testing.T) {
+		checks := 0
+		agent.SetCheckPostmaster(func(dataDir string) error {
+			checks++
+			if checks <= len(segments) {
+				return nil
+			}
+			if dataDir == "/data/primary/gpseg0" {
+				return errors.New("postmaster with pid 123 is not running")
+			}
+			return nil
+		})
+		defer agent.ResetCheckPostmaster()
+
+		stream := newMockWatchSegmentsStream(1)
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.WatchSegments(&idl.WatchSegmentsRequest{Segments: segments, Interval: 1}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"SEGMENT_DOWN /data/primary/gpseg0"}
+		if !reflect.DeepEqual(eventSummaries(stream.Events), expected) {
+			t.Fatalf("got %+v, want %+v", eventSummaries(stream.Events), expected)
+		}
+	})
+
+	t.Run("checks the postmaster process of the data directories", func(t *testing.T) {
+		running := t.TempDir()
+		err := os.WriteFile(filepath.Join(running, "postmaster.pid"), []byte(fmt.Sprintf("%d\n%s\n", os.Getpid(), running)), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		stopped := t.TempDir()
+
+		stream := newMockWatchSegmentsStream(0)
+		agentServer := agent.New(agent.Config{})
+		err = agentServer.WatchSegments(&idl.WatchSegmentsRequest{
+			Segments: []*idl.WatchedSegment{{DataDir: running}, {DataDir: stopped}},
+			Interval: 1,
+		}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{fmt.Sprintf("SEGMENT_DOWN %s", stopped)}
+		if !reflect.DeepEqual(eventSummaries(stream.Events), expected) {
+			t.Fatalf("got %+v, want %+v", eventSummaries(stream.Events), expected)
+		}
+	})
+
+	t.Run("errors out when the interval is invalid", func(t *testing.T) {
+		agentServer := agent.New(agent.Config{})
+		err := agentServer.WatchSegments(&idl.WatchSegmentsRequest{Segments: segments}, newMockWatchSegmentsStream(0))
+
+		expected := "invalid interval 0"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
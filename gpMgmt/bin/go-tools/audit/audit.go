@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/constants"
+)
+
+const (
+	SuccessOutcome = "success"
+	FailureOutcome = "failure"
+	DeniedOutcome  = "denied" // rejected by the authorization of the hub
+)
+
+// Entry is the record of one RPC changing the state of a service or of the
+// cluster, written as a single JSON line
+type Entry struct {
+	Time        time.Time `json:"time" yaml:"time"`
+	Service     string    `json:"service" yaml:"service"` // hub or agent
+	Host        string    `json:"host" yaml:"host"`       // host of the service
+	Caller      string    `json:"caller" yaml:"caller"`
+	Method      string    `json:"method" yaml:"method"`
+	Request     string    `json:"request,omitempty" yaml:"request,omitempty"`
+	Targets     []string  `json:"targets,omitempty" yaml:"targets,omitempty"`
+	Outcome     string    `json:"outcome" yaml:"outcome"`
+	Error       string    `json:"error,omitempty" yaml:"error,omitempty"`
+	FailedHosts []string  `json:"failedHosts,omitempty" yaml:"failedHosts,omitempty"`
+	DurationMs  int64     `json:"durationMs" yaml:"durationMs"`
+}
+
+// Logger appends the audited RPCs of a service to the audit log in its log
+// directory. Failing to write an entry is logged, but does not fail the RPC.
+type Logger struct {
+	path    string
+	service string
+	host    string
+
+	// audited returns whether the RPC with the full method name is recorded,
+	// and targets the hosts it acts on
+	audited func(method string) bool
+	targets func(method string) []string
+
+	mutex sync.Mutex
+}
+
+// NewLogger returns the audit logger of the service, or nil when there is no
+// log directory, in which case nothing is recorded
+func NewLogger(logDir string, service string, audited func(method string) bool, targets func(method string) []string) *Logger {
+	if logDir == "" {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+	return &Logger{
+		path:    Path(logDir),
+		service: service,
+		host:    host,
+		audited: audited,
+		targets: targets,
+	}
+}
+
+// Path returns the audit log of the services logging to logDir
+func Path(logDir string) string {
+	return filepath.Join(logDir, constants.AuditLogFileName)
+}
+
+// Log appends the entry, completed with the service and host of the logger
+func (l *Logger) Log(entry Entry) {
+	entry.Service = l.service
+	entry.Host = l.host
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		gplog.Warn("Could not record %s in the audit log: %s", entry.Method, err)
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		gplog.Warn("Could not record %s in the audit log: %s", entry.Method, err)
+		return
+	}
+	defer file.Close()
+
+	// a single write per entry, so that entries of services sharing the log
+	// directory are not interleaved
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		gplog.Warn("Could not record %s in the audit log: %s", entry.Method, err)
+	}
+}
+
+// Filter selects audit entries. Empty fields match every entry.
+type Filter struct {
+	Since  time.Time
+	Until  time.Time
+	Method string // part of the RPC method name
+	Caller string // part of the caller identity
+	Host   string // host of the service, or one of the targets
+	Failed bool   // only failed or denied RPCs
+}
+
+func (f Filter) Matches(entry Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if !strings.Contains(entry.Method, f.Method) || !strings.Contains(entry.Caller, f.Caller) {
+		return false
+	}
+	if f.Failed && entry.Outcome == SuccessOutcome {
+		return false
+	}
+
+	if f.Host == "" || entry.Host == f.Host {
+		return true
+	}
+	for _, target := range entry.Targets {
+		if target == f.Host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Read returns the entries of the audit log matching the filter. Lines that
+// can not be parsed, such as a last line cut short, are skipped with a warning.
+func Read(reader io.Reader, filter Filter) ([]Entry, error) {
+	entries := make([]Entry, 0)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		err := json.Unmarshal(line, &entry)
+		if err != nil {
+			gplog.Warn("Skipping invalid audit entry on line %d: %s", lineNumber, err)
+			continue
+		}
+
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read audit log: %w", err)
+	}
+
+	return entries, nil
+}
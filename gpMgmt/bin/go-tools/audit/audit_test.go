@@ -0,0 +1,299 @@
+package audit_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/greenplum-db/gpdb/gp/audit"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+type hostsError struct {
+	hosts []string
+}
+
+func (e *hostsError) Error() string {
+	return "failed on " + strings.Join(e.hosts, ", ")
+}
+
+func (e *hostsError) FailedHosts() []string {
+	return e.hosts
+}
+
+// readEntries returns every entry of the audit log in logDir
+func readEntries(t *testing.T, logDir string) []audit.Entry {
+	t.Helper()
+
+	file, err := os.Open(audit.Path(logDir))
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer file.Close()
+
+	entries, err := audit.Read(file, audit.Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return entries
+}
+
+func newLogger(logDir string) *audit.Logger {
+	audited := func(method string) bool {
+		return method != "/idl.Hub/StatusAgents"
+	}
+	targets := func(method string) []string {
+		return []string{"sdw1", "sdw2"}
+	}
+
+	return audit.NewLogger(logDir, "hub", audited, targets)
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer secret"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/idl.Hub/StopAgents"}
+
+	t.Run("records the audited requests", func(t *testing.T) {
+		logDir := t.TempDir()
+		logger := newLogger(logDir)
+
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &idl.StopAgentsReply{}, nil
+		}
+		_, err := logger.UnaryInterceptor(ctx, &idl.StopClusterRequest{CoordinatorPort: 5432}, info, handler)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		entries := readEntries(t, logDir)
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(entries))
+		}
+		entry := entries[0]
+		if entry.Service != "hub" || entry.Method != "/idl.Hub/StopAgents" || entry.Outcome != audit.SuccessOutcome {
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+		if !strings.HasPrefix(entry.Caller, "token 2bb80d53 from 10.0.0.1:1234") {
+			t.Fatalf("unexpected caller %s", entry.Caller)
+		}
+		if !strings.Contains(entry.Request, `"coordinatorPort":5432`) {
+			t.Fatalf("unexpected request summary %s", entry.Request)
+		}
+		if !reflect.DeepEqual(entry.Targets, []string{"sdw1", "sdw2"}) {
+			t.Fatalf("got targets %+v, want [sdw1 sdw2]", entry.Targets)
+		}
+	})
+
+	t.Run("records the failed and denied requests", func(t *testing.T) {
+		logDir := t.TempDir()
+		logger := newLogger(logDir)
+
+		for _, err := range []error{
+			&hostsError{hosts: []string{"sdw2"}},
+			grpcStatus.Error(codes.PermissionDenied, "not allowed"),
+		} {
+			err := err
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, err
+			}
+			_, result := logger.UnaryInterceptor(ctx, &idl.StopAgentsRequest{}, info, handler)
+			if result != err {
+				t.Fatalf("got %v, want %v", result, err)
+			}
+		}
+
+		entries := readEntries(t, logDir)
+		if len(entries) != 2 {
+			t.Fatalf("got %d entries, want 2", len(entries))
+		}
+		if entries[0].Outcome != audit.FailureOutcome || !reflect.DeepEqual(entries[0].FailedHosts, []string{"sdw2"}) || entries[0].Error != "failed on sdw2" {
+			t.Fatalf("unexpected entry %+v", entries[0])
+		}
+		if entries[1].Outcome != audit.DeniedOutcome {
+			t.Fatalf("unexpected entry %+v", entries[1])
+		}
+	})
+
+	t.Run("does not record the other requests", func(t *testing.T) {
+		logDir := t.TempDir()
+		logger := newLogger(logDir)
+
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		}
+		_, err := logger.UnaryInterceptor(ctx, &idl.StatusAgentsRequest{}, &grpc.UnaryServerInfo{FullMethod: "/idl.Hub/StatusAgents"}, handler)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		_, err = os.Stat(audit.Path(logDir))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected no audit log, got %v", err)
+		}
+	})
+
+	t.Run("records nothing without a log directory", func(t *testing.T) {
+		logger := newLogger("")
+		if logger != nil {
+			t.Fatalf("expected no logger")
+		}
+
+		called := false
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		}
+		_, err := logger.UnaryInterceptor(ctx, &idl.StopAgentsRequest{}, info, handler)
+		if err != nil || !called {
+			t.Fatalf("expected the handler to be called, got %v", err)
+		}
+	})
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	requests []*idl.PushFileRequest
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *mockServerStream) RecvMsg(m interface{}) error {
+	if len(s.requests) == 0 {
+		return errors.New("EOF")
+	}
+	proto.Merge(m.(*idl.PushFileRequest), s.requests[0])
+	s.requests = s.requests[1:]
+
+	return nil
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("summarizes the first message of the stream", func(t *testing.T) {
+		logDir := t.TempDir()
+		logger := audit.NewLogger(logDir, "agent", func(string) bool { return true }, nil)
+
+		stream := &mockServerStream{requests: []*idl.PushFileRequest{
+			{Request: &idl.PushFileRequest_Header{Header: &idl.FileHeader{Path: "/gphome/gp.conf"}}},
+			{Request: &idl.PushFileRequest_Chunk{Chunk: []byte("contents")}},
+		}}
+		handler := func(srv interface{}, stream grpc.ServerStream) error {
+			for i := 0; i < 2; i++ {
+				err := stream.RecvMsg(new(idl.PushFileRequest))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		err := logger.StreamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/idl.Agent/PushFile"}, handler)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		entries := readEntries(t, logDir)
+		if len(entries) != 1 || !strings.Contains(entries[0].Request, "/gphome/gp.conf") || entries[0].Targets != nil {
+			t.Fatalf("unexpected entries %+v", entries)
+		}
+	})
+}
+
+func TestCaller(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "gp-client"}, DNSNames: []string{"cdw"}}
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}
+
+	cases := []struct {
+		name     string
+		state    *tls.ConnectionState
+		expected string
+	}{
+		{"verified certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}, "client gp-client from 10.0.0.1:1234"},
+		{"unverified certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, "unverified client gp-client from 10.0.0.1:1234"},
+		{"no certificate", &tls.ConnectionState{}, "unidentified client from 10.0.0.1:1234"},
+		{"no TLS", nil, "unidentified client from 10.0.0.1:1234"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &peer.Peer{Addr: addr}
+			if tc.state != nil {
+				p.AuthInfo = credentials.TLSInfo{State: *tc.state}
+			}
+
+			result := audit.Caller(peer.NewContext(context.Background(), p))
+			if result != tc.expected {
+				t.Fatalf("got %s, want %s", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	log := strings.Join([]string{
+		`{"time":"2024-01-02T01:00:00Z","service":"hub","host":"cdw","caller":"client admin","method":"/idl.Hub/StopAgents","targets":["sdw1","sdw2"],"outcome":"success","durationMs":10}`,
+		`not json`,
+		`{"time":"2024-01-02T02:00:00Z","service":"agent","host":"sdw1","caller":"client cdw","method":"/idl.Agent/Stop","outcome":"failure","durationMs":1}`,
+		``,
+		`{"time":"2024-01-02T03:00:00Z","service":"hub","host":"cdw","caller":"token 2bb80d53","method":"/idl.Hub/StopCluster","outcome":"denied","durationMs":0}`,
+	}, "\n")
+
+	cases := []struct {
+		name     string
+		filter   audit.Filter
+		expected []string
+	}{
+		{"no filter", audit.Filter{}, []string{"/idl.Hub/StopAgents", "/idl.Agent/Stop", "/idl.Hub/StopCluster"}},
+		{"since", audit.Filter{Since: now.Add(-2 * time.Hour)}, []string{"/idl.Agent/Stop", "/idl.Hub/StopCluster"}},
+		{"until", audit.Filter{Until: now.Add(-2 * time.Hour)}, []string{"/idl.Hub/StopAgents"}},
+		{"method", audit.Filter{Method: "Stop"}, []string{"/idl.Hub/StopAgents", "/idl.Agent/Stop", "/idl.Hub/StopCluster"}},
+		{"caller", audit.Filter{Caller: "token"}, []string{"/idl.Hub/StopCluster"}},
+		{"host of the service", audit.Filter{Host: "cdw"}, []string{"/idl.Hub/StopAgents", "/idl.Hub/StopCluster"}},
+		{"target host", audit.Filter{Host: "sdw2"}, []string{"/idl.Hub/StopAgents"}},
+		{"failed", audit.Filter{Failed: true}, []string{"/idl.Agent/Stop", "/idl.Hub/StopCluster"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := audit.Read(strings.NewReader(log), tc.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+
+			methods := []string{}
+			for _, entry := range entries {
+				methods = append(methods, entry.Method)
+			}
+			if !reflect.DeepEqual(methods, tc.expected) {
+				t.Fatalf("got %+v, want %+v", methods, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxRequestLength = 512
+
+// HostsError is implemented by errors carrying the hosts on which an RPC failed
+type HostsError interface {
+	error
+	FailedHosts() []string
+}
+
+// UnaryInterceptor records the audited unary RPCs. It must run before any
+// interceptor rejecting requests, so that the rejected ones are recorded too.
+func (l *Logger) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if l == nil || !l.audited(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	l.record(ctx, info.FullMethod, req, start, err)
+
+	return resp, err
+}
+
+// StreamInterceptor records the audited streaming RPCs, summarizing the first
+// message received from the client as the request
+func (l *Logger) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if l == nil || !l.audited(info.FullMethod) {
+		return handler(srv, stream)
+	}
+
+	start := time.Now()
+	recorded := &recordingStream{ServerStream: stream}
+	err := handler(srv, recorded)
+	l.record(stream.Context(), info.FullMethod, recorded.first, start, err)
+
+	return err
+}
+
+func (l *Logger) record(ctx context.Context, method string, req interface{}, start time.Time, err error) {
+	entry := Entry{
+		Time:       start.UTC(),
+		Caller:     Caller(ctx),
+		Method:     method,
+		Request:    summarize(req),
+		Outcome:    SuccessOutcome,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if l.targets != nil {
+		entry.Targets = l.targets(method)
+	}
+
+	if err != nil {
+		entry.Outcome = FailureOutcome
+		if grpcStatus.Code(err) == codes.PermissionDenied {
+			entry.Outcome = DeniedOutcome
+		}
+		entry.Error = err.Error()
+
+		var hostsErr HostsError
+		if errors.As(err, &hostsErr) {
+			entry.FailedHosts = hostsErr.FailedHosts()
+		}
+	}
+
+	l.Log(entry)
+}
+
+// Caller describes the client of the request: the fingerprint of its bearer
+// token, or else the name of its certificate, followed by its address
+func Caller(ctx context.Context) string {
+	identity := utils.IncomingClientIdentity(ctx).String()
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		identity = fmt.Sprintf("%s from %s", identity, p.Addr)
+	}
+
+	return identity
+}
+
+// summarize renders the request as compact JSON, shortened if needed
+func summarize(req interface{}) string {
+	message, ok := req.(proto.Message)
+	if !ok || message == nil {
+		return ""
+	}
+
+	summary, err := protojson.Marshal(message)
+	if err != nil {
+		return ""
+	}
+	if len(summary) > maxRequestLength {
+		return string(summary[:maxRequestLength]) + "..."
+	}
+
+	return string(summary)
+}
+
+// recordingStream keeps the first message received on the stream
+type recordingStream struct {
+	grpc.ServerStream
+	first interface{}
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.first == nil {
+		s.first = m
+	}
+
+	return err
+}
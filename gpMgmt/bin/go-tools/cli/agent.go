@@ -19,7 +19,7 @@ func agentCmd() *cobra.Command {
 }
 
 func RunAgent(cmd *cobra.Command, args []string) (err error) {
-	agentConf := agent.Config{Port: Conf.AgentPort, ServiceName: Conf.ServiceName, Credentials: Conf.Credentials}
+	agentConf := agent.Config{Port: Conf.AgentPort, ServiceName: Conf.ServiceName, GpHome: Conf.GpHome, LogDir: Conf.LogDir, ConfigFile: ConfigFilePath, MetricsPort: Conf.AgentMetricsPort(), Credentials: Conf.Credentials}
 	a := agent.New(agentConf)
 	err = a.Start()
 	if err != nil {
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/audit"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ShowAudit = ShowAuditFunc
+
+	auditSince  string
+	auditUntil  string
+	auditMethod string
+	auditCaller string
+	auditHost   string
+	auditFailed bool
+	auditAgents bool
+	auditJSON   bool // deprecated, same as --format json
+)
+
+func auditCmd() *cobra.Command {
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Review the requests changing the services or the cluster",
+	}
+
+	auditCmd.AddCommand(auditShowCmd())
+
+	return auditCmd
+}
+
+func auditShowCmd() *cobra.Command {
+	auditShowCmd := &cobra.Command{
+		Use:     "show",
+		Short:   "Show the audit log of the hub, and optionally of the agents",
+		PreRunE: InitializeCommand,
+		RunE:    RunAuditShow,
+	}
+
+	auditShowCmd.Flags().StringVar(&auditSince, "since", "", `Only show requests since the time, given as RFC3339 or as a duration before now such as 24h`)
+	auditShowCmd.Flags().StringVar(&auditUntil, "until", "", `Only show requests until the time, given as RFC3339 or as a duration before now such as 1h`)
+	auditShowCmd.Flags().StringVar(&auditMethod, "method", "", `Only show requests whose RPC contains the text, e.g. StopAgents`)
+	auditShowCmd.Flags().StringVar(&auditCaller, "caller", "", `Only show requests whose caller contains the text`)
+	auditShowCmd.Flags().StringVar(&auditHost, "host", "", `Only show requests handled by or targeting the host`)
+	auditShowCmd.Flags().BoolVar(&auditFailed, "failed", false, `Only show failed or denied requests`)
+	auditShowCmd.Flags().BoolVar(&auditAgents, "agents", false, `Also show the requests handled by the agents, read from every host over SSH`)
+	auditShowCmd.Flags().BoolVar(&auditJSON, "json", false, `Print the matching entries as JSON`)
+	_ = auditShowCmd.Flags().MarkDeprecated("json", "use --format json instead")
+	addFormatFlag(auditShowCmd)
+	auditShowCmd.MarkFlagsMutuallyExclusive("json", "format")
+
+	return auditShowCmd
+}
+
+func RunAuditShow(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	filter := audit.Filter{
+		Method: auditMethod,
+		Caller: auditCaller,
+		Host:   auditHost,
+		Failed: auditFailed,
+	}
+
+	var err error
+	if auditSince != "" {
+		filter.Since, err = parseAuditTime(auditSince, now)
+		if err != nil {
+			return err
+		}
+	}
+	if auditUntil != "" {
+		filter.Until, err = parseAuditTime(auditUntil, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	format := outputFormat
+	if auditJSON {
+		format = "json"
+	}
+	renderer, err := utils.NewRenderer(format)
+	if err != nil {
+		return err
+	}
+
+	return ShowAudit(Conf, filter, auditAgents, renderer)
+}
+
+// parseAuditTime accepts either a time or a duration counted back from now
+func parseAuditTime(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected a duration such as 24h or a time such as 2006-01-02T15:04:05Z", value)
+	}
+
+	return t, nil
+}
+
+// ShowAuditFunc renders the entries of the audit log of the hub matching the
+// filter, along with the ones of the agents if asked to, ordered by time
+func ShowAuditFunc(conf *hub.Config, filter audit.Filter, includeAgents bool, renderer utils.Renderer) error {
+	entries, err := readLocalAudit(conf.LogDir, filter)
+	if err != nil {
+		return err
+	}
+
+	var agentsErr error
+	if includeAgents {
+		var agentEntries []audit.Entry
+		agentEntries, agentsErr = readAgentsAudit(conf, filter)
+		entries = append(entries, agentEntries...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	err = renderer.Render(os.Stdout, auditReport(entries))
+	if err != nil {
+		return err
+	}
+
+	return agentsErr
+}
+
+func readLocalAudit(logDir string, filter audit.Filter) ([]audit.Entry, error) {
+	file, err := os.Open(audit.Path(logDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []audit.Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	return audit.Read(file, filter)
+}
+
+// readAgentsAudit reads the agent entries of the audit logs of the other
+// hosts. The agent of the local host shares the audit log of the hub.
+func readAgentsAudit(conf *hub.Config, filter audit.Filter) ([]audit.Entry, error) {
+	hubHost, err := Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	hosts := make([]string, 0, len(conf.Hostnames))
+	for _, host := range conf.Hostnames {
+		if host != hubHost {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	executor, err := NewRemoteExecutor()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the audit logs of the agents: %w", err)
+	}
+
+	path := remote.Quote(audit.Path(conf.LogDir))
+	results := executor.Run(hosts, fmt.Sprintf("test ! -f %[1]s || cat %[1]s", path))
+
+	entries := make([]audit.Entry, 0)
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		hostEntries, err := audit.Read(strings.NewReader(result.Stdout), filter)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the audit log of host %s: %w", result.Hostname, err)
+		}
+		for _, entry := range hostEntries {
+			if entry.Service == string(utils.AgentRole) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	err = results.Err()
+	if err != nil {
+		return entries, fmt.Errorf("could not read the audit logs of the agents: %w", err)
+	}
+
+	return entries, nil
+}
+
+func auditReport(entries []audit.Entry) *utils.Report {
+	report := utils.NewReport("TIME", "SERVICE", "HOST", "CALLER", "METHOD", "OUTCOME", "DURATION")
+	for _, entry := range entries {
+		outcome := entry.Outcome
+		if len(entry.FailedHosts) > 0 {
+			outcome = fmt.Sprintf("%s on %s", outcome, strings.Join(entry.FailedHosts, ", "))
+		}
+
+		duration := time.Duration(entry.DurationMs) * time.Millisecond
+		report.Add(entry, entry.Time.UTC().Format(time.RFC3339), entry.Service, entry.Host, entry.Caller,
+			strings.TrimPrefix(entry.Method, "/idl."), outcome, duration.String())
+	}
+
+	return report
+}
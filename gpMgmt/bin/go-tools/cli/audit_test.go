@@ -0,0 +1,170 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gpdb/gp/audit"
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+// captureStdout returns what the function prints to stdout
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	output := make(chan string)
+	go func() {
+		contents, _ := io.ReadAll(reader)
+		output <- string(contents)
+	}()
+
+	f()
+	writer.Close()
+
+	return <-output
+}
+
+func TestShowAudit(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	hubLog := strings.Join([]string{
+		`{"time":"2024-01-02T01:00:00Z","service":"hub","host":"cdw","caller":"client admin","method":"/idl.Hub/StopAgents","outcome":"success","durationMs":10}`,
+		`{"time":"2024-01-02T03:00:00Z","service":"agent","host":"cdw","caller":"client cdw","method":"/idl.Agent/Stop","outcome":"success","durationMs":1}`,
+	}, "\n")
+	agentLogs := map[string]string{
+		// the audit log of the hub host is read locally
+		"cdw":  `{"time":"2024-01-02T00:00:00Z","service":"hub","host":"cdw","caller":"client admin","method":"/idl.Hub/Stop","outcome":"success","durationMs":1}`,
+		"sdw1": `{"time":"2024-01-02T02:00:00Z","service":"agent","host":"sdw1","caller":"client cdw","method":"/idl.Agent/Stop","outcome":"failure","durationMs":1}`,
+	}
+
+	setupAudit := func(t *testing.T) *hub.Config {
+		logDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(logDir, constants.AuditLogFileName), []byte(hubLog), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+
+		return &hub.Config{Hostnames: []string{"cdw", "sdw1"}, LogDir: logDir}
+	}
+
+	entryMethods := func(t *testing.T, output string) []string {
+		var entries []audit.Entry
+		err := json.Unmarshal([]byte(output), &entries)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		methods := []string{}
+		for _, entry := range entries {
+			methods = append(methods, entry.Host+" "+entry.Method)
+		}
+
+		return methods
+	}
+
+	t.Run("shows the audit log of the hub host", func(t *testing.T) {
+		defer resetCLIVars()
+		conf := setupAudit(t)
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.ShowAudit(conf, audit.Filter{}, false, utils.JSONRenderer{})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"cdw /idl.Hub/StopAgents", "cdw /idl.Agent/Stop"}
+		if methods := entryMethods(t, output); !reflect.DeepEqual(methods, expected) {
+			t.Fatalf("got %+v, want %+v", methods, expected)
+		}
+	})
+
+	t.Run("shows the audit logs of the agents ordered by time", func(t *testing.T) {
+		defer resetCLIVars()
+		conf := setupAudit(t)
+		executor := &testutils.MockExecutor{
+			Stdout: func(host string, command string) string {
+				return agentLogs[host]
+			},
+		}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.ShowAudit(conf, audit.Filter{Method: "Stop"}, true, utils.JSONRenderer{})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"cdw /idl.Hub/StopAgents", "sdw1 /idl.Agent/Stop", "cdw /idl.Agent/Stop"}
+		if methods := entryMethods(t, output); !reflect.DeepEqual(methods, expected) {
+			t.Fatalf("got %+v, want %+v", methods, expected)
+		}
+		expectedCommands := []string{"test ! -f " + audit.Path(conf.LogDir) + " || cat " + audit.Path(conf.LogDir)}
+		if !reflect.DeepEqual(executor.Commands, expectedCommands) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expectedCommands)
+		}
+	})
+
+	t.Run("shows the entries read before returning the hosts that failed", func(t *testing.T) {
+		defer resetCLIVars()
+		conf := setupAudit(t)
+		executor := &testutils.MockExecutor{
+			Err: func(host string, command string) error {
+				return errors.New("could not connect")
+			},
+		}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.ShowAudit(conf, audit.Filter{}, true, utils.TableRenderer{})
+		})
+		if err == nil || !strings.Contains(err.Error(), "host sdw1: could not connect") {
+			t.Fatalf("got %v, want a failure on sdw1", err)
+		}
+		if !strings.Contains(output, "Hub/StopAgents") {
+			t.Fatalf("expected the entries of the hub in %s", output)
+		}
+	})
+
+	t.Run("shows no entries when there is no audit log", func(t *testing.T) {
+		defer resetCLIVars()
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.ShowAudit(&hub.Config{LogDir: t.TempDir()}, audit.Filter{}, false, utils.JSONRenderer{})
+		})
+		if err != nil || output != "[]\n" {
+			t.Fatalf("got %q, %v, want no entries", output, err)
+		}
+	})
+}
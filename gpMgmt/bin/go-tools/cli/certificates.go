@@ -0,0 +1,603 @@
+package cli
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ReloadCredentials    = ReloadCredentialsFunc
+	GenerateCertificates = GenerateCertificatesFunc
+	CheckCertificates    = CheckCertificatesFunc
+	NewRemoteExecutor    = remote.NewExecutor
+	Hostname             = os.Hostname
+
+	certificatesDir   string
+	certificateDays   int
+	caCertificateDays int
+	newCA             bool
+	expiryWarningDays int
+)
+
+const (
+	caCertFileName     = "ca-cert.pem"
+	caKeyFileName      = "ca-key.pem"
+	serverCertFileName = "server-cert.pem"
+	serverKeyFileName  = "server-key.pem"
+)
+
+func certificatesCmd() *cobra.Command {
+	certificatesCmd := &cobra.Command{
+		Use:   "certificates",
+		Short: "Manage the TLS certificates of the hub and agents",
+	}
+
+	certificatesCmd.AddCommand(
+		certificatesGenerateCmd(),
+		certificatesCheckCmd(),
+		certificatesReloadCmd(),
+	)
+
+	return certificatesCmd
+}
+
+func certificatesGenerateCmd() *cobra.Command {
+	certificatesGenerateCmd := &cobra.Command{
+		Use:     "generate",
+		Short:   "Generate the certificates of the hub and every agent host and copy them to the hosts",
+		PreRunE: InitializeCommand,
+		RunE:    RunCertificatesGenerate,
+	}
+
+	certificatesGenerateCmd.Flags().StringVar(&certificatesDir, "directory", "", `Directory to write the certificates to on every host, defaults to the one of the certificates previously generated or <gphome>/certificates`)
+	certificatesGenerateCmd.Flags().IntVar(&certificateDays, "days", 365, `Number of days the hub and agent certificates are valid`)
+	certificatesGenerateCmd.Flags().IntVar(&caCertificateDays, "ca-days", 3650, `Number of days the CA certificate is valid`)
+	certificatesGenerateCmd.Flags().BoolVar(&newCA, "new-ca", false, `Create a new CA even if one is configured`)
+
+	return certificatesGenerateCmd
+}
+
+func RunCertificatesGenerate(cmd *cobra.Command, args []string) error {
+	dir := certificatesDir
+	if dir == "" {
+		dir = filepath.Join(Conf.GpHome, "certificates")
+		// replacing the files in place lets the services reload them
+		if creds, ok := Conf.Credentials.(*utils.GpCredentials); ok {
+			if generated, ok := generatedCertificatesDir(creds); ok {
+				dir = generated
+			}
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving absolute path for %s: %w", dir, err)
+	}
+
+	return GenerateCertificates(Conf, dir, time.Duration(certificateDays)*24*time.Hour, time.Duration(caCertificateDays)*24*time.Hour, newCA)
+}
+
+func certificatesCheckCmd() *cobra.Command {
+	certificatesCheckCmd := &cobra.Command{
+		Use:     "check",
+		Short:   "Check the certificates of the hub and every agent host",
+		PreRunE: InitializeCommand,
+		RunE:    RunCertificatesCheck,
+	}
+
+	certificatesCheckCmd.Flags().IntVar(&expiryWarningDays, "warn-days", 30, `Warn about certificates expiring within this number of days`)
+	addFormatFlag(certificatesCheckCmd)
+
+	return certificatesCheckCmd
+}
+
+func RunCertificatesCheck(cmd *cobra.Command, args []string) error {
+	renderer, err := utils.NewRenderer(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	return CheckCertificates(Conf, time.Duration(expiryWarningDays)*24*time.Hour, renderer)
+}
+
+func certificatesReloadCmd() *cobra.Command {
+	certificatesReloadCmd := &cobra.Command{
+		Use:     "reload",
+		Short:   "Make the hub and agents use the current certificate files without restarting",
+		PreRunE: InitializeCommand,
+		RunE:    RunCertificatesReload,
+	}
+
+	addFormatFlag(certificatesReloadCmd)
+
+	return certificatesReloadCmd
+}
+
+func RunCertificatesReload(cmd *cobra.Command, args []string) error {
+	renderer, err := utils.NewRenderer(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	return ReloadCredentials(Conf, renderer)
+}
+
+// ReloadCredentialsFunc renders the certificates the services loaded
+func ReloadCredentialsFunc(conf *hub.Config, renderer utils.Renderer) error {
+	client, err := ConnectToHub(conf)
+	if err != nil {
+		return err
+	}
+
+	reply, err := client.ReloadCredentials(context.Background(), &idl.ReloadAllCredentialsRequest{})
+	if err != nil {
+		return hostError("could not reload credentials", err)
+	}
+
+	hostname, _ := os.Hostname()
+	report := certificatesReport("Hub", []*idl.ServiceStatus{{Host: hostname, Certificates: reply.HubCertificates}})
+	report.Append(certificatesReport("Agent", reply.Agents))
+
+	return renderer.Render(os.Stdout, report)
+}
+
+// CertificateRecord is a certificate loaded by a service as output by
+// gp certificates reload
+type CertificateRecord struct {
+	Role     string    `json:"role" yaml:"role"`
+	Host     string    `json:"host" yaml:"host"`
+	Path     string    `json:"path" yaml:"path"`
+	Subject  string    `json:"subject" yaml:"subject"`
+	NotAfter time.Time `json:"notAfter" yaml:"notAfter"`
+}
+
+func certificatesReport(serviceName string, statuses []*idl.ServiceStatus) *utils.Report {
+	report := utils.NewReport("ROLE", "HOST", "CERTIFICATE", "SUBJECT", "EXPIRES")
+	for _, status := range statuses {
+		for _, cert := range status.Certificates {
+			record := CertificateRecord{
+				Role:     serviceName,
+				Host:     status.Host,
+				Path:     cert.Path,
+				Subject:  cert.Subject,
+				NotAfter: cert.NotAfter.AsTime().UTC(),
+			}
+			report.Add(record, record.Role, record.Host, record.Path, record.Subject, record.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	return report
+}
+
+// GenerateCertificatesFunc issues a certificate for the hub and one for each
+// agent host, valid for its hostname, and writes them to dir on every host
+// along with the CA certificate. The configured CA is reused unless there is
+// none or newCA is set, in which case its key only stays on the hub host, and
+// the configured client certificate is only kept when it is signed by the CA
+// used. The configuration is then updated to use the new files, which the
+// services only reload in place when the paths did not change.
+func GenerateCertificatesFunc(conf *hub.Config, dir string, validity time.Duration, caValidity time.Duration, newCA bool) error {
+	creds, ok := conf.Credentials.(*utils.GpCredentials)
+	if !ok {
+		return errors.New("the configured credentials do not support generating certificates")
+	}
+	// the generated certificates are named after the hosts, which the clients
+	// allowed by name would then reject
+	if len(creds.AllowedHubClients) > 0 || len(creds.AllowedAgentClients) > 0 {
+		return errors.New("can not generate certificates when the allowed hub or agent clients are configured, run gp configure again without --allowed-hub-client and --allowed-agent-client first")
+	}
+
+	hubHost, err := Hostname()
+	if err != nil {
+		return fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	ca, caKeyPath, created, err := loadOrGenerateCA(creds, dir, caValidity, newCA)
+	if err != nil {
+		return err
+	}
+
+	// the services present their own certificate as client certificate
+	// unless one is configured and still signed by the CA
+	keepClientCert := creds.ClientCertPath != "" && !created
+	if !keepClientCert && conf.Authorization != nil && len(conf.Authorization.Clients) > 0 {
+		return errors.New("can not generate certificates when client roles are configured, as the roles are keyed by the names of the client certificates and the generated ones are named after the hosts, run gp configure again without --client-role first")
+	}
+
+	hubCert, err := ca.IssueCertificate(hubHost, []string{hubHost, "localhost", "127.0.0.1"}, validity)
+	if err != nil {
+		return fmt.Errorf("could not issue hub certificate: %w", err)
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+
+	files := certificateFiles(ca, hubCert)
+	if created {
+		files = append(files, certificateFile{caKeyFileName, ca.KeyPEM, 0600})
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		err = os.WriteFile(path, file.contents, file.mode)
+		if err != nil {
+			return fmt.Errorf("could not write %s: %w", path, err)
+		}
+	}
+	gplog.Info("Wrote the CA and hub certificates to %s", dir)
+
+	agentHosts := make([]string, 0)
+	for _, host := range conf.Hostnames {
+		if host != hubHost { // the agent on the hub host uses the hub certificate
+			agentHosts = append(agentHosts, host)
+		}
+	}
+
+	err = distributeCertificates(ca, agentHosts, dir, validity)
+	if err != nil {
+		return err
+	}
+
+	previous := *creds
+	creds.CACertPath = filepath.Join(dir, caCertFileName)
+	creds.CAKeyPath = caKeyPath
+	creds.ServerCertPath = filepath.Join(dir, serverCertFileName)
+	creds.ServerKeyPath = filepath.Join(dir, serverKeyFileName)
+	if creds.ClientCertPath != "" && !keepClientCert {
+		gplog.Warn("The client certificate %s is not signed by the new CA, the services now use their own certificate as client certificate", creds.ClientCertPath)
+		creds.ClientCertPath = ""
+		creds.ClientKeyPath = ""
+	}
+	err = WriteConfig(conf, ConfigFilePath)
+	if err != nil {
+		return err
+	}
+
+	gplog.Info("Generated certificates for the hub and %d agent hosts, valid until %s", len(agentHosts), hubCert.Cert.NotAfter.UTC().Format(time.RFC3339))
+	// the services only read the files again from the paths they started with
+	if previous.CACertPath == creds.CACertPath && previous.ServerCertPath == creds.ServerCertPath &&
+		previous.ServerKeyPath == creds.ServerKeyPath && previous.ClientCertPath == creds.ClientCertPath {
+		gplog.Info("Run \"gp certificates reload\" or restart the services for them to use the new certificates")
+	} else {
+		gplog.Info("Restart the services for them to use the new certificates, as their paths changed")
+	}
+
+	return nil
+}
+
+// certificateFile is written to the certificates directory of a host
+type certificateFile struct {
+	name     string
+	contents []byte
+	mode     os.FileMode
+}
+
+// certificateFiles lists the files a host needs. The key goes last, so that
+// an interrupted copy fails to load rather than pairing the new key with the
+// previous certificate.
+func certificateFiles(ca *utils.KeyPair, cert *utils.KeyPair) []certificateFile {
+	return []certificateFile{
+		{caCertFileName, ca.CertPEM, 0644},
+		{serverCertFileName, cert.CertPEM, 0644},
+		{serverKeyFileName, cert.KeyPEM, 0600},
+	}
+}
+
+// loadOrGenerateCA returns the configured CA with the path of its key, or a
+// new CA whose key is to be written to dir
+func loadOrGenerateCA(creds *utils.GpCredentials, dir string, validity time.Duration, newCA bool) (ca *utils.KeyPair, keyPath string, created bool, err error) {
+	if !newCA && creds.CACertPath != "" && creds.CAKeyPath != "" {
+		certPEM, err := os.ReadFile(creds.CACertPath)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("could not read CA certificate, use --new-ca to create a new CA: %w", err)
+		}
+
+		keyPEM, err := os.ReadFile(creds.CAKeyPath)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("could not read CA key, use --new-ca to create a new CA: %w", err)
+		}
+
+		ca, err := utils.LoadKeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("could not load CA, use --new-ca to create a new CA: %w", err)
+		}
+		gplog.Info("Using the CA %s", creds.CACertPath)
+
+		return ca, creds.CAKeyPath, false, nil
+	}
+
+	ca, err = utils.GenerateCA("Greenplum gp CA", validity)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("could not create CA: %w", err)
+	}
+	gplog.Info("Created a new CA")
+
+	return ca, filepath.Join(dir, caKeyFileName), true, nil
+}
+
+// distributeCertificates issues and copies the certificate of every agent
+// host, along with the CA certificate, in parallel
+func distributeCertificates(ca *utils.KeyPair, hosts []string, dir string, validity time.Duration) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	executor, err := NewRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not copy certificates to the agent hosts: %w", err)
+	}
+
+	err = executor.Run(hosts, remote.Command("mkdir", "-p", dir)).Err()
+	if err != nil {
+		return fmt.Errorf("could not create directory %s on the agent hosts: %w", dir, err)
+	}
+
+	var wg sync.WaitGroup
+	results := make(remote.Results, len(hosts))
+	for i, host := range hosts {
+		i, host := i, host
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = copyHostCertificates(executor, ca, host, dir, validity)
+		}()
+	}
+	wg.Wait()
+
+	err = results.Err()
+	if err != nil {
+		return fmt.Errorf("could not copy certificates to the agent hosts: %w", err)
+	}
+	gplog.Info("Copied the certificates to %d agent hosts", len(hosts))
+
+	return nil
+}
+
+// provideHostCertificates makes sure the new hosts have the certificates the
+// agents are configured with before they are. When these were written by gp
+// certificates generate and the key of the CA is at hand, the hosts are issued
+// certificates expiring with the one of the hub. Otherwise they must have been
+// copied to the hosts beforehand.
+func provideHostCertificates(conf *hub.Config, hosts []string) error {
+	creds, ok := conf.Credentials.(*utils.GpCredentials)
+	if !ok || creds.ServerCertPath == "" || len(hosts) == 0 {
+		return nil
+	}
+
+	dir, generated := generatedCertificatesDir(creds)
+	if generated && creds.CAKeyPath != "" {
+		certPEM, err := os.ReadFile(creds.CACertPath)
+		if err != nil {
+			return fmt.Errorf("could not read CA certificate: %w", err)
+		}
+		keyPEM, err := os.ReadFile(creds.CAKeyPath)
+		if err != nil {
+			return fmt.Errorf("could not read CA key: %w", err)
+		}
+		ca, err := utils.LoadKeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("could not load CA: %w", err)
+		}
+
+		hubPEM, err := os.ReadFile(creds.ServerCertPath)
+		if err != nil {
+			return fmt.Errorf("could not read hub certificate: %w", err)
+		}
+		hubCert, err := utils.ParseCertificate(hubPEM)
+		if err != nil {
+			return fmt.Errorf("invalid hub certificate %s: %w", creds.ServerCertPath, err)
+		}
+
+		return distributeCertificates(ca, hosts, dir, time.Until(hubCert.NotAfter))
+	}
+
+	executor, err := NewRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not check certificates on the new hosts: %w", err)
+	}
+
+	paths := []string{creds.CACertPath, creds.ServerCertPath, creds.ServerKeyPath}
+	tests := make([]string, len(paths))
+	for i, path := range paths {
+		tests[i] = fmt.Sprintf("test -f %s", remote.Quote(path))
+	}
+	results := executor.Run(hosts, strings.Join(tests, " && "))
+	missing := make([]string, 0)
+	for _, result := range results {
+		if result.Err != nil {
+			missing = append(missing, result.Hostname)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("hosts %s are missing the certificates %s, copy them there first", strings.Join(missing, ", "), strings.Join(paths, ", "))
+	}
+
+	return nil
+}
+
+// generatedCertificatesDir returns the directory of the configured
+// certificates when they are laid out as gp certificates generate writes them
+func generatedCertificatesDir(creds *utils.GpCredentials) (string, bool) {
+	if creds.ServerCertPath == "" {
+		return "", false
+	}
+
+	dir := filepath.Dir(creds.ServerCertPath)
+	generated := creds.CACertPath == filepath.Join(dir, caCertFileName) &&
+		creds.ServerCertPath == filepath.Join(dir, serverCertFileName) &&
+		creds.ServerKeyPath == filepath.Join(dir, serverKeyFileName)
+
+	return dir, generated
+}
+
+func copyHostCertificates(executor remote.Executor, ca *utils.KeyPair, host string, dir string, validity time.Duration) remote.Result {
+	cert, err := ca.IssueCertificate(host, []string{host}, validity)
+	if err != nil {
+		return remote.Result{Hostname: host, ExitCode: -1, Err: err}
+	}
+
+	for _, file := range certificateFiles(ca, cert) {
+		result := executor.Copy([]string{host}, file.contents, filepath.Join(dir, file.name), file.mode)[0]
+		if result.Err != nil {
+			return result
+		}
+	}
+
+	return remote.Result{Hostname: host}
+}
+
+type certificateCheck struct {
+	role     string
+	host     string
+	notAfter time.Time
+	warning  string
+	err      error
+}
+
+// CheckCertificatesFunc verifies on every host that the certificate chains to
+// the CA of the hub, is valid for the hostname and is not about to expire, and
+// that the host uses the same CA as the hub
+func CheckCertificatesFunc(conf *hub.Config, warnBefore time.Duration, renderer utils.Renderer) error {
+	creds, ok := conf.Credentials.(*utils.GpCredentials)
+	if !ok {
+		return errors.New("the configured credentials do not support checking certificates")
+	}
+
+	caPEM, err := os.ReadFile(creds.CACertPath)
+	if err != nil {
+		return fmt.Errorf("could not read CA certificate: %w", err)
+	}
+	ca, err := utils.ParseCertificate(caPEM)
+	if err != nil {
+		return fmt.Errorf("invalid CA certificate %s: %w", creds.CACertPath, err)
+	}
+
+	now := time.Now()
+	checks := make([]certificateCheck, 0, len(conf.Hostnames)+1)
+
+	hubHost, err := Hostname()
+	if err != nil {
+		return fmt.Errorf("could not get hostname: %w", err)
+	}
+	// the CLI connects to the hub through localhost
+	hubCertPEM, err := os.ReadFile(creds.ServerCertPath)
+	checks = append(checks, checkCertificate("Hub", hubHost, "localhost", hubCertPEM, err, ca, caPEM, now, warnBefore))
+
+	executor, err := NewRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not check certificates on the agent hosts: %w", err)
+	}
+	certResults := executor.Run(conf.Hostnames, remote.Command("cat", creds.ServerCertPath))
+	caResults := executor.Run(conf.Hostnames, remote.Command("cat", creds.CACertPath))
+
+	for i, host := range conf.Hostnames {
+		var err error
+		if certResults[i].Err != nil {
+			err = fmt.Errorf("could not read certificate: %w", certResults[i].Err)
+		} else if caResults[i].Err != nil {
+			err = fmt.Errorf("could not read CA certificate: %w", caResults[i].Err)
+		}
+		check := checkCertificate("Agent", host, host, []byte(certResults[i].Stdout), err, ca, []byte(caResults[i].Stdout), now, warnBefore)
+		checks = append(checks, check)
+	}
+
+	err = renderer.Render(os.Stdout, certificateChecksReport(checks))
+	if err != nil {
+		return err
+	}
+
+	failed := make([]string, 0)
+	for _, check := range checks {
+		if check.err != nil {
+			failed = append(failed, check.host)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("certificate check failed on %d of %d hosts: %s", len(failed), len(checks), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// checkCertificate checks the certificate of the host, which clients reach by
+// the given name
+func checkCertificate(role string, host string, name string, certPEM []byte, readErr error, ca *x509.Certificate, hostCAPEM []byte, now time.Time, warnBefore time.Duration) certificateCheck {
+	check := certificateCheck{role: role, host: host}
+	if readErr != nil {
+		check.err = readErr
+		return check
+	}
+
+	hostCA, err := utils.ParseCertificate(hostCAPEM)
+	if err != nil {
+		check.err = fmt.Errorf("invalid CA certificate: %w", err)
+		return check
+	}
+	if !hostCA.Equal(ca) {
+		check.err = errors.New("CA certificate differs from the one of the hub")
+		return check
+	}
+
+	cert, err := utils.ParseCertificate(certPEM)
+	if err != nil {
+		check.err = err
+		return check
+	}
+	check.notAfter = cert.NotAfter
+
+	check.warning, check.err = utils.CheckCertificate(cert, ca, name, now, warnBefore)
+
+	return check
+}
+
+// CertificateCheckRecord is the outcome of checking the certificate of a host
+// as output by gp certificates check. Status is one of ok, warning or error,
+// explained by Message.
+type CertificateCheckRecord struct {
+	Role     string     `json:"role" yaml:"role"`
+	Host     string     `json:"host" yaml:"host"`
+	NotAfter *time.Time `json:"notAfter,omitempty" yaml:"notAfter,omitempty"`
+	Status   string     `json:"status" yaml:"status"`
+	Message  string     `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func certificateChecksReport(checks []certificateCheck) *utils.Report {
+	report := utils.NewReport("ROLE", "HOST", "EXPIRES", "STATUS")
+	for _, check := range checks {
+		record := CertificateCheckRecord{Role: check.role, Host: check.host, Status: "ok"}
+		expires := "-"
+		if !check.notAfter.IsZero() {
+			notAfter := check.notAfter.UTC()
+			record.NotAfter = &notAfter
+			expires = notAfter.Format(time.RFC3339)
+		}
+
+		status := record.Status
+		if check.err != nil {
+			record.Status, record.Message = "error", check.err.Error()
+			status = fmt.Sprintf("error: %s", record.Message)
+		} else if check.warning != "" {
+			record.Status, record.Message = "warning", check.warning
+			status = fmt.Sprintf("warning: %s", record.Message)
+		}
+
+		report.Add(record, record.Role, record.Host, expires, status)
+	}
+
+	return report
+}
@@ -0,0 +1,474 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestReloadCredentials(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("reloads the credentials through the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ReloadCredentials(gomock.Any(), gomock.Any()).Return(&idl.ReloadAllCredentialsReply{}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.ReloadCredentials(cli.Conf, utils.TableRenderer{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out when not able to connect to the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		expected := errors.New("error")
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			return nil, expected
+		}
+
+		err := cli.ReloadCredentials(cli.Conf, utils.TableRenderer{})
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+
+	t.Run("reports every host on which the credentials could not be reloaded", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ReloadCredentials(gomock.Any(), gomock.Any()).Return(nil, hub.NewHostErrors([]hub.HostResult{
+				{Hostname: "sdw1", Err: errors.New("could not load server credentials")},
+				{Hostname: "sdw2"},
+			}))
+			return hubClient, nil
+		}
+
+		err := cli.ReloadCredentials(cli.Conf, utils.TableRenderer{})
+		expected := "could not reload credentials on 1 of 2 hosts: sdw1"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+}
+
+func newCertificatesConfig(creds *utils.GpCredentials) *hub.Config {
+	return &hub.Config{
+		Hostnames:   []string{"cdw", "sdw1", "sdw2"},
+		GpHome:      "gphome",
+		Credentials: creds,
+	}
+}
+
+// copiedFiles returns the contents copied to each host by path
+func copiedFiles(executor *testutils.MockExecutor) map[string]map[string]testutils.MockCopy {
+	files := make(map[string]map[string]testutils.MockCopy)
+	for _, copy := range executor.Copies {
+		for _, host := range copy.Hostnames {
+			if files[host] == nil {
+				files[host] = make(map[string]testutils.MockCopy)
+			}
+			files[host][copy.Path] = copy
+		}
+	}
+
+	return files
+}
+
+func TestGenerateCertificates(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("generates a CA and a certificate for every host", func(t *testing.T) {
+		defer resetCLIVars()
+		dir := t.TempDir()
+		creds := &utils.GpCredentials{}
+
+		executor := &testutils.MockExecutor{}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+		var written *hub.Config
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			written = conf
+			return nil
+		}
+
+		err := cli.GenerateCertificates(newCertificatesConfig(creds), dir, 24*time.Hour, 48*time.Hour, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedCreds := &utils.GpCredentials{
+			CACertPath:     filepath.Join(dir, "ca-cert.pem"),
+			CAKeyPath:      filepath.Join(dir, "ca-key.pem"),
+			ServerCertPath: filepath.Join(dir, "server-cert.pem"),
+			ServerKeyPath:  filepath.Join(dir, "server-key.pem"),
+		}
+		if written == nil || !reflect.DeepEqual(written.Credentials, expectedCreds) {
+			t.Fatalf("got %+v, want %+v", written, expectedCreds)
+		}
+
+		info, err := os.Stat(expectedCreds.CAKeyPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Fatalf("got mode %o, want 0600", info.Mode().Perm())
+		}
+
+		caPEM, err := os.ReadFile(expectedCreds.CACertPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		ca, err := utils.ParseCertificate(caPEM)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		hubPEM, err := os.ReadFile(expectedCreds.ServerCertPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		hubCert, err := utils.ParseCertificate(hubPEM)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		for _, name := range []string{"cdw", "localhost"} {
+			if _, err := utils.CheckCertificate(hubCert, ca, name, time.Now(), 0); err != nil {
+				t.Fatalf("unexpected error for %s: %#v", name, err)
+			}
+		}
+
+		expectedCommands := []string{"mkdir -p " + dir}
+		if !reflect.DeepEqual(executor.Commands, expectedCommands) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expectedCommands)
+		}
+
+		files := copiedFiles(executor)
+		if _, ok := files["cdw"]; ok {
+			t.Fatalf("expected no copies to the hub host")
+		}
+		for _, host := range []string{"sdw1", "sdw2"} {
+			if files[host][expectedCreds.CACertPath].Contents != string(caPEM) {
+				t.Fatalf("expected the CA certificate to be copied to %s", host)
+			}
+			if _, ok := files[host][expectedCreds.CAKeyPath]; ok {
+				t.Fatalf("expected the CA key not to be copied to %s", host)
+			}
+			if files[host][expectedCreds.ServerKeyPath].Mode != 0600 {
+				t.Fatalf("got mode %o for the key of %s, want 0600", files[host][expectedCreds.ServerKeyPath].Mode, host)
+			}
+
+			cert, err := utils.ParseCertificate([]byte(files[host][expectedCreds.ServerCertPath].Contents))
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if _, err := utils.CheckCertificate(cert, ca, host, time.Now(), 0); err != nil {
+				t.Fatalf("unexpected error for %s: %#v", host, err)
+			}
+		}
+	})
+
+	t.Run("reuses the configured CA", func(t *testing.T) {
+		defer resetCLIVars()
+		caDir := t.TempDir()
+		ca, err := utils.GenerateCA("existing CA", 48*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		creds := &utils.GpCredentials{
+			CACertPath:     filepath.Join(caDir, "ca.pem"),
+			CAKeyPath:      filepath.Join(caDir, "ca.key"),
+			ClientCertPath: filepath.Join(caDir, "client.pem"),
+			ClientKeyPath:  filepath.Join(caDir, "client.key"),
+		}
+		if err := os.WriteFile(creds.CACertPath, ca.CertPEM, 0644); err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if err := os.WriteFile(creds.CAKeyPath, ca.KeyPEM, 0600); err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		executor := &testutils.MockExecutor{}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			return nil
+		}
+
+		dir := t.TempDir()
+		err = cli.GenerateCertificates(newCertificatesConfig(creds), dir, 24*time.Hour, 48*time.Hour, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if creds.CAKeyPath != filepath.Join(caDir, "ca.key") {
+			t.Fatalf("got CA key %s, want it unchanged", creds.CAKeyPath)
+		}
+		if creds.ClientCertPath != filepath.Join(caDir, "client.pem") || creds.ClientKeyPath != filepath.Join(caDir, "client.key") {
+			t.Fatalf("got client certificate %s and key %s, want them unchanged", creds.ClientCertPath, creds.ClientKeyPath)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "ca-key.pem")); !os.IsNotExist(err) {
+			t.Fatalf("expected the CA key not to be written to %s", dir)
+		}
+
+		caPEM, err := os.ReadFile(creds.CACertPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if string(caPEM) != string(ca.CertPEM) {
+			t.Fatalf("expected the CA certificate to be unchanged")
+		}
+	})
+
+	t.Run("drops the client certificate not signed by the new CA", func(t *testing.T) {
+		defer resetCLIVars()
+		executor := &testutils.MockExecutor{}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			return nil
+		}
+		creds := &utils.GpCredentials{
+			ClientCertPath: "/certs/client.pem",
+			ClientKeyPath:  "/certs/client.key",
+		}
+
+		err := cli.GenerateCertificates(newCertificatesConfig(creds), t.TempDir(), time.Hour, time.Hour, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if creds.ClientCertPath != "" || creds.ClientKeyPath != "" {
+			t.Fatalf("got client certificate %s and key %s, want none", creds.ClientCertPath, creds.ClientKeyPath)
+		}
+	})
+
+	t.Run("errors out when the roles of the clients are keyed by certificate name", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+		conf := newCertificatesConfig(&utils.GpCredentials{})
+		conf.Authorization = &hub.Authorization{Clients: map[string]hub.AccessRole{"gp-admin": hub.AdminRole}}
+
+		err := cli.GenerateCertificates(conf, t.TempDir(), time.Hour, time.Hour, false)
+		if err == nil || !strings.Contains(err.Error(), "client roles are configured") {
+			t.Fatalf("got %v, want a client roles error", err)
+		}
+	})
+
+	t.Run("errors out when not able to load the configured CA", func(t *testing.T) {
+		defer resetCLIVars()
+		creds := &utils.GpCredentials{
+			CACertPath: "/does/not/exist/ca.pem",
+			CAKeyPath:  "/does/not/exist/ca.key",
+		}
+
+		err := cli.GenerateCertificates(newCertificatesConfig(creds), t.TempDir(), time.Hour, time.Hour, false)
+		if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "use --new-ca") {
+			t.Fatalf("got %v, want a missing CA error", err)
+		}
+	})
+
+	t.Run("errors out when the allowed clients are configured", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+		creds := &utils.GpCredentials{AllowedHubClients: []string{"gp-client"}}
+
+		err := cli.GenerateCertificates(newCertificatesConfig(creds), t.TempDir(), time.Hour, time.Hour, false)
+		if err == nil || !strings.Contains(err.Error(), "allowed hub or agent clients are configured") {
+			t.Fatalf("got %v, want an allowed clients error", err)
+		}
+	})
+
+	t.Run("errors out when not able to copy the certificates", func(t *testing.T) {
+		defer resetCLIVars()
+		expected := errors.New("error")
+		executor := &testutils.MockExecutor{
+			Err: func(host string, command string) error {
+				if host == "sdw2" && strings.HasPrefix(command, "copy") {
+					return expected
+				}
+				return nil
+			},
+		}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		err := cli.GenerateCertificates(newCertificatesConfig(&utils.GpCredentials{}), t.TempDir(), time.Hour, time.Hour, false)
+		if err == nil || !strings.Contains(err.Error(), "host sdw2: error") {
+			t.Fatalf("got %v, want %v on sdw2", err, expected)
+		}
+	})
+}
+
+func TestCheckCertificates(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ca, err := utils.GenerateCA("test CA", 48*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// setupCertificates writes the hub certificate and serves the one of each
+	// agent host through the executor
+	setupCertificates := func(t *testing.T, hostCerts map[string]*utils.KeyPair) *utils.GpCredentials {
+		dir := t.TempDir()
+		creds := &utils.GpCredentials{
+			CACertPath:     filepath.Join(dir, "ca-cert.pem"),
+			ServerCertPath: filepath.Join(dir, "server-cert.pem"),
+		}
+
+		hubCert, err := ca.IssueCertificate("cdw", []string{"cdw", "localhost"}, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if err := os.WriteFile(creds.CACertPath, ca.CertPEM, 0644); err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if err := os.WriteFile(creds.ServerCertPath, hubCert.CertPEM, 0644); err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		hostCerts["cdw"] = hubCert
+
+		executor := &testutils.MockExecutor{
+			Stdout: func(host string, command string) string {
+				if strings.Contains(command, "ca-cert.pem") {
+					return string(ca.CertPEM)
+				}
+				return string(hostCerts[host].CertPEM)
+			},
+		}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+
+		return creds
+	}
+
+	issue := func(t *testing.T, host string, validity time.Duration) *utils.KeyPair {
+		cert, err := ca.IssueCertificate(host, []string{host}, validity)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		return cert
+	}
+
+	t.Run("succeeds when the certificates of every host are valid", func(t *testing.T) {
+		defer resetCLIVars()
+		creds := setupCertificates(t, map[string]*utils.KeyPair{
+			"sdw1": issue(t, "sdw1", 24*time.Hour),
+			"sdw2": issue(t, "sdw2", 24*time.Hour),
+		})
+
+		err := cli.CheckCertificates(newCertificatesConfig(creds), time.Hour, utils.TableRenderer{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out on the hosts with an invalid certificate", func(t *testing.T) {
+		defer resetCLIVars()
+		creds := setupCertificates(t, map[string]*utils.KeyPair{
+			"sdw1": issue(t, "sdw1", 24*time.Hour),
+			"sdw2": issue(t, "sdw1", 24*time.Hour),
+		})
+
+		err := cli.CheckCertificates(newCertificatesConfig(creds), time.Hour, utils.TableRenderer{})
+		expected := "certificate check failed on 1 of 4 hosts: sdw2"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("only warns about certificates about to expire", func(t *testing.T) {
+		defer resetCLIVars()
+		creds := setupCertificates(t, map[string]*utils.KeyPair{
+			"sdw1": issue(t, "sdw1", time.Hour),
+			"sdw2": issue(t, "sdw2", 24*time.Hour),
+		})
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.CheckCertificates(newCertificatesConfig(creds), 2*time.Hour, utils.JSONRenderer{})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var records []cli.CertificateCheckRecord
+		err = json.Unmarshal([]byte(output), &records)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		statuses := map[string]string{}
+		for _, record := range records {
+			statuses[record.Role+" "+record.Host] = record.Status
+		}
+		expected := map[string]string{"Hub cdw": "ok", "Agent cdw": "ok", "Agent sdw1": "warning", "Agent sdw2": "ok"}
+		if !reflect.DeepEqual(statuses, expected) {
+			t.Fatalf("got %+v, want %+v", statuses, expected)
+		}
+	})
+
+	t.Run("errors out on the hosts not reachable", func(t *testing.T) {
+		defer resetCLIVars()
+		creds := setupCertificates(t, map[string]*utils.KeyPair{
+			"sdw1": issue(t, "sdw1", 24*time.Hour),
+			"sdw2": issue(t, "sdw2", 24*time.Hour),
+		})
+		executor := &testutils.MockExecutor{
+			Err: func(host string, command string) error {
+				if host == "sdw1" {
+					return errors.New("could not connect")
+				}
+				return nil
+			},
+		}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		err := cli.CheckCertificates(newCertificatesConfig(creds), time.Hour, utils.TableRenderer{})
+		expected := "certificate check failed on"
+		if err == nil || !strings.Contains(err.Error(), expected) || !strings.Contains(err.Error(), "sdw1") {
+			t.Fatalf("got %v, want %s sdw1", err, expected)
+		}
+	})
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/greenplum-db/gpdb/gp/constants"
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var (
@@ -22,16 +24,21 @@ var (
 	Unmarshal       = json.Unmarshal
 	DialContextFunc = grpc.DialContext
 	ConnectToHub    = ConnectToHubFunc
+	CheckHubHealth  = CheckHubHealthFunc
 
 	ConfigFilePath string
 	Conf           *hub.Config
 
 	Verbose bool
+
+	skipUnreachable bool
+	outputFormat    = "table"
 )
 
 func RootCommand() *cobra.Command {
 	root := &cobra.Command{
-		Use: "gp",
+		Use:     "gp",
+		Version: constants.Version,
 	}
 
 	root.PersistentFlags().StringVar(&ConfigFilePath, "config-file", filepath.Join(os.Getenv("GPHOME"), constants.ConfigFileName), `Path to gp configuration file`)
@@ -39,11 +46,18 @@ func RootCommand() *cobra.Command {
 
 	root.AddCommand(
 		agentCmd(),
+		auditCmd(),
+		certificatesCmd(),
 		configureCmd(),
+		expandCmd(),
 		hubCmd(),
+		initCmd(),
+		rebalanceCmd(),
+		recoverCmd(),
 		startCmd(),
 		statusCmd(),
 		stopCmd(),
+		unconfigureCmd(),
 	)
 
 	return root
@@ -64,6 +78,17 @@ func InitializeCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// addSkipUnreachableFlag lets the command proceed on the hosts whose agent the
+// hub can reach
+func addSkipUnreachableFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&skipUnreachable, "skip-unreachable", false, `Proceed on the reachable hosts when the agents of some hosts can not be reached`)
+}
+
+// addFormatFlag lets the command render its report in any output format
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFormat, "format", "table", fmt.Sprintf(`Output format, one of %s`, strings.Join(utils.OutputFormats(), ", ")))
+}
+
 func InitializeLogger(cmd *cobra.Command, args []string) {
 	// CommandPath lists the names of the called command and all of its parent commands, so this
 	// turns e.g. "gp stop hub" into "gp_stop_hub" to generate a unique log file name for each command.
@@ -85,6 +110,42 @@ func InitializeLogger(cmd *cobra.Command, args []string) {
 }
 
 func ConnectToHubFunc(conf *hub.Config) (idl.HubClient, error) {
+	conn, err := dialHub(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return idl.NewHubClient(conn), nil
+}
+
+// CheckHubHealthFunc returns an error unless the hub reports the service,
+// e.g. hub.AgentsHealthService, as serving. The empty service stands for the
+// hub as a whole.
+func CheckHubHealthFunc(conf *hub.Config, service string) error {
+	conn, err := dialHub(conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	reply, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("could not check the health of the hub: %w", err)
+	}
+	if reply.Status != healthpb.HealthCheckResponse_SERVING {
+		if service != "" {
+			return fmt.Errorf("hub reports %s as %s", service, reply.Status)
+		}
+		return fmt.Errorf("hub is %s", reply.Status)
+	}
+
+	return nil
+}
+
+func dialHub(conf *hub.Config) (*grpc.ClientConn, error) {
 	var conn *grpc.ClientConn
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -95,16 +156,33 @@ func ConnectToHubFunc(conf *hub.Config) (idl.HubClient, error) {
 		return nil, err
 	}
 
-	address := fmt.Sprintf("localhost:%d", conf.Port)
-	conn, err = DialContextFunc(ctx, address,
+	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(credentials),
 		grpc.WithBlock(),
 		grpc.FailOnNonTempDialError(true),
 		grpc.WithReturnConnectionError(),
-	)
+	}
+	if token := os.Getenv(constants.HubTokenEnv); token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+
+	address := fmt.Sprintf("localhost:%d", conf.Port)
+	conn, err = DialContextFunc(ctx, address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to hub on port %d: %w", conf.Port, err)
 	}
 
-	return idl.NewHubClient(conn), nil
+	return conn, nil
+}
+
+// bearerToken identifies the user of the CLI to the hub, in place of the
+// client certificate
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
 }
@@ -3,6 +3,8 @@ package cli_test
 import (
 	"context"
 	"errors"
+	"net"
+	"os"
 	"strings"
 	"testing"
 
@@ -13,8 +15,13 @@ import (
 	"github.com/greenplum-db/gpdb/gp/constants"
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
 	"github.com/greenplum-db/gpdb/gp/testutils"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 var (
@@ -34,14 +41,32 @@ func teardownTest() {
 func resetCLIVars() {
 	cli.DialContextFunc = grpc.DialContext
 	cli.ConnectToHub = cli.ConnectToHubFunc
+	cli.CheckHubHealth = cli.CheckHubHealthFunc
 	cli.StartHubService = cli.StartHubServiceFunc
 	cli.WaitAndRetryHubConnect = cli.WaitAndRetryHubConnectFunc
+	cli.GetHubStatus = cli.GetHubStatusFunc
+	cli.GetAgentsStatus = cli.GetAgentsStatusFunc
 	cli.ShowHubStatus = cli.ShowHubStatusFunc
 	cli.StartAgentsAll = cli.StartAgentsAllFunc
 	cli.ShowAgentsStatus = cli.ShowAgentsStatusFunc
 	cli.PrintServicesStatus = cli.PrintServicesStatusFunc
+	cli.ShowAgentsHistory = cli.ShowAgentsHistoryFunc
 	cli.StopAgentService = cli.StopAgentServiceFunc
 	cli.StopHubService = cli.StopHubServiceFunc
+	cli.StartCluster = cli.StartClusterFunc
+	cli.StopCluster = cli.StopClusterFunc
+	cli.RecoverCluster = cli.RecoverClusterFunc
+	cli.RebalanceCluster = cli.RebalanceClusterFunc
+	cli.InitCluster = cli.InitClusterFunc
+	cli.ExpandCluster = cli.ExpandClusterFunc
+	cli.ApplyConfigUpdate = cli.ApplyConfigUpdateFunc
+	cli.WriteConfig = cli.WriteConfigFunc
+	cli.ReloadCredentials = cli.ReloadCredentialsFunc
+	cli.GenerateCertificates = cli.GenerateCertificatesFunc
+	cli.CheckCertificates = cli.CheckCertificatesFunc
+	cli.ShowAudit = cli.ShowAuditFunc
+	cli.NewRemoteExecutor = remote.NewExecutor
+	cli.Hostname = os.Hostname
 }
 
 func funcNilError() func() error {
@@ -100,6 +125,28 @@ func TestConnectToHub(t *testing.T) {
 			t.Fatalf("got %v, want %v", err, expectedErr)
 		}
 	})
+	t.Run("Connect to hub sends the token set in the environment", func(t *testing.T) {
+		defer resetCLIVars()
+		var optCounts []int
+		cli.DialContextFunc = func(ctx context.Context, target string, opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
+			optCounts = append(optCounts, len(opts))
+			return &grpc.ClientConn{}, nil
+		}
+
+		_, err := cli.ConnectToHub(&config)
+		if err != nil {
+			t.Fatalf("unexpected error when connecting to hub: %#v", err)
+		}
+		t.Setenv(constants.HubTokenEnv, "secret")
+		_, err = cli.ConnectToHub(&config)
+		if err != nil {
+			t.Fatalf("unexpected error when connecting to hub: %#v", err)
+		}
+
+		if len(optCounts) != 2 || optCounts[1] != optCounts[0]+1 {
+			t.Fatalf("got dial option counts %v, want an extra option for the token", optCounts)
+		}
+	})
 	t.Run("Connect to hub returns error when load client credentials fail", func(t *testing.T) {
 		defer resetCLIVars()
 		cli.DialContextFunc = func(ctx context.Context, target string, opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
@@ -115,3 +162,62 @@ func TestConnectToHub(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckHubHealth(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	listener := bufconn.Listen(1024 * 1024)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(hub.AgentsHealthService, healthpb.HealthCheckResponse_NOT_SERVING)
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	defer grpcServer.Stop()
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+
+	config := &hub.Config{
+		Port:        constants.DefaultHubPort,
+		Credentials: &testutils.MockCredentials{TlsConnection: insecure.NewCredentials()},
+	}
+	dialBufconn := func(ctx context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.Dial()
+		}
+		return grpc.DialContext(ctx, "bufnet", append(opts, grpc.WithContextDialer(dialer))...)
+	}
+
+	t.Run("succeeds when the hub is serving", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.DialContextFunc = dialBufconn
+
+		err := cli.CheckHubHealth(config, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out when the service is not serving", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.DialContextFunc = dialBufconn
+
+		err := cli.CheckHubHealth(config, hub.AgentsHealthService)
+		expected := "hub reports agents as NOT_SERVING"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("errors out when not able to connect to the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		expectedErr := "TEST ERROR while dialing context"
+		cli.DialContextFunc = func(ctx context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+			return nil, errors.New(expectedErr)
+		}
+
+		err := cli.CheckHubHealth(config, "")
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// HostResults returns the per-host results the hub attaches to the status of
+// an RPC that failed on some of the hosts, or nil if there are none
+func HostResults(err error) []*idl.HostResult {
+	status, ok := grpcStatus.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	var results []*idl.HostResult
+	for _, detail := range status.Details() {
+		if result, ok := detail.(*idl.HostResult); ok {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// hostError logs every host on which the hub failed to run the action and
+// returns an error naming them all. Errors without per-host results are
+// wrapped as they are.
+func hostError(action string, err error) error {
+	results := HostResults(err)
+
+	failed := make([]string, 0)
+	for _, result := range results {
+		if result.Error == "" {
+			continue
+		}
+
+		gplog.Error("[%s] %s (after %s)", result.Hostname, result.Error, result.Duration.AsDuration())
+		failed = append(failed, result.Hostname)
+	}
+
+	if len(failed) == 0 {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+
+	return fmt.Errorf("%s on %d of %d hosts: %s", action, len(failed), len(results), strings.Join(failed, ", "))
+}
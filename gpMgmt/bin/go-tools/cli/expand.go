@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/spf13/cobra"
+)
+
+var (
+	RunExpand     = RunExpandFunc
+	ExpandCluster = ExpandClusterFunc
+
+	expandHostnames    []string
+	expandHostfilePath string
+	expandServiceDir   string
+	expandServiceUser  string
+	expandDryRun       bool
+)
+
+func expandCmd() *cobra.Command {
+	expandCmd := &cobra.Command{
+		Use:   "expand",
+		Short: "Add segment hosts to the cluster",
+		Long: `Add segment hosts to a running cluster. The agent service is installed on the
+new hosts and the configuration file is updated on every host, then the hub
+creates as many segments on each new host as on the existing hosts, in the
+same directories and with the same ports, and adds them to the catalog. Its
+progress is saved under the log directory of the hub, and running the command
+again with the same hosts resumes a failed expansion. The hub checks the plan
+before anything is installed on the new hosts.`,
+		PreRunE: InitializeCommand,
+		RunE:    RunExpand,
+	}
+
+	addCoordinatorPortFlag(expandCmd)
+	expandCmd.Flags().StringArrayVar(&expandHostnames, "host", []string{}, `New segment hostname`)
+	expandCmd.Flags().StringVar(&expandHostfilePath, "hostfile", "", `Path to file containing a list of new segment hostnames`)
+	expandCmd.MarkFlagsMutuallyExclusive("host", "hostfile")
+	expandCmd.Flags().StringVar(&expandServiceDir, "service-dir", fmt.Sprintf(DefaultServiceDir, os.Getenv("USER")), `Path to service file directory`)
+	expandCmd.Flags().StringVar(&expandServiceUser, "service-user", os.Getenv("USER"), `User for whom to configure the service`)
+	expandCmd.Flags().BoolVar(&expandDryRun, "dry-run", false, `Only show the segments that would be added`)
+
+	return expandCmd
+}
+
+func RunExpandFunc(cmd *cobra.Command, args []string) error {
+	var err error
+	hosts := expandHostnames
+	if cmd.Flags().Lookup("hostfile").Changed {
+		hosts, err = GetHostnames(expandHostfilePath)
+		if err != nil {
+			return err
+		}
+	}
+	if len(hosts) == 0 {
+		return errors.New("at least one new hostname must be provided using either --host or --hostfile")
+	}
+
+	if cmd.Flags().Lookup("service-user").Changed && !cmd.Flags().Lookup("service-dir").Changed {
+		expandServiceDir = fmt.Sprintf(DefaultServiceDir, expandServiceUser)
+	}
+
+	// The hub checks the plan before the new hosts are configured, so that an
+	// invalid plan leaves them alone
+	err = ExpandCluster(Conf, coordinatorPort, hosts, true)
+	if err != nil || expandDryRun {
+		return err
+	}
+
+	err = AddHosts(Conf, hosts, expandServiceDir, expandServiceUser)
+	if err != nil {
+		return err
+	}
+
+	return ExpandCluster(Conf, coordinatorPort, hosts, false)
+}
+
+// AddHosts provides the hosts missing from the configuration with
+// certificates, installs the agent service on them and adds them to the
+// configuration, updating the configuration file on every host. Hosts that are configured already, e.g. when resuming an expansion,
+// are left alone.
+func AddHosts(conf *hub.Config, hosts []string, serviceDir string, serviceUser string) error {
+	configured := make(map[string]bool, len(conf.Hostnames))
+	for _, host := range conf.Hostnames {
+		configured[host] = true
+	}
+
+	newHosts := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if host == "" {
+			return fmt.Errorf("empty host name found -- please provide a valid input host name")
+		}
+		if !configured[host] {
+			newHosts = append(newHosts, host)
+			configured[host] = true
+		}
+	}
+	if len(newHosts) == 0 {
+		return nil
+	}
+
+	err := provideHostCertificates(conf, newHosts)
+	if err != nil {
+		return err
+	}
+
+	err = Platform.CreateServiceDir(newHosts, serviceDir)
+	if err != nil {
+		return err
+	}
+
+	err = Platform.CreateAndInstallAgentServiceFile(newHosts, conf.GpHome, serviceDir, conf.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	err = Platform.EnableUserLingering(newHosts, serviceUser)
+	if err != nil {
+		return err
+	}
+
+	conf.Hostnames = append(append([]string{}, conf.Hostnames...), newHosts...)
+	return WriteConfig(conf, ConfigFilePath)
+}
+
+func ExpandClusterFunc(hubConfig *hub.Config, port int, hosts []string, dryRun bool) error {
+	client, err := ConnectToHub(hubConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
+	}
+
+	stream, err := client.ExpandCluster(context.Background(), &idl.ExpandClusterRequest{
+		CoordinatorPort: int32(port),
+		Hosts:           hosts,
+		DryRun:          dryRun,
+	})
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("could not expand cluster: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+package cli_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestAddHosts(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("adds the new hosts to the configuration", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		var written []string
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			written = conf.Hostnames
+			return nil
+		}
+
+		conf := &hub.Config{Hostnames: []string{"sdw1", "sdw2"}}
+		err := cli.AddHosts(conf, []string{"sdw2", "sdw3", "sdw4"}, "/service/dir", "gpadmin")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"sdw1", "sdw2", "sdw3", "sdw4"}
+		if !reflect.DeepEqual(written, expected) {
+			t.Fatalf("got %q, want %q", written, expected)
+		}
+	})
+
+	t.Run("leaves the configuration alone when every host is configured", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{Err: errors.New("error")}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		err := cli.AddHosts(&hub.Config{Hostnames: []string{"sdw1", "sdw2"}}, []string{"sdw2"}, "/service/dir", "gpadmin")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("issues certificates to the new hosts with the generated CA", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			return nil
+		}
+
+		dir := t.TempDir()
+		ca, err := utils.GenerateCA("test CA", 48*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		hubCert, err := ca.IssueCertificate("cdw", []string{"cdw"}, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		creds := &utils.GpCredentials{
+			CACertPath:     filepath.Join(dir, "ca-cert.pem"),
+			CAKeyPath:      filepath.Join(dir, "ca-key.pem"),
+			ServerCertPath: filepath.Join(dir, "server-cert.pem"),
+			ServerKeyPath:  filepath.Join(dir, "server-key.pem"),
+		}
+		for path, contents := range map[string][]byte{
+			creds.CACertPath:     ca.CertPEM,
+			creds.CAKeyPath:      ca.KeyPEM,
+			creds.ServerCertPath: hubCert.CertPEM,
+			creds.ServerKeyPath:  hubCert.KeyPEM,
+		} {
+			err = os.WriteFile(path, contents, 0600)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+
+		executor := &testutils.MockExecutor{}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		conf := &hub.Config{Hostnames: []string{"sdw1"}, Credentials: creds}
+		err = cli.AddHosts(conf, []string{"sdw2"}, "/service/dir", "gpadmin")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		copied := copiedFiles(executor)
+		if len(copied) != 1 {
+			t.Fatalf("got certificates copied to %d hosts, want 1", len(copied))
+		}
+		cert, err := utils.ParseCertificate([]byte(copied["sdw2"][creds.ServerCertPath].Contents))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		_, err = utils.CheckCertificate(cert, ca.Cert, "sdw2", time.Now(), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if cert.NotAfter.Sub(hubCert.Cert.NotAfter).Abs() > time.Minute {
+			t.Fatalf("got certificate valid until %s, want it to expire with the hub certificate at %s", cert.NotAfter, hubCert.Cert.NotAfter)
+		}
+	})
+
+	t.Run("errors out when the new hosts are missing the certificates", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("test -f", errors.New("exit status 1"))}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		creds := &utils.GpCredentials{
+			CACertPath:     "/certs/ca.crt",
+			ServerCertPath: "/certs/server.crt",
+			ServerKeyPath:  "/certs/server.key",
+		}
+		conf := &hub.Config{Hostnames: []string{"sdw1"}, Credentials: creds}
+		err := cli.AddHosts(conf, []string{"sdw2"}, "/service/dir", "gpadmin")
+		expected := "hosts sdw2 are missing the certificates /certs/ca.crt, /certs/server.crt, /certs/server.key, copy them there first"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
+		}
+
+		expectedCommands := []string{"test -f /certs/ca.crt && test -f /certs/server.crt && test -f /certs/server.key"}
+		if !reflect.DeepEqual(executor.Commands, expectedCommands) {
+			t.Fatalf("got %q, want %q", executor.Commands, expectedCommands)
+		}
+	})
+
+	t.Run("errors out when the agent service can not be installed", func(t *testing.T) {
+		defer resetCLIVars()
+		expected := errors.New("error")
+		cli.Platform = &testutils.MockPlatform{Err: expected}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		err := cli.AddHosts(&hub.Config{Hostnames: []string{"sdw1"}}, []string{"sdw2"}, "/service/dir", "gpadmin")
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+}
+
+func TestRunExpand(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("asks the hub to check the plan then expand the cluster to the given hosts", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Conf.Hostnames = []string{"sdw1", "sdw2"}
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		var calls []string
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			calls = append(calls, "write config")
+			return nil
+		}
+
+		var requests []*idl.ExpandClusterRequest
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ExpandCluster(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, in *idl.ExpandClusterRequest, _ ...interface{}) (idl.Hub_ExpandClusterClient, error) {
+				requests = append(requests, in)
+				calls = append(calls, fmt.Sprintf("expand, dry run %t", in.DryRun))
+				return &testutils.MockHubReplies{}, nil
+			})
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"expand", "--host", "sdw3", "--host", "sdw4", "--coordinator-port", "15432"})
+		expandCmd, _, _ := cmd.Find([]string{"expand"})
+		expandCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"expand, dry run true", "write config", "expand, dry run false"}
+		if !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("got %q, want %q", calls, expected)
+		}
+		for _, request := range requests {
+			if request.CoordinatorPort != 15432 || !reflect.DeepEqual(request.Hosts, []string{"sdw3", "sdw4"}) {
+				t.Fatalf("got %+v, want the new hosts to be sent", request)
+			}
+		}
+	})
+
+	t.Run("leaves the new hosts alone when the plan is invalid", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Conf.Hostnames = []string{"sdw1", "sdw2"}
+		cli.Platform = &testutils.MockPlatform{Err: errors.New("unexpected installation of the agent service")}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ExpandCluster(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{Err: errors.New("host sdw2 already has segments of the cluster")}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"expand", "--host", "sdw2", "--host", "sdw3"})
+		expandCmd, _, _ := cmd.Find([]string{"expand"})
+		expandCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		expected := "could not expand cluster: host sdw2 already has segments of the cluster"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("only checks the plan on a dry run", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Conf.Hostnames = []string{"sdw1", "sdw2"}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ExpandCluster(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, in *idl.ExpandClusterRequest, _ ...interface{}) (idl.Hub_ExpandClusterClient, error) {
+				if !in.DryRun {
+					t.Fatalf("got %+v, want a dry run", in)
+				}
+				return &testutils.MockHubReplies{}, nil
+			})
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"expand", "--host", "sdw3", "--host", "sdw4", "--dry-run"})
+		expandCmd, _, _ := cmd.Find([]string{"expand"})
+		expandCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out when no host is given", func(t *testing.T) {
+		defer resetCLIVars()
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"expand"})
+		expandCmd, _, _ := cmd.Find([]string{"expand"})
+		expandCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		expected := "at least one new hostname must be provided using either --host or --hostfile"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("returns error when the hub fails to expand the cluster", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ExpandCluster(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{Err: errors.New("TEST Error expanding cluster")}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.ExpandCluster(cli.Conf, 5432, []string{"sdw3"}, false)
+		expected := "could not expand cluster: TEST Error expanding cluster"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
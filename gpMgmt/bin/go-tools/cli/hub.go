@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gpdb/gp/constants"
 	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
 	"github.com/greenplum-db/gpdb/gp/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -17,20 +23,34 @@ import (
 var (
 	Platform          = utils.GetPlatform()
 	DefaultServiceDir = Platform.GetDefaultServiceDir()
+	WriteConfig       = WriteConfigFunc
 
-	agentPort      int
-	caCertPath     string
-	caKeyPath      string
-	gphome         string
-	hubLogDir      string
-	hubPort        int
-	hostnames      []string
-	hostfilePath   string
-	serverCertPath string
-	serverKeyPath  string
-	serviceDir     string // Provide the service file's directory and name separately so users can name different files for different clusters
-	serviceName    string
-	serviceUser    string
+	agentMetricsPort    int
+	agentPort           int
+	allowedAgentClients []string
+	allowedHubClients   []string
+	caCertPath          string
+	caKeyPath           string
+	clientCertPath      string
+	clientKeyPath       string
+	clientRoles         []string
+	eventExec           string
+	eventLogFile        string
+	eventWebhook        string
+	gphome              string
+	hubLogDir           string
+	hubCoordinatorPort  int
+	hubMetricsPort      int
+	hubPort             int
+	hostnames           []string
+	hostfilePath        string
+	serverCertPath      string
+	serverKeyPath       string
+	serviceDir          string // Provide the service file's directory and name separately so users can name different files for different clusters
+	serviceName         string
+	serviceUser         string
+	tokenRoles          []string
+	verifyClients       bool
 )
 
 func hubCmd() *cobra.Command {
@@ -48,6 +68,7 @@ func hubCmd() *cobra.Command {
 
 func RunHub(cmd *cobra.Command, args []string) (err error) {
 	h := hub.New(Conf, nil)
+	h.ConfigFile = ConfigFilePath
 	err = h.Start()
 	if err != nil {
 		return err
@@ -70,6 +91,13 @@ func configureCmd() *cobra.Command {
 	configureCmd.Flags().StringVar(&gphome, "gphome", "/usr/local/greenplum-db", `Path to GPDB installation`)
 	configureCmd.Flags().IntVar(&hubPort, "hub-port", constants.DefaultHubPort, `Port on which the hub should listen`)
 	configureCmd.Flags().StringVar(&hubLogDir, "log-dir", constants.DefaultHubLogDir, `Path to gp hub log directory`)
+	configureCmd.Flags().IntVar(&hubCoordinatorPort, "coordinator-port", 0, `Port of the coordinator, for the hub to keep the topology of the cluster up to date, which is then also known after a restart of the hub`)
+	configureCmd.Flags().IntVar(&hubMetricsPort, "hub-metrics-port", 0, `Port on which the hub should serve Prometheus metrics on /metrics, disabled when 0`)
+	configureCmd.Flags().IntVar(&agentMetricsPort, "agent-metrics-port", 0, `Port on which the agents should serve Prometheus metrics on /metrics, disabled when 0`)
+	// The segments are only watched when events are sent somewhere
+	configureCmd.Flags().StringVar(&eventLogFile, "event-log-file", "", `Path to the file the hub appends the events it raises to, as JSON lines`)
+	configureCmd.Flags().StringVar(&eventExec, "event-exec", "", `Path to an executable the hub runs with every event it raises as JSON on its standard input`)
+	configureCmd.Flags().StringVar(&eventWebhook, "event-webhook", "", `Local URL the hub posts every event it raises to as JSON`)
 	configureCmd.Flags().StringVar(&serviceName, "service-name", constants.DefaultServiceName, `Name for the generated systemd service file`)
 	configureCmd.Flags().StringVar(&serviceDir, "service-dir", fmt.Sprintf(DefaultServiceDir, os.Getenv("USER")), `Path to service file directory`)
 	configureCmd.Flags().StringVar(&serviceUser, "service-user", os.Getenv("USER"), `User for whom to configure the service`)
@@ -78,6 +106,15 @@ func configureCmd() *cobra.Command {
 	configureCmd.Flags().StringVar(&caKeyPath, "ca-key", "", `Path to SSL/TLS CA private key`)
 	configureCmd.Flags().StringVar(&serverCertPath, "server-certificate", "", `Path to hub SSL/TLS server certificate`)
 	configureCmd.Flags().StringVar(&serverKeyPath, "server-key", "", `Path to hub SSL/TLS server private key`)
+	configureCmd.Flags().StringVar(&clientCertPath, "client-certificate", "", `Path to SSL/TLS client certificate, defaults to the server certificate`)
+	configureCmd.Flags().StringVar(&clientKeyPath, "client-key", "", `Path to SSL/TLS client private key, defaults to the server private key`)
+	configureCmd.MarkFlagsRequiredTogether("client-certificate", "client-key")
+	configureCmd.Flags().BoolVar(&verifyClients, "verify-client-certificates", false, `Only accept client certificates signed by the CA`)
+	configureCmd.Flags().StringArrayVar(&allowedHubClients, "allowed-hub-client", []string{}, `SAN or CN of the client certificates allowed to connect to the hub, requires --verify-client-certificates`)
+	configureCmd.Flags().StringArrayVar(&allowedAgentClients, "allowed-agent-client", []string{}, `SAN or CN of the client certificates allowed to connect to the agents, requires --verify-client-certificates`)
+	// Clients are allowed to call every hub RPC unless at least one role is given
+	configureCmd.Flags().StringArrayVar(&clientRoles, "client-role", []string{}, `Role of the clients connecting to the hub with a certificate, as <SAN or CN>=<viewer|operator|admin>, requires --verify-client-certificates`)
+	configureCmd.Flags().StringArrayVar(&tokenRoles, "token-role", []string{}, `Role of the clients connecting to the hub with a bearer token, as <hex SHA-256 of the token>=<viewer|operator|admin>`)
 	// Allow passing a hostfile for "real" use cases or a few host names for tests, but not both
 	configureCmd.Flags().StringArrayVar(&hostnames, "host", []string{}, `Segment hostname`)
 	configureCmd.Flags().StringVar(&hostfilePath, "hostfile", "", `Path to file containing a list of segment hostnames`)
@@ -85,6 +122,9 @@ func configureCmd() *cobra.Command {
 
 	viper.BindPFlag("gphome", configureCmd.Flags().Lookup("gphome")) //nolint
 	gphome = viper.GetString("gphome")
+
+	configureCmd.AddCommand(configureUpdateCmd())
+
 	return configureCmd
 }
 func RunConfigure(cmd *cobra.Command, args []string) (err error) {
@@ -105,6 +145,26 @@ func RunConfigure(cmd *cobra.Command, args []string) (err error) {
 		return errors.New("at least one hostname must be provided using either --host or --hostfile")
 	}
 
+	if !verifyClients && (len(allowedHubClients) > 0 || len(allowedAgentClients) > 0) {
+		return errors.New("allowed client names can only be used with --verify-client-certificates")
+	}
+
+	if !verifyClients && len(clientRoles) > 0 {
+		return errors.New("client roles can only be used with --verify-client-certificates")
+	}
+
+	authorization, err := parseAuthorization(clientRoles, tokenRoles)
+	if err != nil {
+		return err
+	}
+
+	if eventWebhook != "" {
+		err = hub.ValidateWebhookURL(eventWebhook)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Convert file/directory paths to absolute path before writing to gp.Conf file
 	err = resolveAbsolutePaths(cmd)
 	if err != nil {
@@ -133,18 +193,31 @@ func RunConfigure(cmd *cobra.Command, args []string) (err error) {
 		ServiceName: serviceName,
 		GpHome:      gphome,
 		Credentials: &utils.GpCredentials{
-			CACertPath:     caCertPath,
-			CAKeyPath:      caKeyPath,
-			ServerCertPath: serverCertPath,
-			ServerKeyPath:  serverKeyPath,
+			CACertPath:          caCertPath,
+			CAKeyPath:           caKeyPath,
+			ServerCertPath:      serverCertPath,
+			ServerKeyPath:       serverKeyPath,
+			ClientCertPath:      clientCertPath,
+			ClientKeyPath:       clientKeyPath,
+			VerifyClientCerts:   verifyClients,
+			AllowedHubClients:   allowedHubClients,
+			AllowedAgentClients: allowedAgentClients,
 		},
+		Authorization:   authorization,
+		CoordinatorPort: hubCoordinatorPort,
+	}
+	if hubMetricsPort != 0 || agentMetricsPort != 0 {
+		Conf.Metrics = &hub.MetricsConfig{HubPort: hubMetricsPort, AgentPort: agentMetricsPort}
 	}
-	err = Conf.Write(ConfigFilePath)
+	if eventLogFile != "" || eventExec != "" || eventWebhook != "" {
+		Conf.Events = &hub.EventsConfig{LogFile: eventLogFile, Exec: eventExec, Webhook: eventWebhook}
+	}
+	err = WriteConfig(Conf, ConfigFilePath)
 	if err != nil {
 		return err
 	}
 
-	err = Platform.CreateServiceDir(hostnames, serviceDir, gphome)
+	err = Platform.CreateServiceDir(hostnames, serviceDir)
 	if err != nil {
 		return err
 	}
@@ -159,14 +232,91 @@ func RunConfigure(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	err = Platform.EnableUserLingering(hostnames, gphome, serviceUser)
+	err = Platform.EnableUserLingering(hostnames, serviceUser)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WriteConfigFunc writes the configuration file and copies it to the segment
+// hosts. When the hub of the previous configuration is running with the same
+// hosts, the file is pushed through the agents, otherwise it is copied over SSH.
+func WriteConfigFunc(conf *hub.Config, configFilePath string) error {
+	previous := &hub.Config{}
+	err := previous.Load(configFilePath)
+	if err != nil || !reflect.DeepEqual(previous.Hostnames, conf.Hostnames) {
+		return conf.Write(configFilePath)
+	}
+
+	client, err := ConnectToHub(previous)
+	if err != nil {
+		gplog.Debug("Hub is not running, copying the configuration file over SSH: %s", err)
+		return conf.Write(configFilePath)
+	}
+
+	err = conf.WriteLocal(configFilePath)
 	if err != nil {
 		return err
 	}
 
+	_, err = client.DistributeConfig(context.Background(), &idl.DistributeConfigRequest{})
+	if err != nil {
+		return hostError("could not distribute configuration", err)
+	}
+
 	return nil
 }
 
+// parseAuthorization builds the roles of the hub clients from the <name>=<role>
+// flag values, returning nil when there are none
+func parseAuthorization(clientRoles []string, tokenRoles []string) (*hub.Authorization, error) {
+	if len(clientRoles) == 0 && len(tokenRoles) == 0 {
+		return nil, nil
+	}
+
+	authorization := &hub.Authorization{
+		Clients: make(map[string]hub.AccessRole),
+		Tokens:  make(map[string]hub.AccessRole),
+	}
+
+	for _, value := range clientRoles {
+		name, role, err := parseRoleFlag(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --client-role %s: %w", value, err)
+		}
+		authorization.Clients[name] = role
+	}
+
+	for _, value := range tokenRoles {
+		hash, role, err := parseRoleFlag(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --token-role %s: %w", value, err)
+		}
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 2*sha256.Size {
+			return nil, fmt.Errorf("invalid --token-role %s: expected the hex-encoded SHA-256 of the token", value)
+		}
+		authorization.Tokens[strings.ToLower(hash)] = role
+	}
+
+	return authorization, nil
+}
+
+func parseRoleFlag(value string) (string, hub.AccessRole, error) {
+	name, roleName, found := strings.Cut(value, "=")
+	if !found || name == "" {
+		return "", "", errors.New("expected <name>=<role>")
+	}
+
+	role, err := hub.ParseAccessRole(roleName)
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, role, nil
+}
+
 func resolveAbsolutePaths(cmd *cobra.Command) error {
 	paths := []*string{&caCertPath, &caKeyPath, &serverCertPath, &serverKeyPath, &hubLogDir, &gphome}
 	for _, path := range paths {
@@ -177,6 +327,19 @@ func resolveAbsolutePaths(cmd *cobra.Command) error {
 		*path = p
 	}
 
+	// The client credentials and event sinks are optional, keep them empty when not set
+	for _, path := range []*string{&clientCertPath, &clientKeyPath, &eventLogFile, &eventExec} {
+		if *path == "" {
+			continue
+		}
+
+		p, err := filepath.Abs(*path)
+		if err != nil {
+			return fmt.Errorf("error resolving absolute path for %s: %w", *path, err)
+		}
+		*path = p
+	}
+
 	return nil
 }
 
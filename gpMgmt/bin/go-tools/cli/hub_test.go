@@ -3,11 +3,19 @@ package cli_test
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
 )
 
 func TestGetHostnames(t *testing.T) {
@@ -54,3 +62,129 @@ func TestGetHostnames(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteConfig(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	newConfig := func(hostnames ...string) *hub.Config {
+		return &hub.Config{
+			Port:        constants.DefaultHubPort,
+			AgentPort:   constants.DefaultAgentPort,
+			Hostnames:   hostnames,
+			LogDir:      "/tmp/logDir",
+			ServiceName: constants.DefaultServiceName,
+			GpHome:      "gphome",
+			Credentials: &utils.GpCredentials{},
+		}
+	}
+
+	t.Run("copies the configuration over SSH when there is no previous configuration", func(t *testing.T) {
+		defer resetCLIVars()
+		path := filepath.Join(t.TempDir(), "gp.conf")
+
+		executor := &testutils.MockExecutor{}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			t.Fatalf("unexpected connection to the hub")
+			return nil, nil
+		}
+
+		err := cli.WriteConfig(newConfig("sdw1", "sdw2"), path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if len(executor.Copies) != 1 || executor.Copies[0].Path != path {
+			t.Fatalf("got %+v, want a copy of %s", executor.Copies, path)
+		}
+	})
+
+	t.Run("copies the configuration over SSH when the hub is not running", func(t *testing.T) {
+		defer resetCLIVars()
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		err := newConfig("sdw1", "sdw2").WriteLocal(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		executor := &testutils.MockExecutor{}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			return nil, errors.New("error")
+		}
+
+		err = cli.WriteConfig(newConfig("sdw1", "sdw2"), path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if len(executor.Copies) != 1 {
+			t.Fatalf("got %+v, want a copy of %s", executor.Copies, path)
+		}
+	})
+
+	t.Run("distributes the configuration through the hub when it is running with the same hosts", func(t *testing.T) {
+		defer resetCLIVars()
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		err := newConfig("sdw1", "sdw2").WriteLocal(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		executor := &testutils.MockExecutor{}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().DistributeConfig(gomock.Any(), &idl.DistributeConfigRequest{})
+			return hubClient, nil
+		}
+
+		conf := newConfig("sdw1", "sdw2")
+		conf.LogDir = "/tmp/newLogDir"
+		err = cli.WriteConfig(conf, path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if len(executor.Copies) != 0 {
+			t.Fatalf("got %+v, want no copies over SSH", executor.Copies)
+		}
+
+		written := &hub.Config{}
+		err = written.Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if written.LogDir != "/tmp/newLogDir" {
+			t.Fatalf("got log directory %s, want /tmp/newLogDir", written.LogDir)
+		}
+	})
+
+	t.Run("reports every host to which the configuration could not be distributed", func(t *testing.T) {
+		defer resetCLIVars()
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		err := newConfig("sdw1", "sdw2").WriteLocal(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().DistributeConfig(gomock.Any(), gomock.Any()).Return(nil, hub.NewHostErrors([]hub.HostResult{
+				{Hostname: "sdw1"},
+				{Hostname: "sdw2", Err: errors.New("failed to push file")},
+			}))
+			return hubClient, nil
+		}
+
+		err = cli.WriteConfig(newConfig("sdw1", "sdw2"), path)
+		expected := "could not distribute configuration on 1 of 2 hosts: sdw2"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	RunInitCluster = RunInitClusterFunc
+	InitCluster    = InitClusterFunc
+
+	clusterSpecPath string
+)
+
+var mirroringStrategies = map[string]idl.MirroringStrategy{
+	"none":   idl.MirroringStrategy_NO_MIRRORS,
+	"group":  idl.MirroringStrategy_GROUP,
+	"spread": idl.MirroringStrategy_SPREAD,
+}
+
+// ClusterSpec is the declarative spec of a new cluster, as read from YAML
+type ClusterSpec struct {
+	Coordinator struct {
+		Hostname  string // host of the hub, the local host when not set
+		Directory string
+		Port      int
+	}
+	Segments struct {
+		Hosts              []string
+		PrimaryDirectories []string
+		PrimaryBasePort    int
+		MirrorDirectories  []string
+		MirrorBasePort     int
+		Mirroring          string // none, group or spread, group when mirror directories are given
+		Prefix             string
+	}
+	Locale   string
+	Encoding string
+}
+
+func initCmd() *cobra.Command {
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize a cluster",
+	}
+
+	initCmd.AddCommand(initClusterCmd())
+
+	return initCmd
+}
+
+func initClusterCmd() *cobra.Command {
+	initClusterCmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Initialize a new cluster from a spec",
+		Long: `Initialize a new cluster from a YAML spec declaring the coordinator, the segment
+hosts, their data directories and ports, the mirroring strategy and the locale
+and encoding, e.g.
+
+  coordinator:
+    directory: /data/coordinator
+    port: 5432
+  segments:
+    hosts: [sdw1, sdw2]
+    primaryDirectories: [/data/primary, /data/primary]
+    primaryBasePort: 6000
+    mirrorDirectories: [/data/mirror, /data/mirror]
+    mirrorBasePort: 7000
+    mirroring: group
+  encoding: UTF-8
+
+The data directory of every segment is <directory>/gpseg<content ID>. Whatever
+was created is removed when the initialization fails.`,
+		PreRunE: InitializeCommand,
+		RunE:    RunInitCluster,
+	}
+
+	initClusterCmd.Flags().StringVar(&clusterSpecPath, "spec", "", `Path to the YAML spec of the cluster`)
+	_ = initClusterCmd.MarkFlagRequired("spec")
+
+	return initClusterCmd
+}
+
+func RunInitClusterFunc(cmd *cobra.Command, args []string) error {
+	request, err := ReadClusterSpec(clusterSpecPath)
+	if err != nil {
+		return err
+	}
+
+	return InitCluster(Conf, request)
+}
+
+// ReadClusterSpec reads the spec of a new cluster, rejecting unknown keys
+func ReadClusterSpec(path string) (*idl.InitClusterRequest, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	v.SetDefault("coordinator.port", constants.DefaultCoordinatorPort)
+	v.SetDefault("encoding", "UTF-8")
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not read cluster spec %s: %w", path, err)
+	}
+
+	var spec ClusterSpec
+	err = v.UnmarshalExact(&spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse cluster spec %s: %w", path, err)
+	}
+
+	if spec.Coordinator.Hostname == "" {
+		spec.Coordinator.Hostname, err = Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not get the coordinator hostname: %w", err)
+		}
+	}
+
+	mirroring := strings.ToLower(spec.Segments.Mirroring)
+	if mirroring == "" {
+		mirroring = "none"
+		if len(spec.Segments.MirrorDirectories) > 0 {
+			mirroring = "group"
+		}
+	}
+	strategy, ok := mirroringStrategies[mirroring]
+	if !ok {
+		return nil, fmt.Errorf("invalid mirroring strategy %q in cluster spec %s, expected none, group or spread", spec.Segments.Mirroring, path)
+	}
+
+	return &idl.InitClusterRequest{
+		Coordinator: &idl.CoordinatorSpec{
+			Hostname:  spec.Coordinator.Hostname,
+			Directory: spec.Coordinator.Directory,
+			Port:      int32(spec.Coordinator.Port),
+		},
+		Segments: &idl.SegmentsSpec{
+			Hosts:              spec.Segments.Hosts,
+			PrimaryDirectories: spec.Segments.PrimaryDirectories,
+			PrimaryBasePort:    int32(spec.Segments.PrimaryBasePort),
+			MirrorDirectories:  spec.Segments.MirrorDirectories,
+			MirrorBasePort:     int32(spec.Segments.MirrorBasePort),
+			Mirroring:          strategy,
+			Prefix:             spec.Segments.Prefix,
+		},
+		Locale:   spec.Locale,
+		Encoding: spec.Encoding,
+	}, nil
+}
+
+func InitClusterFunc(hubConfig *hub.Config, request *idl.InitClusterRequest) error {
+	client, err := ConnectToHub(hubConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
+	}
+
+	stream, err := client.InitCluster(context.Background(), request)
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("could not initialize cluster: %w", err)
+	}
+
+	return nil
+}
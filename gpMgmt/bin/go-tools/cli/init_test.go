@@ -0,0 +1,192 @@
+package cli_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+func writeClusterSpec(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cluster.yaml")
+	err := os.WriteFile(path, []byte(contents), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return path
+}
+
+func TestReadClusterSpec(t *testing.T) {
+	t.Run("reads the spec of the cluster", func(t *testing.T) {
+		path := writeClusterSpec(t, `
+coordinator:
+  hostname: cdw
+  directory: /data/coordinator
+  port: 15432
+segments:
+  hosts: [sdw1, sdw2, sdw3]
+  primaryDirectories: [/data/primary, /data/primary]
+  primaryBasePort: 6000
+  mirrorDirectories: [/data/mirror, /data/mirror]
+  mirrorBasePort: 7000
+  mirroring: spread
+  prefix: demoseg
+locale: en_US.utf8
+encoding: LATIN1
+`)
+
+		result, err := cli.ReadClusterSpec(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := &idl.InitClusterRequest{
+			Coordinator: &idl.CoordinatorSpec{Hostname: "cdw", Directory: "/data/coordinator", Port: 15432},
+			Segments: &idl.SegmentsSpec{
+				Hosts:              []string{"sdw1", "sdw2", "sdw3"},
+				PrimaryDirectories: []string{"/data/primary", "/data/primary"},
+				PrimaryBasePort:    6000,
+				MirrorDirectories:  []string{"/data/mirror", "/data/mirror"},
+				MirrorBasePort:     7000,
+				Mirroring:          idl.MirroringStrategy_SPREAD,
+				Prefix:             "demoseg",
+			},
+			Locale:   "en_US.utf8",
+			Encoding: "LATIN1",
+		}
+		if !proto.Equal(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("defaults to the local coordinator and group mirroring when mirrored", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+
+		path := writeClusterSpec(t, `
+coordinator:
+  directory: /data/coordinator
+segments:
+  hosts: [sdw1, sdw2]
+  primaryDirectories: [/data/primary]
+  primaryBasePort: 6000
+  mirrorDirectories: [/data/mirror]
+  mirrorBasePort: 7000
+`)
+
+		result, err := cli.ReadClusterSpec(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if result.Coordinator.Hostname != "cdw" || result.Coordinator.Port != 5432 || result.Segments.Mirroring != idl.MirroringStrategy_GROUP || result.Encoding != "UTF-8" {
+			t.Fatalf("got %+v, want the defaults to be set", result)
+		}
+	})
+
+	t.Run("errors out on unknown keys", func(t *testing.T) {
+		path := writeClusterSpec(t, `
+coordinator:
+  directory: /data/coordinator
+  datadir: /data/coordinator/gpseg-1
+`)
+
+		_, err := cli.ReadClusterSpec(path)
+		expectedStr := "could not parse cluster spec " + path
+		if err == nil || !strings.HasPrefix(err.Error(), expectedStr) || !strings.Contains(err.Error(), "datadir") {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+
+	t.Run("errors out on an invalid mirroring strategy", func(t *testing.T) {
+		path := writeClusterSpec(t, `
+coordinator:
+  hostname: cdw
+segments:
+  mirroring: ring
+`)
+
+		_, err := cli.ReadClusterSpec(path)
+		expectedStr := `invalid mirroring strategy "ring" in cluster spec ` + path + ", expected none, group or spread"
+		if err == nil || err.Error() != expectedStr {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+
+	t.Run("errors out when the spec can not be read", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing.yaml")
+
+		_, err := cli.ReadClusterSpec(path)
+		expectedStr := "could not read cluster spec " + path
+		if err == nil || !strings.HasPrefix(err.Error(), expectedStr) {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+}
+
+func TestRunInitCluster(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("sends the spec to the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		path := writeClusterSpec(t, `
+coordinator:
+  hostname: cdw
+  directory: /data/coordinator
+segments:
+  hosts: [sdw1]
+  primaryDirectories: [/data/primary]
+  primaryBasePort: 6000
+`)
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().InitCluster(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, in *idl.InitClusterRequest, _ ...interface{}) (idl.Hub_InitClusterClient, error) {
+				if in.Coordinator.Directory != "/data/coordinator" || in.Segments.Mirroring != idl.MirroringStrategy_NO_MIRRORS {
+					t.Fatalf("got %+v, want the spec to be sent", in)
+				}
+				return &testutils.MockHubReplies{}, nil
+			})
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"init", "cluster", "--spec", path})
+		initClusterCmd, _, _ := cmd.Find([]string{"init", "cluster"})
+		initClusterCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("returns error when the hub fails to initialize the cluster", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().InitCluster(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{Err: errors.New("TEST Error initializing cluster")}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.InitCluster(cli.Conf, &idl.InitClusterRequest{})
+		expectedStr := "could not initialize cluster: TEST Error initializing cluster"
+		if err == nil || err.Error() != expectedStr {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+}
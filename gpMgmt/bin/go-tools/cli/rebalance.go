@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/spf13/cobra"
+)
+
+var (
+	RunRebalance     = RunRebalanceFunc
+	RebalanceCluster = RebalanceClusterFunc
+
+	rebalanceDryRun    bool
+	rebalanceBatchSize int
+)
+
+func rebalanceCmd() *cobra.Command {
+	rebalanceCmd := &cobra.Command{
+		Use:   "rebalance",
+		Short: "Return the segments to their preferred role",
+		Long: `Return the segments to their preferred role after failovers. For every content
+whose primary is not in its preferred role, the segment acting as primary is
+stopped so that FTS promotes its mirror, then it is rewound with pg_rewind and
+started as mirror.`,
+		PreRunE: InitializeCommand,
+		RunE:    RunRebalance,
+	}
+
+	addCoordinatorPortFlag(rebalanceCmd)
+	rebalanceCmd.Flags().BoolVar(&rebalanceDryRun, "dry-run", false, `Only show the segments that would be rebalanced`)
+	rebalanceCmd.Flags().IntVar(&rebalanceBatchSize, "batch-size", constants.DefaultRebalanceBatchSize, `Maximum number of segments rebalanced at once on every host`)
+
+	return rebalanceCmd
+}
+
+func RunRebalanceFunc(cmd *cobra.Command, args []string) error {
+	if rebalanceBatchSize < 1 {
+		return fmt.Errorf("invalid batch size %d, expected a positive number", rebalanceBatchSize)
+	}
+
+	return RebalanceCluster(Conf, coordinatorPort, rebalanceDryRun, rebalanceBatchSize)
+}
+
+func RebalanceClusterFunc(hubConfig *hub.Config, port int, dryRun bool, batchSize int) error {
+	client, err := ConnectToHub(hubConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
+	}
+
+	stream, err := client.RebalanceCluster(context.Background(), &idl.RebalanceClusterRequest{
+		CoordinatorPort: int32(port),
+		DryRun:          dryRun,
+		BatchSize:       int32(batchSize),
+	})
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("could not rebalance segments: %w", err)
+	}
+
+	return nil
+}
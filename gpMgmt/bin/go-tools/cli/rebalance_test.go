@@ -0,0 +1,90 @@
+package cli_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+func TestRunRebalance(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("asks the hub for the plan on a dry run", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().RebalanceCluster(gomock.Any(), &idl.RebalanceClusterRequest{
+				CoordinatorPort: 5432,
+				DryRun:          true,
+				BatchSize:       2,
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"rebalance", "--coordinator-port", "5432", "--dry-run", "--batch-size", "2"})
+		rebalanceCmd, _, _ := cmd.Find([]string{"rebalance"})
+		rebalanceCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("uses the default batch size", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().RebalanceCluster(gomock.Any(), &idl.RebalanceClusterRequest{
+				CoordinatorPort: 5432,
+				BatchSize:       4,
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"rebalance", "--coordinator-port", "5432"})
+		rebalanceCmd, _, _ := cmd.Find([]string{"rebalance"})
+		rebalanceCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("returns error when the batch size is not positive", func(t *testing.T) {
+		defer resetCLIVars()
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"rebalance", "--batch-size", "0"})
+		rebalanceCmd, _, _ := cmd.Find([]string{"rebalance"})
+		rebalanceCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		expectedStr := "invalid batch size 0, expected a positive number"
+		if err == nil || err.Error() != expectedStr {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+	t.Run("returns error when the hub fails to rebalance the segments", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().RebalanceCluster(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{Err: errors.New("TEST Error rebalancing segments")}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.RebalanceCluster(cli.Conf, 5432, false, 4)
+		expectedStr := "could not rebalance segments: TEST Error rebalancing segments"
+		if err == nil || err.Error() != expectedStr {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+}
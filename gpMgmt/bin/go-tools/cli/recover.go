@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/spf13/cobra"
+)
+
+var (
+	RunRecover     = RunRecoverFunc
+	RecoverCluster = RecoverClusterFunc
+
+	fullRecovery     bool
+	recoveryContents []int
+)
+
+func recoverCmd() *cobra.Command {
+	recoverCmd := &cobra.Command{
+		Use:   "recover",
+		Short: "Recover the segments marked down",
+		Long: `Recover the segments marked down in gp_segment_configuration from the segments
+acting as their primary, then start them. The data directories are rewound with
+pg_rewind, or copied over with pg_basebackup when --full is given.`,
+		PreRunE: InitializeCommand,
+		RunE:    RunRecover,
+	}
+
+	addCoordinatorPortFlag(recoverCmd)
+	recoverCmd.Flags().BoolVar(&fullRecovery, "full", false, `Copy the data directories of the segments instead of rewinding them`)
+	recoverCmd.Flags().IntSliceVar(&recoveryContents, "content", []int{}, `Content ID of the segments to recover, every segment marked down when not given`)
+
+	return recoverCmd
+}
+
+func RunRecoverFunc(cmd *cobra.Command, args []string) error {
+	return RecoverCluster(Conf, coordinatorPort, fullRecovery, recoveryContents)
+}
+
+func RecoverClusterFunc(hubConfig *hub.Config, port int, full bool, contentIDs []int) error {
+	client, err := ConnectToHub(hubConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
+	}
+
+	request := &idl.RecoverClusterRequest{
+		CoordinatorPort: int32(port),
+		Full:            full,
+	}
+	for _, contentID := range contentIDs {
+		request.ContentIds = append(request.ContentIds, int32(contentID))
+	}
+
+	stream, err := client.RecoverCluster(context.Background(), request)
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("could not recover segments: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cli_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+func TestRunRecover(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("recovers the given contents through the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().RecoverCluster(gomock.Any(), &idl.RecoverClusterRequest{
+				CoordinatorPort: 5432,
+				Full:            true,
+				ContentIds:      []int32{0, 2},
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"recover", "--coordinator-port", "5432", "--full", "--content", "0,2"})
+		recoverCmd, _, _ := cmd.Find([]string{"recover"})
+		recoverCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("recovers every down segment incrementally by default", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().RecoverCluster(gomock.Any(), &idl.RecoverClusterRequest{
+				CoordinatorPort: 5432,
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"recover", "--coordinator-port", "5432"})
+		recoverCmd, _, _ := cmd.Find([]string{"recover"})
+		recoverCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("returns error when the hub fails to recover the segments", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().RecoverCluster(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{Err: errors.New("TEST Error recovering segments")}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.RecoverCluster(cli.Conf, 5432, false, nil)
+		expectedStr := "could not recover segments: TEST Error recovering segments"
+		if err == nil || err.Error() != expectedStr {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/greenplum-db/gpdb/gp/constants"
@@ -10,6 +12,7 @@ import (
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +25,7 @@ func startCmd() *cobra.Command {
 	startCmd.AddCommand(startHubCmd())
 	startCmd.AddCommand(startAgentsCmd())
 	startCmd.AddCommand(startServiceCmd())
+	startCmd.AddCommand(startClusterCmd())
 
 	return startCmd
 }
@@ -33,6 +37,11 @@ var (
 	StartAgentsAll         = StartAgentsAllFunc
 	RunStartService        = RunStartServiceFunc
 	WaitAndRetryHubConnect = WaitAndRetryHubConnectFunc
+	RunStartCluster        = RunStartClusterFunc
+	StartCluster           = StartClusterFunc
+
+	coordinatorDataDir string
+	coordinatorPort    int
 )
 
 func startHubCmd() *cobra.Command {
@@ -65,7 +74,7 @@ func RunStartHubFunc(cmd *cobra.Command, args []string) error {
 	}
 	gplog.Info("Hub %s started successfully", Conf.ServiceName)
 	if Verbose {
-		_, err = ShowHubStatus(Conf, true)
+		_, err = ShowHubStatus(Conf, utils.TableRenderer{SkipHeader: true})
 		if err != nil {
 			return fmt.Errorf("could not retrieve hub status: %w", err)
 		}
@@ -101,9 +110,8 @@ func RunStartAgentFunc(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	gplog.Info("Agents started successfully")
 	if Verbose {
-		err = ShowAgentsStatus(Conf, true)
+		err = ShowAgentsStatus(Conf, utils.TableRenderer{SkipHeader: true})
 		if err != nil {
 			return fmt.Errorf("could not retrieve agent status: %w", err)
 		}
@@ -118,7 +126,10 @@ func StartAgentsAllFunc(hubConfig *hub.Config) (idl.HubClient, error) {
 		return client, err
 	}
 
-	_, err = client.StartAgents(context.Background(), &idl.StartAgentsRequest{})
+	stream, err := client.StartAgentsStream(context.Background(), &idl.StartAgentsRequest{})
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
 	if err != nil {
 		return client, fmt.Errorf("could not start agents: %w", err)
 	}
@@ -145,7 +156,7 @@ func RunStartServiceFunc(cmd *cobra.Command, args []string) error {
 	}
 	gplog.Info("Agents %s started successfully", Conf.ServiceName)
 	if Verbose {
-		err = PrintServicesStatus()
+		err = PrintServicesStatus(utils.TableRenderer{})
 		if err != nil {
 			return err
 		}
@@ -154,10 +165,12 @@ func RunStartServiceFunc(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// WaitAndRetryHubConnectFunc waits for the health service of the hub to
+// report it as serving
 func WaitAndRetryHubConnectFunc() error {
 	var err error
 	for try := 0; try < constants.MaxRetries; try++ {
-		_, err = ConnectToHub(Conf)
+		err = CheckHubHealth(Conf, "")
 		if err == nil {
 			return nil
 		}
@@ -166,3 +179,60 @@ func WaitAndRetryHubConnectFunc() error {
 	}
 	return fmt.Errorf("failed to connect to hub service. Check hub service log for details. Error: %w", err)
 }
+
+func startClusterCmd() *cobra.Command {
+	startClusterCmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   "Start the coordinator, its standby and all segments",
+		PreRunE: InitializeCommand,
+		RunE:    RunStartCluster,
+	}
+
+	addCoordinatorFlags(startClusterCmd)
+	addSkipUnreachableFlag(startClusterCmd)
+
+	return startClusterCmd
+}
+
+func RunStartClusterFunc(cmd *cobra.Command, args []string) error {
+	return StartCluster(Conf, coordinatorDataDir, coordinatorPort)
+}
+
+func StartClusterFunc(hubConfig *hub.Config, dataDir string, port int) error {
+	client, err := ConnectToHub(hubConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
+	}
+
+	stream, err := client.StartCluster(context.Background(), &idl.StartClusterRequest{
+		CoordinatorDataDir: dataDir,
+		CoordinatorPort:    int32(port),
+		SkipUnreachable:    skipUnreachable,
+	})
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("could not start cluster: %w", err)
+	}
+
+	return nil
+}
+
+// addCoordinatorFlags adds the flags needed to locate the coordinator, which
+// default to the environment used by the legacy utilities.
+func addCoordinatorFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&coordinatorDataDir, "coordinator-data-directory", os.Getenv("COORDINATOR_DATA_DIRECTORY"), `Coordinator data directory, defaults to $COORDINATOR_DATA_DIRECTORY`)
+	addCoordinatorPortFlag(cmd)
+}
+
+// addCoordinatorPortFlag adds the flag needed to connect to the running
+// coordinator
+func addCoordinatorPortFlag(cmd *cobra.Command) {
+	defaultPort, err := strconv.Atoi(os.Getenv("PGPORT"))
+	if err != nil {
+		defaultPort = constants.DefaultCoordinatorPort
+	}
+
+	cmd.Flags().IntVar(&coordinatorPort, "coordinator-port", defaultPort, `Coordinator port, defaults to $PGPORT`)
+}
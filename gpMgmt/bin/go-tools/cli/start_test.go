@@ -10,6 +10,8 @@ import (
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
 	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
 )
 
 func TestWaitAndRetryHubConnect(t *testing.T) {
@@ -18,8 +20,8 @@ func TestWaitAndRetryHubConnect(t *testing.T) {
 
 	t.Run("WaitAndRetryHubConnect returns success on success", func(t *testing.T) {
 		defer resetCLIVars()
-		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
-			return nil, nil
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return nil
 		}
 		err := cli.WaitAndRetryHubConnect()
 		if err != nil {
@@ -29,8 +31,8 @@ func TestWaitAndRetryHubConnect(t *testing.T) {
 	t.Run("WaitAndRetryHubConnect returns failure upon failure to connect", func(t *testing.T) {
 		defer resetCLIVars()
 		expectedErr := "failed to connect to hub service. Check hub service log for details."
-		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
-			return nil, errors.New(expectedErr)
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New(expectedErr)
 		}
 
 		err := cli.WaitAndRetryHubConnect()
@@ -48,7 +50,7 @@ func TestStartAgentsAll(t *testing.T) {
 		defer resetCLIVars()
 		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
 			hubClient := mock_idl.NewMockHubClient(ctrl)
-			hubClient.EXPECT().StartAgents(gomock.Any(), gomock.Any())
+			hubClient.EXPECT().StartAgentsStream(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{}, nil)
 			return hubClient, nil
 		}
 		_, err := cli.StartAgentsAll(cli.Conf)
@@ -73,7 +75,7 @@ func TestStartAgentsAll(t *testing.T) {
 		expectedStr := "TEST: Agent Start ERROR"
 		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
 			hubClient := mock_idl.NewMockHubClient(ctrl)
-			hubClient.EXPECT().StartAgents(gomock.Any(), gomock.Any()).Return(nil, errors.New(expectedStr))
+			hubClient.EXPECT().StartAgentsStream(gomock.Any(), gomock.Any()).Return(nil, errors.New(expectedStr))
 			return hubClient, nil
 		}
 
@@ -82,6 +84,25 @@ func TestStartAgentsAll(t *testing.T) {
 			t.Fatalf("got %v, want %v", err, expectedStr)
 		}
 	})
+	t.Run("start all agent fails when the hub fails while streaming progress", func(t *testing.T) {
+		defer resetCLIVars()
+		expectedStr := "TEST: Agent Start ERROR"
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StartAgentsStream(gomock.Any(), gomock.Any()).Return(&testutils.MockHubReplies{
+				Replies: []*idl.HubReply{
+					{Message: &idl.HubReply_Progress{Progress: &idl.HostProgress{Host: "sdw1", Status: idl.HostProgress_SUCCEEDED}}},
+				},
+				Err: errors.New(expectedStr),
+			}, nil)
+			return hubClient, nil
+		}
+
+		_, err := cli.StartAgentsAll(cli.Conf)
+		if err == nil || !strings.Contains(err.Error(), expectedStr) {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
 }
 
 func TestRunStartService(t *testing.T) {
@@ -189,7 +210,7 @@ func TestRunStartHub(t *testing.T) {
 			return nil
 		}
 
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
+		cli.ShowHubStatus = func(conf *hub.Config, renderer utils.Renderer) (bool, error) {
 			return true, nil
 		}
 
@@ -219,7 +240,7 @@ func TestRunStartHub(t *testing.T) {
 			return nil
 		}
 		cli.Verbose = true
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
+		cli.ShowHubStatus = func(conf *hub.Config, renderer utils.Renderer) (bool, error) {
 			return false, errors.New(expectedStr)
 		}
 		cli.WaitAndRetryHubConnect = funcNilError()
@@ -230,3 +251,51 @@ func TestRunStartHub(t *testing.T) {
 		}
 	})
 }
+
+func TestStartCluster(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("starts the cluster through the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StartCluster(gomock.Any(), &idl.StartClusterRequest{
+				CoordinatorDataDir: "/data/qddir/gpseg-1",
+				CoordinatorPort:    5432,
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.StartCluster(cli.Conf, "/data/qddir/gpseg-1", 5432)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("returns error when the hub fails to start the cluster", func(t *testing.T) {
+		defer resetCLIVars()
+		expectedStr := "TEST Error starting cluster"
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StartCluster(gomock.Any(), gomock.Any()).Return(nil, errors.New(expectedStr))
+			return hubClient, nil
+		}
+
+		err := cli.StartCluster(cli.Conf, "/data/qddir/gpseg-1", 5432)
+		if !strings.Contains(err.Error(), expectedStr) {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+	t.Run("returns error when not able to connect to the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		expectedStr := "TEST Error connecting Hub"
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			return nil, errors.New(expectedStr)
+		}
+
+		err := cli.StartCluster(cli.Conf, "/data/qddir/gpseg-1", 5432)
+		if !strings.Contains(err.Error(), expectedStr) {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+}
@@ -4,16 +4,27 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
 	"github.com/spf13/cobra"
 )
 
 var (
+	GetHubStatus        = GetHubStatusFunc
+	GetAgentsStatus     = GetAgentsStatusFunc
 	ShowHubStatus       = ShowHubStatusFunc
 	ShowAgentsStatus    = ShowAgentsStatusFunc
 	PrintServicesStatus = PrintServicesStatusFunc
+	ShowAgentsHistory   = ShowAgentsHistoryFunc
+
+	statusFormat  = "table"
+	agentsRefresh bool
+	agentsHistory bool
 )
 
 func statusCmd() *cobra.Command {
@@ -22,6 +33,8 @@ func statusCmd() *cobra.Command {
 		Short: "Display status",
 	}
 
+	statusCmd.PersistentFlags().StringVar(&statusFormat, "format", "table", fmt.Sprintf(`Output format, one of %s`, strings.Join(utils.OutputFormats(), ", ")))
+
 	statusCmd.AddCommand(statusHubCmd())
 	statusCmd.AddCommand(statusAgentsCmd())
 	statusCmd.AddCommand(statusServicesCmd())
@@ -41,7 +54,12 @@ func statusHubCmd() *cobra.Command {
 }
 
 func RunStatusHub(cmd *cobra.Command, args []string) error {
-	_, err := ShowHubStatus(Conf, false)
+	renderer, err := utils.NewRenderer(statusFormat)
+	if err != nil {
+		return err
+	}
+
+	_, err = ShowHubStatus(Conf, renderer)
 	if err != nil {
 		return err
 	}
@@ -57,6 +75,10 @@ func statusAgentsCmd() *cobra.Command {
 		RunE:    RunStatusAgent,
 	}
 
+	addSkipUnreachableFlag(statusAgentsCmd)
+	statusAgentsCmd.Flags().BoolVar(&agentsRefresh, "refresh", false, `Request the status from every agent instead of reporting the last heartbeat of the hub`)
+	statusAgentsCmd.Flags().BoolVar(&agentsHistory, "history", false, `Show when each agent last changed state, as seen by the heartbeat of the hub`)
+
 	return statusAgentsCmd
 }
 
@@ -72,7 +94,16 @@ func statusServicesCmd() *cobra.Command {
 }
 
 func RunStatusAgent(cmd *cobra.Command, args []string) error {
-	err := ShowAgentsStatus(Conf, false)
+	renderer, err := utils.NewRenderer(statusFormat)
+	if err != nil {
+		return err
+	}
+
+	if agentsHistory {
+		return ShowAgentsHistory(Conf, renderer)
+	}
+
+	err = ShowAgentsStatus(Conf, renderer)
 	if err != nil {
 		return err
 	}
@@ -80,38 +111,123 @@ func RunStatusAgent(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func ShowHubStatusFunc(conf *hub.Config, skipHeader bool) (bool, error) {
+// GetHubStatusFunc returns the status of the hub service on this host
+func GetHubStatusFunc(conf *hub.Config) (*idl.ServiceStatus, error) {
 	message, err := Platform.GetServiceStatusMessage(fmt.Sprintf("%s_hub", conf.ServiceName))
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	status := Platform.ParseServiceStatusMessage(message)
+	utils.AddProcessUsage(Platform, &status)
 	status.Host, _ = os.Hostname()
-	Platform.DisplayServiceStatus(os.Stdout, "Hub", []*idl.ServiceStatus{&status}, skipHeader)
-	if status.Status == "Unknown" {
-		return false, nil
+	// the hub follows changes to its certificate files, so they are the ones it serves
+	status.Certificates, err = conf.Credentials.Certificates()
+	if err != nil {
+		gplog.Warn("Could not read the hub certificates: %s", err)
 	}
 
-	return true, nil
+	return &status, nil
+}
+
+// GetAgentsStatusFunc returns the status of every agent, as last seen by the
+// heartbeat of the hub unless a refresh is requested
+func GetAgentsStatusFunc(conf *hub.Config) ([]*idl.ServiceStatus, error) {
+	client, err := ConnectToHub(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if !agentsRefresh {
+		reply, err := client.ListAgents(context.Background(), &idl.ListAgentsRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("could not list agents: %w", err)
+		}
+
+		statuses := make([]*idl.ServiceStatus, 0, len(reply.Agents))
+		for _, agent := range reply.Agents {
+			statuses = append(statuses, agent.Status)
+		}
+
+		return statuses, nil
+	}
+
+	reply, err := client.StatusAgents(context.Background(), &idl.StatusAgentsRequest{SkipUnreachable: skipUnreachable})
+	if err != nil {
+		return nil, hostError("could not get agent status", err)
+	}
+
+	return reply.Statuses, nil
+}
+
+// AgentStateChangeRecord is a change of state of an agent as output by
+// gp status agents --history
+type AgentStateChangeRecord struct {
+	Host  string    `json:"host" yaml:"host"`
+	State string    `json:"state" yaml:"state"`
+	Since time.Time `json:"since" yaml:"since"`
+	Error string    `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
-func ShowAgentsStatusFunc(conf *hub.Config, skipHeader bool) error {
+// ShowAgentsHistoryFunc renders the state changes of every agent seen by the
+// heartbeat of the hub, oldest first for each host
+func ShowAgentsHistoryFunc(conf *hub.Config, renderer utils.Renderer) error {
 	client, err := ConnectToHub(conf)
 	if err != nil {
 		return err
 	}
 
-	reply, err := client.StatusAgents(context.Background(), &idl.StatusAgentsRequest{})
+	reply, err := client.ListAgents(context.Background(), &idl.ListAgentsRequest{})
+	if err != nil {
+		return fmt.Errorf("could not list agents: %w", err)
+	}
+
+	report := utils.NewReport("HOST", "STATE", "SINCE", "ERROR")
+	for _, agent := range reply.Agents {
+		for _, change := range agent.History {
+			record := AgentStateChangeRecord{
+				Host:  agent.Host,
+				State: change.State,
+				Since: change.Time.AsTime().UTC(),
+				Error: change.Error,
+			}
+			report.Add(record, record.Host, record.State, record.Since.Format(time.RFC3339), record.Error)
+		}
+	}
+
+	return renderer.Render(os.Stdout, report)
+}
+
+// ShowHubStatusFunc renders the status of the hub, returning whether it is running
+func ShowHubStatusFunc(conf *hub.Config, renderer utils.Renderer) (bool, error) {
+	status, err := GetHubStatus(conf)
+	if err != nil {
+		return false, err
+	}
+
+	err = renderer.Render(os.Stdout, utils.ServiceStatusReport("Hub", []*idl.ServiceStatus{status}))
+	if err != nil {
+		return false, err
+	}
+
+	return isRunning(status), nil
+}
+
+func ShowAgentsStatusFunc(conf *hub.Config, renderer utils.Renderer) error {
+	statuses, err := GetAgentsStatus(conf)
 	if err != nil {
 		return err
 	}
-	Platform.DisplayServiceStatus(os.Stdout, "Agent", reply.Statuses, skipHeader)
 
-	return nil
+	return renderer.Render(os.Stdout, utils.ServiceStatusReport("Agent", statuses))
 }
 
 func RunServiceStatus(cmd *cobra.Command, args []string) error {
-	err := PrintServicesStatus()
+	renderer, err := utils.NewRenderer(statusFormat)
+	if err != nil {
+		return err
+	}
+
+	err = PrintServicesStatus(renderer)
 	if err != nil {
 		return err
 	}
@@ -119,20 +235,36 @@ func RunServiceStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func PrintServicesStatusFunc() error {
-	// TODO: Check if Hub is down, do not check for Agents
-	hubRunning, err := ShowHubStatus(Conf, false)
+// PrintServicesStatusFunc renders the status of the hub and the agents as a
+// single report, leaving out the agents when the hub is not running
+func PrintServicesStatusFunc(renderer utils.Renderer) error {
+	hubStatus, err := GetHubStatus(Conf)
 	if err != nil {
 		return err
 	}
-	if !hubRunning {
-		fmt.Println("Hub service not running, not able to fetch agent status.")
-		return nil
+	report := utils.ServiceStatusReport("Hub", []*idl.ServiceStatus{hubStatus})
+
+	if isRunning(hubStatus) {
+		agentStatuses, err := GetAgentsStatus(Conf)
+		if err != nil {
+			return err
+		}
+		report.Append(utils.ServiceStatusReport("Agent", agentStatuses))
 	}
-	err = ShowAgentsStatus(Conf, true)
+
+	err = renderer.Render(os.Stdout, report)
 	if err != nil {
 		return err
 	}
 
+	if !isRunning(hubStatus) {
+		// not on stdout, which may be parsed by scripts
+		fmt.Fprintln(os.Stderr, "Hub service not running, not able to fetch agent status.")
+	}
+
 	return nil
 }
+
+func isRunning(status *idl.ServiceStatus) bool {
+	return status.Status != "Unknown"
+}
@@ -1,9 +1,12 @@
 package cli_test
 
 import (
+	"encoding/json"
 	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/greenplum-db/gpdb/gp/cli"
@@ -12,22 +15,73 @@ import (
 	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
 	"github.com/greenplum-db/gpdb/gp/testutils"
 	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestPrintServicesStatus(t *testing.T) {
 	setupTest(t)
 	defer teardownTest()
 
+	hubStatus := &idl.ServiceStatus{Host: "cdw", Status: "Running", Pid: 1234, Uptime: "10s"}
+	agentStatuses := []*idl.ServiceStatus{
+		{Host: "sdw1", Status: "Running", Pid: 2345, Uptime: "5s"},
+	}
+
 	t.Run("returns no error when there's none", func(t *testing.T) {
 		defer resetCLIVars()
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
-			return true, nil
+		cli.GetHubStatus = func(conf *hub.Config) (*idl.ServiceStatus, error) {
+			return hubStatus, nil
 		}
-		cli.ShowAgentsStatus = func(conf *hub.Config, skipHeader bool) error {
-			return nil
+		cli.GetAgentsStatus = func(conf *hub.Config) ([]*idl.ServiceStatus, error) {
+			return agentStatuses, nil
+		}
+
+		err := cli.PrintServicesStatus(utils.TableRenderer{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("renders the hub and agents as a single report", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.GetHubStatus = func(conf *hub.Config) (*idl.ServiceStatus, error) {
+			return hubStatus, nil
+		}
+		cli.GetAgentsStatus = func(conf *hub.Config) ([]*idl.ServiceStatus, error) {
+			return agentStatuses, nil
+		}
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.PrintServicesStatus(utils.JSONRenderer{})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var records []utils.ServiceStatusRecord
+		err = json.Unmarshal([]byte(output), &records)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		expected := []utils.ServiceStatusRecord{
+			{Role: "Hub", Host: "cdw", Status: "Running", Pid: 1234, Uptime: "10s"},
+			{Role: "Agent", Host: "sdw1", Status: "Running", Pid: 2345, Uptime: "5s"},
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Fatalf("got %+v, want %+v", records, expected)
+		}
+	})
+	t.Run("does not get the agent status when the hub is not running", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.GetHubStatus = func(conf *hub.Config) (*idl.ServiceStatus, error) {
+			return &idl.ServiceStatus{Status: "Unknown"}, nil
+		}
+		cli.GetAgentsStatus = func(conf *hub.Config) ([]*idl.ServiceStatus, error) {
+			t.Fatalf("unexpected call to get the agent status")
+			return nil, nil
 		}
 
-		err := cli.PrintServicesStatus()
+		err := cli.PrintServicesStatus(utils.TableRenderer{})
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
@@ -35,10 +89,10 @@ func TestPrintServicesStatus(t *testing.T) {
 	t.Run("returns an error when error printing Hub status", func(t *testing.T) {
 		defer resetCLIVars()
 		expectedStr := "TEST Error printing Hub status"
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
-			return false, errors.New(expectedStr)
+		cli.GetHubStatus = func(conf *hub.Config) (*idl.ServiceStatus, error) {
+			return nil, errors.New(expectedStr)
 		}
-		err := cli.PrintServicesStatus()
+		err := cli.PrintServicesStatus(utils.TableRenderer{})
 		if !strings.Contains(err.Error(), expectedStr) {
 			t.Fatalf("got %v, want %v", err, expectedStr)
 		}
@@ -46,14 +100,14 @@ func TestPrintServicesStatus(t *testing.T) {
 	t.Run("returns an error when error printing Agent status", func(t *testing.T) {
 		defer resetCLIVars()
 		expectedStr := "TEST Error printing Agent status"
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
-			return true, nil
+		cli.GetHubStatus = func(conf *hub.Config) (*idl.ServiceStatus, error) {
+			return hubStatus, nil
 		}
-		cli.ShowAgentsStatus = func(conf *hub.Config, skipHeader bool) error {
-			return errors.New(expectedStr)
+		cli.GetAgentsStatus = func(conf *hub.Config) ([]*idl.ServiceStatus, error) {
+			return nil, errors.New(expectedStr)
 		}
 
-		err := cli.PrintServicesStatus()
+		err := cli.PrintServicesStatus(utils.TableRenderer{})
 		if !strings.Contains(err.Error(), expectedStr) {
 			t.Fatalf("got %v, want %v", err, expectedStr)
 		}
@@ -66,7 +120,9 @@ func TestRunServiceStatus(t *testing.T) {
 
 	t.Run("returns no error when there is none", func(t *testing.T) {
 		defer resetCLIVars()
-		cli.PrintServicesStatus = funcNilError()
+		cli.PrintServicesStatus = func(renderer utils.Renderer) error {
+			return nil
+		}
 
 		err := cli.RunServiceStatus(nil, nil)
 		if err != nil {
@@ -76,7 +132,7 @@ func TestRunServiceStatus(t *testing.T) {
 	t.Run("returns error when print service status fails", func(t *testing.T) {
 		expectedStr := "TEST Error printing service status"
 		defer resetCLIVars()
-		cli.PrintServicesStatus = func() error {
+		cli.PrintServicesStatus = func(renderer utils.Renderer) error {
 			return errors.New(expectedStr)
 		}
 
@@ -95,11 +151,11 @@ func TestShowAgentStatus(t *testing.T) {
 		defer resetCLIVars()
 		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
 			hubClient := mock_idl.NewMockHubClient(ctrl)
-			hubClient.EXPECT().StatusAgents(gomock.Any(), gomock.Any()).Return(&idl.StatusAgentsReply{}, nil)
+			hubClient.EXPECT().ListAgents(gomock.Any(), gomock.Any()).Return(&idl.ListAgentsReply{}, nil)
 			return hubClient, nil
 		}
 
-		err := cli.ShowAgentsStatus(cli.Conf, false)
+		err := cli.ShowAgentsStatus(cli.Conf, utils.TableRenderer{})
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
@@ -108,11 +164,68 @@ func TestShowAgentStatus(t *testing.T) {
 		defer resetCLIVars()
 		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
 			hubClient := mock_idl.NewMockHubClient(ctrl)
-			hubClient.EXPECT().StatusAgents(gomock.Any(), gomock.Any()).Return(&idl.StatusAgentsReply{}, nil)
+			hubClient.EXPECT().ListAgents(gomock.Any(), gomock.Any()).Return(&idl.ListAgentsReply{}, nil)
+			return hubClient, nil
+		}
+
+		err := cli.ShowAgentsStatus(cli.Conf, utils.TableRenderer{SkipHeader: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("reports the agents as last seen by the hub", func(t *testing.T) {
+		defer resetCLIVars()
+		lastSeen := timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ListAgents(gomock.Any(), &idl.ListAgentsRequest{}).Return(&idl.ListAgentsReply{
+				Agents: []*idl.AgentInfo{
+					{Host: "sdw1", State: "alive", Status: &idl.ServiceStatus{Host: "sdw1", Status: "running", Pid: 123, LastSeen: lastSeen}},
+					{Host: "sdw2", State: "unreachable", Status: &idl.ServiceStatus{Host: "sdw2", Status: "unreachable", LastSeen: lastSeen}},
+				},
+			}, nil)
+			return hubClient, nil
+		}
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.ShowAgentsStatus(cli.Conf, utils.JSONRenderer{})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var records []utils.ServiceStatusRecord
+		err = json.Unmarshal([]byte(output), &records)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		seen := lastSeen.AsTime()
+		expected := []utils.ServiceStatusRecord{
+			{Role: "Agent", Host: "sdw1", Status: "running", Pid: 123, LastSeen: &seen},
+			{Role: "Agent", Host: "sdw2", Status: "unreachable", LastSeen: &seen},
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Fatalf("got %+v, want %+v", records, expected)
+		}
+	})
+	t.Run("requests the status from the agents when asked to refresh it", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StatusAgents(gomock.Any(), &idl.StatusAgentsRequest{SkipUnreachable: true}).Return(&idl.StatusAgentsReply{}, nil)
 			return hubClient, nil
 		}
 
-		err := cli.ShowAgentsStatus(cli.Conf, true)
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"status", "agents", "--refresh", "--skip-unreachable"})
+		statusAgentsCmd, _, _ := cmd.Find([]string{"status", "agents"})
+		statusAgentsCmd.PreRunE = nil
+
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
@@ -124,13 +237,70 @@ func TestShowAgentStatus(t *testing.T) {
 			return nil, errors.New(expectedStr)
 		}
 
-		err := cli.ShowAgentsStatus(cli.Conf, true)
+		err := cli.ShowAgentsStatus(cli.Conf, utils.TableRenderer{SkipHeader: true})
 		if !strings.Contains(err.Error(), expectedStr) {
 			t.Fatalf("got %v, want %v", err, expectedStr)
 		}
 	})
 }
 
+func TestShowAgentsHistory(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("renders the state changes of every agent", func(t *testing.T) {
+		defer resetCLIVars()
+		wentDark := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ListAgents(gomock.Any(), gomock.Any()).Return(&idl.ListAgentsReply{
+				Agents: []*idl.AgentInfo{
+					{Host: "sdw1", History: []*idl.AgentStateChange{
+						{State: "alive", Time: timestamppb.New(wentDark.Add(-time.Hour))},
+						{State: "unreachable", Time: timestamppb.New(wentDark), Error: "connection refused"},
+					}},
+				},
+			}, nil)
+			return hubClient, nil
+		}
+
+		var err error
+		output := captureStdout(t, func() {
+			err = cli.ShowAgentsHistory(cli.Conf, utils.JSONRenderer{})
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var records []cli.AgentStateChangeRecord
+		err = json.Unmarshal([]byte(output), &records)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		expected := []cli.AgentStateChangeRecord{
+			{Host: "sdw1", State: "alive", Since: wentDark.Add(-time.Hour)},
+			{Host: "sdw1", State: "unreachable", Since: wentDark, Error: "connection refused"},
+		}
+		if !reflect.DeepEqual(records, expected) {
+			t.Fatalf("got %+v, want %+v", records, expected)
+		}
+	})
+	t.Run("returns error when not able to list the agents", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ListAgents(gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+			return hubClient, nil
+		}
+
+		err := cli.ShowAgentsHistory(cli.Conf, utils.TableRenderer{})
+		expected := "could not list agents: error"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
+
 func TestShowHubStatus(t *testing.T) {
 	setupTest(t)
 	defer teardownTest()
@@ -141,7 +311,7 @@ func TestShowHubStatus(t *testing.T) {
 		cli.Platform = mockPlatform
 		defer func() { cli.Platform = utils.GetPlatform() }()
 
-		_, err := cli.ShowHubStatus(cli.Conf, true)
+		_, err := cli.ShowHubStatus(cli.Conf, utils.TableRenderer{SkipHeader: true})
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
@@ -153,7 +323,7 @@ func TestShowHubStatus(t *testing.T) {
 		cli.Platform = mockPlatform
 		defer func() { cli.Platform = utils.GetPlatform() }()
 
-		_, err := cli.ShowHubStatus(cli.Conf, true)
+		_, err := cli.ShowHubStatus(cli.Conf, utils.TableRenderer{SkipHeader: true})
 		if !strings.Contains(err.Error(), expectedStr) {
 			t.Fatalf("got %v, want %v", err, expectedStr)
 		}
@@ -166,7 +336,7 @@ func TestRunStatusAgent(t *testing.T) {
 
 	t.Run("return no error when there is none", func(t *testing.T) {
 		defer resetCLIVars()
-		cli.ShowAgentsStatus = func(conf *hub.Config, skipHeader bool) error {
+		cli.ShowAgentsStatus = func(conf *hub.Config, renderer utils.Renderer) error {
 			return nil
 		}
 
@@ -178,7 +348,7 @@ func TestRunStatusAgent(t *testing.T) {
 	t.Run("return error when there is error getting agent status", func(t *testing.T) {
 		defer resetCLIVars()
 		expectedStr := "TEST Error getting agent status"
-		cli.ShowAgentsStatus = func(conf *hub.Config, skipHeader bool) error {
+		cli.ShowAgentsStatus = func(conf *hub.Config, renderer utils.Renderer) error {
 			return errors.New(expectedStr)
 		}
 
@@ -195,7 +365,7 @@ func TestRunStatusHub(t *testing.T) {
 
 	t.Run("return no error when there is none", func(t *testing.T) {
 		defer resetCLIVars()
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
+		cli.ShowHubStatus = func(conf *hub.Config, renderer utils.Renderer) (bool, error) {
 			return true, nil
 		}
 
@@ -207,7 +377,7 @@ func TestRunStatusHub(t *testing.T) {
 	t.Run("return error when there is error getting agent status", func(t *testing.T) {
 		defer resetCLIVars()
 		expectedStr := "TEST Error getting agent status"
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
+		cli.ShowHubStatus = func(conf *hub.Config, renderer utils.Renderer) (bool, error) {
 			return false, errors.New(expectedStr)
 		}
 
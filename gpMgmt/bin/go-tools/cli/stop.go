@@ -3,9 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc/codes"
 	grpcStatus "google.golang.org/grpc/status"
@@ -14,6 +17,10 @@ import (
 var (
 	StopAgentService = StopAgentServiceFunc
 	StopHubService   = StopHubServiceFunc
+	RunStopCluster   = RunStopClusterFunc
+	StopCluster      = StopClusterFunc
+
+	stopMode string
 )
 
 func stopCmd() *cobra.Command {
@@ -25,6 +32,7 @@ func stopCmd() *cobra.Command {
 	stopCmd.AddCommand(stopHubCmd())
 	stopCmd.AddCommand(stopAgentsCmd())
 	stopCmd.AddCommand(StopServicesCmd())
+	stopCmd.AddCommand(stopClusterCmd())
 
 	return stopCmd
 }
@@ -47,7 +55,7 @@ func RunStopHub(cmd *cobra.Command, args []string) error {
 	}
 	gplog.Info("Hub stopped successfully")
 	if Verbose {
-		ShowHubStatus(Conf, false)
+		ShowHubStatus(Conf, utils.TableRenderer{})
 	}
 	return nil
 }
@@ -79,6 +87,8 @@ func stopAgentsCmd() *cobra.Command {
 		RunE:    RunStopAgents,
 	}
 
+	addSkipUnreachableFlag(stopAgentsCmd)
+
 	return stopAgentsCmd
 }
 
@@ -89,7 +99,7 @@ func RunStopAgents(cmd *cobra.Command, args []string) error {
 	}
 	gplog.Info("Agents stopped successfully")
 	if Verbose {
-		ShowAgentsStatus(Conf, false)
+		ShowAgentsStatus(Conf, utils.TableRenderer{})
 	}
 	return nil
 }
@@ -100,9 +110,9 @@ func StopAgentServiceFunc() error {
 		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
 	}
 
-	_, err = client.StopAgents(context.Background(), &idl.StopAgentsRequest{})
+	_, err = client.StopAgents(context.Background(), &idl.StopAgentsRequest{SkipUnreachable: skipUnreachable})
 	if err != nil {
-		return fmt.Errorf("could not stop agents: %w", err)
+		return hostError("could not stop agents", err)
 	}
 	return nil
 }
@@ -131,3 +141,49 @@ func RunStopServices(cmd *cobra.Command, args []string) error {
 	gplog.Info("Hub stopped successfully")
 	return nil
 }
+
+func stopClusterCmd() *cobra.Command {
+	stopClusterCmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   "Stop the coordinator, its standby and all segments",
+		PreRunE: InitializeCommand,
+		RunE:    RunStopCluster,
+	}
+
+	addCoordinatorFlags(stopClusterCmd)
+	stopClusterCmd.Flags().StringVar(&stopMode, "mode", "smart", `Shutdown mode: smart, fast or immediate`)
+	addSkipUnreachableFlag(stopClusterCmd)
+
+	return stopClusterCmd
+}
+
+func RunStopClusterFunc(cmd *cobra.Command, args []string) error {
+	mode, ok := idl.StopMode_value[strings.ToUpper(stopMode)]
+	if !ok {
+		return fmt.Errorf("invalid shutdown mode %q, expected one of smart, fast or immediate", stopMode)
+	}
+
+	return StopCluster(Conf, coordinatorDataDir, coordinatorPort, idl.StopMode(mode))
+}
+
+func StopClusterFunc(hubConfig *hub.Config, dataDir string, port int, mode idl.StopMode) error {
+	client, err := ConnectToHub(hubConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to hub; is the hub running? Error: %v", err)
+	}
+
+	stream, err := client.StopCluster(context.Background(), &idl.StopClusterRequest{
+		CoordinatorDataDir: dataDir,
+		CoordinatorPort:    int32(port),
+		Mode:               mode,
+		SkipUnreachable:    skipUnreachable,
+	})
+	if err == nil {
+		err = ReceiveProgress(stream)
+	}
+	if err != nil {
+		return fmt.Errorf("could not stop cluster: %w", err)
+	}
+
+	return nil
+}
@@ -8,9 +8,12 @@ import (
 	"github.com/greenplum-db/gpdb/gp/cli"
 
 	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
 	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
 )
 
 func TestStopAgentService(t *testing.T) {
@@ -55,6 +58,31 @@ func TestStopAgentService(t *testing.T) {
 			t.Fatalf("got %v, want %v", err, expectedStr)
 		}
 	})
+	t.Run("StopAgentService reports every host on which the agents could not be stopped", func(t *testing.T) {
+		defer resetCLIVars()
+		_, stderr, _ := testhelper.SetupTestLogger()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StopAgents(gomock.Any(), gomock.Any()).Return(nil, hub.NewHostErrors([]hub.HostResult{
+				{Hostname: "sdw1", Err: errors.New("failed to stop agent on host sdw1")},
+				{Hostname: "sdw2"},
+				{Hostname: "sdw3", Err: errors.New("failed to stop agent on host sdw3")},
+			}))
+			return hubClient, nil
+		}
+
+		err := cli.StopAgentService()
+		expectedStr := "could not stop agents on 2 of 3 hosts: sdw1, sdw3"
+		if err == nil || err.Error() != expectedStr {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+
+		for _, expected := range []string{"[sdw1] failed to stop agent on host sdw1", "[sdw3] failed to stop agent on host sdw3"} {
+			if !strings.Contains(string(stderr.Contents()), expected) {
+				t.Fatalf("got %q, want it to contain %q", stderr.Contents(), expected)
+			}
+		}
+	})
 }
 
 func TestStopHubService(t *testing.T) {
@@ -161,7 +189,7 @@ func TestRunStopAgents(t *testing.T) {
 	t.Run("returns no error where there none in verbose", func(t *testing.T) {
 		defer resetCLIVars()
 		cli.StopAgentService = funcNilError()
-		cli.ShowAgentsStatus = func(conf *hub.Config, skipHeader bool) error {
+		cli.ShowAgentsStatus = func(conf *hub.Config, renderer utils.Renderer) error {
 			return nil
 		}
 		cli.Verbose = true
@@ -200,7 +228,7 @@ func TestRunStopHub(t *testing.T) {
 	t.Run("return no error when there is none verbose mode", func(t *testing.T) {
 		defer resetCLIVars()
 		cli.StopHubService = funcNilError()
-		cli.ShowHubStatus = func(conf *hub.Config, skipHeader bool) (bool, error) {
+		cli.ShowHubStatus = func(conf *hub.Config, renderer utils.Renderer) (bool, error) {
 			return true, nil
 		}
 		cli.Verbose = true
@@ -222,3 +250,81 @@ func TestRunStopHub(t *testing.T) {
 		}
 	})
 }
+
+func TestRunStopCluster(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("stops the cluster through the hub with the given mode", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StopCluster(gomock.Any(), &idl.StopClusterRequest{
+				CoordinatorDataDir: "/data/qddir/gpseg-1",
+				CoordinatorPort:    5432,
+				Mode:               idl.StopMode_IMMEDIATE,
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"stop", "cluster", "--coordinator-data-directory", "/data/qddir/gpseg-1", "--coordinator-port", "5432", "--mode", "immediate"})
+		stopClusterCmd, _, _ := cmd.Find([]string{"stop", "cluster"})
+		stopClusterCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("asks the hub to skip the unreachable hosts", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StopCluster(gomock.Any(), &idl.StopClusterRequest{
+				CoordinatorDataDir: "/data/qddir/gpseg-1",
+				CoordinatorPort:    5432,
+				Mode:               idl.StopMode_SMART,
+				SkipUnreachable:    true,
+			}).Return(&testutils.MockHubReplies{}, nil)
+			return hubClient, nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"stop", "cluster", "--coordinator-data-directory", "/data/qddir/gpseg-1", "--coordinator-port", "5432", "--skip-unreachable"})
+		stopClusterCmd, _, _ := cmd.Find([]string{"stop", "cluster"})
+		stopClusterCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+	t.Run("returns error when the mode is invalid", func(t *testing.T) {
+		defer resetCLIVars()
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"stop", "cluster", "--mode", "abrupt"})
+		stopClusterCmd, _, _ := cmd.Find([]string{"stop", "cluster"})
+		stopClusterCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		expectedStr := `invalid shutdown mode "abrupt"`
+		if err == nil || !strings.HasPrefix(err.Error(), expectedStr) {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+	t.Run("returns error when the hub fails to stop the cluster", func(t *testing.T) {
+		defer resetCLIVars()
+		expectedStr := "TEST Error stopping cluster"
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().StopCluster(gomock.Any(), gomock.Any()).Return(nil, errors.New(expectedStr))
+			return hubClient, nil
+		}
+
+		err := cli.StopCluster(cli.Conf, "/data/qddir/gpseg-1", 5432, idl.StopMode_SMART)
+		if !strings.Contains(err.Error(), expectedStr) {
+			t.Fatalf("got %v, want %v", err, expectedStr)
+		}
+	})
+}
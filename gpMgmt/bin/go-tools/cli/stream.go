@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+// replyReceiver is implemented by the client side of every streaming hub RPC
+type replyReceiver interface {
+	Recv() (*idl.HubReply, error)
+}
+
+// ReceiveProgress renders the replies of a streaming hub RPC as they arrive
+// and returns once the hub closes the stream. The error returned by the RPC
+// itself is only seen here, so callers must not ignore it.
+func ReceiveProgress(stream replyReceiver) error {
+	for {
+		reply, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		renderReply(reply)
+	}
+}
+
+func renderReply(reply *idl.HubReply) {
+	switch message := reply.Message.(type) {
+	case *idl.HubReply_Progress:
+		renderHostProgress(message.Progress)
+	case *idl.HubReply_Log:
+		renderLogMessage(message.Log)
+	case *idl.HubReply_Summary:
+		renderSummary(message.Summary)
+	}
+}
+
+func renderHostProgress(progress *idl.HostProgress) {
+	switch progress.Status {
+	case idl.HostProgress_RUNNING:
+		gplog.Verbose("[%s] %s", progress.Host, progress.Detail)
+	case idl.HostProgress_SUCCEEDED:
+		gplog.Info("[%s] %s", progress.Host, progress.Detail)
+	case idl.HostProgress_FAILED:
+		gplog.Error("[%s] %s", progress.Host, progress.Detail)
+	}
+}
+
+func renderLogMessage(log *idl.LogMessage) {
+	switch log.Level {
+	case idl.LogMessage_DEBUG:
+		gplog.Debug("%s", log.Message)
+	case idl.LogMessage_WARNING:
+		gplog.Warn("%s", log.Message)
+	case idl.LogMessage_ERROR:
+		gplog.Error("%s", log.Message)
+	default:
+		gplog.Info("%s", log.Message)
+	}
+}
+
+func renderSummary(summary *idl.Summary) {
+	message := summary.Message
+	if summary.Failed > 0 {
+		message = fmt.Sprintf("%s (%d succeeded, %d failed)", message, summary.Succeeded, summary.Failed)
+		gplog.Warn("%s", message)
+
+		return
+	}
+
+	gplog.Info("%s", message)
+}
@@ -0,0 +1,61 @@
+package cli_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+func TestReceiveProgress(t *testing.T) {
+	t.Run("renders the replies until the stream ends", func(t *testing.T) {
+		stdout, stderr, _ := testhelper.SetupTestLogger()
+
+		stream := &testutils.MockHubReplies{
+			Replies: []*idl.HubReply{
+				{Message: &idl.HubReply_Log{Log: &idl.LogMessage{Level: idl.LogMessage_INFO, Message: "Starting agents on 2 hosts"}}},
+				{Message: &idl.HubReply_Progress{Progress: &idl.HostProgress{Host: "sdw1", Status: idl.HostProgress_SUCCEEDED, Detail: "agent is running"}}},
+				{Message: &idl.HubReply_Progress{Progress: &idl.HostProgress{Host: "sdw2", Status: idl.HostProgress_FAILED, Detail: "connect to agent: error"}}},
+				{Message: &idl.HubReply_Summary{Summary: &idl.Summary{Succeeded: 1, Failed: 1, Message: "Agents started"}}},
+			},
+		}
+
+		err := cli.ReceiveProgress(stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		output := string(stdout.Contents())
+		for _, expected := range []string{"Starting agents on 2 hosts", "[sdw1] agent is running", "Agents started (1 succeeded, 1 failed)"} {
+			if !strings.Contains(output, expected) {
+				t.Fatalf("got %q, want it to contain %q", output, expected)
+			}
+		}
+
+		expected := "[sdw2] connect to agent: error"
+		if !strings.Contains(string(stderr.Contents()), expected) {
+			t.Fatalf("got %q, want it to contain %q", stderr.Contents(), expected)
+		}
+	})
+
+	t.Run("returns the error the stream ends with", func(t *testing.T) {
+		testhelper.SetupTestLogger()
+
+		expected := errors.New("error")
+		stream := &testutils.MockHubReplies{
+			Replies: []*idl.HubReply{
+				{Message: &idl.HubReply_Progress{Progress: &idl.HostProgress{Host: "sdw1", Status: idl.HostProgress_RUNNING}}},
+			},
+			Err: expected,
+		}
+
+		err := cli.ReceiveProgress(stream)
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+}
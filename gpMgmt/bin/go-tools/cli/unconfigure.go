@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	RunUnconfigure = RunUnconfigureFunc
+
+	unconfigureServiceDir  string
+	unconfigureServiceUser string
+	disableLingering       bool
+)
+
+// UnconfigureRecord is the outcome of gp unconfigure on a host
+type UnconfigureRecord struct {
+	Host   string `json:"host" yaml:"host"`
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func unconfigureCmd() *cobra.Command {
+	unconfigureCmd := &cobra.Command{
+		Use:   "unconfigure",
+		Short: "Stop and uninstall the hub and agent services",
+		Long: `Undo gp configure. The hub and the agents are stopped, their service files are
+removed and the service manager is reloaded, and the configuration file is
+deleted from every host. The result is reported for each host. The
+configuration file of the coordinator host is only deleted once every host is
+done, so that the command can be run again when some hosts failed.`,
+		PreRunE: InitializeCommand,
+		RunE:    RunUnconfigure,
+	}
+
+	unconfigureCmd.Flags().StringVar(&unconfigureServiceDir, "service-dir", fmt.Sprintf(DefaultServiceDir, os.Getenv("USER")), `Path to service file directory`)
+	unconfigureCmd.Flags().StringVar(&unconfigureServiceUser, "service-user", os.Getenv("USER"), `User for whom the service was configured`)
+	unconfigureCmd.Flags().BoolVar(&disableLingering, "disable-lingering", false, `Also disable user lingering on the segment hosts`)
+	addFormatFlag(unconfigureCmd)
+
+	return unconfigureCmd
+}
+
+func RunUnconfigureFunc(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Lookup("service-user").Changed && !cmd.Flags().Lookup("service-dir").Changed {
+		unconfigureServiceDir = fmt.Sprintf(DefaultServiceDir, unconfigureServiceUser)
+	}
+
+	renderer, err := utils.NewRenderer(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	hubHost, err := Hostname()
+	if err != nil {
+		return fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	executor, err := NewRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not unconfigure the hosts: %w", err)
+	}
+
+	stopServices(Conf)
+
+	records := unconfigureHosts(Conf, hubHost, executor)
+
+	report := utils.NewReport("HOST", "STATUS", "ERROR")
+	failed := make([]string, 0)
+	for _, record := range records {
+		report.Add(record, record.Host, record.Status, record.Error)
+		if record.Error != "" {
+			failed = append(failed, record.Host)
+		}
+	}
+	err = renderer.Render(os.Stdout, report)
+	if err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not unconfigure %d of %d hosts: %s, run gp unconfigure again once they are reachable", len(failed), len(records), strings.Join(failed, ", "))
+	}
+
+	err = Platform.RemoveHubService(unconfigureServiceDir, Conf.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(ConfigFilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove configuration file %s: %w", ConfigFilePath, err)
+	}
+	gplog.Info("Services unconfigured successfully")
+
+	return nil
+}
+
+// stopServices stops the agents and the hub when the hub runs. Failing to do
+// so is not fatal, as removing the services stops them on each host.
+func stopServices(conf *hub.Config) {
+	if CheckHubHealth(conf, "") != nil {
+		gplog.Debug("Hub is not running, the services are stopped on each host")
+		return
+	}
+
+	err := StopAgentService()
+	if err != nil {
+		gplog.Warn("Could not stop the agents through the hub: %s", err)
+	}
+
+	err = StopHubService()
+	if err != nil {
+		gplog.Warn("Could not stop the hub: %s", err)
+	}
+}
+
+// unconfigureHosts removes the agent service and the configuration file from
+// every segment host in parallel, and disables lingering if asked to. The
+// configuration file of the hub host is left to the caller.
+func unconfigureHosts(conf *hub.Config, hubHost string, executor remote.Executor) []UnconfigureRecord {
+	records := make([]UnconfigureRecord, len(conf.Hostnames))
+	var wg sync.WaitGroup
+	for i, host := range conf.Hostnames {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+
+			records[i] = UnconfigureRecord{Host: host, Status: "unconfigured"}
+			err := unconfigureHost(conf, host, host != hubHost, executor)
+			if err != nil {
+				records[i].Status = "failed"
+				records[i].Error = err.Error()
+			}
+		}(i, host)
+	}
+	wg.Wait()
+
+	return records
+}
+
+func unconfigureHost(conf *hub.Config, host string, removeConfig bool, executor remote.Executor) error {
+	hosts := []string{host}
+	err := Platform.RemoveAgentService(executor, hosts, unconfigureServiceDir, conf.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	if disableLingering {
+		err = Platform.DisableUserLingering(executor, hosts, unconfigureServiceUser)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !removeConfig {
+		return nil
+	}
+
+	err = executor.Run(hosts, remote.Command("rm", "-f", ConfigFilePath)).Err()
+	if err != nil {
+		return fmt.Errorf("could not remove configuration file %s: %w", ConfigFilePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package cli_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestRunUnconfigure(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	// runUnconfigure runs gp unconfigure for the cdw, sdw1 and sdw2 hosts from
+	// cdw, returning the configuration file it was given. A single executor is
+	// to be used for every host.
+	runUnconfigure := func(t *testing.T, executor *testutils.MockExecutor) (string, error) {
+		t.Helper()
+
+		configFile := filepath.Join(t.TempDir(), "gp.conf")
+		err := os.WriteFile(configFile, []byte("{}"), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		cli.Conf.Hostnames = []string{"cdw", "sdw1", "sdw2"}
+		newExecutor := executor.NewExecutor()
+		created := 0
+		cli.NewRemoteExecutor = func() (remote.Executor, error) {
+			created++
+			return newExecutor()
+		}
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"unconfigure", "--config-file", configFile})
+		unconfigureCmd, _, _ := cmd.Find([]string{"unconfigure"})
+		unconfigureCmd.PreRunE = nil
+
+		err = cmd.Execute()
+		if created != 1 {
+			t.Fatalf("got %d executors, want 1", created)
+		}
+
+		return configFile, err
+	}
+
+	t.Run("stops the running services and removes the configuration from every host", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		var calls []string
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return nil
+		}
+		cli.StopAgentService = func() error {
+			calls = append(calls, "stop agents")
+			return nil
+		}
+		cli.StopHubService = func() error {
+			calls = append(calls, "stop hub")
+			return nil
+		}
+
+		executor := &testutils.MockExecutor{}
+		configFile, err := runUnconfigure(t, executor)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if !reflect.DeepEqual(calls, []string{"stop agents", "stop hub"}) {
+			t.Fatalf("got %q, want the agents and the hub to be stopped", calls)
+		}
+		expected := []string{"rm -f " + configFile, "rm -f " + configFile}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %q, want %q", executor.Commands, expected)
+		}
+		if _, err := os.Stat(configFile); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected %s to be removed, got %v", configFile, err)
+		}
+	})
+
+	t.Run("keeps the configuration file when a host fails", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New("hub is not running")
+		}
+		cli.StopHubService = func() error {
+			t.Fatalf("unexpected stop of the hub")
+			return nil
+		}
+
+		executor := &testutils.MockExecutor{Err: func(host string, command string) error {
+			if host == "sdw2" {
+				return errors.New("connection refused")
+			}
+			return nil
+		}}
+		configFile, err := runUnconfigure(t, executor)
+		expected := "could not unconfigure 1 of 3 hosts: sdw2, run gp unconfigure again once they are reachable"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+
+		if _, err := os.Stat(configFile); err != nil {
+			t.Fatalf("expected %s to be kept, got %v", configFile, err)
+		}
+	})
+
+	t.Run("reports every host when the agent service can not be removed", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{Err: errors.New("error")}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New("hub is not running")
+		}
+
+		_, err := runUnconfigure(t, &testutils.MockExecutor{})
+		expected := "could not unconfigure 3 of 3 hosts: cdw, sdw1, sdw2, run gp unconfigure again once they are reachable"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
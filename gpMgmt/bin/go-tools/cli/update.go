@@ -0,0 +1,437 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	RunConfigureUpdate = RunConfigureUpdateFunc
+	ApplyConfigUpdate  = ApplyConfigUpdateFunc
+)
+
+// ConfigUpdate is what it takes to go from the current configuration to the
+// desired one
+type ConfigUpdate struct {
+	AddedHosts    []string
+	RemovedHosts  []string
+	Changes       []string // the changed settings, as shown in the plan
+	RestartHub    bool
+	RestartAgents bool
+}
+
+// IsEmpty returns whether the configuration is up to date
+func (update *ConfigUpdate) IsEmpty() bool {
+	return len(update.AddedHosts) == 0 && len(update.RemovedHosts) == 0 && len(update.Changes) == 0
+}
+
+// Plan returns the steps of the update, in the order they are applied
+func (update *ConfigUpdate) Plan() []string {
+	plan := make([]string, 0)
+	if len(update.AddedHosts) > 0 {
+		plan = append(plan, fmt.Sprintf("Install the agent service on hosts %s", strings.Join(update.AddedHosts, ", ")))
+	}
+	for _, change := range update.Changes {
+		plan = append(plan, fmt.Sprintf("Change %s", change))
+	}
+	plan = append(plan, "Write the configuration file to every host")
+	if len(update.RemovedHosts) > 0 {
+		plan = append(plan, fmt.Sprintf("Stop and uninstall the agent service on hosts %s", strings.Join(update.RemovedHosts, ", ")))
+	}
+	if update.RestartAgents {
+		plan = append(plan, "Restart the hub and the agents, if running")
+	} else if update.RestartHub {
+		plan = append(plan, "Restart the hub, if running")
+	}
+
+	return plan
+}
+
+func configureUpdateCmd() *cobra.Command {
+	configureUpdateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the configuration of the services",
+		Long: `Update the configuration of the services in place. Only the settings given as
+flags change. The agent service is installed on the hosts added and stopped and
+uninstalled on the hosts removed, along with the configuration file. Hosts
+carrying segments can not be removed. The running hub and agents are only
+restarted when their ports, credentials or hosts change. The plan is printed
+before it is applied, and nothing is done when the configuration is up to date.`,
+		PreRunE: InitializeCommand,
+		RunE:    RunConfigureUpdate,
+	}
+
+	configureUpdateCmd.Flags().IntVar(&agentPort, "agent-port", constants.DefaultAgentPort, `Port on which the agents should listen`)
+	configureUpdateCmd.Flags().IntVar(&hubPort, "hub-port", constants.DefaultHubPort, `Port on which the hub should listen`)
+	configureUpdateCmd.Flags().IntVar(&hubCoordinatorPort, "coordinator-port", 0, `Port of the coordinator, for the hub to keep the topology of the cluster up to date`)
+	configureUpdateCmd.Flags().StringVar(&serviceDir, "service-dir", fmt.Sprintf(DefaultServiceDir, os.Getenv("USER")), `Path to service file directory`)
+	configureUpdateCmd.Flags().StringVar(&serviceUser, "service-user", os.Getenv("USER"), `User for whom to configure the service`)
+	configureUpdateCmd.Flags().StringVar(&caCertPath, "ca-certificate", "", `Path to SSL/TLS CA certificate`)
+	configureUpdateCmd.Flags().StringVar(&caKeyPath, "ca-key", "", `Path to SSL/TLS CA private key`)
+	configureUpdateCmd.Flags().StringVar(&serverCertPath, "server-certificate", "", `Path to hub SSL/TLS server certificate`)
+	configureUpdateCmd.Flags().StringVar(&serverKeyPath, "server-key", "", `Path to hub SSL/TLS server private key`)
+	configureUpdateCmd.Flags().StringVar(&clientCertPath, "client-certificate", "", `Path to SSL/TLS client certificate`)
+	configureUpdateCmd.Flags().StringVar(&clientKeyPath, "client-key", "", `Path to SSL/TLS client private key`)
+	configureUpdateCmd.Flags().StringArrayVar(&hostnames, "host", []string{}, `Segment hostname, every segment host is to be given`)
+	configureUpdateCmd.Flags().StringVar(&hostfilePath, "hostfile", "", `Path to file containing the list of every segment hostname`)
+	configureUpdateCmd.MarkFlagsMutuallyExclusive("host", "hostfile")
+
+	return configureUpdateCmd
+}
+
+func RunConfigureUpdateFunc(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Lookup("service-user").Changed && !cmd.Flags().Lookup("service-dir").Changed {
+		serviceDir = fmt.Sprintf(DefaultServiceDir, serviceUser)
+	}
+
+	desired, err := desiredConfig(cmd, Conf)
+	if err != nil {
+		return err
+	}
+
+	update := PlanConfigUpdate(Conf, desired)
+	if update.IsEmpty() {
+		gplog.Info("The configuration is up to date, nothing to do")
+		return nil
+	}
+
+	gplog.Info("Updating the configuration:")
+	for _, step := range update.Plan() {
+		gplog.Info("  %s", step)
+	}
+
+	err = ApplyConfigUpdate(Conf, desired, update)
+	if err != nil {
+		return err
+	}
+	gplog.Info("Configuration updated successfully")
+
+	return nil
+}
+
+// desiredConfig returns the current configuration with the settings given as
+// flags changed
+func desiredConfig(cmd *cobra.Command, current *hub.Config) (*hub.Config, error) {
+	var err error
+	desired := *current
+	flags := cmd.Flags()
+
+	if flags.Changed("host") || flags.Changed("hostfile") {
+		desired.Hostnames = hostnames
+		if flags.Changed("hostfile") {
+			desired.Hostnames, err = GetHostnames(hostfilePath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(desired.Hostnames) < 1 {
+			return nil, errors.New("expected at least one host or hostlist specified")
+		}
+		seen := make(map[string]bool, len(desired.Hostnames))
+		for _, host := range desired.Hostnames {
+			if len(host) < 1 {
+				return nil, fmt.Errorf("empty host name found -- please provide a valid input host name")
+			}
+			if seen[host] {
+				return nil, fmt.Errorf("host %s is listed more than once", host)
+			}
+			seen[host] = true
+		}
+	}
+
+	if flags.Changed("hub-port") {
+		desired.Port = hubPort
+	}
+	if flags.Changed("agent-port") {
+		desired.AgentPort = agentPort
+	}
+	if flags.Changed("coordinator-port") {
+		desired.CoordinatorPort = hubCoordinatorPort
+	}
+
+	credentials := &utils.GpCredentials{}
+	if currentCredentials, ok := current.Credentials.(*utils.GpCredentials); ok {
+		*credentials = *currentCredentials
+	}
+	credentialFlags := []struct {
+		name  string
+		value string
+		field *string
+	}{
+		{"ca-certificate", caCertPath, &credentials.CACertPath},
+		{"ca-key", caKeyPath, &credentials.CAKeyPath},
+		{"server-certificate", serverCertPath, &credentials.ServerCertPath},
+		{"server-key", serverKeyPath, &credentials.ServerKeyPath},
+		{"client-certificate", clientCertPath, &credentials.ClientCertPath},
+		{"client-key", clientKeyPath, &credentials.ClientKeyPath},
+	}
+	for _, flag := range credentialFlags {
+		if !flags.Changed(flag.name) {
+			continue
+		}
+
+		// The client credentials may be cleared to use the server ones
+		path := flag.value
+		if path != "" {
+			path, err = filepath.Abs(path)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving absolute path for %s: %w", flag.value, err)
+			}
+		}
+		*flag.field = path
+		desired.Credentials = credentials
+	}
+
+	return &desired, nil
+}
+
+// PlanConfigUpdate compares the desired configuration with the current one.
+// The hub reads its hosts and the ports of the services when it starts, so it
+// is restarted when any of them change, or the credentials. The agents are
+// only restarted when their port or the credentials change.
+func PlanConfigUpdate(current *hub.Config, desired *hub.Config) *ConfigUpdate {
+	update := &ConfigUpdate{
+		AddedHosts:   hostsMissingFrom(current.Hostnames, desired.Hostnames),
+		RemovedHosts: hostsMissingFrom(desired.Hostnames, current.Hostnames),
+	}
+
+	if current.Port != desired.Port {
+		update.Changes = append(update.Changes, fmt.Sprintf("hub port from %d to %d", current.Port, desired.Port))
+		update.RestartHub = true
+	}
+	if current.AgentPort != desired.AgentPort {
+		update.Changes = append(update.Changes, fmt.Sprintf("agent port from %d to %d", current.AgentPort, desired.AgentPort))
+		update.RestartHub = true
+		update.RestartAgents = true
+	}
+	if current.CoordinatorPort != desired.CoordinatorPort {
+		update.Changes = append(update.Changes, fmt.Sprintf("coordinator port from %d to %d", current.CoordinatorPort, desired.CoordinatorPort))
+		update.RestartHub = true
+	}
+	if !reflect.DeepEqual(current.Credentials, desired.Credentials) {
+		update.Changes = append(update.Changes, "credentials")
+		update.RestartHub = true
+		update.RestartAgents = true
+	}
+	if len(update.AddedHosts) > 0 || len(update.RemovedHosts) > 0 {
+		update.RestartHub = true
+	}
+
+	return update
+}
+
+// checkRemovedHosts errors out when some of the removed hosts carry segments
+// in the topology known to the hub. The hosts are removed with a warning when
+// the hub does not know the topology.
+func checkRemovedHosts(conf *hub.Config, hosts []string, hubRunning bool) error {
+	if !hubRunning {
+		gplog.Warn("Hub is not running, could not check that the removed hosts %s carry no segments", strings.Join(hosts, ", "))
+		return nil
+	}
+
+	client, err := ConnectToHub(conf)
+	if err != nil {
+		return err
+	}
+
+	reply, err := client.ListSegmentHosts(context.Background(), &idl.ListSegmentHostsRequest{})
+	if err != nil {
+		return fmt.Errorf("could not list the segment hosts: %w", err)
+	}
+	if !reply.Known {
+		gplog.Warn("Hub does not know the coordinator port, could not check that the removed hosts %s carry no segments", strings.Join(hosts, ", "))
+		return nil
+	}
+
+	segmentHosts := make(map[string]bool, len(reply.Hosts))
+	for _, host := range reply.Hosts {
+		segmentHosts[host] = true
+	}
+	carrying := make([]string, 0)
+	for _, host := range hosts {
+		if segmentHosts[host] {
+			carrying = append(carrying, host)
+		}
+	}
+	if len(carrying) > 0 {
+		return fmt.Errorf("hosts %s carry segments of the cluster, move the segments off them before removing them", strings.Join(carrying, ", "))
+	}
+
+	return nil
+}
+
+// removeConfigFile deletes the configuration file from the removed hosts, as
+// gp unconfigure does, leaving the one of the hub host
+func removeConfigFile(executor remote.Executor, hosts []string) error {
+	hubHost, err := Hostname()
+	if err != nil {
+		return fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	remoteHosts := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if host != hubHost {
+			remoteHosts = append(remoteHosts, host)
+		}
+	}
+	if len(remoteHosts) == 0 {
+		return nil
+	}
+
+	err = executor.Run(remoteHosts, remote.Command("rm", "-f", ConfigFilePath)).Err()
+	if err != nil {
+		return fmt.Errorf("could not remove configuration file %s: %w", ConfigFilePath, err)
+	}
+
+	return nil
+}
+
+// hostsMissingFrom returns the hosts that are not among the given hostnames
+func hostsMissingFrom(hostnames []string, hosts []string) []string {
+	known := make(map[string]bool, len(hostnames))
+	for _, host := range hostnames {
+		known[host] = true
+	}
+
+	missing := make([]string, 0)
+	for _, host := range hosts {
+		if !known[host] {
+			missing = append(missing, host)
+		}
+	}
+
+	return missing
+}
+
+// ApplyConfigUpdateFunc applies the update and leaves the desired
+// configuration in Conf. The services are stopped with the current
+// configuration and started with the desired one, and only when the hub runs.
+// The configuration file is written before the agents are stopped, as the hub
+// distributes it through them when the hosts do not change. Hosts carrying
+// segments are not removed.
+func ApplyConfigUpdateFunc(current *hub.Config, desired *hub.Config, update *ConfigUpdate) error {
+	hubRunning := CheckHubHealth(current, "") == nil
+	if !hubRunning {
+		gplog.Debug("Hub is not running, the services are not restarted")
+	}
+
+	if len(update.RemovedHosts) > 0 {
+		err := checkRemovedHosts(current, update.RemovedHosts, hubRunning)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(update.AddedHosts) > 0 {
+		err := provideHostCertificates(desired, update.AddedHosts)
+		if err != nil {
+			return err
+		}
+
+		err = Platform.CreateServiceDir(update.AddedHosts, serviceDir)
+		if err != nil {
+			return err
+		}
+
+		err = Platform.CreateAndInstallAgentServiceFile(update.AddedHosts, desired.GpHome, serviceDir, desired.ServiceName)
+		if err != nil {
+			return err
+		}
+
+		err = Platform.EnableUserLingering(update.AddedHosts, serviceUser)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := WriteConfig(desired, ConfigFilePath)
+	if err != nil {
+		return err
+	}
+
+	if hubRunning && update.RestartAgents {
+		err = StopAgentService()
+		if err != nil {
+			return fmt.Errorf("could not stop agents: %w", err)
+		}
+	}
+
+	if len(update.RemovedHosts) > 0 {
+		executor, err := NewRemoteExecutor()
+		if err != nil {
+			return fmt.Errorf("could not remove the hosts: %w", err)
+		}
+
+		err = Platform.RemoveAgentService(executor, update.RemovedHosts, serviceDir, current.ServiceName)
+		if err != nil {
+			return err
+		}
+
+		err = removeConfigFile(executor, update.RemovedHosts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !hubRunning || !update.RestartHub {
+		Conf = desired
+		return nil
+	}
+
+	err = StopHubService()
+	if err != nil {
+		return err
+	}
+	err = waitForHubToStop(current)
+	if err != nil {
+		return err
+	}
+
+	Conf = desired
+	err = StartHubService(Conf.ServiceName)
+	if err != nil {
+		return err
+	}
+	err = WaitAndRetryHubConnect()
+	if err != nil {
+		return err
+	}
+	gplog.Info("Hub %s restarted successfully", Conf.ServiceName)
+
+	_, err = StartAgentsAll(Conf)
+	if err != nil {
+		return fmt.Errorf("failed to start agents. Error: %w", err)
+	}
+	gplog.Info("Agents %s started successfully", Conf.ServiceName)
+
+	return nil
+}
+
+// waitForHubToStop waits for the hub to stop answering, so that its service
+// is not started again while it is still shutting down
+func waitForHubToStop(conf *hub.Config) error {
+	for try := 0; try < constants.MaxRetries; try++ {
+		if CheckHubHealth(conf, "") != nil {
+			return nil
+		}
+
+		time.Sleep(time.Second / 2)
+	}
+
+	return errors.New("hub is still running after it was asked to stop")
+}
@@ -0,0 +1,433 @@
+package cli_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gpdb/gp/cli"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestPlanConfigUpdate(t *testing.T) {
+	current := &hub.Config{
+		Port:        4242,
+		AgentPort:   8000,
+		Hostnames:   []string{"sdw1", "sdw2"},
+		Credentials: &utils.GpCredentials{CACertPath: "/certs/ca.crt"},
+	}
+
+	cases := []struct {
+		name     string
+		update   func(conf *hub.Config)
+		expected *cli.ConfigUpdate
+	}{
+		{
+			name:     "is empty when nothing changes",
+			update:   func(conf *hub.Config) {},
+			expected: &cli.ConfigUpdate{AddedHosts: []string{}, RemovedHosts: []string{}},
+		},
+		{
+			name: "restarts the hub when the hosts change",
+			update: func(conf *hub.Config) {
+				conf.Hostnames = []string{"sdw2", "sdw3"}
+			},
+			expected: &cli.ConfigUpdate{AddedHosts: []string{"sdw3"}, RemovedHosts: []string{"sdw1"}, RestartHub: true},
+		},
+		{
+			name: "restarts the hub when its port changes",
+			update: func(conf *hub.Config) {
+				conf.Port = 4243
+			},
+			expected: &cli.ConfigUpdate{AddedHosts: []string{}, RemovedHosts: []string{}, Changes: []string{"hub port from 4242 to 4243"}, RestartHub: true},
+		},
+		{
+			name: "restarts the hub and the agents when the agent port changes",
+			update: func(conf *hub.Config) {
+				conf.AgentPort = 8001
+			},
+			expected: &cli.ConfigUpdate{AddedHosts: []string{}, RemovedHosts: []string{}, Changes: []string{"agent port from 8000 to 8001"}, RestartHub: true, RestartAgents: true},
+		},
+		{
+			name: "restarts the hub when the coordinator port changes",
+			update: func(conf *hub.Config) {
+				conf.CoordinatorPort = 5432
+			},
+			expected: &cli.ConfigUpdate{AddedHosts: []string{}, RemovedHosts: []string{}, Changes: []string{"coordinator port from 0 to 5432"}, RestartHub: true},
+		},
+		{
+			name: "restarts the hub and the agents when the credentials change",
+			update: func(conf *hub.Config) {
+				conf.Credentials = &utils.GpCredentials{CACertPath: "/certs/new-ca.crt"}
+			},
+			expected: &cli.ConfigUpdate{AddedHosts: []string{}, RemovedHosts: []string{}, Changes: []string{"credentials"}, RestartHub: true, RestartAgents: true},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			desired := *current
+			tc.update(&desired)
+
+			update := cli.PlanConfigUpdate(current, &desired)
+			if !reflect.DeepEqual(update, tc.expected) {
+				t.Fatalf("got %+v, want %+v", update, tc.expected)
+			}
+		})
+	}
+
+	t.Run("plans every step of the update", func(t *testing.T) {
+		update := &cli.ConfigUpdate{
+			AddedHosts:    []string{"sdw3"},
+			RemovedHosts:  []string{"sdw1"},
+			Changes:       []string{"agent port from 8000 to 8001"},
+			RestartHub:    true,
+			RestartAgents: true,
+		}
+
+		expected := []string{
+			"Install the agent service on hosts sdw3",
+			"Change agent port from 8000 to 8001",
+			"Write the configuration file to every host",
+			"Stop and uninstall the agent service on hosts sdw1",
+			"Restart the hub and the agents, if running",
+		}
+		if plan := update.Plan(); !reflect.DeepEqual(plan, expected) {
+			t.Fatalf("got %q, want %q", plan, expected)
+		}
+	})
+}
+
+func TestApplyConfigUpdate(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("restarts the running services with the desired configuration", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		current := cli.Conf
+		desired := *current
+		desired.AgentPort = current.AgentPort + 1
+
+		var calls []string
+		stopped := false
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			if stopped {
+				return errors.New("hub is not running")
+			}
+			return nil
+		}
+		cli.StopAgentService = func() error {
+			calls = append(calls, "stop agents")
+			return nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			calls = append(calls, "write config")
+			return nil
+		}
+		cli.StopHubService = func() error {
+			calls = append(calls, "stop hub")
+			stopped = true
+			return nil
+		}
+		cli.StartHubService = func(serviceName string) error {
+			calls = append(calls, "start hub")
+			return nil
+		}
+		cli.WaitAndRetryHubConnect = funcNilError()
+		cli.StartAgentsAll = func(conf *hub.Config) (idl.HubClient, error) {
+			if conf.AgentPort != desired.AgentPort {
+				t.Fatalf("got agent port %d, want %d", conf.AgentPort, desired.AgentPort)
+			}
+			calls = append(calls, "start agents")
+			return nil, nil
+		}
+
+		err := cli.ApplyConfigUpdate(current, &desired, cli.PlanConfigUpdate(current, &desired))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"write config", "stop agents", "stop hub", "start hub", "start agents"}
+		if !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("got %q, want %q", calls, expected)
+		}
+		if cli.Conf != &desired {
+			t.Fatalf("got %+v, want the desired configuration", cli.Conf)
+		}
+	})
+
+	t.Run("distributes the configuration before stopping the agents", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		// the hosts do not change, so the hub distributes the configuration
+		// through the agents, which must still run
+		current := cli.Conf
+		desired := *current
+		desired.AgentPort = current.AgentPort + 1
+
+		agentsStopped, hubStopped := false, false
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			if hubStopped {
+				return errors.New("hub is not running")
+			}
+			return nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			if agentsStopped {
+				return errors.New("could not distribute configuration: agents are not running")
+			}
+			return nil
+		}
+		cli.StopAgentService = func() error {
+			agentsStopped = true
+			return nil
+		}
+		cli.StopHubService = func() error {
+			hubStopped = true
+			return nil
+		}
+		cli.StartHubService = func(serviceName string) error {
+			return nil
+		}
+		cli.WaitAndRetryHubConnect = funcNilError()
+		cli.StartAgentsAll = func(conf *hub.Config) (idl.HubClient, error) {
+			return nil, nil
+		}
+
+		err := cli.ApplyConfigUpdate(current, &desired, cli.PlanConfigUpdate(current, &desired))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if !agentsStopped {
+			t.Fatalf("expected the agents to be restarted")
+		}
+	})
+
+	t.Run("only writes the configuration when the hub is not running", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		current := cli.Conf
+		desired := *current
+		desired.Port = current.Port + 1
+
+		written := false
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New("hub is not running")
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			written = true
+			return nil
+		}
+		cli.StopHubService = func() error {
+			t.Fatalf("unexpected stop of the hub")
+			return nil
+		}
+
+		err := cli.ApplyConfigUpdate(current, &desired, cli.PlanConfigUpdate(current, &desired))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if !written {
+			t.Fatalf("expected the configuration to be written")
+		}
+	})
+
+	t.Run("errors out when the added hosts are missing the certificates", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("test -f", errors.New("exit status 1"))}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		current := cli.Conf
+		desired := *current
+		desired.Hostnames = append(append([]string{}, current.Hostnames...), "sdw3")
+		desired.Credentials = &utils.GpCredentials{
+			CACertPath:     "/certs/ca.crt",
+			ServerCertPath: "/certs/server.crt",
+			ServerKeyPath:  "/certs/server.key",
+		}
+
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New("hub is not running")
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		err := cli.ApplyConfigUpdate(current, &desired, cli.PlanConfigUpdate(current, &desired))
+		expected := "hosts sdw3 are missing the certificates /certs/ca.crt, /certs/server.crt, /certs/server.key, copy them there first"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
+		}
+	})
+
+	t.Run("errors out when the agent service can not be removed", func(t *testing.T) {
+		defer resetCLIVars()
+		expected := errors.New("error")
+		cli.Platform = &testutils.MockPlatform{Err: expected}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+		executor := &testutils.MockExecutor{}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+
+		current := cli.Conf
+		current.Hostnames = append(current.Hostnames, "sdw2")
+		desired := *current
+		desired.Hostnames = current.Hostnames[:1]
+
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New("hub is not running")
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			return nil
+		}
+
+		err := cli.ApplyConfigUpdate(current, &desired, cli.PlanConfigUpdate(current, &desired))
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+
+	t.Run("refuses to remove hosts carrying segments", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+
+		current := *cli.Conf
+		current.Hostnames = []string{"cdw", "sdw1", "sdw2"}
+		desired := current
+		desired.Hostnames = []string{"cdw"}
+
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return nil
+		}
+		cli.ConnectToHub = func(conf *hub.Config) (idl.HubClient, error) {
+			hubClient := mock_idl.NewMockHubClient(ctrl)
+			hubClient.EXPECT().ListSegmentHosts(gomock.Any(), gomock.Any()).Return(&idl.ListSegmentHostsReply{Hosts: []string{"cdw", "sdw2"}, Known: true}, nil)
+			return hubClient, nil
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			t.Fatalf("unexpected write of the configuration")
+			return nil
+		}
+
+		err := cli.ApplyConfigUpdate(&current, &desired, cli.PlanConfigUpdate(&current, &desired))
+		expected := "hosts sdw2 carry segments of the cluster, move the segments off them before removing them"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
+		}
+	})
+
+	t.Run("removes the configuration file from the removed hosts", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.Platform = &testutils.MockPlatform{}
+		defer func() { cli.Platform = utils.GetPlatform() }()
+		executor := &testutils.MockExecutor{}
+		cli.NewRemoteExecutor = executor.NewExecutor()
+		cli.Hostname = func() (string, error) {
+			return "cdw", nil
+		}
+
+		current := *cli.Conf
+		current.Hostnames = []string{"cdw", "sdw1", "sdw2"}
+		desired := current
+		desired.Hostnames = []string{"cdw", "sdw1"}
+
+		cli.CheckHubHealth = func(conf *hub.Config, service string) error {
+			return errors.New("hub is not running")
+		}
+		cli.WriteConfig = func(conf *hub.Config, path string) error {
+			return nil
+		}
+
+		err := cli.ApplyConfigUpdate(&current, &desired, cli.PlanConfigUpdate(&current, &desired))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{remote.Command("rm", "-f", cli.ConfigFilePath)}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %q, want %q", executor.Commands, expected)
+		}
+	})
+}
+
+func TestRunConfigureUpdate(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	t.Run("applies only the settings that are given", func(t *testing.T) {
+		defer resetCLIVars()
+		var desired *hub.Config
+		var update *cli.ConfigUpdate
+		cli.ApplyConfigUpdate = func(current *hub.Config, conf *hub.Config, u *cli.ConfigUpdate) error {
+			desired, update = conf, u
+			return nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"configure", "update", "--host", "sdw1", "--host", "sdw2"})
+		updateCmd, _, _ := cmd.Find([]string{"configure", "update"})
+		updateCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if !reflect.DeepEqual(desired.Hostnames, []string{"sdw1", "sdw2"}) || desired.Port != cli.Conf.Port || desired.AgentPort != cli.Conf.AgentPort {
+			t.Fatalf("got %+v, want only the hosts to change", desired)
+		}
+		if !update.RestartHub || update.RestartAgents {
+			t.Fatalf("got %+v, want only the hub to restart", update)
+		}
+	})
+
+	t.Run("does nothing when the configuration is up to date", func(t *testing.T) {
+		defer resetCLIVars()
+		cli.ApplyConfigUpdate = func(current *hub.Config, desired *hub.Config, update *cli.ConfigUpdate) error {
+			t.Fatalf("unexpected update of the configuration")
+			return nil
+		}
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"configure", "update", "--hub-port", "4242"})
+		updateCmd, _, _ := cmd.Find([]string{"configure", "update"})
+		updateCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out when a host is listed more than once", func(t *testing.T) {
+		defer resetCLIVars()
+
+		cmd := cli.RootCommand()
+		cmd.SetArgs([]string{"configure", "update", "--host", "sdw1", "--host", "sdw1"})
+		updateCmd, _, _ := cmd.Find([]string{"configure", "update"})
+		updateCmd.PreRunE = nil
+
+		err := cmd.Execute()
+		expected := "host sdw1 is listed more than once"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
@@ -6,9 +6,33 @@ const (
 	DefaultAgentPort   = 8000
 	DefaultServiceName = "gp"
 	ConfigFileName     = "gp.conf"
+	AuditLogFileName   = "gp_audit.jsonl"
+	ExpandStateFile    = "gp_expand.json"
 	ShellPath          = "/bin/bash"
-	GpSSH              = "gpssh"
 	MaxRetries         = 10
 	PlatformDarwin     = "darwin"
 	PlatformLinux      = "linux"
+
+	DefaultCoordinatorPort   = 5432
+	DefaultDatabase          = "template1"
+	DefaultStartTimeout      = 600 // seconds, matching gpstart
+	DefaultStopTimeout       = 120 // seconds, matching gpstop
+	DefaultSSHPort           = 22
+	DefaultSSHTimeout        = 60 // seconds
+	CertificateCheckInterval = 60 // seconds between checks of the certificate files for changes
+	HealthCheckInterval      = 5  // seconds between checks of the agents by the hub
+	GracefulStopTimeout      = 10 // seconds for the pending requests to finish when stopping the hub
+	AgentReconnectInterval   = 1  // seconds between checks of the hub for agents to reconnect to
+	AgentReconnectMaxDelay   = 60 // seconds, upper bound of the backoff between attempts to reconnect to an agent
+	HeartbeatInterval        = 10 // seconds between requests of the hub for the status of every agent
+	SegmentWatchInterval     = 10 // default seconds between checks of the segments by the agents
+	TopologyRefreshInterval  = 60 // seconds between refreshes of the topology by the hub, when it knows the coordinator port
+	DiskUsageThreshold       = 90 // default percent of a data directory file system used above which the disk is nearly full
+	EventTimeout             = 30 // seconds for an exec hook or a webhook to handle an event
+
+	EventQueueSize = 100 // events waiting for the sinks, newer ones are dropped when it is full
+
+	DefaultRebalanceBatchSize = 4 // segments rebalanced at once on every host
+
+	HubTokenEnv = "GP_HUB_TOKEN" // bearer token sent to the hub by the CLI, if set
 )
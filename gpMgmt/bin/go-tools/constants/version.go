@@ -0,0 +1,5 @@
+package constants
+
+// Version of the gp binary, set at build time with
+// -ldflags "-X github.com/greenplum-db/gpdb/gp/constants.Version=<version>"
+var Version = "dev"
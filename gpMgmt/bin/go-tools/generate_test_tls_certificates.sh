@@ -12,3 +12,11 @@ openssl req -newkey rsa:4096 -nodes -keyout ./certificates/server-key.pem -out .
 # Signed certificate
 echo "subjectAltName=DNS:$1,DNS:localhost,IP:0.0.0.0" > ./certificates/extensions.conf
 openssl x509 -req -in ./certificates/server-request.pem -days 365 -CA ./certificates/ca-cert.pem -CAkey ./certificates/ca-key.pem -CAcreateserial -out ./certificates/server-cert.pem -extfile ./certificates/extensions.conf -sha256
+
+# Client certificate signed by the same CA
+openssl req -newkey rsa:4096 -nodes -keyout ./certificates/client-key.pem -out ./certificates/client-request.pem -subj "/C=US/ST=California/L=Palo Alto/O=Greenplum/OU=GPDB/CN=gp-client" -sha256
+echo "subjectAltName=DNS:gp-client" > ./certificates/client-extensions.conf
+openssl x509 -req -in ./certificates/client-request.pem -days 365 -CA ./certificates/ca-cert.pem -CAkey ./certificates/ca-key.pem -CAcreateserial -out ./certificates/client-cert.pem -extfile ./certificates/client-extensions.conf -sha256
+
+# Self-signed client certificate, not trusted by the CA
+openssl req -x509 -sha256 -newkey rsa:4096 -days 365 -nodes -keyout ./certificates/untrusted-key.pem -out ./certificates/untrusted-cert.pem -subj "/C=US/ST=California/L=Palo Alto/O=Greenplum/OU=GPDB/CN=gp-client"
@@ -0,0 +1,144 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// AccessRole is the set of hub RPCs a client may call, each role allowing
+// everything the previous ones do
+type AccessRole string
+
+const (
+	NoRole       AccessRole = ""         // needed by the RPCs any client may call
+	ViewerRole   AccessRole = "viewer"   // status only
+	OperatorRole AccessRole = "operator" // start and stop the services and the cluster
+	AdminRole    AccessRole = "admin"    // change the configuration and credentials
+)
+
+var accessLevels = map[AccessRole]int{
+	ViewerRole:   1,
+	OperatorRole: 2,
+	AdminRole:    3,
+}
+
+// rpcRoles is the role needed to call each hub RPC. RPCs missing from the
+// table need the admin role.
+var rpcRoles = map[string]AccessRole{
+	"/idl.Hub/ListAgents":        ViewerRole,
+	"/idl.Hub/ListSegmentHosts":  ViewerRole,
+	"/idl.Hub/StatusAgents":      ViewerRole,
+	"/idl.Hub/Stop":              OperatorRole,
+	"/idl.Hub/StartAgents":       OperatorRole,
+	"/idl.Hub/StartAgentsStream": OperatorRole,
+	"/idl.Hub/StopAgents":        OperatorRole,
+	"/idl.Hub/StartCluster":      OperatorRole,
+	"/idl.Hub/StopCluster":       OperatorRole,
+	"/idl.Hub/RecoverCluster":    OperatorRole,
+	"/idl.Hub/RebalanceCluster":  OperatorRole,
+	"/idl.Hub/InitCluster":       AdminRole,
+	"/idl.Hub/ExpandCluster":     AdminRole,
+	"/idl.Hub/DistributeConfig":  AdminRole,
+	"/idl.Hub/ReloadCredentials": AdminRole,
+
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": ViewerRole,
+
+	// probes check the health of the hub without any role
+	"/grpc.health.v1.Health/Check": NoRole,
+	"/grpc.health.v1.Health/Watch": NoRole,
+}
+
+// Authorization maps the clients of the hub to their role. Clients are
+// identified by the SAN or CN of their verified certificate, or by the bearer
+// token they send, which takes precedence over the certificate. Tokens are
+// stored as the hex-encoded SHA-256 of their value.
+type Authorization struct {
+	Clients map[string]AccessRole `json:"clients,omitempty"`
+	Tokens  map[string]AccessRole `json:"tokens,omitempty"`
+}
+
+// ParseAccessRole validates the name of a role
+func ParseAccessRole(name string) (AccessRole, error) {
+	role := AccessRole(name)
+	if _, ok := accessLevels[role]; !ok {
+		return "", fmt.Errorf("invalid role %q, expected one of viewer, operator or admin", name)
+	}
+
+	return role, nil
+}
+
+// RequiredRole returns the role needed to call the RPC with the full method name
+func RequiredRole(method string) AccessRole {
+	role, ok := rpcRoles[method]
+	if !ok {
+		return AdminRole
+	}
+
+	return role
+}
+
+// Allows reports whether the role grants access to the RPCs of the other role
+func (r AccessRole) Allows(required AccessRole) bool {
+	return accessLevels[r] >= accessLevels[required]
+}
+
+// Authorize checks that the client of the request has a role allowing it to
+// call the RPC, returning a PermissionDenied error otherwise. Every client is
+// allowed when no authorization is configured.
+func (a *Authorization) Authorize(ctx context.Context, method string) error {
+	if a == nil {
+		return nil
+	}
+
+	identity, role := a.clientRole(ctx)
+	required := RequiredRole(method)
+	if !role.Allows(required) {
+		return grpcStatus.Errorf(codes.PermissionDenied, "%s is not allowed to call %s, which requires the %s role", identity, method, required)
+	}
+
+	return nil
+}
+
+// clientRole returns a description of the client along with its role, which is
+// empty when the client is not known
+func (a *Authorization) clientRole(ctx context.Context) (string, AccessRole) {
+	identity := utils.IncomingClientIdentity(ctx)
+	if identity.TokenHash != "" {
+		return identity.String(), a.Tokens[identity.TokenHash]
+	}
+	if !identity.Verified {
+		return identity.String(), ""
+	}
+
+	var role AccessRole
+	for _, name := range identity.Names {
+		if clientRole, ok := a.Clients[name]; ok && clientRole.Allows(role) {
+			role = clientRole
+		}
+	}
+
+	return identity.String(), role
+}
+
+func (s *Server) authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	err := s.Authorization.Authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) authorizeStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := s.Authorization.Authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
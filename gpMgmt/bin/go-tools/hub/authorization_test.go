@@ -0,0 +1,252 @@
+package hub_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	grpcStatus "google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpdb/gp/audit"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestRequiredRole(t *testing.T) {
+	expected := map[string]hub.AccessRole{
+		"Stop":              hub.OperatorRole,
+		"StartAgents":       hub.OperatorRole,
+		"StatusAgents":      hub.ViewerRole,
+		"ListAgents":        hub.ViewerRole,
+		"ListSegmentHosts":  hub.ViewerRole,
+		"StopAgents":        hub.OperatorRole,
+		"StartCluster":      hub.OperatorRole,
+		"StopCluster":       hub.OperatorRole,
+		"RecoverCluster":    hub.OperatorRole,
+		"RebalanceCluster":  hub.OperatorRole,
+		"InitCluster":       hub.AdminRole,
+		"ExpandCluster":     hub.AdminRole,
+		"DistributeConfig":  hub.AdminRole,
+		"ReloadCredentials": hub.AdminRole,
+		"StartAgentsStream": hub.OperatorRole,
+	}
+
+	t.Run("has a role for every hub RPC", func(t *testing.T) {
+		grpcServer := grpc.NewServer()
+		idl.RegisterHubServer(grpcServer, &hub.Server{})
+
+		for _, method := range grpcServer.GetServiceInfo()["idl.Hub"].Methods {
+			role, ok := expected[method.Name]
+			if !ok {
+				t.Fatalf("no expected role for RPC %s", method.Name)
+			}
+
+			result := hub.RequiredRole("/idl.Hub/" + method.Name)
+			if result != role {
+				t.Fatalf("got role %s for RPC %s, want %s", result, method.Name, role)
+			}
+		}
+	})
+
+	t.Run("requires no role for the health checks", func(t *testing.T) {
+		for _, method := range []string{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch"} {
+			result := hub.RequiredRole(method)
+			if result != hub.NoRole {
+				t.Fatalf("got role %s for RPC %s, want none", result, method)
+			}
+		}
+	})
+
+	t.Run("requires the admin role for unknown RPCs", func(t *testing.T) {
+		result := hub.RequiredRole("/idl.Hub/Unknown")
+		if result != hub.AdminRole {
+			t.Fatalf("got %s, want %s", result, hub.AdminRole)
+		}
+	})
+}
+
+func TestParseAccessRole(t *testing.T) {
+	for _, name := range []string{"viewer", "operator", "admin"} {
+		role, err := hub.ParseAccessRole(name)
+		if err != nil || string(role) != name {
+			t.Fatalf("got %s, %v, want %s", role, err, name)
+		}
+	}
+
+	_, err := hub.ParseAccessRole("root")
+	expected := `invalid role "root", expected one of viewer, operator or admin`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("got %v, want %s", err, expected)
+	}
+}
+
+// clientContext returns the context of a request from a client with the given
+// certificate common name, verified or not, and bearer token, if not empty
+func clientContext(commonName string, verified bool, token string) context.Context {
+	ctx := context.Background()
+
+	if commonName != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		if verified {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+
+	return ctx
+}
+
+func TestAuthorize(t *testing.T) {
+	authorization := &hub.Authorization{
+		Clients: map[string]hub.AccessRole{
+			"on-call": hub.ViewerRole,
+			"dba":     hub.OperatorRole,
+		},
+		Tokens: map[string]hub.AccessRole{
+			utils.HashToken("admin-token"):  hub.AdminRole,
+			utils.HashToken("viewer-token"): hub.ViewerRole,
+		},
+	}
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		method  string
+		allowed bool
+	}{
+		{"viewer can get the status", clientContext("on-call", true, ""), "/idl.Hub/StatusAgents", true},
+		{"viewer can not stop the cluster", clientContext("on-call", true, ""), "/idl.Hub/StopCluster", false},
+		{"operator can stop the cluster", clientContext("dba", true, ""), "/idl.Hub/StopCluster", true},
+		{"operator can not reload the credentials", clientContext("dba", true, ""), "/idl.Hub/ReloadCredentials", false},
+		{"unverified certificates have no role", clientContext("dba", false, ""), "/idl.Hub/StatusAgents", false},
+		{"unknown clients have no role", clientContext("intruder", true, ""), "/idl.Hub/StatusAgents", false},
+		{"clients without certificate have no role", clientContext("", false, ""), "/idl.Hub/StatusAgents", false},
+		{"tokens grant their role", clientContext("", false, "admin-token"), "/idl.Hub/ReloadCredentials", true},
+		{"tokens take precedence over certificates", clientContext("dba", true, "viewer-token"), "/idl.Hub/StopCluster", false},
+		{"unknown tokens have no role", clientContext("dba", true, "other-token"), "/idl.Hub/StatusAgents", false},
+		{"unknown clients can check the health", clientContext("intruder", true, ""), "/grpc.health.v1.Health/Check", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := authorization.Authorize(tc.ctx, tc.method)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if !tc.allowed && grpcStatus.Code(err) != codes.PermissionDenied {
+				t.Fatalf("got %v, want a PermissionDenied error", err)
+			}
+		})
+	}
+
+	t.Run("allows every client when there is no authorization", func(t *testing.T) {
+		var authorization *hub.Authorization
+		err := authorization.Authorize(context.Background(), "/idl.Hub/Stop")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestHubAuthorization(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	creds := testutils.CreateCertificates(t, t.TempDir(), "on-call")
+	creds.VerifyClientCerts = true
+	logDir := t.TempDir()
+	hubConfig := &hub.Config{
+		LogDir:      logDir,
+		Credentials: creds,
+		Authorization: &hub.Authorization{
+			Clients: map[string]hub.AccessRole{"on-call": hub.ViewerRole},
+			Tokens:  map[string]hub.AccessRole{utils.HashToken("secret"): hub.AdminRole},
+		},
+	}
+	startHub(t, hubConfig)
+
+	clientCreds, err := creds.LoadClientCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", hubConfig.Port), grpc.WithTransportCredentials(clientCreds), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer conn.Close()
+	client := idl.NewHubClient(conn)
+
+	t.Run("denies the unary RPCs the client is not allowed to call", func(t *testing.T) {
+		_, err := client.ReloadCredentials(context.Background(), &idl.ReloadAllCredentialsRequest{})
+		if grpcStatus.Code(err) != codes.PermissionDenied {
+			t.Fatalf("got %v, want a PermissionDenied error", err)
+		}
+	})
+
+	t.Run("denies the streaming RPCs the client is not allowed to call", func(t *testing.T) {
+		stream, err := client.StopCluster(context.Background(), &idl.StopClusterRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		_, err = stream.Recv()
+		if grpcStatus.Code(err) != codes.PermissionDenied {
+			t.Fatalf("got %v, want a PermissionDenied error", err)
+		}
+	})
+
+	t.Run("allows the RPCs the token of the client grants", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+		_, err := client.ReloadCredentials(ctx, &idl.ReloadAllCredentialsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("records the denied and allowed requests in the audit log", func(t *testing.T) {
+		file, err := os.Open(audit.Path(logDir))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		defer file.Close()
+
+		entries, err := audit.Read(file, audit.Filter{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		outcomes := []string{}
+		for _, entry := range entries {
+			outcomes = append(outcomes, fmt.Sprintf("%s %s %s", entry.Method, entry.Caller[:strings.Index(entry.Caller, " from ")], entry.Outcome))
+		}
+		expected := []string{
+			"/idl.Hub/ReloadCredentials client on-call denied",
+			"/idl.Hub/StopCluster client on-call denied",
+			"/idl.Hub/ReloadCredentials token 2bb80d53 success",
+		}
+		if !reflect.DeepEqual(outcomes, expected) {
+			t.Fatalf("got %+v, want %+v", outcomes, expected)
+		}
+	})
+}
@@ -0,0 +1,309 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+var stopModes = map[idl.StopMode]string{
+	idl.StopMode_SMART:     utils.StopModeSmart,
+	idl.StopMode_FAST:      utils.StopModeFast,
+	idl.StopMode_IMMEDIATE: utils.StopModeImmediate,
+}
+
+func (s *Server) StartCluster(in *idl.StartClusterRequest, stream idl.Hub_StartClusterServer) error {
+	progress := newProgressReporter(stream)
+	dataDir, port := in.CoordinatorDataDir, int(in.CoordinatorPort)
+
+	// As with gpstart, bring the coordinator up in utility mode just long enough
+	// to read the segment configuration, then restart it for dispatch once all
+	// the segments are running.
+	progress.Info("Starting coordinator in utility mode")
+	err := s.startCoordinator(dataDir, port, utils.GpRoleUtility)
+	if err != nil {
+		return err
+	}
+
+	topology, err := s.RefreshTopology(port, true)
+	if err != nil {
+		_ = s.stopCoordinator(dataDir, utils.StopModeFast)
+		return err
+	}
+
+	err = s.stopCoordinator(dataDir, utils.StopModeSmart)
+	if err != nil {
+		return err
+	}
+
+	conns, err := s.connectAgents(nil, in.SkipUnreachable)
+	if err != nil {
+		return err
+	}
+
+	topology = onReachableHosts(topology, conns, progress)
+	segments := topology.Filter(func(seg Segment) bool {
+		return seg.IsPrimary() || seg.IsMirror()
+	})
+	progress.Info("Starting %d segments on %d hosts", len(segments), len(segments.ByHost()))
+	err = s.startSegments(segments, progress)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Starting coordinator")
+	err = s.startCoordinator(dataDir, port, utils.GpRoleDispatch)
+	if err != nil {
+		return err
+	}
+
+	// As with gpstart, the standby follows the coordinator once it runs
+	standby := topology.Filter(Segment.IsStandby)
+	if len(standby) > 0 {
+		progress.Info("Starting standby coordinator")
+		err = s.executeOnSegments(standby, func(conn *Connection, seg Segment) error {
+			return startStandby(conn, seg, progress)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	s.resumeSegmentWatches()
+	progress.Summary("Cluster started successfully")
+
+	return nil
+}
+
+func (s *Server) StopCluster(in *idl.StopClusterRequest, stream idl.Hub_StopClusterServer) error {
+	progress := newProgressReporter(stream)
+	mode, ok := stopModes[in.Mode]
+	if !ok {
+		return fmt.Errorf("invalid stop mode %s", in.Mode)
+	}
+
+	topology, err := s.RefreshTopology(int(in.CoordinatorPort), false)
+	if err != nil {
+		return err
+	}
+
+	conns, err := s.connectAgents(nil, in.SkipUnreachable)
+	if err != nil {
+		return err
+	}
+	topology = onReachableHosts(topology, conns, progress)
+
+	s.stopSegmentWatches(true)
+	progress.Info("Stopping coordinator using %s mode", mode)
+	err = s.stopCoordinator(in.CoordinatorDataDir, mode)
+	if err != nil {
+		return err
+	}
+
+	standby := topology.Filter(Segment.IsStandby)
+	if len(standby) > 0 {
+		progress.Info("Stopping standby coordinator")
+		err = s.stopSegments(standby, in.Mode, progress)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stop the primaries before their mirrors so that no mirror is left
+	// running without its primary while the cluster is going down.
+	progress.Info("Stopping primary segments")
+	err = s.stopSegments(topology.Primaries(), in.Mode, progress)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Stopping mirror segments")
+	err = s.stopSegments(topology.Mirrors(), in.Mode, progress)
+	if err != nil {
+		return err
+	}
+	progress.Summary("Cluster stopped successfully")
+
+	return nil
+}
+
+// onReachableHosts leaves out the segments, standby included, of the hosts
+// without a connection among conns, warning about the segments skipped. The
+// coordinator is run by the hub and always kept.
+func onReachableHosts(segments Segments, conns []*Connection, progress *progressReporter) Segments {
+	reachable := make(map[string]bool, len(conns))
+	for _, conn := range conns {
+		reachable[conn.Hostname] = true
+	}
+
+	kept := segments.Filter(func(seg Segment) bool {
+		return seg.IsCoordinator() || reachable[seg.Hostname]
+	})
+	if skipped := len(segments) - len(kept); skipped > 0 {
+		unreachable := segments.Filter(func(seg Segment) bool {
+			return !seg.IsCoordinator() && !reachable[seg.Hostname]
+		}).Hostnames()
+		progress.Warn("Skipping %d segments on unreachable hosts: %s", skipped, strings.Join(unreachable, ", "))
+	}
+
+	return kept
+}
+
+func (s *Server) startCoordinator(dataDir string, port int, gpRole string) error {
+	args := utils.PgCtlStartArgs(dataDir, port, gpRole, constants.DefaultStartTimeout)
+	output, err := execCommand(utils.PgCtlPath(s.GpHome), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("could not start coordinator with data directory %s: %w, Command Output: %s", dataDir, err, string(output))
+	}
+	gplog.Info("Started coordinator with data directory %s in %s mode", dataDir, gpRole)
+
+	return nil
+}
+
+func (s *Server) stopCoordinator(dataDir string, mode string) error {
+	args := utils.PgCtlStopArgs(dataDir, mode, constants.DefaultStopTimeout)
+	output, err := execCommand(utils.PgCtlPath(s.GpHome), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("could not stop coordinator with data directory %s: %w, Command Output: %s", dataDir, err, string(output))
+	}
+	gplog.Info("Stopped coordinator with data directory %s", dataDir)
+
+	return nil
+}
+
+func (s *Server) startSegments(segments Segments, progress *progressReporter) error {
+	return s.executeOnSegments(segments, func(conn *Connection, seg Segment) error {
+		return startSegment(conn, seg, progress)
+	})
+}
+
+func startSegment(conn *Connection, seg Segment, progress *progressReporter) error {
+	progress.Running(conn.Hostname, fmt.Sprintf("starting segment %d", seg.ContentID))
+	_, err := conn.AgentClient.StartSegment(context.Background(), &idl.StartSegmentRequest{
+		DataDir:   seg.DataDir,
+		Port:      int32(seg.Port),
+		ContentId: int32(seg.ContentID),
+		Timeout:   constants.DefaultStartTimeout,
+	})
+	progress.Host(conn.Hostname, fmt.Sprintf("start segment %d with data directory %s", seg.ContentID, seg.DataDir), err)
+	if err != nil {
+		return fmt.Errorf("failed to start segment %d on host %s: %w", seg.ContentID, conn.Hostname, err)
+	}
+
+	return nil
+}
+
+func startStandby(conn *Connection, seg Segment, progress *progressReporter) error {
+	progress.Running(conn.Hostname, "starting standby coordinator")
+	_, err := conn.AgentClient.StartSegment(context.Background(), &idl.StartSegmentRequest{
+		DataDir:   seg.DataDir,
+		Port:      int32(seg.Port),
+		ContentId: int32(seg.ContentID),
+		Timeout:   constants.DefaultStartTimeout,
+		Standby:   true,
+	})
+	progress.Host(conn.Hostname, fmt.Sprintf("start standby coordinator with data directory %s", seg.DataDir), err)
+	if err != nil {
+		return fmt.Errorf("failed to start standby coordinator on host %s: %w", conn.Hostname, err)
+	}
+
+	return nil
+}
+
+func (s *Server) stopSegments(segments Segments, mode idl.StopMode, progress *progressReporter) error {
+	return s.executeOnSegments(segments, func(conn *Connection, seg Segment) error {
+		return stopSegment(conn, seg, mode, progress)
+	})
+}
+
+func stopSegment(conn *Connection, seg Segment, mode idl.StopMode, progress *progressReporter) error {
+	progress.Running(conn.Hostname, fmt.Sprintf("stopping segment %d", seg.ContentID))
+	_, err := conn.AgentClient.StopSegment(context.Background(), &idl.StopSegmentRequest{
+		DataDir:   seg.DataDir,
+		ContentId: int32(seg.ContentID),
+		Mode:      mode,
+		Timeout:   constants.DefaultStopTimeout,
+	})
+	progress.Host(conn.Hostname, fmt.Sprintf("stop segment %d with data directory %s", seg.ContentID, seg.DataDir), err)
+	if err != nil {
+		return fmt.Errorf("failed to stop segment %d on host %s: %w", seg.ContentID, conn.Hostname, err)
+	}
+
+	return nil
+}
+
+// executeOnSegments groups the segments by host and runs the request for
+// every segment in parallel, using the agent connection of its host.
+func (s *Server) executeOnSegments(segments Segments, request func(conn *Connection, seg Segment) error) error {
+	return s.executeOnSegmentsInBatches(segments, 0, request)
+}
+
+// executeOnSegmentsInBatches is executeOnSegments running the request for at
+// most batchSize segments at once on every host, or for all of them when
+// batchSize is 0.
+func (s *Server) executeOnSegmentsInBatches(segments Segments, batchSize int, request func(conn *Connection, seg Segment) error) error {
+	segmentsByHost := segments.ByHost()
+
+	s.mutex.Lock()
+	conns := make([]*Connection, 0, len(segmentsByHost))
+	for host := range segmentsByHost {
+		conn := s.getConnection(host)
+		if conn == nil {
+			s.mutex.Unlock()
+			return fmt.Errorf("no agent connection found for segment host %s", host)
+		}
+		conns = append(conns, conn)
+	}
+	s.mutex.Unlock()
+
+	return ExecuteRPC(conns, func(conn *Connection) error {
+		hostSegments := segmentsByHost[conn.Hostname]
+
+		limit := batchSize
+		if limit <= 0 {
+			limit = len(hostSegments)
+		}
+		slots := make(chan struct{}, limit)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, len(hostSegments))
+		for _, seg := range hostSegments {
+			seg := seg
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				errs <- request(conn, seg)
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// getConnection returns the connection to the agent of the host, if any. The
+// caller holds s.mutex.
+func (s *Server) getConnection(hostname string) *Connection {
+	for _, conn := range s.Conns {
+		if conn.Hostname == hostname {
+			return conn
+		}
+	}
+
+	return nil
+}
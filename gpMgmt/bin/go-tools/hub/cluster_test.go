@@ -0,0 +1,414 @@
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
+)
+
+// segment returns a segment whose address is its hostname
+func segment(dbid int, content int, role string, preferredRole string, mode string, status string, port int, hostname string, dataDir string) hub.Segment {
+	return hub.Segment{
+		DbID:          dbid,
+		ContentID:     content,
+		Role:          role,
+		PreferredRole: preferredRole,
+		Mode:          mode,
+		Status:        status,
+		Port:          port,
+		Hostname:      hostname,
+		Address:       hostname,
+		DataDir:       dataDir,
+	}
+}
+
+// mirroredSegments has a cluster of one primary per host on sdw1 and sdw2,
+// with group mirroring, which the tests adjust to their needs
+func mirroredSegments() hub.Segments {
+	return hub.Segments{
+		segment(1, -1, "p", "p", "n", "u", 5432, "cdw", "/data/qddir/gpseg-1"),
+		segment(2, 0, "p", "p", "s", "u", 6000, "sdw1", "/data/primary/gpseg0"),
+		segment(4, 0, "m", "m", "s", "u", 7000, "sdw2", "/data/mirror/gpseg0"),
+		segment(3, 1, "p", "p", "s", "u", 6000, "sdw2", "/data/primary/gpseg1"),
+		segment(5, 1, "m", "m", "s", "u", 7000, "sdw1", "/data/mirror/gpseg1"),
+	}
+}
+
+// segmentRows returns the segments as the rows of gp_segment_configuration
+func segmentRows(segments ...hub.Segment) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"dbid", "content", "role", "preferred_role", "mode", "status", "port", "hostname", "address", "datadir"})
+	for _, seg := range segments {
+		rows.AddRow(seg.DbID, seg.ContentID, seg.Role, seg.PreferredRole, seg.Mode, seg.Status, seg.Port, seg.Hostname, seg.Address, seg.DataDir)
+	}
+
+	return rows
+}
+
+func setMockSegmentConfiguration(t *testing.T, segments ...hub.Segment) {
+	t.Helper()
+
+	if len(segments) == 0 {
+		segments = mirroredSegments()
+	}
+	hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+		conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+		mock.ExpectQuery("SELECT").WillReturnRows(segmentRows(segments...))
+
+		return conn, nil
+	})
+}
+
+// standbySegment is the standby coordinator of mirroredSegments on sdw2
+func standbySegment() hub.Segment {
+	return segment(6, -1, "m", "m", "s", "u", 5432, "sdw2", "/data/standby")
+}
+
+func TestStartCluster(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hubConfig := testutils.InitializeTestEnv()
+	hubConfig.Hostnames = []string{"sdw1", "sdw2"}
+	hubServer := hub.New(hubConfig, nil)
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+
+	t.Run("starts the coordinator and all the segments", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t)
+		defer hub.ResetConnectToCoordinator()
+
+		var mutex sync.Mutex
+		var pgCtlActions []string
+		hub.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			action := args[len(args)-1]
+			if action == "start" {
+				action += " " + args[len(args)-2]
+			}
+			pgCtlActions = append(pgCtlActions, action)
+		}))
+		defer hub.ResetExecCommand()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StartSegmentReply{}, nil)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StartSegmentReply{}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.StartCluster(&idl.StartClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+		}, &testutils.MockHubStream{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"start -p 5432 -c gp_role=utility", "stop", "start -p 5432 -c gp_role=dispatch"}
+		if strings.Join(pgCtlActions, ",") != strings.Join(expected, ",") {
+			t.Fatalf("got %+v, want %+v", pgCtlActions, expected)
+		}
+	})
+
+	t.Run("starts the standby coordinator once the coordinator runs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t, append(mirroredSegments(), standbySegment())...)
+		defer hub.ResetConnectToCoordinator()
+
+		var mutex sync.Mutex
+		var calls []string
+		hub.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls = append(calls, "coordinator "+args[len(args)-1])
+		}))
+		defer hub.ResetExecCommand()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StartSegmentReply{}, nil)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(3).DoAndReturn(func(ctx context.Context, in *idl.StartSegmentRequest, opts ...interface{}) (*idl.StartSegmentReply, error) {
+			if in.Standby {
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls = append(calls, "standby start "+in.DataDir)
+			}
+			return &idl.StartSegmentReply{}, nil
+		})
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.StartCluster(&idl.StartClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+		}, &testutils.MockHubStream{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"coordinator start", "coordinator stop", "coordinator start", "standby start /data/standby"}
+		if !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("got %+v, want %+v", calls, expected)
+		}
+	})
+
+	t.Run("errors out when a segment fails to start", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t)
+		defer hub.ResetConnectToCoordinator()
+
+		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
+		defer hub.ResetExecCommand()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StartSegmentReply{}, nil)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StartSegmentReply{}, errors.New("error"))
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.StartCluster(&idl.StartClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+		}, &testutils.MockHubStream{})
+		expectedErr := "on host sdw2: error"
+		if err == nil || !strings.HasSuffix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("errors out when the coordinator fails to start", func(t *testing.T) {
+		hub.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer hub.ResetExecCommand()
+
+		err := hubServer.StartCluster(&idl.StartClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+		}, &testutils.MockHubStream{})
+		expectedErr := "could not start coordinator with data directory /data/qddir/gpseg-1: exit status 1"
+		if err == nil || !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("errors out when the agent of a segment host can not be reached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t)
+		defer hub.ResetConnectToCoordinator()
+
+		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
+		defer hub.ResetExecCommand()
+
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+		}
+
+		err := hubServer.StartCluster(&idl.StartClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+		}, &testutils.MockHubStream{})
+		expectedErr := "could not connect to agent on host sdw2: "
+		if err == nil || !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("skips the segments of unreachable hosts when asked to", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t)
+		defer hub.ResetConnectToCoordinator()
+
+		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
+		defer hub.ResetExecCommand()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StartSegmentReply{}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.StartCluster(&idl.StartClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+			SkipUnreachable:    true,
+		}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		warnings := []string{}
+		for _, reply := range stream.Replies {
+			if log := reply.GetLog(); log != nil && log.Level == idl.LogMessage_WARNING {
+				warnings = append(warnings, log.Message)
+			}
+		}
+		expected := []string{"Skipping 2 segments on unreachable hosts: sdw2"}
+		if !reflect.DeepEqual(warnings, expected) {
+			t.Fatalf("got %+v, want %+v", warnings, expected)
+		}
+		if summary := stream.Summary(); summary.Succeeded != 2 || summary.Failed != 0 {
+			t.Fatalf("got %+v, want 2 segments started", summary)
+		}
+	})
+}
+
+func TestStopCluster(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hubConfig := testutils.InitializeTestEnv()
+	hubConfig.Hostnames = []string{"sdw1", "sdw2"}
+	hubServer := hub.New(hubConfig, nil)
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+
+	t.Run("stops the coordinator, the primaries and then the mirrors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t)
+		defer hub.ResetConnectToCoordinator()
+
+		hub.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			expected := "-D /data/qddir/gpseg-1 -m fast -w -t 120 stop"
+			if strings.Join(args, " ") != expected {
+				t.Errorf("got %q, want %q", strings.Join(args, " "), expected)
+			}
+		}))
+		defer hub.ResetExecCommand()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		gomock.InOrder(
+			sdw1.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(expectStopSegment(t, "/data/primary/gpseg0")),
+			sdw1.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(expectStopSegment(t, "/data/mirror/gpseg1")),
+		)
+		gomock.InOrder(
+			sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(expectStopSegment(t, "/data/primary/gpseg1")),
+			sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(expectStopSegment(t, "/data/mirror/gpseg0")),
+		)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.StopCluster(&idl.StopClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+			Mode:               idl.StopMode_FAST,
+		}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		summary := stream.Summary()
+		expected := &idl.Summary{Succeeded: 4, Message: "Cluster stopped successfully"}
+		if summary.Succeeded != expected.Succeeded || summary.Failed != expected.Failed || summary.Message != expected.Message {
+			t.Fatalf("got %+v, want %+v", summary, expected)
+		}
+	})
+
+	t.Run("stops the standby coordinator after the coordinator", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockSegmentConfiguration(t, append(mirroredSegments(), standbySegment())...)
+		defer hub.ResetConnectToCoordinator()
+
+		coordinatorStopped := false
+		hub.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			coordinatorStopped = true
+		}))
+		defer hub.ResetExecCommand()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().StopSegment(gomock.Any(), gomock.Any()).Times(2).Return(&idl.StopSegmentReply{}, nil)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		gomock.InOrder(
+			sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, in *idl.StopSegmentRequest, opts ...interface{}) (*idl.StopSegmentReply, error) {
+				if !coordinatorStopped {
+					t.Errorf("expected the coordinator to be stopped before the standby")
+				}
+				return expectStopSegment(t, "/data/standby")(ctx, in, opts...)
+			}),
+			sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(expectStopSegment(t, "/data/primary/gpseg1")),
+			sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(expectStopSegment(t, "/data/mirror/gpseg0")),
+		)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.StopCluster(&idl.StopClusterRequest{
+			CoordinatorDataDir: "/data/qddir/gpseg-1",
+			CoordinatorPort:    5432,
+			Mode:               idl.StopMode_FAST,
+		}, &testutils.MockHubStream{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out when not able to connect to the coordinator", func(t *testing.T) {
+		expected := errors.New("error")
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			return nil, expected
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		err := hubServer.StopCluster(&idl.StopClusterRequest{}, &testutils.MockHubStream{})
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+}
+
+func expectStopSegment(t *testing.T, dataDir string) func(context.Context, *idl.StopSegmentRequest, ...interface{}) (*idl.StopSegmentReply, error) {
+	return func(ctx context.Context, in *idl.StopSegmentRequest, opts ...interface{}) (*idl.StopSegmentReply, error) {
+		if in.DataDir != dataDir {
+			t.Errorf("got %s, want %s", in.DataDir, dataDir)
+		}
+		if in.Mode != idl.StopMode_FAST {
+			t.Errorf("got %s, want %s", in.Mode, idl.StopMode_FAST)
+		}
+
+		return &idl.StopSegmentReply{}, nil
+	}
+}
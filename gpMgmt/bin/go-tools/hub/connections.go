@@ -0,0 +1,411 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+)
+
+type Connection struct {
+	Conn          *grpc.ClientConn
+	AgentClient   idl.AgentClient
+	Hostname      string
+	CancelContext func()
+}
+
+// Close releases the connection, which can not be used afterwards
+func (c *Connection) Close() {
+	if c.CancelContext != nil {
+		c.CancelContext()
+	}
+	if c.Conn != nil {
+		_ = c.Conn.Close()
+	}
+}
+
+// AgentState describes the connection of the hub to the agent of a host
+type AgentState struct {
+	Hostname    string
+	State       string    // connectivity state of the connection, NONE when there is none
+	Failures    int       // consecutive failed attempts to connect to the agent
+	LastError   error     // cause of the last failed attempt
+	NextAttempt time.Time // when the hub next dials the agent, zero when not pending
+}
+
+// reconnectState tracks a host the hub failed to connect to, so that it can
+// be dialed again in the background with an increasing delay
+type reconnectState struct {
+	failures int
+	lastErr  error
+	next     time.Time
+}
+
+// reconnectDelay doubles the delay before the next attempt with every
+// consecutive failure, up to constants.AgentReconnectMaxDelay
+func reconnectDelay(failures int) time.Duration {
+	maxDelay := constants.AgentReconnectMaxDelay * time.Second
+
+	delay := constants.AgentReconnectInterval * time.Second
+	for i := 1; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
+
+// connectAgents returns the connection to the agent of every host, dialing the
+// hosts the hub is not connected to and waiting for the others to be ready.
+// Hosts whose agent can not be reached fail the whole call, unless
+// skipUnreachable is set in which case they are reported and left out. The
+// agents are dialed without holding s.mutex, as the reconnect loop does.
+func (s *Server) connectAgents(progress *progressReporter, skipUnreachable bool) ([]*Connection, error) {
+	s.mutex.Lock()
+	hosts := append([]string{}, s.Hostnames...)
+	configured := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		configured[host] = true
+	}
+	s.removeConnections(func(conn *Connection) bool {
+		return !configured[conn.Hostname] || (conn.Conn != nil && conn.Conn.GetState() == connectivity.Shutdown)
+	})
+
+	conns := make([]*Connection, len(hosts))
+	existing := make([]bool, len(hosts))
+	for i, host := range hosts {
+		conns[i] = s.getConnection(host)
+		existing[i] = conns[i] != nil
+	}
+	s.mutex.Unlock()
+
+	errs := make([]error, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		i, host := i, host
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if existing[i] {
+				errs[i] = waitForAgent(conns[i])
+			} else {
+				conns[i], errs[i] = s.dialAgent(host)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.mutex.Lock()
+	for i, host := range hosts {
+		if errs[i] != nil {
+			s.scheduleReconnect(host, errs[i])
+			continue
+		}
+
+		// the reconnect loop or another request may have connected meanwhile
+		if !existing[i] {
+			if current := s.getConnection(host); current != nil {
+				conns[i].Close()
+				conns[i] = current
+			} else {
+				s.Conns = append(s.Conns, conns[i])
+			}
+		}
+		delete(s.reconnects, host)
+	}
+	s.mutex.Unlock()
+
+	reachable := make([]*Connection, 0, len(hosts))
+	results := make([]HostResult, 0, len(hosts))
+	unreachable := make([]string, 0)
+	for i, host := range hosts {
+		results = append(results, HostResult{Hostname: host, Err: errs[i]})
+		if errs[i] != nil {
+			progress.Host(host, "connect to agent", errs[i])
+			unreachable = append(unreachable, host)
+			continue
+		}
+
+		if existing[i] {
+			progress.Host(host, "agent is running", nil)
+		} else {
+			progress.Host(host, "connect to agent", nil)
+		}
+		reachable = append(reachable, conns[i])
+	}
+
+	if len(unreachable) > 0 {
+		if !skipUnreachable {
+			return nil, NewHostErrors(results)
+		}
+		progress.Warn("Skipping %d unreachable hosts: %s", len(unreachable), strings.Join(unreachable, ", "))
+	}
+
+	err := ensureConnectionsAreReadyFunc(reachable)
+	if err != nil {
+		return nil, err
+	}
+
+	return reachable, nil
+}
+
+// dialAgent connects to the agent of the host. The returned connection
+// reconnects with backoff by itself when the agent goes away.
+func (s *Server) dialAgent(host string) (*Connection, error) {
+	credentials, err := s.Credentials.LoadClientCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), DialTimeout)
+
+	address := fmt.Sprintf("%s:%d", host, s.AgentPort)
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithTransportCredentials(credentials),
+		grpc.WithReturnConnectionError(),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  constants.AgentReconnectInterval * time.Second,
+				Multiplier: backoff.DefaultConfig.Multiplier,
+				Jitter:     backoff.DefaultConfig.Jitter,
+				MaxDelay:   constants.AgentReconnectMaxDelay * time.Second,
+			},
+			MinConnectTimeout: DialTimeout,
+		}),
+	}
+	if s.grpcDialer != nil {
+		opts = append(opts, grpc.WithContextDialer(s.grpcDialer))
+	}
+	conn, err := grpc.DialContext(ctx, address, opts...)
+	if err != nil {
+		cancelFunc()
+		return nil, fmt.Errorf("could not connect to agent on host %s: %w", host, err)
+	}
+
+	connection := &Connection{
+		Conn:          conn,
+		AgentClient:   idl.NewAgentClient(conn),
+		Hostname:      host,
+		CancelContext: cancelFunc,
+	}
+	err = checkAgentHealth(connection)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+// waitForAgent waits up to DialTimeout for an existing connection to be ready,
+// skipping what is left of its backoff, and checks the health of the agent
+func waitForAgent(conn *Connection) error {
+	if conn.Conn == nil {
+		return nil
+	}
+
+	state := conn.Conn.GetState()
+	if state != connectivity.Ready {
+		conn.Conn.ResetConnectBackoff()
+		conn.Conn.Connect()
+
+		ctx, cancel := context.WithTimeout(context.Background(), DialTimeout)
+		defer cancel()
+		for state = conn.Conn.GetState(); state != connectivity.Ready; state = conn.Conn.GetState() {
+			if !conn.Conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("could not connect to agent on host %s: connection is %s", conn.Hostname, state)
+			}
+		}
+	}
+
+	return checkAgentHealth(conn)
+}
+
+// removeConnections closes and forgets the connections for which remove
+// returns true. The caller holds s.mutex.
+func (s *Server) removeConnections(remove func(conn *Connection) bool) {
+	kept := make([]*Connection, 0, len(s.Conns))
+	for _, conn := range s.Conns {
+		if remove(conn) {
+			gplog.Debug("Closing connection to agent on host %s", conn.Hostname)
+			conn.Close()
+			continue
+		}
+		kept = append(kept, conn)
+	}
+
+	s.Conns = kept
+}
+
+// AddConnections adds connections to agents dialed by the caller, which the
+// hub then uses as its own
+func (s *Server) AddConnections(conns ...*Connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Conns = append(s.Conns, conns...)
+}
+
+// disconnectAgents closes every agent connection and stops reconnecting to
+// the hosts that could not be reached
+func (s *Server) disconnectAgents() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeConnections(func(conn *Connection) bool {
+		return true
+	})
+	s.reconnects = nil
+}
+
+// scheduleReconnect records a failed attempt to connect to the host and when
+// to try again. The caller holds s.mutex.
+func (s *Server) scheduleReconnect(host string, err error) {
+	if s.reconnects == nil {
+		s.reconnects = make(map[string]*reconnectState)
+	}
+
+	state, ok := s.reconnects[host]
+	if !ok {
+		state = &reconnectState{}
+		s.reconnects[host] = state
+	}
+	state.failures++
+	state.lastErr = err
+	state.next = time.Now().Add(reconnectDelay(state.failures))
+}
+
+// requestReconnect hands a host the hub is not connected to over to the
+// reconnect loop, unless it is already pending or the agents are stopped. The
+// caller holds s.mutex.
+func (s *Server) requestReconnect(host string) {
+	if _, pending := s.reconnects[host]; pending || s.agentsStopped {
+		return
+	}
+
+	if s.reconnects == nil {
+		s.reconnects = make(map[string]*reconnectState)
+	}
+	s.reconnects[host] = &reconnectState{next: time.Now()}
+}
+
+// reconnectAgents dials the hosts the hub failed to connect to once their
+// delay has passed, unless the agents are stopped. Idle connections are woken
+// up so that gRPC keeps reconnecting them in the background.
+func (s *Server) reconnectAgents() {
+	now := time.Now()
+
+	s.mutex.Lock()
+	if s.agentsStopped {
+		s.mutex.Unlock()
+		return
+	}
+	for _, conn := range s.Conns {
+		if conn.Conn != nil && conn.Conn.GetState() == connectivity.Idle {
+			conn.Conn.Connect()
+		}
+	}
+
+	due := make([]string, 0)
+	for host, state := range s.reconnects {
+		conn := s.getConnection(host)
+		if conn != nil {
+			if conn.Conn == nil || conn.Conn.GetState() == connectivity.Ready {
+				delete(s.reconnects, host)
+			}
+			continue
+		}
+
+		if !now.Before(state.next) {
+			due = append(due, host)
+		}
+	}
+	s.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, host := range due {
+		host := host
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			conn, err := s.dialAgent(host)
+
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			// the agents may have been stopped or connected to meanwhile
+			if _, pending := s.reconnects[host]; !pending || s.agentsStopped || s.getConnection(host) != nil {
+				if conn != nil {
+					conn.Close()
+				}
+				return
+			}
+
+			if err != nil {
+				gplog.Debug("Could not reconnect to agent on host %s: %s", host, err)
+				s.scheduleReconnect(host, err)
+				return
+			}
+
+			gplog.Info("Reconnected to agent on host %s", host)
+			s.Conns = append(s.Conns, conn)
+			delete(s.reconnects, host)
+		}()
+	}
+	wg.Wait()
+}
+
+// watchAgentConnections reconnects to the agents at every interval until done
+// is closed
+func (s *Server) watchAgentConnections(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.reconnectAgents()
+		}
+	}
+}
+
+// AgentStates returns the state of the connection to the agent of every host
+func (s *Server) AgentStates() []AgentState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	states := make([]AgentState, 0, len(s.Hostnames))
+	for _, host := range s.Hostnames {
+		state := AgentState{Hostname: host, State: "NONE"}
+
+		conn := s.getConnection(host)
+		if conn != nil && conn.Conn != nil {
+			state.State = conn.Conn.GetState().String()
+		}
+		if reconnect, ok := s.reconnects[host]; ok {
+			state.Failures = reconnect.failures
+			state.LastError = reconnect.lastErr
+			if conn == nil {
+				state.NextAttempt = reconnect.next
+			}
+		}
+
+		states = append(states, state)
+	}
+
+	return states
+}
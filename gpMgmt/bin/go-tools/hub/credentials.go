@@ -0,0 +1,59 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+// ReloadCredentials makes the hub and every agent pick up rotated certificates
+// for their new connections, returning the certificates each of them now uses
+func (s *Server) ReloadCredentials(ctx context.Context, in *idl.ReloadAllCredentialsRequest) (*idl.ReloadAllCredentialsReply, error) {
+	s.mutex.Lock()
+	certWatcher := s.certWatcher
+	s.mutex.Unlock()
+
+	if certWatcher == nil {
+		return &idl.ReloadAllCredentialsReply{}, errors.New("the hub credentials can not be reloaded")
+	}
+
+	err := certWatcher.Reload()
+	if err != nil {
+		return &idl.ReloadAllCredentialsReply{}, fmt.Errorf("could not reload hub credentials: %w", err)
+	}
+	gplog.Info("Reloaded the hub credentials")
+
+	conns, err := s.connectAgents(nil, false)
+	if err != nil {
+		return &idl.ReloadAllCredentialsReply{}, err
+	}
+
+	agentChan := make(chan *idl.ServiceStatus, len(conns))
+	err = ExecuteRPC(conns, func(conn *Connection) error {
+		reply, err := conn.AgentClient.ReloadCredentials(context.Background(), &idl.ReloadCredentialsRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to reload credentials on host %s: %w", conn.Hostname, err)
+		}
+		agentChan <- &idl.ServiceStatus{Host: conn.Hostname, Certificates: reply.Certificates}
+
+		return nil
+	})
+	if err != nil {
+		return &idl.ReloadAllCredentialsReply{}, err
+	}
+	close(agentChan)
+
+	agents := make([]*idl.ServiceStatus, 0)
+	for agent := range agentChan {
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].Host < agents[j].Host
+	})
+
+	return &idl.ReloadAllCredentialsReply{HubCertificates: certWatcher.Certificates(), Agents: agents}, nil
+}
@@ -0,0 +1,167 @@
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// startHub starts the hub with the given credentials on a free port and
+// returns once it accepts connections
+func startHub(t *testing.T, conf *hub.Config) *hub.Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	conf.Port = listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	hubServer := hub.New(conf, nil)
+	serveHub(t, hubServer)
+
+	return hubServer
+}
+
+// serveHub starts the hub and waits for it to accept connections. The hub is
+// stopped at the end of the test, which waits for it to be done.
+func serveHub(t *testing.T, hubServer *hub.Server) {
+	t.Helper()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = hubServer.Start()
+	}()
+
+	clientCreds, err := hubServer.Credentials.LoadClientCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", hubServer.Port), grpc.WithTransportCredentials(clientCreds), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("hub did not start: %#v", err)
+	}
+	conn.Close()
+
+	t.Cleanup(func() {
+		hubServer.Shutdown()
+		<-stopped
+	})
+}
+
+func TestReloadCredentials(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("reloads the credentials of the hub and every agent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		dir := t.TempDir()
+		hubConfig := &hub.Config{
+			AgentPort:   constants.DefaultAgentPort,
+			Hostnames:   []string{"sdw1", "sdw2"},
+			LogDir:      "/tmp/logDir",
+			ServiceName: "gp",
+			GpHome:      "gphome",
+			Credentials: testutils.CreateCertificates(t, dir, "first"),
+		}
+		hubServer := startHub(t, hubConfig)
+		testutils.CreateCertificates(t, dir, "second")
+
+		for _, host := range []string{"sdw2", "sdw1"} {
+			client := mock_idl.NewMockAgentClient(ctrl)
+			client.EXPECT().ReloadCredentials(gomock.Any(), gomock.Any()).Return(&idl.ReloadCredentialsReply{
+				Certificates: []*idl.Certificate{{Path: "/certs/" + host}},
+			}, nil)
+			hubServer.AddConnections(&hub.Connection{AgentClient: client, Hostname: host})
+		}
+		hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+			return nil
+		})
+		defer hub.ResetEnsureConnectionsAreReady()
+
+		reply, err := hubServer.ReloadCredentials(context.Background(), &idl.ReloadAllCredentialsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if len(reply.HubCertificates) != 2 || reply.HubCertificates[0].Subject != "CN=second" {
+			t.Fatalf("unexpected hub certificates %+v", reply.HubCertificates)
+		}
+
+		hosts := []string{}
+		for _, agent := range reply.Agents {
+			hosts = append(hosts, agent.Host)
+			if agent.Certificates[0].Path != "/certs/"+agent.Host {
+				t.Fatalf("unexpected certificates %+v for host %s", agent.Certificates, agent.Host)
+			}
+		}
+		if !reflect.DeepEqual(hosts, []string{"sdw1", "sdw2"}) {
+			t.Fatalf("got %+v, want [sdw1 sdw2]", hosts)
+		}
+	})
+
+	t.Run("returns the hosts on which the credentials could not be reloaded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hubConfig := &hub.Config{
+			AgentPort:   constants.DefaultAgentPort,
+			Hostnames:   []string{"sdw1", "sdw2"},
+			LogDir:      "/tmp/logDir",
+			ServiceName: "gp",
+			GpHome:      "gphome",
+			Credentials: testutils.CreateCertificates(t, t.TempDir(), "first"),
+		}
+		hubServer := startHub(t, hubConfig)
+
+		expected := errors.New("error")
+		for _, host := range hubConfig.Hostnames {
+			client := mock_idl.NewMockAgentClient(ctrl)
+			if host == "sdw1" {
+				client.EXPECT().ReloadCredentials(gomock.Any(), gomock.Any()).Return(nil, expected)
+			} else {
+				client.EXPECT().ReloadCredentials(gomock.Any(), gomock.Any()).Return(&idl.ReloadCredentialsReply{}, nil)
+			}
+			hubServer.AddConnections(&hub.Connection{AgentClient: client, Hostname: host})
+		}
+		hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+			return nil
+		})
+		defer hub.ResetEnsureConnectionsAreReady()
+
+		_, err := hubServer.ReloadCredentials(context.Background(), &idl.ReloadAllCredentialsRequest{})
+		var hostErrs *hub.HostErrors
+		if !errors.As(err, &hostErrs) || len(hostErrs.Failed()) != 1 || hostErrs.Failed()[0].Hostname != "sdw1" {
+			t.Fatalf("got %v, want a failure on sdw1", err)
+		}
+	})
+
+	t.Run("errors out when the hub is not serving reloadable credentials", func(t *testing.T) {
+		hubServer := hub.New(&hub.Config{Credentials: &testutils.MockCredentials{}}, nil)
+
+		_, err := hubServer.ReloadCredentials(context.Background(), &idl.ReloadAllCredentialsRequest{})
+		expected := "the hub credentials can not be reloaded"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
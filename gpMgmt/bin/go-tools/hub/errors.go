@@ -0,0 +1,109 @@
+package hub
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// HostResult is the outcome of a request on a single host
+type HostResult struct {
+	Hostname string
+	Err      error
+	Duration time.Duration
+}
+
+// HostErrors is returned by ExecuteRPC when the request failed on at least one
+// host. It keeps the result of every host, successful or not, so that callers
+// can report all the failures of a run instead of only the first one.
+type HostErrors struct {
+	Results []HostResult
+}
+
+func NewHostErrors(results []HostResult) *HostErrors {
+	sorted := append([]HostResult{}, results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Hostname < sorted[j].Hostname
+	})
+
+	return &HostErrors{Results: sorted}
+}
+
+// Failed returns the results of the hosts on which the request failed
+func (e *HostErrors) Failed() []HostResult {
+	failed := make([]HostResult, 0)
+	for _, result := range e.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// FailedHosts returns the names of the hosts on which the request failed
+func (e *HostErrors) FailedHosts() []string {
+	hosts := make([]string, 0)
+	for _, result := range e.Failed() {
+		hosts = append(hosts, result.Hostname)
+	}
+
+	return hosts
+}
+
+func (e *HostErrors) Error() string {
+	failed := e.Failed()
+	if len(failed) == 1 {
+		return failed[0].Err.Error()
+	}
+
+	messages := make([]string, 0, len(failed))
+	for _, result := range failed {
+		messages = append(messages, result.Err.Error())
+	}
+
+	return fmt.Sprintf("failed on %d of %d hosts: %s", len(failed), len(e.Results), strings.Join(messages, "; "))
+}
+
+// Is reports whether any of the host errors matches target, so that errors.Is
+// keeps working on the errors returned by the requests.
+func (e *HostErrors) Is(target error) bool {
+	for _, result := range e.Failed() {
+		if errors.Is(result.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GRPCStatus lets gRPC send the result of every host to the client as details
+// of the returned status.
+func (e *HostErrors) GRPCStatus() *grpcStatus.Status {
+	status := grpcStatus.New(codes.Unknown, e.Error())
+
+	for _, result := range e.Results {
+		detail := &idl.HostResult{
+			Hostname: result.Hostname,
+			Duration: durationpb.New(result.Duration),
+		}
+		if result.Err != nil {
+			detail.Error = result.Err.Error()
+		}
+
+		withDetail, err := status.WithDetails(detail)
+		if err != nil {
+			return status
+		}
+		status = withDetail
+	}
+
+	return status
+}
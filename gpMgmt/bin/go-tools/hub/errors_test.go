@@ -0,0 +1,117 @@
+package hub_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+func TestExecuteRPC(t *testing.T) {
+	conns := []*hub.Connection{
+		{Hostname: "sdw1"},
+		{Hostname: "sdw2"},
+		{Hostname: "sdw3"},
+	}
+
+	t.Run("returns no error when the request succeeds on all hosts", func(t *testing.T) {
+		err := hub.ExecuteRPC(conns, func(conn *hub.Connection) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("returns the result of every host when the request fails on some of them", func(t *testing.T) {
+		expected := errors.New("error")
+		err := hub.ExecuteRPC(conns, func(conn *hub.Connection) error {
+			if conn.Hostname == "sdw2" {
+				return nil
+			}
+
+			return expected
+		})
+
+		var hostErrs *hub.HostErrors
+		if !errors.As(err, &hostErrs) {
+			t.Fatalf("got %T, want %T", err, hostErrs)
+		}
+
+		hosts := []string{}
+		for _, result := range hostErrs.Results {
+			hosts = append(hosts, result.Hostname)
+		}
+		expectedHosts := []string{"sdw1", "sdw2", "sdw3"}
+		if !reflect.DeepEqual(hosts, expectedHosts) {
+			t.Fatalf("got %+v, want %+v", hosts, expectedHosts)
+		}
+
+		failed := []string{}
+		for _, result := range hostErrs.Failed() {
+			failed = append(failed, result.Hostname)
+		}
+		expectedFailed := []string{"sdw1", "sdw3"}
+		if !reflect.DeepEqual(failed, expectedFailed) {
+			t.Fatalf("got %+v, want %+v", failed, expectedFailed)
+		}
+
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+
+		expectedErr := "failed on 2 of 3 hosts: error; error"
+		if err.Error() != expectedErr {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+	})
+}
+
+func TestHostErrors(t *testing.T) {
+	t.Run("uses the error of the failed host when only one failed", func(t *testing.T) {
+		err := hub.NewHostErrors([]hub.HostResult{
+			{Hostname: "sdw1"},
+			{Hostname: "sdw2", Err: errors.New("failed on host sdw2")},
+		})
+
+		expected := "failed on host sdw2"
+		if err.Error() != expected {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+
+	t.Run("attaches the result of every host to the gRPC status", func(t *testing.T) {
+		err := hub.NewHostErrors([]hub.HostResult{
+			{Hostname: "sdw2", Err: errors.New("error"), Duration: 2 * time.Second},
+			{Hostname: "sdw1", Duration: time.Second},
+		})
+
+		status, ok := grpcStatus.FromError(err)
+		if !ok {
+			t.Fatalf("expected a gRPC status")
+		}
+
+		results := []*idl.HostResult{}
+		for _, detail := range status.Details() {
+			result, ok := detail.(*idl.HostResult)
+			if !ok {
+				t.Fatalf("unexpected detail %T", detail)
+			}
+			results = append(results, result)
+		}
+
+		if len(results) != 2 {
+			t.Fatalf("got %d results, want 2", len(results))
+		}
+		if results[0].Hostname != "sdw1" || results[0].Error != "" || results[0].Duration.AsDuration() != time.Second {
+			t.Fatalf("unexpected result %+v", results[0])
+		}
+		if results[1].Hostname != "sdw2" || results[1].Error != "error" || results[1].Duration.AsDuration() != 2*time.Second {
+			t.Fatalf("unexpected result %+v", results[1])
+		}
+	})
+}
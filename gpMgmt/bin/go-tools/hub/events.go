@@ -0,0 +1,285 @@
+package hub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/constants"
+)
+
+const (
+	EventSegmentDown      = "segment_down"
+	EventSegmentUp        = "segment_up"
+	EventDiskNearlyFull   = "disk_nearly_full"
+	EventDiskOK           = "disk_ok"
+	EventAgentUnreachable = "agent_unreachable"
+	EventAgentReachable   = "agent_reachable"
+)
+
+// EventsConfig sets where the hub sends the events it raises, and how the
+// segments are watched. The segments are not watched when it is not set.
+type EventsConfig struct {
+	LogFile            string `json:"logFile,omitempty"`            // file the events are appended to, as JSON lines
+	Exec               string `json:"exec,omitempty"`               // executable run with every event as JSON on its standard input
+	Webhook            string `json:"webhook,omitempty"`            // local URL every event is posted to as JSON
+	WatchInterval      int    `json:"watchInterval,omitempty"`      // seconds between checks of the segments, constants.SegmentWatchInterval when 0
+	DiskUsageThreshold int    `json:"diskUsageThreshold,omitempty"` // percent, constants.DiskUsageThreshold when 0
+}
+
+// Interval returns the time between checks of the segments by the agents
+func (conf *EventsConfig) Interval() time.Duration {
+	if conf.WatchInterval <= 0 {
+		return constants.SegmentWatchInterval * time.Second
+	}
+
+	return time.Duration(conf.WatchInterval) * time.Second
+}
+
+// Threshold returns the percentage of a data directory file system used above
+// which its disk is nearly full
+func (conf *EventsConfig) Threshold() int {
+	if conf.DiskUsageThreshold <= 0 {
+		return constants.DiskUsageThreshold
+	}
+
+	return conf.DiskUsageThreshold
+}
+
+// EventSegment identifies the segment an event is about
+type EventSegment struct {
+	ContentID int    `json:"contentId"`
+	DbID      int    `json:"dbid"`
+	Port      int    `json:"port"`
+	DataDir   string `json:"dataDir"`
+}
+
+// Event is something the hub noticed about the cluster
+type Event struct {
+	Type      string        `json:"type"`
+	Time      time.Time     `json:"time"`
+	Host      string        `json:"host"`
+	Segment   *EventSegment `json:"segment,omitempty"` // not set for the events about an agent
+	Message   string        `json:"message"`
+	DiskUsage float64       `json:"diskUsage,omitempty"` // percent
+}
+
+// EventSink is somewhere the events are sent to
+type EventSink interface {
+	Name() string
+	Send(event Event) error
+}
+
+// NewEventSinks returns the sinks of the configuration
+func NewEventSinks(conf *EventsConfig) ([]EventSink, error) {
+	sinks := make([]EventSink, 0)
+	if conf == nil {
+		return sinks, nil
+	}
+
+	if conf.LogFile != "" {
+		sinks = append(sinks, &LogFileSink{Path: conf.LogFile})
+	}
+	if conf.Exec != "" {
+		sinks = append(sinks, &ExecSink{Path: conf.Exec})
+	}
+	if conf.Webhook != "" {
+		err := ValidateWebhookURL(conf.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, &WebhookSink{URL: conf.Webhook})
+	}
+
+	return sinks, nil
+}
+
+// LogFileSink appends the events to a file, one JSON object per line
+type LogFileSink struct {
+	Path string
+
+	mutex sync.Mutex
+}
+
+func (sink *LogFileSink) Name() string {
+	return fmt.Sprintf("log file %s", sink.Path)
+}
+
+func (sink *LogFileSink) Send(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+
+	file, err := os.OpenFile(sink.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// ExecSink runs an executable for every event, which gets the event as JSON on
+// its standard input and its type and host in the GP_EVENT_TYPE and
+// GP_EVENT_HOST environment variables
+type ExecSink struct {
+	Path string
+}
+
+func (sink *ExecSink) Name() string {
+	return fmt.Sprintf("exec hook %s", sink.Path)
+}
+
+func (sink *ExecSink) Send(event Event) error {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	cmd := execCommand(sink.Path)
+	cmd.Env = append(os.Environ(), "GP_EVENT_TYPE="+event.Type, "GP_EVENT_HOST="+event.Host)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(constants.EventTimeout * time.Second):
+		_ = cmd.Process.Kill()
+		<-done
+		err = fmt.Errorf("timed out after %d seconds", constants.EventTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w, Command Output: %s", err, output.String())
+	}
+
+	return nil
+}
+
+// WebhookSink posts every event as JSON to a URL on the hub host
+type WebhookSink struct {
+	URL string
+}
+
+func (sink *WebhookSink) Name() string {
+	return fmt.Sprintf("webhook %s", sink.URL)
+}
+
+func (sink *WebhookSink) Send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: constants.EventTimeout * time.Second}
+	resp, err := client.Post(sink.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("got status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// ValidateWebhookURL checks that the webhook is an http(s) URL on the loopback
+// interface, so that the events do not leave the hub host
+func ValidateWebhookURL(rawURL string) error {
+	webhook, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL %q: %w", rawURL, err)
+	}
+
+	if webhook.Scheme != "http" && webhook.Scheme != "https" {
+		return fmt.Errorf("invalid webhook URL %q: expected an http or https URL", rawURL)
+	}
+
+	host := webhook.Hostname()
+	ip := net.ParseIP(host)
+	if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("invalid webhook URL %q: expected a URL on localhost", rawURL)
+	}
+
+	return nil
+}
+
+// Notifier sends the events to the sinks, one event at a time so that every
+// sink gets them in order. A sink failing is logged and does not prevent the
+// others from getting the event.
+type Notifier struct {
+	sinks  []EventSink
+	events chan Event
+}
+
+func NewNotifier(sinks ...EventSink) *Notifier {
+	return &Notifier{
+		sinks:  sinks,
+		events: make(chan Event, constants.EventQueueSize),
+	}
+}
+
+// Notify logs the event and queues it for the sinks, dropping it when too
+// many events are waiting for them
+func (n *Notifier) Notify(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	gplog.Info("Event %s on host %s: %s", event.Type, event.Host, event.Message)
+
+	if len(n.sinks) == 0 {
+		return
+	}
+
+	select {
+	case n.events <- event:
+	default:
+		gplog.Warn("Dropping event %s on host %s, too many events are waiting to be sent", event.Type, event.Host)
+	}
+}
+
+// Run sends the queued events to the sinks until done is closed
+func (n *Notifier) Run(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case event := <-n.events:
+			n.send(event)
+		}
+	}
+}
+
+func (n *Notifier) send(event Event) {
+	for _, sink := range n.sinks {
+		err := sink.Send(event)
+		if err != nil {
+			gplog.Warn("Could not send event %s to %s: %s", event.Type, sink.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package hub_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// recordingSink passes the events it gets on to a channel
+type recordingSink struct {
+	events chan hub.Event
+	err    error
+}
+
+func newRecordingSink(err error) *recordingSink {
+	return &recordingSink{events: make(chan hub.Event, 10), err: err}
+}
+
+func (sink *recordingSink) Name() string {
+	return "recording sink"
+}
+
+func (sink *recordingSink) Send(event hub.Event) error {
+	sink.events <- event
+	return sink.err
+}
+
+// next returns the next event sent to the sink
+func (sink *recordingSink) next(t *testing.T) hub.Event {
+	t.Helper()
+
+	select {
+	case event := <-sink.events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event was sent to the sink")
+		return hub.Event{}
+	}
+}
+
+// readEventLog waits for the log file of the events to have the expected
+// number of events and returns them
+func readEventLog(t *testing.T, path string, expected int) []hub.Event {
+	t.Helper()
+
+	var events []hub.Event
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		events = nil
+		for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+			var event hub.Event
+			err = json.Unmarshal([]byte(line), &event)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			events = append(events, event)
+		}
+		if len(events) >= expected {
+			return events
+		}
+	}
+
+	t.Fatalf("got events %+v, want %d events", events, expected)
+	return nil
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{url: "http://localhost:8080/events"},
+		{url: "https://127.0.0.1/events"},
+		{url: "http://[::1]:8080"},
+		{url: "ftp://localhost/events", expected: `invalid webhook URL "ftp://localhost/events": expected an http or https URL`},
+		{url: "http://example.com/events", expected: `invalid webhook URL "http://example.com/events": expected a URL on localhost`},
+		{url: "http://10.0.0.1/events", expected: `invalid webhook URL "http://10.0.0.1/events": expected a URL on localhost`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			err := hub.ValidateWebhookURL(tc.url)
+			if tc.expected == "" && err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+				t.Fatalf("got %v, want %s", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestEventSinks(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	event := hub.Event{
+		Type:    hub.EventSegmentDown,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Host:    "sdw1",
+		Segment: &hub.EventSegment{ContentID: 0, DbID: 2, Port: 6000, DataDir: "/data/primary/gpseg0"},
+		Message: "segment 0 with data directory /data/primary/gpseg0 is down",
+	}
+
+	t.Run("returns the sinks of the configuration", func(t *testing.T) {
+		sinks, err := hub.NewEventSinks(&hub.EventsConfig{
+			LogFile: "/tmp/events.jsonl",
+			Exec:    "/usr/local/bin/on-event",
+			Webhook: "http://localhost:8080/events",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		names := make([]string, 0)
+		for _, sink := range sinks {
+			names = append(names, sink.Name())
+		}
+		expected := []string{"log file /tmp/events.jsonl", "exec hook /usr/local/bin/on-event", "webhook http://localhost:8080/events"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("got %+v, want %+v", names, expected)
+		}
+	})
+
+	t.Run("errors out when the webhook is not local", func(t *testing.T) {
+		_, err := hub.NewEventSinks(&hub.EventsConfig{Webhook: "http://example.com/events"})
+
+		expected := `invalid webhook URL "http://example.com/events": expected a URL on localhost`
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("appends the events to the log file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		sink := &hub.LogFileSink{Path: path}
+
+		for i := 0; i < 2; i++ {
+			err := sink.Send(event)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+
+		events := readEventLog(t, path, 2)
+		if len(events) != 2 || !reflect.DeepEqual(events[1], event) {
+			t.Fatalf("got %+v, want the event twice", events)
+		}
+	})
+
+	t.Run("runs the exec hook with the event", func(t *testing.T) {
+		dir := t.TempDir()
+		output := filepath.Join(dir, "output")
+		script := filepath.Join(dir, "on-event")
+		err := os.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\necho \"$GP_EVENT_TYPE $GP_EVENT_HOST\" > %s\ncat >> %s\n", output, output)), 0755)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		err = (&hub.ExecSink{Path: script}).Send(event)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		contents, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		lines := strings.SplitN(string(contents), "\n", 2)
+		if lines[0] != "segment_down sdw1" {
+			t.Fatalf("got %q, want the type and host of the event", lines[0])
+		}
+		var got hub.Event
+		err = json.Unmarshal([]byte(lines[1]), &got)
+		if err != nil || !reflect.DeepEqual(got, event) {
+			t.Fatalf("got %+v (%v), want %+v", got, err, event)
+		}
+	})
+
+	t.Run("errors out when the exec hook fails", func(t *testing.T) {
+		script := filepath.Join(t.TempDir(), "on-event")
+		err := os.WriteFile(script, []byte("#!/bin/sh\necho failed\nexit 1\n"), 0755)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		err = (&hub.ExecSink{Path: script}).Send(event)
+
+		expected := "exit status 1, Command Output: failed\n"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
+		}
+	})
+
+	t.Run("posts the events to the webhook", func(t *testing.T) {
+		received := make(chan hub.Event, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var got hub.Event
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &got)
+			received <- got
+
+			if r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer server.Close()
+
+		err := (&hub.WebhookSink{URL: server.URL}).Send(event)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		got := <-received
+		if !reflect.DeepEqual(got, event) {
+			t.Fatalf("got %+v, want %+v", got, event)
+		}
+	})
+
+	t.Run("errors out when the webhook does not accept the event", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := (&hub.WebhookSink{URL: server.URL}).Send(event)
+
+		expected := "got status 500 Internal Server Error"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("sends the events to every sink in order, even when one of them fails", func(t *testing.T) {
+		failing := newRecordingSink(errors.New("error"))
+		sink := newRecordingSink(nil)
+		notifier := hub.NewNotifier(failing, sink)
+
+		done := make(chan struct{})
+		defer close(done)
+		go notifier.Run(done)
+
+		notifier.Notify(hub.Event{Type: hub.EventSegmentDown, Host: "sdw1"})
+		notifier.Notify(hub.Event{Type: hub.EventSegmentUp, Host: "sdw1"})
+
+		for _, expected := range []string{hub.EventSegmentDown, hub.EventSegmentUp} {
+			if event := failing.next(t); event.Type != expected {
+				t.Fatalf("got %+v, want a %s event", event, expected)
+			}
+			event := sink.next(t)
+			if event.Type != expected || event.Time.IsZero() {
+				t.Fatalf("got %+v, want a %s event with its time", event, expected)
+			}
+		}
+	})
+
+	t.Run("raises an event when an agent becomes unreachable or reachable again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hubConfig := testutils.InitializeTestEnv()
+		hubConfig.Hostnames = []string{"sdw1"}
+		hubServer := hub.New(hubConfig, nil)
+		sink := newRecordingSink(nil)
+		hubServer.Notifier = hub.NewNotifier(sink)
+
+		done := make(chan struct{})
+		defer close(done)
+		go hubServer.Notifier.Run(done)
+
+		hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+			return nil
+		})
+		defer hub.ResetEnsureConnectionsAreReady()
+
+		client := mock_idl.NewMockAgentClient(ctrl)
+		gomock.InOrder(
+			client.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(&idl.StatusAgentReply{Status: "running"}, nil),
+			client.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused")).Times(2),
+			client.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(&idl.StatusAgentReply{Status: "running"}, nil),
+		)
+		hubServer.Conns = []*hub.Connection{{AgentClient: client, Hostname: "sdw1"}}
+
+		for i := 0; i < 4; i++ {
+			_, _ = hubServer.StatusAgents(context.Background(), &idl.StatusAgentsRequest{})
+		}
+
+		event := sink.next(t)
+		if event.Type != hub.EventAgentUnreachable || event.Host != "sdw1" || event.Message != "connection refused" {
+			t.Fatalf("got %+v, want sdw1 to be unreachable", event)
+		}
+		event = sink.next(t)
+		if event.Type != hub.EventAgentReachable || event.Host != "sdw1" {
+			t.Fatalf("got %+v, want sdw1 to be reachable again", event)
+		}
+		select {
+		case event := <-sink.events:
+			t.Fatalf("got unexpected event %+v", event)
+		default:
+		}
+	})
+}
+
+// eventsAgent streams the events to the hub when it watches the segments
+type eventsAgent struct {
+	idl.UnimplementedAgentServer
+	events   []*idl.SegmentEvent
+	requests chan *idl.WatchSegmentsRequest
+}
+
+func (a *eventsAgent) Status(ctx context.Context, in *idl.StatusAgentRequest) (*idl.StatusAgentReply, error) {
+	return &idl.StatusAgentReply{Status: "running"}, nil
+}
+
+func (a *eventsAgent) WatchSegments(in *idl.WatchSegmentsRequest, stream idl.Agent_WatchSegmentsServer) error {
+	a.requests <- in
+	for _, event := range a.events {
+		err := stream.Send(event)
+		if err != nil {
+			return err
+		}
+	}
+
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestWatchSegments(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	agentPort := freePort(t)
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", agentPort))
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	agent := &eventsAgent{
+		events: []*idl.SegmentEvent{{
+			Type:    idl.SegmentEventType_SEGMENT_DOWN,
+			Segment: &idl.WatchedSegment{DataDir: "/data/primary/gpseg0", ContentId: 0, Dbid: 2, Port: 6000},
+			Time:    timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+			Message: "segment 0 with data directory /data/primary/gpseg0 is down",
+		}},
+		requests: make(chan *idl.WatchSegmentsRequest, 10),
+	}
+	agentServer := grpc.NewServer()
+	idl.RegisterAgentServer(agentServer, agent)
+	go func() {
+		_ = agentServer.Serve(listener)
+	}()
+	defer agentServer.Stop()
+
+	logFile := filepath.Join(t.TempDir(), "events.jsonl")
+	hubConfig := &hub.Config{
+		AgentPort:   agentPort,
+		Hostnames:   []string{"localhost"},
+		Credentials: &testutils.MockCredentials{TlsConnection: insecure.NewCredentials()},
+		Events:      &hub.EventsConfig{LogFile: logFile, WatchInterval: 1, DiskUsageThreshold: 80},
+	}
+	hubServer := startHub(t, hubConfig)
+	hubServer.Topology.Set(hub.Segments{
+		{DbID: 1, ContentID: -1, Role: hub.RolePrimary, Port: 5432, Hostname: "cdw", DataDir: "/data/qddir/gpseg-1"},
+		{DbID: 2, ContentID: 0, Role: hub.RolePrimary, Port: 6000, Hostname: "localhost", DataDir: "/data/primary/gpseg0"},
+	})
+
+	var request *idl.WatchSegmentsRequest
+	select {
+	case request = <-agent.requests:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("the hub did not watch the segments")
+	}
+	expectedSegments := []*idl.WatchedSegment{{DataDir: "/data/primary/gpseg0", ContentId: 0, Dbid: 2, Port: 6000}}
+	if request.Interval != 1 || request.DiskUsageThreshold != 80 || len(request.Segments) != 1 || request.Segments[0].DataDir != expectedSegments[0].DataDir {
+		t.Fatalf("got %+v, want the segments of the host to be watched", request)
+	}
+
+	events := readEventLog(t, logFile, 1)
+	expected := hub.Event{
+		Type:    hub.EventSegmentDown,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Host:    "localhost",
+		Segment: &hub.EventSegment{ContentID: 0, DbID: 2, Port: 6000, DataDir: "/data/primary/gpseg0"},
+		Message: "segment 0 with data directory /data/primary/gpseg0 is down",
+	}
+	if !reflect.DeepEqual(events[0], expected) {
+		t.Fatalf("got %+v, want %+v", events[0], expected)
+	}
+
+	// the segment going down does not change what is watched
+	hubServer.Topology.Set(hub.Segments{
+		{DbID: 1, ContentID: -1, Role: hub.RolePrimary, Port: 5432, Hostname: "cdw", DataDir: "/data/qddir/gpseg-1"},
+		{DbID: 2, ContentID: 0, Role: hub.RolePrimary, Status: "d", Port: 6000, Hostname: "localhost", DataDir: "/data/primary/gpseg0"},
+	})
+	select {
+	case request = <-agent.requests:
+		t.Fatalf("got %+v, want the watch to be kept", request)
+	case <-time.After(2500 * time.Millisecond):
+	}
+
+	hubServer.Topology.Set(hub.Segments{
+		{DbID: 1, ContentID: -1, Role: hub.RolePrimary, Port: 5432, Hostname: "cdw", DataDir: "/data/qddir/gpseg-1"},
+		{DbID: 2, ContentID: 0, Role: hub.RolePrimary, Port: 6001, Hostname: "localhost", DataDir: "/data/primary/gpseg0"},
+	})
+	select {
+	case request = <-agent.requests:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("the hub did not watch the moved segment")
+	}
+	if len(request.Segments) != 1 || request.Segments[0].Port != 6001 {
+		t.Fatalf("got %+v, want the segment to be watched on its new port", request)
+	}
+}
@@ -0,0 +1,565 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+// expansionStep is how far the creation of a new segment went
+type expansionStep string
+
+const (
+	expansionPlanned expansionStep = "planned"
+	// set before the agent is asked to create the segment, as its data
+	// directory may be left behind when the creation is interrupted
+	expansionCreating expansionStep = "creating"
+	expansionCreated  expansionStep = "created"
+	expansionStarted  expansionStep = "started"
+)
+
+type expansionSegment struct {
+	Segment
+	Step expansionStep `json:"step"`
+}
+
+// expansionState is the plan of an expansion along with the progress of each
+// new segment. It is saved under the log directory of the hub after every
+// step, so that a failed expansion is resumed where it stopped.
+type expansionState struct {
+	Hosts    []string           `json:"hosts"`
+	Trusted  bool               `json:"trusted"` // whether the coordinator trusts the new hosts
+	Segments []expansionSegment `json:"segments"`
+
+	path  string
+	mutex sync.Mutex
+}
+
+// loadExpansionState returns the state of the expansion in progress, or nil
+// when there is none
+func loadExpansionState(path string) (*expansionState, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read expansion state: %w", err)
+	}
+
+	state := &expansionState{path: path}
+	err = json.Unmarshal(contents, state)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse expansion state %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// save writes the state to a temporary file first, so that a crash leaves
+// either the previous state or the new one behind. The caller holds
+// state.mutex.
+func (state *expansionState) save() error {
+	contents, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return fmt.Errorf("could not encode expansion state: %w", err)
+	}
+
+	tempPath := state.path + ".tmp"
+	err = os.WriteFile(tempPath, contents, 0600)
+	if err == nil {
+		err = os.Rename(tempPath, state.path)
+	}
+	if err != nil {
+		return fmt.Errorf("could not save expansion state: %w", err)
+	}
+
+	return nil
+}
+
+func (state *expansionState) setStep(seg Segment, step expansionStep) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	for i := range state.Segments {
+		if state.Segments[i].DbID == seg.DbID {
+			state.Segments[i].Step = step
+		}
+	}
+
+	return state.save()
+}
+
+func (state *expansionState) setTrusted() error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	state.Trusted = true
+	return state.save()
+}
+
+// segments returns the new segments, or only those at the given step when set
+func (state *expansionState) segments(step expansionStep) Segments {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	result := make(Segments, 0, len(state.Segments))
+	for _, seg := range state.Segments {
+		if step == "" || seg.Step == step {
+			result = append(result, seg.Segment)
+		}
+	}
+
+	return result
+}
+
+func (state *expansionState) remove() error {
+	err := os.Remove(state.path)
+	if err != nil {
+		return fmt.Errorf("could not remove expansion state: %w", err)
+	}
+
+	return nil
+}
+
+// ExpandCluster adds segments to the cluster on new hosts, laid out as on the
+// host of the first primary. As gpexpand does, the new primaries are copied
+// from the coordinator with pg_basebackup, so that they get the catalog of the
+// cluster, then given their own port, content ID and dbid. Their mirrors are
+// copied from them, and they are registered in gp_segment_configuration once
+// they all run. The plan and the progress of the expansion are saved, and
+// running it again with the same hosts resumes it after a failure. Only the
+// plan is reported when in.DryRun is set.
+func (s *Server) ExpandCluster(in *idl.ExpandClusterRequest, stream idl.Hub_ExpandClusterServer) error {
+	progress := newProgressReporter(stream)
+	port := int(in.CoordinatorPort)
+
+	state, err := loadExpansionState(filepath.Join(s.LogDir, constants.ExpandStateFile))
+	if err != nil {
+		return err
+	}
+
+	topology, err := s.RefreshTopology(port, false)
+	if err != nil {
+		return err
+	}
+
+	if state != nil {
+		if !sameHosts(state.Hosts, in.Hosts) {
+			return fmt.Errorf("an expansion to hosts %s is in progress, run gp expand with the same hosts to resume it", strings.Join(state.Hosts, ", "))
+		}
+		progress.Info("Resuming the expansion to hosts %s", strings.Join(state.Hosts, ", "))
+	} else {
+		state, err = s.planExpansionState(topology, in.Hosts, !in.DryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	segments := state.segments("")
+	if in.DryRun {
+		for _, seg := range state.Segments {
+			role := "mirror"
+			if seg.IsPrimary() {
+				role = "primary"
+			}
+			progress.Info("Content %d: %s %d on %s:%d with data directory %s, %s",
+				seg.ContentID, role, seg.DbID, seg.Hostname, seg.Port, seg.DataDir, seg.Step)
+		}
+		progress.Summary("Would add %d primaries and %d mirrors on %d new hosts",
+			len(segments.Primaries()), len(segments.Mirrors()), len(state.Hosts))
+		return nil
+	}
+
+	progress.Info("Starting agents on the new hosts")
+	s.addHostnames(state.Hosts)
+	err = s.startAgentsReporting(state.Hosts, progress)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.connectAgents(progress, false)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Expanding the cluster with %d primaries and %d mirrors on %d new hosts",
+		len(segments.Primaries()), len(segments.Mirrors()), len(state.Hosts))
+	err = s.runExpansion(port, state, topology, progress)
+	if err != nil {
+		progress.Warn("Could not expand the cluster, run gp expand again with the same hosts to resume: %s", err)
+		return err
+	}
+
+	err = state.remove()
+	if err != nil {
+		return err
+	}
+	progress.Summary("Cluster expanded successfully, the existing tables still need to be redistributed to the new segments")
+
+	return nil
+}
+
+// planExpansionState lays out the new segments, and saves the plan when asked
+// to
+func (s *Server) planExpansionState(topology Segments, hosts []string, save bool) (*expansionState, error) {
+	segments, err := planExpansion(topology, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &expansionState{
+		Hosts: hosts,
+		path:  filepath.Join(s.LogDir, constants.ExpandStateFile),
+	}
+	for _, seg := range segments {
+		state.Segments = append(state.Segments, expansionSegment{Segment: seg, Step: expansionPlanned})
+	}
+	if !save {
+		return state, nil
+	}
+
+	err = state.save()
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// planExpansion lays out the segments of the new hosts after the segments of
+// the host of the first primary: each new host gets as many primaries, with
+// the same ports and in the same directories, and as many mirrors when the
+// cluster is mirrored. The mirrors of a new host all go to the next new host.
+func planExpansion(topology Segments, hosts []string) (Segments, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("at least one new host is needed")
+	}
+
+	segmentHosts := make(map[string]bool)
+	for _, seg := range topology {
+		if seg.ContentID != CoordinatorContentID {
+			segmentHosts[seg.Hostname] = true
+		}
+	}
+	newHosts := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		if segmentHosts[host] {
+			return nil, fmt.Errorf("host %s already has segments of the cluster", host)
+		}
+		if newHosts[host] {
+			return nil, fmt.Errorf("host %s is listed more than once", host)
+		}
+		newHosts[host] = true
+	}
+
+	primaries := topology.Filter(func(seg Segment) bool {
+		return seg.ContentID != CoordinatorContentID && seg.PreferredRole == RolePrimary
+	})
+	mirrors := topology.Filter(func(seg Segment) bool {
+		return seg.ContentID != CoordinatorContentID && seg.PreferredRole == RoleMirror
+	})
+	if len(primaries) == 0 {
+		return nil, fmt.Errorf("the cluster has no segments to lay out the new hosts after")
+	}
+
+	template := primaries[0].Hostname
+	onTemplate := func(seg Segment) bool { return seg.Hostname == template }
+	hostPrimaries := sortedByPort(primaries.Filter(onTemplate))
+	hostMirrors := sortedByPort(mirrors.Filter(onTemplate))
+	if len(mirrors) > 0 {
+		if len(hostMirrors) != len(hostPrimaries) {
+			return nil, fmt.Errorf("host %s has %d primaries and %d mirrors, can not lay out the new hosts after it", template, len(hostPrimaries), len(hostMirrors))
+		}
+		if len(hosts) < 2 {
+			return nil, fmt.Errorf("the cluster is mirrored, at least 2 new hosts are needed")
+		}
+	}
+
+	maxContentID, maxDbID := 0, 0
+	for _, seg := range topology {
+		if seg.ContentID > maxContentID {
+			maxContentID = seg.ContentID
+		}
+		if seg.DbID > maxDbID {
+			maxDbID = seg.DbID
+		}
+	}
+
+	// The data directories are named as the existing ones, e.g. gpseg12
+	dataDir := func(existing Segment, contentID int) string {
+		prefix := strings.TrimRight(filepath.Base(existing.DataDir), "0123456789")
+		return filepath.Join(filepath.Dir(existing.DataDir), fmt.Sprintf("%s%d", prefix, contentID))
+	}
+
+	perHost := len(hostPrimaries)
+	numPrimaries := len(hosts) * perHost
+	segments := make(Segments, 0)
+	for i, host := range hosts {
+		for j, existing := range hostPrimaries {
+			n := i*perHost + j
+			segments = append(segments, Segment{
+				DbID:          maxDbID + 1 + n,
+				ContentID:     maxContentID + 1 + n,
+				Role:          RolePrimary,
+				PreferredRole: RolePrimary,
+				Mode:          ModeNotSynchronized,
+				Status:        StatusUp,
+				Port:          existing.Port,
+				Hostname:      host,
+				Address:       host,
+				DataDir:       dataDir(existing, maxContentID+1+n),
+			})
+		}
+	}
+
+	if len(mirrors) > 0 {
+		for i := range hosts {
+			for j, existing := range hostMirrors {
+				n := i*perHost + j
+				host := hosts[(i+1)%len(hosts)]
+				segments = append(segments, Segment{
+					DbID:          maxDbID + 1 + numPrimaries + n,
+					ContentID:     maxContentID + 1 + n,
+					Role:          RoleMirror,
+					PreferredRole: RoleMirror,
+					Mode:          ModeNotSynchronized,
+					Status:        StatusUp,
+					Port:          existing.Port,
+					Hostname:      host,
+					Address:       host,
+					DataDir:       dataDir(existing, maxContentID+1+n),
+				})
+			}
+		}
+	}
+
+	err := checkLayout(append(append(Segments{}, topology...), segments...))
+	if err != nil {
+		return nil, err
+	}
+
+	return segments, nil
+}
+
+func sortedByPort(segments Segments) Segments {
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Port < segments[j].Port
+	})
+
+	return segments
+}
+
+func sameHosts(hosts []string, others []string) bool {
+	sorted := func(hosts []string) string {
+		hosts = append([]string{}, hosts...)
+		sort.Strings(hosts)
+		return strings.Join(hosts, ",")
+	}
+
+	return sorted(hosts) == sorted(others)
+}
+
+// addHostnames adds the hosts the hub does not know of yet to its
+// configuration, whose file was already updated by the CLI
+func (s *Server) addHostnames(hosts []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	known := make(map[string]bool, len(s.Hostnames))
+	for _, host := range s.Hostnames {
+		known[host] = true
+	}
+
+	hostnames := append([]string{}, s.Hostnames...)
+	for _, host := range hosts {
+		if !known[host] {
+			hostnames = append(hostnames, host)
+		}
+	}
+	s.Hostnames = hostnames
+}
+
+// runExpansion creates and starts the new segments that are not yet, then
+// registers those missing from gp_segment_configuration
+func (s *Server) runExpansion(port int, state *expansionState, topology Segments, progress *progressReporter) error {
+	coordinators := topology.Filter(Segment.IsCoordinator)
+	if len(coordinators) == 0 {
+		return fmt.Errorf("the cluster has no coordinator to copy the new primaries from")
+	}
+	coordinator := coordinators[0]
+
+	initializer := &clusterInitializer{
+		server:   s,
+		progress: progress,
+		segments: append(append(Segments{}, topology...), state.segments("")...),
+		copyFrom: &coordinator,
+	}
+
+	if !state.Trusted {
+		progress.Info("Allowing the new hosts to copy the coordinator")
+		err := trustHosts(port, coordinator, state.Hosts)
+		if err != nil {
+			return err
+		}
+
+		err = state.setTrusted()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.removeUnfinishedSegments(state, progress)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Copying the coordinator to the new primary segments")
+	err = s.executeOnSegments(state.segments(expansionPlanned).Primaries(), func(conn *Connection, seg Segment) error {
+		err := state.setStep(seg, expansionCreating)
+		if err != nil {
+			return err
+		}
+
+		err = initializer.initSegment(conn, seg)
+		if err != nil {
+			return err
+		}
+
+		return state.setStep(seg, expansionCreated)
+	})
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Starting new primary segments")
+	err = s.startNewSegments(state, state.segments(expansionCreated).Primaries(), progress)
+	if err != nil {
+		return err
+	}
+
+	primaryOf := make(map[int]Segment)
+	for _, primary := range state.segments("").Primaries() {
+		primaryOf[primary.ContentID] = primary
+	}
+
+	if mirrors := state.segments(expansionPlanned).Mirrors(); len(mirrors) > 0 {
+		progress.Info("Creating new mirror segments")
+		err = s.executeOnSegments(mirrors, func(conn *Connection, seg Segment) error {
+			err := state.setStep(seg, expansionCreating)
+			if err != nil {
+				return err
+			}
+
+			err = initializer.createMirror(conn, seg, primaryOf[seg.ContentID])
+			if err != nil {
+				return err
+			}
+
+			return state.setStep(seg, expansionCreated)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if mirrors := state.segments(expansionCreated).Mirrors(); len(mirrors) > 0 {
+		progress.Info("Starting new mirror segments")
+		err = s.startNewSegments(state, mirrors, progress)
+		if err != nil {
+			return err
+		}
+	}
+
+	progress.Info("Registering the new segments in the catalog")
+	topology, err = s.RefreshTopology(port, false)
+	if err != nil {
+		return err
+	}
+	registered := make(map[int]bool, len(topology))
+	for _, seg := range topology {
+		registered[seg.DbID] = true
+	}
+	unregistered := state.segments("").Filter(func(seg Segment) bool {
+		return !registered[seg.DbID]
+	})
+
+	err = addSegments(port, false, unregistered)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.RefreshTopology(port, false)
+	return err
+}
+
+// removeUnfinishedSegments removes the data directories of the new segments
+// whose creation failed or was interrupted, e.g. by the hub stopping, so that
+// they are created again from scratch
+func (s *Server) removeUnfinishedSegments(state *expansionState, progress *progressReporter) error {
+	segments := state.segments(expansionCreating)
+	if len(segments) == 0 {
+		return nil
+	}
+
+	progress.Info("Removing the new segments left partially created")
+	return s.executeOnSegments(segments, func(conn *Connection, seg Segment) error {
+		_, err := conn.AgentClient.RemoveSegment(context.Background(), &idl.RemoveSegmentRequest{
+			DataDir:   seg.DataDir,
+			ContentId: int32(seg.ContentID),
+		})
+		if err != nil {
+			return fmt.Errorf("could not remove segment %d with data directory %s on host %s: %w", seg.ContentID, seg.DataDir, conn.Hostname, err)
+		}
+
+		return state.setStep(seg, expansionPlanned)
+	})
+}
+
+// trustHosts adds the hosts to pg_hba.conf of the coordinator, which lives on
+// the hub host, and has it reload its configuration
+func trustHosts(port int, coordinator Segment, hosts []string) error {
+	currentUser, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("could not get current user: %w", err)
+	}
+
+	err = utils.TrustHosts(coordinator.DataDir, currentUser.Username, hosts)
+	if err != nil {
+		return fmt.Errorf("could not add the new hosts to pg_hba.conf of the coordinator: %w", err)
+	}
+
+	conn, err := connectToCoordinatorFunc(port, false)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec("SELECT pg_catalog.pg_reload_conf()")
+	if err != nil {
+		return fmt.Errorf("could not reload the configuration of the coordinator: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) startNewSegments(state *expansionState, segments Segments, progress *progressReporter) error {
+	return s.executeOnSegments(segments, func(conn *Connection, seg Segment) error {
+		err := startSegment(conn, seg, progress)
+		if err != nil {
+			return err
+		}
+
+		return state.setStep(seg, expansionStarted)
+	})
+}
@@ -0,0 +1,361 @@
+package hub_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// coordinatorDataDir is where the coordinator of expansionSegmentRows lives,
+// which the hub adds the new hosts to pg_hba.conf of
+var coordinatorDataDir = "/data/qddir/gpseg-1"
+
+// expansionSegmentRows has the mirrored cluster with its coordinator in
+// coordinatorDataDir
+func expansionSegmentRows() *sqlmock.Rows {
+	segments := mirroredSegments()
+	segments[0].DataDir = coordinatorDataDir
+
+	return segmentRows(segments...)
+}
+
+// setMockExpansionConnections has the nth connection to the coordinator expect
+// the queries set by the nth function, and any further one get the topology
+func setMockExpansionConnections(t *testing.T, expect ...func(mock sqlmock.Sqlmock)) {
+	t.Helper()
+
+	calls := 0
+	hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+		conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+		if calls < len(expect) {
+			expect[calls](mock)
+		} else {
+			mock.ExpectQuery("SELECT").WillReturnRows(expansionSegmentRows())
+		}
+		calls++
+
+		return conn, nil
+	})
+}
+
+func expectTopology(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("FROM gp_segment_configuration").WillReturnRows(expansionSegmentRows())
+}
+
+func expectReload(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_catalog.pg_reload_conf()")).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+var expansionQueries = []string{
+	"SELECT pg_catalog.gp_add_segment(6::int2, 2::int2, 'p', 'p', 'n', 'u', 6000, 'sdw3', 'sdw3', '/data/primary/gpseg2')",
+	"SELECT pg_catalog.gp_add_segment(7::int2, 3::int2, 'p', 'p', 'n', 'u', 6000, 'sdw4', 'sdw4', '/data/primary/gpseg3')",
+	"SELECT pg_catalog.gp_add_segment(8::int2, 2::int2, 'm', 'm', 'n', 'u', 7000, 'sdw4', 'sdw4', '/data/mirror/gpseg2')",
+	"SELECT pg_catalog.gp_add_segment(9::int2, 3::int2, 'm', 'm', 'n', 'u', 7000, 'sdw3', 'sdw3', '/data/mirror/gpseg3')",
+}
+
+func expectAddSegments(mock sqlmock.Sqlmock) {
+	for _, query := range expansionQueries {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+func TestExpandCluster(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+
+	newHubServer := func(t *testing.T) *hub.Server {
+		hubConfig := testutils.InitializeTestEnv()
+		hubConfig.Hostnames = []string{"sdw1", "sdw2"}
+		hubConfig.LogDir = t.TempDir()
+
+		return hub.New(hubConfig, nil)
+	}
+
+	executor := &testutils.MockExecutor{}
+	hub.SetNewRemoteExecutor(executor.NewExecutor())
+	defer hub.ResetNewRemoteExecutor()
+
+	coordinatorDataDir = t.TempDir()
+	defer func() { coordinatorDataDir = "/data/qddir/gpseg-1" }()
+
+	t.Run("creates, starts and registers the segments of the new hosts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hubServer := newHubServer(t)
+		setMockExpansionConnections(t, expectTopology, expectReload, expectTopology, expectAddSegments)
+		defer hub.ResetConnectToCoordinator()
+
+		hbaHostnames := []string{"cdw", "sdw1", "sdw2", "sdw3", "sdw4"}
+		sdw3 := mock_idl.NewMockAgentClient(ctrl)
+		sdw3.EXPECT().InitSegment(gomock.Any(), &idl.InitSegmentRequest{
+			DataDir:         "/data/primary/gpseg2",
+			Port:            6000,
+			ContentId:       2,
+			Dbid:            6,
+			HbaHostnames:    hbaHostnames,
+			CoordinatorHost: "cdw",
+			CoordinatorPort: 5432,
+		}).Return(&idl.InitSegmentReply{}, nil)
+		sdw3.EXPECT().RecoverSegment(gomock.Any(), &idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg3",
+			ContentId:  3,
+			Dbid:       9,
+			SourceHost: "sdw4",
+			SourcePort: 6000,
+			NewMirror:  true,
+		}).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw3.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil).Times(2)
+
+		sdw4 := mock_idl.NewMockAgentClient(ctrl)
+		sdw4.EXPECT().InitSegment(gomock.Any(), &idl.InitSegmentRequest{
+			DataDir:         "/data/primary/gpseg3",
+			Port:            6000,
+			ContentId:       3,
+			Dbid:            7,
+			HbaHostnames:    hbaHostnames,
+			CoordinatorHost: "cdw",
+			CoordinatorPort: 5432,
+		}).Return(&idl.InitSegmentReply{}, nil)
+		sdw4.EXPECT().RecoverSegment(gomock.Any(), &idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg2",
+			ContentId:  2,
+			Dbid:       8,
+			SourceHost: "sdw3",
+			SourcePort: 6000,
+			NewMirror:  true,
+		}).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw4.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil).Times(2)
+
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw2"},
+			{AgentClient: sdw3, Hostname: "sdw3"},
+			{AgentClient: sdw4, Hostname: "sdw4"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.ExpandCluster(&idl.ExpandClusterRequest{CoordinatorPort: 5432, Hosts: []string{"sdw3", "sdw4"}}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedHosts := []string{"sdw1", "sdw2", "sdw3", "sdw4"}
+		if !reflect.DeepEqual(hubServer.Hostnames, expectedHosts) {
+			t.Fatalf("got %q, want %q", hubServer.Hostnames, expectedHosts)
+		}
+
+		currentUser, err := user.Current()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		hba, err := os.ReadFile(filepath.Join(coordinatorDataDir, "pg_hba.conf"))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		for _, host := range []string{"sdw3", "sdw4"} {
+			expected := fmt.Sprintf("host\treplication\t%s\t%s\ttrust\n", currentUser.Username, host)
+			if !strings.Contains(string(hba), expected) {
+				t.Fatalf("got %q, want the coordinator to trust %s", hba, host)
+			}
+		}
+
+		_, err = os.Stat(filepath.Join(hubServer.LogDir, constants.ExpandStateFile))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("got %v, want the expansion state to be removed", err)
+		}
+
+		summary := stream.Summary()
+		if summary.Message != "Cluster expanded successfully, the existing tables still need to be redistributed to the new segments" {
+			t.Fatalf("got %+v, want the cluster to be expanded", summary)
+		}
+	})
+
+	t.Run("resumes a failed expansion where it stopped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hubServer := newHubServer(t)
+		setMockExpansionConnections(t, expectTopology, expectReload)
+		defer hub.ResetConnectToCoordinator()
+
+		sdw3 := mock_idl.NewMockAgentClient(ctrl)
+		sdw3.EXPECT().InitSegment(gomock.Any(), gomock.Any()).Return(&idl.InitSegmentReply{}, nil)
+		sdw3.EXPECT().RecoverSegment(gomock.Any(), gomock.Any()).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw3.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil)
+		sdw4 := mock_idl.NewMockAgentClient(ctrl)
+		sdw4.EXPECT().InitSegment(gomock.Any(), gomock.Any()).Return(&idl.InitSegmentReply{}, nil)
+		sdw4.EXPECT().RecoverSegment(gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+		sdw4.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw2"},
+			{AgentClient: sdw3, Hostname: "sdw3"},
+			{AgentClient: sdw4, Hostname: "sdw4"},
+		}
+
+		request := &idl.ExpandClusterRequest{CoordinatorPort: 5432, Hosts: []string{"sdw3", "sdw4"}}
+		err := hubServer.ExpandCluster(request, &testutils.MockHubStream{})
+		if err == nil {
+			t.Fatalf("expected the expansion to fail")
+		}
+
+		contents, err := os.ReadFile(filepath.Join(hubServer.LogDir, constants.ExpandStateFile))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		var state struct {
+			Segments []struct {
+				DbID int
+				Step string
+			}
+		}
+		err = json.Unmarshal(contents, &state)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		steps := make(map[int]string)
+		for _, seg := range state.Segments {
+			steps[seg.DbID] = seg.Step
+		}
+		expectedSteps := map[int]string{6: "started", 7: "started", 8: "creating", 9: "created"}
+		if !reflect.DeepEqual(steps, expectedSteps) {
+			t.Fatalf("got %v, want %v", steps, expectedSteps)
+		}
+
+		// Only the mirror that failed is removed and created again, then both
+		// new mirrors are started and every new segment is registered
+		setMockExpansionConnections(t, expectTopology, expectTopology, expectAddSegments)
+		sdw4.EXPECT().RemoveSegment(gomock.Any(), &idl.RemoveSegmentRequest{
+			DataDir:   "/data/mirror/gpseg2",
+			ContentId: 2,
+		}).Return(&idl.RemoveSegmentReply{}, nil)
+		var mutex sync.Mutex
+		var started []string
+		recordStarted := func(_ interface{}, in *idl.StartSegmentRequest, _ ...interface{}) (*idl.StartSegmentReply, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			started = append(started, in.DataDir)
+			return &idl.StartSegmentReply{}, nil
+		}
+		sdw3.EXPECT().StartSegment(gomock.Any(), gomock.Any()).DoAndReturn(recordStarted)
+		sdw4.EXPECT().RecoverSegment(gomock.Any(), gomock.Any()).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw4.EXPECT().StartSegment(gomock.Any(), gomock.Any()).DoAndReturn(recordStarted)
+
+		stream := &testutils.MockHubStream{}
+		err = hubServer.ExpandCluster(request, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		sort.Strings(started)
+		expectedStarted := []string{"/data/mirror/gpseg2", "/data/mirror/gpseg3"}
+		if !reflect.DeepEqual(started, expectedStarted) {
+			t.Fatalf("got %q, want %q", started, expectedStarted)
+		}
+
+		log := stream.Replies[0].GetLog()
+		if log == nil || log.Message != "Resuming the expansion to hosts sdw3, sdw4" {
+			t.Fatalf("got %+v, want the expansion to be resumed", stream.Replies[0])
+		}
+	})
+
+	t.Run("only reports the plan on a dry run", func(t *testing.T) {
+		hubServer := newHubServer(t)
+		setMockExpansionConnections(t, expectTopology)
+		defer hub.ResetConnectToCoordinator()
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.ExpandCluster(&idl.ExpandClusterRequest{CoordinatorPort: 5432, Hosts: []string{"sdw3", "sdw4"}, DryRun: true}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		log := stream.Replies[0].GetLog()
+		if log == nil || log.Message != "Content 2: primary 6 on sdw3:6000 with data directory /data/primary/gpseg2, planned" {
+			t.Fatalf("got %+v, want the new segments to be reported", stream.Replies[0])
+		}
+		summary := stream.Summary()
+		if summary.Message != "Would add 2 primaries and 2 mirrors on 2 new hosts" {
+			t.Fatalf("got %+v, want the plan to be summarized", summary)
+		}
+
+		if !reflect.DeepEqual(hubServer.Hostnames, []string{"sdw1", "sdw2"}) {
+			t.Fatalf("got %q, want the new hosts to be left alone", hubServer.Hostnames)
+		}
+		_, err = os.Stat(filepath.Join(hubServer.LogDir, constants.ExpandStateFile))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("got %v, want no expansion state to be saved", err)
+		}
+	})
+
+	t.Run("errors out when another expansion is in progress", func(t *testing.T) {
+		hubServer := newHubServer(t)
+		err := os.WriteFile(filepath.Join(hubServer.LogDir, constants.ExpandStateFile), []byte(`{"hosts": ["sdw3", "sdw4"]}`), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		setMockExpansionConnections(t, expectTopology)
+		defer hub.ResetConnectToCoordinator()
+
+		err = hubServer.ExpandCluster(&idl.ExpandClusterRequest{CoordinatorPort: 5432, Hosts: []string{"sdw5"}}, &testutils.MockHubStream{})
+
+		expected := "an expansion to hosts sdw3, sdw4 is in progress, run gp expand with the same hosts to resume it"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("errors out on an invalid plan", func(t *testing.T) {
+		cases := []struct {
+			hosts    []string
+			expected string
+		}{
+			{hosts: []string{"sdw2", "sdw3"}, expected: "host sdw2 already has segments of the cluster"},
+			{hosts: []string{"sdw3", "sdw3"}, expected: "host sdw3 is listed more than once"},
+			{hosts: []string{"sdw3"}, expected: "the cluster is mirrored, at least 2 new hosts are needed"},
+			{hosts: []string{}, expected: "at least one new host is needed"},
+		}
+
+		for _, c := range cases {
+			hubServer := newHubServer(t)
+			setMockExpansionConnections(t, expectTopology)
+
+			err := hubServer.ExpandCluster(&idl.ExpandClusterRequest{CoordinatorPort: 5432, Hosts: c.hosts}, &testutils.MockHubStream{})
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("got %v, want %s", err, c.expected)
+			}
+
+			_, err = os.Stat(filepath.Join(hubServer.LogDir, constants.ExpandStateFile))
+			if !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("got %v, want no expansion state to be saved", err)
+			}
+		}
+		hub.ResetConnectToCoordinator()
+	})
+}
@@ -0,0 +1,99 @@
+package hub
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+// fileChunkSize keeps every message well below the default gRPC limit of 4MB
+const fileChunkSize = 64 * 1024
+
+// DistributeConfig pushes the configuration file the hub was started with to
+// the same path on every agent host. The running hub and agents keep using
+// their current configuration until they are restarted.
+func (s *Server) DistributeConfig(ctx context.Context, in *idl.DistributeConfigRequest) (*idl.DistributeConfigReply, error) {
+	if s.ConfigFile == "" {
+		return &idl.DistributeConfigReply{}, fmt.Errorf("the hub was started without a configuration file")
+	}
+
+	contents, err := os.ReadFile(s.ConfigFile)
+	if err != nil {
+		return &idl.DistributeConfigReply{}, fmt.Errorf("could not read configuration file %s: %w", s.ConfigFile, err)
+	}
+
+	err = s.PushFileToAgents(s.ConfigFile, contents, 0644)
+	if err != nil {
+		return &idl.DistributeConfigReply{}, err
+	}
+	gplog.Info("Distributed configuration file %s to %d hosts", s.ConfigFile, len(s.Hostnames))
+
+	return &idl.DistributeConfigReply{}, nil
+}
+
+// PushFileToAgents writes the file on every agent host in parallel
+func (s *Server) PushFileToAgents(path string, contents []byte, mode os.FileMode) error {
+	conns, err := s.connectAgents(nil, false)
+	if err != nil {
+		return err
+	}
+
+	return ExecuteRPC(conns, func(conn *Connection) error {
+		err := PushFile(conn.AgentClient, path, contents, mode)
+		if err != nil {
+			return fmt.Errorf("failed to push file %s to host %s: %w", path, conn.Hostname, err)
+		}
+
+		return nil
+	})
+}
+
+// PushFile streams the file to the agent and verifies the checksum of the
+// file the agent wrote
+func PushFile(client idl.AgentClient, path string, contents []byte, mode os.FileMode) error {
+	sum := sha256.Sum256(contents)
+	checksum := hex.EncodeToString(sum[:])
+
+	stream, err := client.PushFile(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&idl.PushFileRequest{Request: &idl.PushFileRequest_Header{Header: &idl.FileHeader{
+		Path:   path,
+		Mode:   uint32(mode.Perm()),
+		Size:   int64(len(contents)),
+		Sha256: checksum,
+	}}})
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(contents); start += fileChunkSize {
+		end := start + fileChunkSize
+		if end > len(contents) {
+			end = len(contents)
+		}
+
+		err = stream.Send(&idl.PushFileRequest{Request: &idl.PushFileRequest_Chunk{Chunk: contents[start:end]}})
+		if err != nil {
+			break // the agent has failed, the reason is returned by CloseAndRecv
+		}
+	}
+
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	if reply.Sha256 != checksum {
+		return fmt.Errorf("checksum of the written file is %s, expected %s", reply.Sha256, checksum)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/agent"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+func TestPushFile(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	agentServer := grpc.NewServer()
+	defer agentServer.Stop()
+
+	idl.RegisterAgentServer(agentServer, &agent.Server{})
+	go func() {
+		if err := agentServer.Serve(listener); err != nil {
+			log.Fatalf("server exited with error: %v", err)
+		}
+	}()
+
+	dialer := func(ctx context.Context, address string) (net.Conn, error) {
+		return listener.Dial()
+	}
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer conn.Close()
+	client := idl.NewAgentClient(conn)
+
+	t.Run("writes files larger than a single message", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		contents := []byte(strings.Repeat("0123456789abcdef", 10000))
+
+		err := hub.PushFile(client, path, contents, 0640)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		written, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if string(written) != string(contents) {
+			t.Fatalf("got %d bytes, want %d", len(written), len(contents))
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Fatalf("got mode %o, want %o", info.Mode().Perm(), 0640)
+		}
+	})
+
+	t.Run("writes empty files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty")
+
+		err := hub.PushFile(client, path, []byte{}, 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if info.Size() != 0 {
+			t.Fatalf("got size %d, want 0", info.Size())
+		}
+	})
+
+	t.Run("returns the error of the agent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "gp.conf")
+
+		err := hub.PushFile(client, path, []byte("contents"), 0644)
+		expected := "could not write file " + path
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
+
+func TestDistributeConfig(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hubConfig := &hub.Config{
+		constants.DefaultHubPort,
+		constants.DefaultAgentPort,
+		[]string{"sdw1", "sdw2"},
+		"/tmp/logDir",
+		"gp",
+		"gphome",
+		&testutils.MockCredentials{},
+		nil,
+		nil,
+		nil,
+		0,
+	}
+
+	t.Run("pushes the configuration file to every agent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		err := os.WriteFile(path, []byte("{}"), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hubServer := hub.New(hubConfig, nil)
+		hubServer.ConfigFile = path
+		for _, host := range hubConfig.Hostnames {
+			stream := mock_idl.NewMockAgent_PushFileClient(ctrl)
+			stream.EXPECT().Send(gomock.Any()).Return(nil).Times(2)
+			stream.EXPECT().CloseAndRecv().Return(&idl.PushFileReply{
+				Size:   2,
+				Sha256: "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+			}, nil)
+
+			client := mock_idl.NewMockAgentClient(ctrl)
+			client.EXPECT().PushFile(gomock.Any()).Return(stream, nil)
+			hubServer.Conns = append(hubServer.Conns, &hub.Connection{AgentClient: client, Hostname: host})
+		}
+		hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+			return nil
+		})
+		defer hub.ResetEnsureConnectionsAreReady()
+
+		_, err = hubServer.DistributeConfig(context.Background(), &idl.DistributeConfigRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("returns the hosts on which pushing the file failed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gp.conf")
+		err := os.WriteFile(path, []byte("{}"), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expected := errors.New("error")
+		hubServer := hub.New(hubConfig, nil)
+		hubServer.ConfigFile = path
+		for _, host := range hubConfig.Hostnames {
+			client := mock_idl.NewMockAgentClient(ctrl)
+			client.EXPECT().PushFile(gomock.Any()).Return(nil, expected)
+			hubServer.Conns = append(hubServer.Conns, &hub.Connection{AgentClient: client, Hostname: host})
+		}
+		hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+			return nil
+		})
+		defer hub.ResetEnsureConnectionsAreReady()
+
+		_, err = hubServer.DistributeConfig(context.Background(), &idl.DistributeConfigRequest{})
+		var hostErrs *hub.HostErrors
+		if !errors.As(err, &hostErrs) || len(hostErrs.Failed()) != 2 {
+			t.Fatalf("got %v, want failures on both hosts", err)
+		}
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+
+	t.Run("errors out when not able to read the configuration file", func(t *testing.T) {
+		hubServer := hub.New(hubConfig, nil)
+		hubServer.ConfigFile = "/does/not/exist"
+
+		_, err := hubServer.DistributeConfig(context.Background(), &idl.DistributeConfigRequest{})
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("got %v, want %v", err, os.ErrNotExist)
+		}
+	})
+
+	t.Run("errors out when the hub was started without a configuration file", func(t *testing.T) {
+		hubServer := hub.New(hubConfig, nil)
+
+		_, err := hubServer.DistributeConfig(context.Background(), &idl.DistributeConfigRequest{})
+		expected := "the hub was started without a configuration file"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+const (
+	// HubHealthService reports whether the hub accepts requests
+	HubHealthService = "idl.Hub"
+	// AgentsHealthService reports whether the hub is connected to every
+	// agent, each of them serving
+	AgentsHealthService = "agents"
+)
+
+// checkAgentHealth returns an error unless the connection to the agent is
+// ready and the agent reports serving. Agents without the health service are
+// considered serving once the connection is ready.
+func checkAgentHealth(conn *Connection) error {
+	if state := conn.Conn.GetState(); state != connectivity.Ready {
+		return fmt.Errorf("connection to agent on host %s is %s", conn.Hostname, state)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DialTimeout)
+	defer cancel()
+
+	reply, err := healthpb.NewHealthClient(conn.Conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if grpcStatus.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not check the health of agent on host %s: %w", conn.Hostname, err)
+	}
+	if reply.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("agent on host %s is %s", conn.Hostname, reply.Status)
+	}
+
+	return nil
+}
+
+// updateAgentsHealth sets the status of the agents subsystem, checking the
+// agents in parallel. Hosts to which the hub is not connected count as
+// unreachable.
+func (s *Server) updateAgentsHealth(healthServer *health.Server) {
+	s.mutex.Lock()
+	conns := s.Conns
+	hostCount := len(s.Hostnames)
+	s.mutex.Unlock()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if len(conns) < hostCount {
+		gplog.Debug("Agents are not serving: connected to %d of %d agents", len(conns), hostCount)
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	errs := make([]error, len(conns))
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		if conn.Conn == nil {
+			continue
+		}
+
+		i, conn := i, conn
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = checkAgentHealth(conn)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			gplog.Debug("Agents are not serving: %s", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	healthServer.SetServingStatus(AgentsHealthService, status)
+}
+
+// refreshAgentsHealth updates the status of the agents subsystem right away,
+// once the agents have been started or stopped
+func (s *Server) refreshAgentsHealth() {
+	s.mutex.Lock()
+	healthServer := s.health
+	s.mutex.Unlock()
+
+	if healthServer != nil {
+		s.updateAgentsHealth(healthServer)
+	}
+}
+
+// watchAgentsHealth updates the status of the agents subsystem at every
+// interval until done is closed
+func (s *Server) watchAgentsHealth(healthServer *health.Server, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.updateAgentsHealth(healthServer)
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
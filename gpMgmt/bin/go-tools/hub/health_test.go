@@ -0,0 +1,73 @@
+package hub_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// waitForHealth checks the service of the hub until it reports the expected
+// status, as the agents are checked in the background
+func waitForHealth(t *testing.T, client healthpb.HealthClient, service string, expected healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+
+	var reply *healthpb.HealthCheckResponse
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		reply, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err == nil && reply.Status == expected {
+			return
+		}
+	}
+
+	t.Fatalf("got %v %v for service %q, want %s", reply, err, service, expected)
+}
+
+func TestHubHealth(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	dialHealth := func(t *testing.T, conf *hub.Config) healthpb.HealthClient {
+		t.Helper()
+
+		conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", conf.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		return healthpb.NewHealthClient(conn)
+	}
+
+	t.Run("reports the hub as serving", func(t *testing.T) {
+		hubConfig := &hub.Config{
+			Credentials: &testutils.MockCredentials{TlsConnection: insecure.NewCredentials()},
+		}
+		startHub(t, hubConfig)
+		client := dialHealth(t, hubConfig)
+
+		waitForHealth(t, client, "", healthpb.HealthCheckResponse_SERVING)
+		waitForHealth(t, client, hub.HubHealthService, healthpb.HealthCheckResponse_SERVING)
+		waitForHealth(t, client, hub.AgentsHealthService, healthpb.HealthCheckResponse_SERVING)
+	})
+
+	t.Run("reports the agents as not serving when the hub is not connected to them", func(t *testing.T) {
+		hubConfig := &hub.Config{
+			Hostnames:   []string{"sdw1", "sdw2"},
+			Credentials: &testutils.MockCredentials{TlsConnection: insecure.NewCredentials()},
+		}
+		startHub(t, hubConfig)
+		client := dialHealth(t, hubConfig)
+
+		waitForHealth(t, client, hub.HubHealthService, healthpb.HealthCheckResponse_SERVING)
+		waitForHealth(t, client, hub.AgentsHealthService, healthpb.HealthCheckResponse_NOT_SERVING)
+	})
+}
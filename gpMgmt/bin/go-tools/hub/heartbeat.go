@@ -0,0 +1,240 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	AgentAlive       = "alive"
+	AgentUnreachable = "unreachable"
+	AgentUnknown     = "unknown" // before the first heartbeat
+
+	// heartbeatHistorySize bounds the state changes kept for every host
+	heartbeatHistorySize = 20
+)
+
+// AgentStateChange records when the agent of a host changed state
+type AgentStateChange struct {
+	State string
+	Time  time.Time
+	Error string
+}
+
+// AgentLiveness is what the heartbeat knows of the agent of a host
+type AgentLiveness struct {
+	Hostname  string
+	State     string
+	LastSeen  time.Time          // zero when the agent never answered
+	LastCheck time.Time          // zero until the first heartbeat
+	Error     string             // why the last heartbeat failed
+	Status    *idl.ServiceStatus // as reported by the agent when last seen
+	History   []AgentStateChange // oldest first
+}
+
+// Heartbeats caches the last status reported by every agent, so that it can
+// be listed without contacting the agents
+type Heartbeats struct {
+	mutex  sync.RWMutex
+	agents map[string]*AgentLiveness
+}
+
+func NewHeartbeats() *Heartbeats {
+	return &Heartbeats{agents: make(map[string]*AgentLiveness)}
+}
+
+// Record stores the outcome of a status request to the agent of the host,
+// either its status or the error the request failed with, and returns the
+// state of the agent before
+func (h *Heartbeats) Record(host string, status *idl.ServiceStatus, err error, now time.Time) string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	agent, ok := h.agents[host]
+	if !ok {
+		agent = &AgentLiveness{Hostname: host, State: AgentUnknown}
+		h.agents[host] = agent
+	}
+
+	previous := agent.State
+	state := AgentAlive
+	agent.LastCheck = now
+	agent.Error = ""
+	if err != nil {
+		state = AgentUnreachable
+		agent.Error = err.Error()
+	} else {
+		agent.LastSeen = now
+		agent.Status = status
+	}
+
+	if state != agent.State {
+		if state == AgentUnreachable {
+			gplog.Warn("Agent on host %s is unreachable: %s", host, err)
+		} else if agent.State == AgentUnreachable {
+			gplog.Info("Agent on host %s is reachable again", host)
+		}
+
+		agent.State = state
+		agent.History = append(agent.History, AgentStateChange{State: state, Time: now, Error: agent.Error})
+		if len(agent.History) > heartbeatHistorySize {
+			agent.History = agent.History[len(agent.History)-heartbeatHistorySize:]
+		}
+	}
+
+	return previous
+}
+
+// Agent returns what is known of the agent of the host, with the unknown
+// state when it was never checked
+func (h *Heartbeats) Agent(host string) AgentLiveness {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	agent, ok := h.agents[host]
+	if !ok {
+		return AgentLiveness{Hostname: host, State: AgentUnknown}
+	}
+
+	liveness := *agent
+	liveness.History = append([]AgentStateChange{}, agent.History...)
+
+	return liveness
+}
+
+// agentStatus requests the status of the agent, as reported by the hub
+func agentStatus(ctx context.Context, conn *Connection) (*idl.ServiceStatus, error) {
+	status, err := conn.AgentClient.Status(ctx, &idl.StatusAgentRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &idl.ServiceStatus{
+		Host:           conn.Hostname,
+		Status:         status.Status,
+		Uptime:         status.Uptime,
+		Pid:            status.Pid,
+		Certificates:   status.Certificates,
+		StartTime:      status.StartTime,
+		MemoryRss:      status.MemoryRss,
+		CpuTime:        status.CpuTime,
+		Connections:    status.Connections,
+		Version:        status.Version,
+		ConfigChecksum: status.ConfigChecksum,
+		ActiveState:    status.ActiveState,
+		SubState:       status.SubState,
+		Result:         status.Result,
+	}, nil
+}
+
+// heartbeat requests the status of every agent the hub is connected to and
+// records the outcome. Hosts without a connection are handed to the reconnect
+// loop, and recorded as unreachable unless the hub has yet to try them.
+// Nothing is checked while the agents are stopped through the hub.
+func (s *Server) heartbeat() {
+	s.mutex.Lock()
+	if s.agentsStopped {
+		s.mutex.Unlock()
+		return
+	}
+	hosts := append([]string{}, s.Hostnames...)
+	conns := make(map[string]*Connection, len(s.Conns))
+	for _, conn := range s.Conns {
+		conns[conn.Hostname] = conn
+	}
+	failed := make(map[string]bool)
+	for _, host := range hosts {
+		if conns[host] == nil {
+			s.requestReconnect(host)
+			failed[host] = s.reconnects[host].failures > 0
+		}
+	}
+	s.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		host := host
+		conn := conns[host]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if conn == nil {
+				if !failed[host] && s.Heartbeats.Agent(host).State == AgentUnknown {
+					return
+				}
+				s.recordHeartbeat(host, nil, fmt.Errorf("not connected to agent on host %s", host))
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), DialTimeout)
+			defer cancel()
+
+			status, err := agentStatus(ctx, conn)
+			s.recordHeartbeat(host, status, err)
+		}()
+	}
+	wg.Wait()
+}
+
+// watchHeartbeats checks the agents at every interval until done is closed
+func (s *Server) watchHeartbeats(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.heartbeat()
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListAgents returns what the heartbeat knows of every agent, without
+// contacting them
+func (s *Server) ListAgents(ctx context.Context, in *idl.ListAgentsRequest) (*idl.ListAgentsReply, error) {
+	agents := make([]*idl.AgentInfo, 0, len(s.Hostnames))
+	for _, state := range s.AgentStates() {
+		agent := s.Heartbeats.Agent(state.Hostname)
+
+		info := &idl.AgentInfo{
+			Host:            agent.Hostname,
+			State:           agent.State,
+			Error:           agent.Error,
+			ConnectionState: state.State,
+			ConnectFailures: int32(state.Failures),
+			Status:          &idl.ServiceStatus{Host: agent.Hostname, Status: agent.State},
+		}
+		if !agent.LastSeen.IsZero() {
+			info.LastSeen = timestamppb.New(agent.LastSeen)
+		}
+		if !agent.LastCheck.IsZero() {
+			info.LastCheck = timestamppb.New(agent.LastCheck)
+		}
+		if agent.State == AgentAlive && agent.Status != nil {
+			info.Status = proto.Clone(agent.Status).(*idl.ServiceStatus)
+		}
+		info.Status.LastSeen = info.LastSeen
+		for _, change := range agent.History {
+			info.History = append(info.History, &idl.AgentStateChange{
+				State: change.State,
+				Time:  timestamppb.New(change.Time),
+				Error: change.Error,
+			})
+		}
+
+		agents = append(agents, info)
+	}
+
+	return &idl.ListAgentsReply{Agents: agents}, nil
+}
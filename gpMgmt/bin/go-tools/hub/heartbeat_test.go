@@ -0,0 +1,142 @@
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+func TestHeartbeats(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("reports the unknown state before the first heartbeat", func(t *testing.T) {
+		heartbeats := hub.NewHeartbeats()
+
+		agent := heartbeats.Agent("sdw1")
+		if agent.State != hub.AgentUnknown || !agent.LastSeen.IsZero() {
+			t.Fatalf("got %+v, want an unknown agent", agent)
+		}
+	})
+
+	t.Run("keeps the last seen time and the state changes", func(t *testing.T) {
+		heartbeats := hub.NewHeartbeats()
+		status := &idl.ServiceStatus{Host: "sdw1", Status: "running"}
+
+		heartbeats.Record("sdw1", status, nil, start)
+		heartbeats.Record("sdw1", status, nil, start.Add(time.Minute))
+		heartbeats.Record("sdw1", nil, errors.New("connection refused"), start.Add(2*time.Minute))
+		heartbeats.Record("sdw1", nil, errors.New("connection refused"), start.Add(3*time.Minute))
+
+		agent := heartbeats.Agent("sdw1")
+		if agent.State != hub.AgentUnreachable || agent.Error != "connection refused" {
+			t.Fatalf("got %+v, want an unreachable agent", agent)
+		}
+		if !agent.LastSeen.Equal(start.Add(time.Minute)) || !agent.LastCheck.Equal(start.Add(3*time.Minute)) {
+			t.Fatalf("got last seen %s and last check %s", agent.LastSeen, agent.LastCheck)
+		}
+		if agent.Status != status {
+			t.Fatalf("got %+v, want the last status reported", agent.Status)
+		}
+
+		expected := []hub.AgentStateChange{
+			{State: hub.AgentAlive, Time: start},
+			{State: hub.AgentUnreachable, Time: start.Add(2 * time.Minute), Error: "connection refused"},
+		}
+		if len(agent.History) != len(expected) {
+			t.Fatalf("got %+v, want %+v", agent.History, expected)
+		}
+		for i := range expected {
+			if agent.History[i] != expected[i] {
+				t.Fatalf("got %+v, want %+v", agent.History, expected)
+			}
+		}
+	})
+
+	t.Run("bounds the history of every host", func(t *testing.T) {
+		heartbeats := hub.NewHeartbeats()
+
+		for i := 0; i < 50; i++ {
+			var err error
+			if i%2 == 1 {
+				err = errors.New("error")
+			}
+			heartbeats.Record("sdw1", &idl.ServiceStatus{}, err, start.Add(time.Duration(i)*time.Minute))
+		}
+
+		history := heartbeats.Agent("sdw1").History
+		if len(history) != 20 || !history[19].Time.Equal(start.Add(49*time.Minute)) {
+			t.Fatalf("got %d changes ending with %+v, want the last 20", len(history), history[len(history)-1])
+		}
+	})
+}
+
+func TestListAgents(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hubConfig := testutils.InitializeTestEnv()
+	hubConfig.Hostnames = []string{"sdw1", "sdw2", "sdw3"}
+	hubServer := hub.New(hubConfig, nil)
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+
+	sdw1 := mock_idl.NewMockAgentClient(ctrl)
+	sdw1.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(&idl.StatusAgentReply{Status: "running", Pid: 123}, nil)
+	sdw2 := mock_idl.NewMockAgentClient(ctrl)
+	sdw2.EXPECT().Status(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+	hubServer.Conns = []*hub.Connection{
+		{AgentClient: sdw1, Hostname: "sdw1"},
+		{AgentClient: sdw2, Hostname: "sdw2"},
+	}
+	hubServer.Heartbeats.Record("sdw2", &idl.ServiceStatus{Host: "sdw2", Status: "running"}, nil, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	// the status requested by a client is recorded as well
+	_, _ = hubServer.StatusAgents(context.Background(), &idl.StatusAgentsRequest{SkipUnreachable: true})
+
+	reply, err := hubServer.ListAgents(context.Background(), &idl.ListAgentsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if len(reply.Agents) != 3 {
+		t.Fatalf("got %+v, want 3 agents", reply.Agents)
+	}
+
+	sdw1Info := reply.Agents[0]
+	expectedStatus := &idl.ServiceStatus{Host: "sdw1", Status: "running", Pid: 123, LastSeen: sdw1Info.LastSeen}
+	if sdw1Info.State != hub.AgentAlive || sdw1Info.LastSeen == nil || !proto.Equal(sdw1Info.Status, expectedStatus) {
+		t.Fatalf("got %+v, want sdw1 alive", sdw1Info)
+	}
+
+	sdw2Info := reply.Agents[1]
+	lastSeen := timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	expectedStatus = &idl.ServiceStatus{Host: "sdw2", Status: hub.AgentUnreachable, LastSeen: lastSeen}
+	if sdw2Info.State != hub.AgentUnreachable || !proto.Equal(sdw2Info.LastSeen, lastSeen) || !proto.Equal(sdw2Info.Status, expectedStatus) {
+		t.Fatalf("got %+v, want sdw2 unreachable since it was last seen", sdw2Info)
+	}
+	if len(sdw2Info.History) != 2 || sdw2Info.History[1].State != hub.AgentUnreachable || sdw2Info.Error == "" {
+		t.Fatalf("got %+v, want sdw2 to have gone from alive to unreachable", sdw2Info.History)
+	}
+
+	sdw3Info := reply.Agents[2]
+	if sdw3Info.State != hub.AgentUnknown || sdw3Info.ConnectionState != "NONE" || sdw3Info.LastSeen != nil {
+		t.Fatalf("got %+v, want sdw3 unknown", sdw3Info)
+	}
+}
@@ -0,0 +1,483 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+const defaultDataDirPrefix = "gpseg"
+
+// InitCluster initializes the cluster declared by the request as gpinitsystem
+// does: the coordinator and the primaries are created with initdb, registered
+// in gp_segment_configuration with the coordinator in utility mode and started,
+// then the mirrors are copied from their primaries. Whatever was created is
+// removed when any step fails.
+func (s *Server) InitCluster(in *idl.InitClusterRequest, stream idl.Hub_InitClusterServer) error {
+	progress := newProgressReporter(stream)
+
+	hubHost, err := hostname()
+	if err != nil {
+		return fmt.Errorf("could not get hostname: %w", err)
+	}
+
+	segments, err := planCluster(in, hubHost)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkSegmentHosts(segments)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.connectAgents(nil, false)
+	if err != nil {
+		return err
+	}
+
+	initializer := &clusterInitializer{
+		server:   s,
+		progress: progress,
+		segments: segments,
+		locale:   in.Locale,
+		encoding: in.Encoding,
+	}
+	progress.Info("Initializing a cluster of %d primaries and %d mirrors on %d segment hosts",
+		len(segments.Primaries()), len(segments.Mirrors()), len(in.Segments.Hosts))
+
+	err = initializer.run()
+	if err != nil {
+		progress.Warn("Could not initialize the cluster, removing the segments created: %s", err)
+		initializer.cleanup()
+		return err
+	}
+	progress.Summary("Cluster initialized successfully")
+
+	return nil
+}
+
+// planCluster lays out the segments of the cluster declared by the request,
+// coordinator first, then the primaries and their mirrors. The primaries of
+// a host get consecutive content IDs and ports, one for each primary
+// directory, and so do the mirrors. The coordinator is initialized by the hub
+// itself, so it has to be on the hub host.
+func planCluster(in *idl.InitClusterRequest, hubHost string) (Segments, error) {
+	coordinator, spec := in.Coordinator, in.Segments
+	if coordinator == nil || coordinator.Hostname == "" || coordinator.Directory == "" {
+		return nil, fmt.Errorf("the coordinator needs a hostname and a directory")
+	}
+	if coordinator.Hostname != hubHost {
+		return nil, fmt.Errorf("the coordinator has to be on the hub host %s, not on %s", hubHost, coordinator.Hostname)
+	}
+	if coordinator.Port <= 0 {
+		return nil, fmt.Errorf("invalid coordinator port %d", coordinator.Port)
+	}
+	if spec == nil || len(spec.Hosts) == 0 {
+		return nil, fmt.Errorf("at least one segment host is needed")
+	}
+	if len(spec.PrimaryDirectories) == 0 {
+		return nil, fmt.Errorf("at least one primary directory is needed")
+	}
+	if spec.PrimaryBasePort <= 0 {
+		return nil, fmt.Errorf("invalid primary base port %d", spec.PrimaryBasePort)
+	}
+
+	directories := append([]string{coordinator.Directory}, spec.PrimaryDirectories...)
+	for _, directory := range append(directories, spec.MirrorDirectories...) {
+		if !filepath.IsAbs(directory) {
+			return nil, fmt.Errorf("directory %s is not an absolute path", directory)
+		}
+	}
+
+	hosts := make(map[string]bool, len(spec.Hosts))
+	for _, host := range spec.Hosts {
+		if hosts[host] {
+			return nil, fmt.Errorf("segment host %s is listed more than once", host)
+		}
+		hosts[host] = true
+	}
+
+	perHost := len(spec.PrimaryDirectories)
+	switch spec.Mirroring {
+	case idl.MirroringStrategy_NO_MIRRORS:
+		if len(spec.MirrorDirectories) > 0 {
+			return nil, fmt.Errorf("mirror directories are given without a mirroring strategy")
+		}
+	case idl.MirroringStrategy_GROUP, idl.MirroringStrategy_SPREAD:
+		if len(spec.MirrorDirectories) != perHost {
+			return nil, fmt.Errorf("expected %d mirror directories, one for each primary directory, got %d", perHost, len(spec.MirrorDirectories))
+		}
+		if spec.MirrorBasePort <= 0 {
+			return nil, fmt.Errorf("invalid mirror base port %d", spec.MirrorBasePort)
+		}
+		if len(spec.Hosts) < 2 {
+			return nil, fmt.Errorf("mirroring needs at least 2 segment hosts")
+		}
+		if spec.Mirroring == idl.MirroringStrategy_SPREAD && len(spec.Hosts) <= perHost {
+			return nil, fmt.Errorf("spread mirroring needs more segment hosts than primaries per host, got %d hosts for %d primaries", len(spec.Hosts), perHost)
+		}
+	default:
+		return nil, fmt.Errorf("invalid mirroring strategy %s", spec.Mirroring)
+	}
+
+	prefix := spec.Prefix
+	if prefix == "" {
+		prefix = defaultDataDirPrefix
+	}
+	dataDir := func(directory string, contentID int) string {
+		return filepath.Join(directory, fmt.Sprintf("%s%d", prefix, contentID))
+	}
+
+	numPrimaries := len(spec.Hosts) * perHost
+	segments := Segments{{
+		DbID:          1,
+		ContentID:     CoordinatorContentID,
+		Role:          RolePrimary,
+		PreferredRole: RolePrimary,
+		Mode:          ModeNotSynchronized,
+		Status:        StatusUp,
+		Port:          int(coordinator.Port),
+		Hostname:      coordinator.Hostname,
+		Address:       coordinator.Hostname,
+		DataDir:       dataDir(coordinator.Directory, CoordinatorContentID),
+	}}
+
+	for i, host := range spec.Hosts {
+		for j, directory := range spec.PrimaryDirectories {
+			contentID := i*perHost + j
+			segments = append(segments, Segment{
+				DbID:          contentID + 2,
+				ContentID:     contentID,
+				Role:          RolePrimary,
+				PreferredRole: RolePrimary,
+				Mode:          ModeNotSynchronized,
+				Status:        StatusUp,
+				Port:          int(spec.PrimaryBasePort) + j,
+				Hostname:      host,
+				Address:       host,
+				DataDir:       dataDir(directory, contentID),
+			})
+		}
+	}
+
+	if spec.Mirroring != idl.MirroringStrategy_NO_MIRRORS {
+		for i := range spec.Hosts {
+			for j, directory := range spec.MirrorDirectories {
+				contentID := i*perHost + j
+
+				// Group mirroring moves every primary of a host to the next
+				// host, spread mirroring moves each of them to a different one
+				host := spec.Hosts[(i+1)%len(spec.Hosts)]
+				if spec.Mirroring == idl.MirroringStrategy_SPREAD {
+					host = spec.Hosts[(i+1+j)%len(spec.Hosts)]
+				}
+
+				segments = append(segments, Segment{
+					DbID:          numPrimaries + contentID + 2,
+					ContentID:     contentID,
+					Role:          RoleMirror,
+					PreferredRole: RoleMirror,
+					Mode:          ModeNotSynchronized,
+					Status:        StatusUp,
+					Port:          int(spec.MirrorBasePort) + j,
+					Hostname:      host,
+					Address:       host,
+					DataDir:       dataDir(directory, contentID),
+				})
+			}
+		}
+	}
+
+	err := checkLayout(segments)
+	if err != nil {
+		return nil, err
+	}
+
+	return segments, nil
+}
+
+// checkLayout checks that no two segments share a port or a data directory on
+// the same host
+func checkLayout(segments Segments) error {
+	ports := make(map[string]Segment)
+	dataDirs := make(map[string]Segment)
+	for _, seg := range segments {
+		port := fmt.Sprintf("%s:%d", seg.Hostname, seg.Port)
+		if other, ok := ports[port]; ok {
+			return fmt.Errorf("segments %d and %d would both use port %d on host %s", other.DbID, seg.DbID, seg.Port, seg.Hostname)
+		}
+		ports[port] = seg
+
+		dataDir := fmt.Sprintf("%s:%s", seg.Hostname, seg.DataDir)
+		if other, ok := dataDirs[dataDir]; ok {
+			return fmt.Errorf("segments %d and %d would both use data directory %s on host %s", other.DbID, seg.DbID, seg.DataDir, seg.Hostname)
+		}
+		dataDirs[dataDir] = seg
+	}
+
+	return nil
+}
+
+// checkSegmentHosts checks that the hub has an agent on every segment host
+func (s *Server) checkSegmentHosts(segments Segments) error {
+	configured := make(map[string]bool, len(s.Hostnames))
+	for _, host := range s.Hostnames {
+		configured[host] = true
+	}
+
+	for _, host := range segments.Filter(func(seg Segment) bool { return !seg.IsCoordinator() }).Hostnames() {
+		if !configured[host] {
+			return fmt.Errorf("segment host %s is not among the hosts of the hub configuration", host)
+		}
+	}
+
+	return nil
+}
+
+// clusterInitializer runs the steps of the initialization of a cluster,
+// keeping track of the segments it created to remove them on failure
+type clusterInitializer struct {
+	server   *Server
+	progress *progressReporter
+	segments Segments
+	locale   string
+	encoding string
+	copyFrom *Segment // the coordinator the new primaries are copied from instead of created with initdb, when set
+
+	mutex   sync.Mutex
+	created Segments
+}
+
+func (ci *clusterInitializer) run() error {
+	s, progress := ci.server, ci.progress
+	coordinator := ci.segments[0]
+	primaries := ci.segments.Primaries()
+	mirrors := ci.segments.Mirrors()
+
+	progress.Info("Initializing coordinator")
+	err := ci.initCoordinator(coordinator)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Initializing primary segments")
+	err = s.executeOnSegments(primaries, ci.initSegment)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Registering the segments in the catalog")
+	err = ci.registerSegments(coordinator)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Starting primary segments")
+	err = s.startSegments(primaries, progress)
+	if err != nil {
+		return err
+	}
+
+	if len(mirrors) > 0 {
+		progress.Info("Creating mirror segments")
+		primaryOf := make(map[int]Segment, len(primaries))
+		for _, primary := range primaries {
+			primaryOf[primary.ContentID] = primary
+		}
+
+		err = s.executeOnSegments(mirrors, func(conn *Connection, seg Segment) error {
+			return ci.createMirror(conn, seg, primaryOf[seg.ContentID])
+		})
+		if err != nil {
+			return err
+		}
+
+		progress.Info("Starting mirror segments")
+		err = s.startSegments(mirrors, progress)
+		if err != nil {
+			return err
+		}
+	}
+
+	progress.Info("Starting coordinator")
+	return s.startCoordinator(coordinator.DataDir, coordinator.Port, utils.GpRoleDispatch)
+}
+
+func (ci *clusterInitializer) markCreated(seg Segment) {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+
+	ci.created = append(ci.created, seg)
+}
+
+// hbaHostnames returns the hosts of the cluster, which the segments trust
+func (ci *clusterInitializer) hbaHostnames() []string {
+	return ci.segments.Hostnames()
+}
+
+// initCoordinator creates the data directory of the coordinator, which lives
+// on the hub host
+func (ci *clusterInitializer) initCoordinator(coordinator Segment) error {
+	currentUser, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("could not get current user: %w", err)
+	}
+
+	args := utils.InitdbArgs(coordinator.DataDir, ci.encoding, ci.locale)
+	output, err := execCommand(utils.InitdbPath(ci.server.GpHome), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("could not initialize coordinator with data directory %s: %w, Command Output: %s", coordinator.DataDir, err, string(output))
+	}
+	ci.markCreated(coordinator)
+
+	err = utils.WriteSegmentConfig(coordinator.DataDir, coordinator.Port, coordinator.ContentID, coordinator.DbID, currentUser.Username, ci.hbaHostnames())
+	if err != nil {
+		return fmt.Errorf("could not configure coordinator with data directory %s: %w", coordinator.DataDir, err)
+	}
+	gplog.Info("Initialized coordinator with data directory %s", coordinator.DataDir)
+
+	return nil
+}
+
+func (ci *clusterInitializer) initSegment(conn *Connection, seg Segment) error {
+	ci.progress.Running(conn.Hostname, fmt.Sprintf("initializing segment %d", seg.ContentID))
+	request := &idl.InitSegmentRequest{
+		DataDir:      seg.DataDir,
+		Port:         int32(seg.Port),
+		ContentId:    int32(seg.ContentID),
+		Dbid:         int32(seg.DbID),
+		Locale:       ci.locale,
+		Encoding:     ci.encoding,
+		HbaHostnames: ci.hbaHostnames(),
+	}
+	if ci.copyFrom != nil {
+		request.CoordinatorHost = ci.copyFrom.Address
+		request.CoordinatorPort = int32(ci.copyFrom.Port)
+	}
+	_, err := conn.AgentClient.InitSegment(context.Background(), request)
+	ci.progress.Host(conn.Hostname, fmt.Sprintf("initialize segment %d with data directory %s", seg.ContentID, seg.DataDir), err)
+	if err != nil {
+		return fmt.Errorf("failed to initialize segment %d on host %s: %w", seg.ContentID, conn.Hostname, err)
+	}
+	ci.markCreated(seg)
+
+	return nil
+}
+
+// registerSegments adds every segment to gp_segment_configuration, with the
+// coordinator started in utility mode for the time being
+func (ci *clusterInitializer) registerSegments(coordinator Segment) error {
+	s := ci.server
+	err := s.startCoordinator(coordinator.DataDir, coordinator.Port, utils.GpRoleUtility)
+	if err != nil {
+		return err
+	}
+
+	err = addSegments(coordinator.Port, true, ci.segments)
+	if err != nil {
+		return err
+	}
+
+	return s.stopCoordinator(coordinator.DataDir, utils.StopModeFast)
+}
+
+// addSegments registers the segments in gp_segment_configuration, connecting
+// to the coordinator in utility mode when it was started without the segments
+func addSegments(port int, utilityMode bool, segments Segments) error {
+	conn, err := connectToCoordinatorFunc(port, utilityMode)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, seg := range segments {
+		_, err = conn.Exec(addSegmentQuery(seg))
+		if err != nil {
+			return fmt.Errorf("could not register segment %d with data directory %s on host %s: %w", seg.ContentID, seg.DataDir, seg.Hostname, err)
+		}
+	}
+
+	return nil
+}
+
+func addSegmentQuery(seg Segment) string {
+	return fmt.Sprintf("SELECT pg_catalog.gp_add_segment(%d::int2, %d::int2, '%s', '%s', '%s', '%s', %d, %s, %s, %s)",
+		seg.DbID, seg.ContentID, seg.Role, seg.PreferredRole, seg.Mode, seg.Status, seg.Port,
+		quoteLiteral(seg.Hostname), quoteLiteral(seg.Address), quoteLiteral(seg.DataDir))
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// createMirror has the agent copy the data directory of the mirror from its
+// running primary
+func (ci *clusterInitializer) createMirror(conn *Connection, seg Segment, primary Segment) error {
+	ci.progress.Running(conn.Hostname, fmt.Sprintf("creating segment %d from %s:%d", seg.ContentID, primary.Address, primary.Port))
+	err := streamRecovery(conn, seg, &idl.RecoverSegmentRequest{
+		DataDir:    seg.DataDir,
+		ContentId:  int32(seg.ContentID),
+		Dbid:       int32(seg.DbID),
+		SourceHost: primary.Address,
+		SourcePort: int32(primary.Port),
+		NewMirror:  true,
+	}, ci.progress)
+	ci.progress.Host(conn.Hostname, fmt.Sprintf("create mirror segment %d with data directory %s", seg.ContentID, seg.DataDir), err)
+	if err != nil {
+		return fmt.Errorf("failed to create mirror segment %d on host %s: %w", seg.ContentID, conn.Hostname, err)
+	}
+	ci.markCreated(seg)
+
+	return nil
+}
+
+// cleanup stops and removes the segments created, reporting those that could
+// not be removed
+func (ci *clusterInitializer) cleanup() {
+	s, progress := ci.server, ci.progress
+
+	var coordinator *Segment
+	segments := make(Segments, 0, len(ci.created))
+	for _, seg := range ci.created {
+		if seg.IsCoordinator() {
+			seg := seg
+			coordinator = &seg
+			continue
+		}
+		segments = append(segments, seg)
+	}
+
+	if coordinator != nil {
+		err := s.stopCoordinator(coordinator.DataDir, utils.StopModeImmediate)
+		if err != nil {
+			gplog.Debug("Could not stop coordinator, it may not be running: %s", err)
+		}
+
+		err = os.RemoveAll(coordinator.DataDir)
+		if err != nil {
+			progress.Warn("Could not remove coordinator data directory %s: %s", coordinator.DataDir, err)
+		}
+	}
+
+	err := s.executeOnSegments(segments, func(conn *Connection, seg Segment) error {
+		_, err := conn.AgentClient.RemoveSegment(context.Background(), &idl.RemoveSegmentRequest{
+			DataDir:   seg.DataDir,
+			ContentId: int32(seg.ContentID),
+		})
+		progress.Host(conn.Hostname, fmt.Sprintf("remove segment %d with data directory %s", seg.ContentID, seg.DataDir), err)
+
+		return nil
+	})
+	if err != nil {
+		progress.Warn("Could not remove the segments created: %s", err)
+	}
+}
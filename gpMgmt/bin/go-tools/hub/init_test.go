@@ -0,0 +1,391 @@
+package hub_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
+)
+
+func initClusterRequest(coordinatorDir string) *idl.InitClusterRequest {
+	return &idl.InitClusterRequest{
+		Coordinator: &idl.CoordinatorSpec{Hostname: "cdw", Directory: coordinatorDir, Port: 5432},
+		Segments: &idl.SegmentsSpec{
+			Hosts:              []string{"sdw1", "sdw2"},
+			PrimaryDirectories: []string{"/data/primary"},
+			PrimaryBasePort:    6000,
+			MirrorDirectories:  []string{"/data/mirror"},
+			MirrorBasePort:     7000,
+			Mirroring:          idl.MirroringStrategy_GROUP,
+		},
+		Encoding: "UTF-8",
+	}
+}
+
+func TestInitCluster(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hubConfig := testutils.InitializeTestEnv()
+	hubConfig.Hostnames = []string{"sdw1", "sdw2", "sdw3"}
+	hubServer := hub.New(hubConfig, nil)
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+	hub.SetHostname(func() (string, error) {
+		return "cdw", nil
+	})
+	defer hub.ResetHostname()
+
+	t.Run("initializes the coordinator and the segments, then registers and starts them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		coordinatorDir := t.TempDir()
+		err := os.Mkdir(filepath.Join(coordinatorDir, "gpseg-1"), 0700)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var mutex sync.Mutex
+		var commands []string
+		hub.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			command := filepath.Base(utility)
+			if command == "pg_ctl" {
+				command += " " + args[len(args)-1]
+				if args[len(args)-1] == "start" {
+					command += " " + args[len(args)-2]
+				}
+			}
+			commands = append(commands, command)
+		}))
+		defer hub.ResetExecCommand()
+
+		queries := []string{
+			"SELECT pg_catalog.gp_add_segment(1::int2, -1::int2, 'p', 'p', 'n', 'u', 5432, 'cdw', 'cdw', '" + filepath.Join(coordinatorDir, "gpseg-1") + "')",
+			"SELECT pg_catalog.gp_add_segment(2::int2, 0::int2, 'p', 'p', 'n', 'u', 6000, 'sdw1', 'sdw1', '/data/primary/gpseg0')",
+			"SELECT pg_catalog.gp_add_segment(3::int2, 1::int2, 'p', 'p', 'n', 'u', 6000, 'sdw2', 'sdw2', '/data/primary/gpseg1')",
+			"SELECT pg_catalog.gp_add_segment(4::int2, 0::int2, 'm', 'm', 'n', 'u', 7000, 'sdw2', 'sdw2', '/data/mirror/gpseg0')",
+			"SELECT pg_catalog.gp_add_segment(5::int2, 1::int2, 'm', 'm', 'n', 'u', 7000, 'sdw1', 'sdw1', '/data/mirror/gpseg1')",
+		}
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			if !utilityMode {
+				t.Fatalf("expected a utility mode connection")
+			}
+
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			for _, query := range queries {
+				mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		hbaHostnames := []string{"cdw", "sdw1", "sdw2"}
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().InitSegment(gomock.Any(), &idl.InitSegmentRequest{
+			DataDir:      "/data/primary/gpseg0",
+			Port:         6000,
+			ContentId:    0,
+			Dbid:         2,
+			Encoding:     "UTF-8",
+			HbaHostnames: hbaHostnames,
+		}).Return(&idl.InitSegmentReply{}, nil)
+		sdw1.EXPECT().RecoverSegment(gomock.Any(), &idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg1",
+			ContentId:  1,
+			Dbid:       5,
+			SourceHost: "sdw2",
+			SourcePort: 6000,
+			NewMirror:  true,
+		}).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw1.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil).Times(2)
+
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().InitSegment(gomock.Any(), &idl.InitSegmentRequest{
+			DataDir:      "/data/primary/gpseg1",
+			Port:         6000,
+			ContentId:    1,
+			Dbid:         3,
+			Encoding:     "UTF-8",
+			HbaHostnames: hbaHostnames,
+		}).Return(&idl.InitSegmentReply{}, nil)
+		sdw2.EXPECT().RecoverSegment(gomock.Any(), &idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg0",
+			ContentId:  0,
+			Dbid:       4,
+			SourceHost: "sdw1",
+			SourcePort: 6000,
+			NewMirror:  true,
+		}).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw2.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil).Times(2)
+
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw3"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err = hubServer.InitCluster(initClusterRequest(coordinatorDir), stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expectedCommands := []string{"initdb", "pg_ctl start -p 5432 -c gp_role=utility", "pg_ctl stop", "pg_ctl start -p 5432 -c gp_role=dispatch"}
+		if !reflect.DeepEqual(commands, expectedCommands) {
+			t.Fatalf("got %q, want %q", commands, expectedCommands)
+		}
+
+		contents, err := os.ReadFile(filepath.Join(coordinatorDir, "gpseg-1", "internal.auto.conf"))
+		if err != nil || string(contents) != "gp_dbid=1\n" {
+			t.Fatalf("got %q (%v), want the coordinator to be configured", contents, err)
+		}
+
+		summary := stream.Summary()
+		if summary.Message != "Cluster initialized successfully" {
+			t.Fatalf("got %+v, want the cluster to be initialized", summary)
+		}
+	})
+
+	t.Run("removes the segments created when a step fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		coordinatorDir := t.TempDir()
+		err := os.Mkdir(filepath.Join(coordinatorDir, "gpseg-1"), 0700)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
+		defer hub.ResetExecCommand()
+
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			mock.ExpectExec("SELECT pg_catalog.gp_add_segment").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("SELECT pg_catalog.gp_add_segment").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("SELECT pg_catalog.gp_add_segment").WillReturnError(errors.New("error"))
+
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		var mutex sync.Mutex
+		var removed []string
+		recordRemoved := func(in *idl.RemoveSegmentRequest) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			removed = append(removed, in.DataDir)
+		}
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().InitSegment(gomock.Any(), gomock.Any()).Return(&idl.InitSegmentReply{}, nil)
+		sdw1.EXPECT().RemoveSegment(gomock.Any(), gomock.Any()).Do(func(_ interface{}, in *idl.RemoveSegmentRequest, _ ...interface{}) {
+			recordRemoved(in)
+		}).Return(&idl.RemoveSegmentReply{}, nil)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().InitSegment(gomock.Any(), gomock.Any()).Return(&idl.InitSegmentReply{}, nil)
+		sdw2.EXPECT().RemoveSegment(gomock.Any(), gomock.Any()).Do(func(_ interface{}, in *idl.RemoveSegmentRequest, _ ...interface{}) {
+			recordRemoved(in)
+		}).Return(&idl.RemoveSegmentReply{}, nil)
+
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw3"},
+		}
+
+		err = hubServer.InitCluster(initClusterRequest(coordinatorDir), &testutils.MockHubStream{})
+
+		expected := "could not register segment 1 with data directory /data/primary/gpseg1 on host sdw2: error"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+
+		sort.Strings(removed)
+		expectedRemoved := []string{"/data/primary/gpseg0", "/data/primary/gpseg1"}
+		if !reflect.DeepEqual(removed, expectedRemoved) {
+			t.Fatalf("got %q, want %q", removed, expectedRemoved)
+		}
+
+		_, err = os.Stat(filepath.Join(coordinatorDir, "gpseg-1"))
+		if !os.IsNotExist(err) {
+			t.Fatalf("got %v, want the coordinator data directory to be removed", err)
+		}
+	})
+
+	t.Run("errors out when the spec is invalid", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			modify   func(in *idl.InitClusterRequest)
+			expected string
+		}{
+			{
+				name: "coordinator on another host than the hub",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Coordinator.Hostname = "smdw"
+				},
+				expected: "the coordinator has to be on the hub host cdw, not on smdw",
+			},
+			{
+				name: "no segment host",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.Hosts = nil
+				},
+				expected: "at least one segment host is needed",
+			},
+			{
+				name: "relative directory",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.PrimaryDirectories = []string{"data/primary"}
+				},
+				expected: "directory data/primary is not an absolute path",
+			},
+			{
+				name: "segment host listed twice",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.Hosts = []string{"sdw1", "sdw1"}
+				},
+				expected: "segment host sdw1 is listed more than once",
+			},
+			{
+				name: "missing mirror directories",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.PrimaryDirectories = []string{"/data/primary", "/data/primary"}
+				},
+				expected: "expected 2 mirror directories, one for each primary directory, got 1",
+			},
+			{
+				name: "mirror directories without mirroring",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.Mirroring = idl.MirroringStrategy_NO_MIRRORS
+				},
+				expected: "mirror directories are given without a mirroring strategy",
+			},
+			{
+				name: "mirroring on a single host",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.Hosts = []string{"sdw1"}
+				},
+				expected: "mirroring needs at least 2 segment hosts",
+			},
+			{
+				name: "spread mirroring on too few hosts",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.Mirroring = idl.MirroringStrategy_SPREAD
+					in.Segments.PrimaryDirectories = []string{"/data/primary", "/data/primary"}
+					in.Segments.MirrorDirectories = []string{"/data/mirror", "/data/mirror"}
+				},
+				expected: "spread mirroring needs more segment hosts than primaries per host, got 2 hosts for 2 primaries",
+			},
+			{
+				name: "port used twice on a host",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.MirrorBasePort = 6000
+				},
+				expected: "segments 3 and 4 would both use port 6000 on host sdw2",
+			},
+			{
+				name: "segment host unknown to the hub",
+				modify: func(in *idl.InitClusterRequest) {
+					in.Segments.Hosts = []string{"sdw1", "sdw4"}
+				},
+				expected: "segment host sdw4 is not among the hosts of the hub configuration",
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				in := initClusterRequest("/data/coordinator")
+				tc.modify(in)
+
+				err := hubServer.InitCluster(in, &testutils.MockHubStream{})
+				if err == nil || err.Error() != tc.expected {
+					t.Fatalf("got %v, want %s", err, tc.expected)
+				}
+			})
+		}
+	})
+
+	t.Run("spreads the mirrors of a host over the other hosts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		coordinatorDir := t.TempDir()
+		err := os.Mkdir(filepath.Join(coordinatorDir, "gpseg-1"), 0700)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		in := initClusterRequest(coordinatorDir)
+		in.Segments.Hosts = []string{"sdw1", "sdw2", "sdw3"}
+		in.Segments.PrimaryDirectories = []string{"/data/primary", "/data/primary"}
+		in.Segments.MirrorDirectories = []string{"/data/mirror", "/data/mirror"}
+		in.Segments.Mirroring = idl.MirroringStrategy_SPREAD
+
+		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
+		defer hub.ResetExecCommand()
+
+		// The mirrors of the 2 primaries of a host go to the 2 hosts that
+		// follow it, registered after the coordinator and the 6 primaries
+		mirrors := []string{
+			"SELECT pg_catalog.gp_add_segment(8::int2, 0::int2, 'm', 'm', 'n', 'u', 7000, 'sdw2', 'sdw2', '/data/mirror/gpseg0')",
+			"SELECT pg_catalog.gp_add_segment(9::int2, 1::int2, 'm', 'm', 'n', 'u', 7001, 'sdw3', 'sdw3', '/data/mirror/gpseg1')",
+			"SELECT pg_catalog.gp_add_segment(10::int2, 2::int2, 'm', 'm', 'n', 'u', 7000, 'sdw3', 'sdw3', '/data/mirror/gpseg2')",
+			"SELECT pg_catalog.gp_add_segment(11::int2, 3::int2, 'm', 'm', 'n', 'u', 7001, 'sdw1', 'sdw1', '/data/mirror/gpseg3')",
+			"SELECT pg_catalog.gp_add_segment(12::int2, 4::int2, 'm', 'm', 'n', 'u', 7000, 'sdw1', 'sdw1', '/data/mirror/gpseg4')",
+			"SELECT pg_catalog.gp_add_segment(13::int2, 5::int2, 'm', 'm', 'n', 'u', 7001, 'sdw2', 'sdw2', '/data/mirror/gpseg5')",
+		}
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			for i := 0; i < 7; i++ {
+				mock.ExpectExec("SELECT pg_catalog.gp_add_segment").WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			for _, query := range mirrors {
+				mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		// Stop once the segments are registered
+		conns := make([]*hub.Connection, 0)
+		for _, host := range in.Segments.Hosts {
+			client := mock_idl.NewMockAgentClient(ctrl)
+			client.EXPECT().InitSegment(gomock.Any(), gomock.Any()).Return(&idl.InitSegmentReply{}, nil).Times(2)
+			client.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(nil, errors.New("error")).Times(2)
+			client.EXPECT().RemoveSegment(gomock.Any(), gomock.Any()).Return(&idl.RemoveSegmentReply{}, nil).Times(2)
+			conns = append(conns, &hub.Connection{AgentClient: client, Hostname: host})
+		}
+		hubServer.Conns = conns
+
+		err = hubServer.InitCluster(in, &testutils.MockHubStream{})
+
+		expected := "failed to start segment"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
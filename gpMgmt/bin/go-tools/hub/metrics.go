@@ -0,0 +1,84 @@
+package hub
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/greenplum-db/gpdb/gp/metrics"
+)
+
+var (
+	agentUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "agent", "up"),
+		"Whether the connection of the hub to the agent of the host is ready.",
+		[]string{"host"}, nil,
+	)
+	agentStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "agent", "connection_state"),
+		"State of the connection of the hub to the agent of the host, NONE when the hub is not connected.",
+		[]string{"host", "state"}, nil,
+	)
+	segmentUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "segment", "up"),
+		"Whether the segment is up in gp_segment_configuration, as of the last refresh of the topology.",
+		[]string{"content", "dbid", "role", "preferred_role", "host"}, nil,
+	)
+	topologyRefreshDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "topology", "refresh_timestamp_seconds"),
+		"Time the hub last loaded gp_segment_configuration.",
+		nil, nil,
+	)
+
+	// connectionStates are reported for every host, so that a state can be
+	// alerted on even before the hub first sees it
+	connectionStates = []string{
+		connectivity.Idle.String(),
+		connectivity.Connecting.String(),
+		connectivity.Ready.String(),
+		connectivity.TransientFailure.String(),
+		connectivity.Shutdown.String(),
+		"NONE",
+	}
+)
+
+// clusterCollector reports the agent connections and the segments known to
+// the hub when scraped
+type clusterCollector struct {
+	server *Server
+}
+
+func (c clusterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- agentUpDesc
+	ch <- agentStateDesc
+	ch <- segmentUpDesc
+	ch <- topologyRefreshDesc
+}
+
+func (c clusterCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, agent := range c.server.AgentStates() {
+		ch <- prometheus.MustNewConstMetric(agentUpDesc, prometheus.GaugeValue, gaugeValue(agent.State == connectivity.Ready.String()), agent.Hostname)
+		for _, state := range connectionStates {
+			ch <- prometheus.MustNewConstMetric(agentStateDesc, prometheus.GaugeValue, gaugeValue(agent.State == state), agent.Hostname, state)
+		}
+	}
+
+	if !c.server.Topology.IsLoaded() {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(topologyRefreshDesc, prometheus.GaugeValue, float64(c.server.Topology.RefreshTime().Unix()))
+	for _, seg := range c.server.Topology.Segments() {
+		ch <- prometheus.MustNewConstMetric(segmentUpDesc, prometheus.GaugeValue, gaugeValue(seg.IsUp()),
+			strconv.Itoa(seg.ContentID), strconv.Itoa(seg.DbID), seg.Role, seg.PreferredRole, seg.Hostname)
+	}
+}
+
+func gaugeValue(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,128 @@
+package hub_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// freePort returns a port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// scrapeMetrics returns the metrics served on the port
+func scrapeMetrics(t *testing.T, port int) string {
+	t.Helper()
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", port))
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return string(body)
+}
+
+func TestHubMetrics(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// a connection to a running agent, for its state to be ready
+	listener := bufconn.Listen(1024 * 1024)
+	agentServer := grpc.NewServer()
+	defer agentServer.Stop()
+	go func() {
+		_ = agentServer.Serve(listener)
+	}()
+	dialer := func(ctx context.Context, address string) (net.Conn, error) {
+		return listener.Dial()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer conn.Close()
+
+	sdw1 := mock_idl.NewMockAgentClient(ctrl)
+
+	metricsPort := freePort(t)
+	hubConfig := &hub.Config{
+		Hostnames:   []string{"sdw1", "sdw2"},
+		Credentials: &testutils.MockCredentials{TlsConnection: insecure.NewCredentials()},
+		Metrics:     &hub.MetricsConfig{HubPort: metricsPort},
+	}
+	hubServer := startHub(t, hubConfig)
+	hubServer.AddConnections(&hub.Connection{Conn: conn, AgentClient: sdw1, Hostname: "sdw1"})
+	hubServer.Topology.Set(hub.Segments{
+		{DbID: 1, ContentID: -1, Role: hub.RolePrimary, PreferredRole: hub.RolePrimary, Status: hub.StatusUp, Hostname: "cdw"},
+		{DbID: 2, ContentID: 0, Role: hub.RolePrimary, PreferredRole: hub.RolePrimary, Status: hub.StatusDown, Hostname: "sdw1"},
+	})
+
+	client, err := hubServer.Credentials.LoadClientCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	hubConn, err := grpc.Dial(fmt.Sprintf("localhost:%d", hubConfig.Port), grpc.WithTransportCredentials(client))
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer hubConn.Close()
+
+	_, err = healthpb.NewHealthClient(hubConn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	metrics := scrapeMetrics(t, metricsPort)
+	for _, expected := range []string{
+		`gp_grpc_requests_total{code="OK",method="/grpc.health.v1.Health/Check",service="hub"} 1`,
+		`gp_grpc_request_duration_seconds_count{method="/grpc.health.v1.Health/Check",service="hub"} 1`,
+		`gp_agent_up{host="sdw1"} 1`,
+		`gp_agent_up{host="sdw2"} 0`,
+		`gp_agent_connection_state{host="sdw1",state="READY"} 1`,
+		`gp_agent_connection_state{host="sdw2",state="NONE"} 1`,
+		`gp_agent_connection_state{host="sdw2",state="READY"} 0`,
+		`gp_segment_up{content="-1",dbid="1",host="cdw",preferred_role="p",role="p"} 1`,
+		`gp_segment_up{content="0",dbid="2",host="sdw1",preferred_role="p",role="p"} 0`,
+		`gp_topology_refresh_timestamp_seconds `,
+		`process_resident_memory_bytes `,
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Fatalf("expected %q in the metrics:\n%s", expected, metrics)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package hub
+
+import (
+	"fmt"
+
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+// rebalanceStep swaps the roles of the segments of a content. The segment
+// acting as primary outside of its preferred role is stopped so that FTS
+// promotes its mirror, then it is rewound from it and restarted as mirror.
+type rebalanceStep struct {
+	demoted  Segment // acting as primary, preferred mirror
+	promoted Segment // acting as mirror, preferred primary
+}
+
+// RebalanceCluster has the segments that are not in their preferred role swap
+// roles with the other segment of their content, working on at most
+// in.BatchSize segments at once on every host. Only the plan is reported when
+// in.DryRun is set.
+func (s *Server) RebalanceCluster(in *idl.RebalanceClusterRequest, stream idl.Hub_RebalanceClusterServer) error {
+	progress := newProgressReporter(stream)
+	port := int(in.CoordinatorPort)
+	batchSize := int(in.BatchSize)
+
+	topology, err := s.RefreshTopology(port, false)
+	if err != nil {
+		return err
+	}
+
+	steps := rebalancePlan(topology, progress)
+	if len(steps) == 0 {
+		progress.Summary("No segments to rebalance")
+		return nil
+	}
+
+	for _, step := range steps {
+		progress.Info("Content %d: stop segment %d on %s:%d, promote segment %d on %s:%d, then rewind segment %d as mirror",
+			step.demoted.ContentID,
+			step.demoted.DbID, step.demoted.Hostname, step.demoted.Port,
+			step.promoted.DbID, step.promoted.Hostname, step.promoted.Port,
+			step.demoted.DbID)
+	}
+	if in.DryRun {
+		progress.Summary("Would rebalance %d contents", len(steps))
+		return nil
+	}
+
+	_, err = s.connectAgents(nil, false)
+	if err != nil {
+		return err
+	}
+
+	demoted := make(Segments, 0, len(steps))
+	promoted := make(map[int]Segment, len(steps))
+	for _, step := range steps {
+		demoted = append(demoted, step.demoted)
+		promoted[step.demoted.DbID] = step.promoted
+	}
+
+	progress.Info("Stopping the segments acting as primary outside of their preferred role")
+	err = s.executeOnSegmentsInBatches(demoted, batchSize, func(conn *Connection, seg Segment) error {
+		return stopSegment(conn, seg, idl.StopMode_FAST, progress)
+	})
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Requesting an FTS probe to promote their mirrors")
+	segments, err := s.probeSegments(port)
+	if err != nil {
+		return err
+	}
+	err = checkPromoted(segments, steps)
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Rewinding the stopped segments as mirrors")
+	err = s.executeOnSegmentsInBatches(demoted, batchSize, func(conn *Connection, seg Segment) error {
+		return recoverSegment(conn, seg, promoted[seg.DbID], false, progress)
+	})
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Requesting an FTS probe to mark the rewound segments up")
+	segments, err = s.probeSegments(port)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if _, rewound := promoted[seg.DbID]; rewound && !seg.IsUp() {
+			progress.Warn("Segment %d with data directory %s on host %s is still marked down, FTS may mark it up later", seg.ContentID, seg.DataDir, seg.Hostname)
+		}
+	}
+	progress.Summary("Rebalanced %d contents", len(steps))
+
+	return nil
+}
+
+// rebalancePlan returns a step for every content whose primary is not in its
+// preferred role, warning about the contents whose mirror can not take over
+func rebalancePlan(segments Segments, progress *progressReporter) []rebalanceStep {
+	steps := make([]rebalanceStep, 0)
+	for _, primary := range segments.Primaries() {
+		if primary.InPreferredRole() {
+			continue
+		}
+
+		mirrors := segments.ByContent(primary.ContentID).Mirrors()
+		if len(mirrors) == 0 || !mirrors[0].IsUp() || primary.Mode != ModeSynchronized {
+			progress.Warn("Content %d can not be rebalanced: its mirror is not up and synchronized, run gp recover first", primary.ContentID)
+			continue
+		}
+
+		steps = append(steps, rebalanceStep{demoted: primary, promoted: mirrors[0]})
+	}
+
+	return steps
+}
+
+// checkPromoted checks that FTS promoted the mirror of every step
+func checkPromoted(segments Segments, steps []rebalanceStep) error {
+	for _, step := range steps {
+		acting := segments.ByContent(step.promoted.ContentID).Primaries()
+		if len(acting) == 0 || acting[0].DbID != step.promoted.DbID || !acting[0].IsUp() {
+			return fmt.Errorf("segment %d with data directory %s on host %s was not promoted, run gp recover once it acts as primary",
+				step.promoted.ContentID, step.promoted.DataDir, step.promoted.Hostname)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package hub_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// unbalancedSegmentRows has the contents failed over to the mirrors on sdw2.
+// Once promoted, the preferred primaries on sdw1 act as primaries again with
+// their former primaries down, and once rebalanced every segment is up.
+func unbalancedSegmentRows(promoted bool, rebalanced bool) *sqlmock.Rows {
+	segments := hub.Segments{segment(1, -1, "p", "p", "n", "u", 5432, "cdw", "/data/qddir/gpseg-1")}
+
+	for content := 0; content < 2; content++ {
+		port := 6000 + content
+		mirrorPort := 7000 + content
+		primaryDir := fmt.Sprintf("/data/primary/gpseg%d", content)
+		mirrorDir := fmt.Sprintf("/data/mirror/gpseg%d", content)
+
+		switch {
+		case !promoted:
+			segments = append(segments,
+				segment(4+content, content, "p", "m", "s", "u", mirrorPort, "sdw2", mirrorDir),
+				segment(2+content, content, "m", "p", "s", "u", port, "sdw1", primaryDir))
+		case !rebalanced:
+			segments = append(segments,
+				segment(2+content, content, "p", "p", "n", "u", port, "sdw1", primaryDir),
+				segment(4+content, content, "m", "m", "n", "d", mirrorPort, "sdw2", mirrorDir))
+		default:
+			segments = append(segments,
+				segment(2+content, content, "p", "p", "s", "u", port, "sdw1", primaryDir),
+				segment(4+content, content, "m", "m", "s", "u", mirrorPort, "sdw2", mirrorDir))
+		}
+	}
+
+	return segmentRows(segments...)
+}
+
+// setMockUnbalancedSegmentConfiguration returns the unbalanced configuration,
+// then the one after every FTS probe, where FTS promotes the mirrors only
+// when failover is set
+func setMockUnbalancedSegmentConfiguration(t *testing.T, failover bool) {
+	t.Helper()
+
+	probes := 0
+	hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+		conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+
+		if probes > 0 {
+			mock.ExpectExec("SELECT gp_request_fts_probe_scan").WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectQuery("SELECT").WillReturnRows(unbalancedSegmentRows(failover && probes > 0, failover && probes > 1))
+		probes++
+
+		return conn, nil
+	})
+}
+
+func TestRebalanceCluster(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hubConfig := testutils.InitializeTestEnv()
+	hubConfig.Hostnames = []string{"sdw1", "sdw2"}
+	hubServer := hub.New(hubConfig, nil)
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+
+	t.Run("only reports the plan on a dry run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockUnbalancedSegmentConfiguration(t, true)
+		defer hub.ResetConnectToCoordinator()
+
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw2"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RebalanceCluster(&idl.RebalanceClusterRequest{CoordinatorPort: 5432, DryRun: true}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var plan []string
+		for _, reply := range stream.Replies {
+			if log := reply.GetLog(); log != nil {
+				plan = append(plan, log.Message)
+			}
+		}
+		expected := []string{
+			"Content 0: stop segment 4 on sdw2:7000, promote segment 2 on sdw1:6000, then rewind segment 4 as mirror",
+			"Content 1: stop segment 5 on sdw2:7001, promote segment 3 on sdw1:6001, then rewind segment 5 as mirror",
+		}
+		if strings.Join(plan, "\n") != strings.Join(expected, "\n") {
+			t.Fatalf("got %q, want %q", plan, expected)
+		}
+
+		summary := stream.Summary()
+		if summary.Message != "Would rebalance 2 contents" {
+			t.Fatalf("got %+v, want the plan to be summarized", summary)
+		}
+	})
+
+	t.Run("swaps the roles one segment at a time on every host with a batch size of 1", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockUnbalancedSegmentConfiguration(t, true)
+		defer hub.ResetConnectToCoordinator()
+
+		var mutex sync.Mutex
+		running, maxRunning := 0, 0
+		// track counts the segments worked on, holding them long enough for
+		// the requests running at once to overlap
+		track := func() func() {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+
+			return func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				running--
+			}
+		}
+
+		var stopped, recovered []string
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, in *idl.StopSegmentRequest, opts ...grpc.CallOption) (*idl.StopSegmentReply, error) {
+			defer track()()
+			mutex.Lock()
+			defer mutex.Unlock()
+			stopped = append(stopped, fmt.Sprintf("%s %s", in.DataDir, in.Mode))
+			if in.Mode == idl.StopMode_IMMEDIATE { // before the recovery, once stopped
+				return nil, status.Errorf(codes.FailedPrecondition, "segment is not running")
+			}
+			return &idl.StopSegmentReply{}, nil
+		}).Times(4)
+		sdw2.EXPECT().RecoverSegment(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, in *idl.RecoverSegmentRequest, opts ...grpc.CallOption) (idl.Agent_RecoverSegmentClient, error) {
+			defer track()()
+			mutex.Lock()
+			defer mutex.Unlock()
+			recovered = append(recovered, fmt.Sprintf("%s dbid %d from %s:%d full %t", in.DataDir, in.Dbid, in.SourceHost, in.SourcePort, in.Full))
+			return &mockRecoverSegmentReplies{}, nil
+		}).Times(2)
+		sdw2.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil).Times(2)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RebalanceCluster(&idl.RebalanceClusterRequest{CoordinatorPort: 5432, BatchSize: 1}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if maxRunning != 1 {
+			t.Fatalf("got %d segments worked on at once, want 1", maxRunning)
+		}
+
+		sort.Strings(stopped)
+		expectedStopped := []string{
+			"/data/mirror/gpseg0 FAST",
+			"/data/mirror/gpseg0 IMMEDIATE",
+			"/data/mirror/gpseg1 FAST",
+			"/data/mirror/gpseg1 IMMEDIATE",
+		}
+		if !reflect.DeepEqual(stopped, expectedStopped) {
+			t.Fatalf("got %q, want %q", stopped, expectedStopped)
+		}
+
+		sort.Strings(recovered)
+		expectedRecovered := []string{
+			"/data/mirror/gpseg0 dbid 4 from sdw1:6000 full false",
+			"/data/mirror/gpseg1 dbid 5 from sdw1:6001 full false",
+		}
+		if !reflect.DeepEqual(recovered, expectedRecovered) {
+			t.Fatalf("got %q, want %q", recovered, expectedRecovered)
+		}
+
+		summary := stream.Summary()
+		if summary.Message != "Rebalanced 2 contents" {
+			t.Fatalf("got %+v, want the contents to be rebalanced", summary)
+		}
+	})
+
+	t.Run("has nothing to do when every segment is in its preferred role", func(t *testing.T) {
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			mock.ExpectQuery("SELECT").WillReturnRows(unbalancedSegmentRows(true, true))
+
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RebalanceCluster(&idl.RebalanceClusterRequest{CoordinatorPort: 5432}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		summary := stream.Summary()
+		if summary.Message != "No segments to rebalance" {
+			t.Fatalf("got %+v, want nothing to be rebalanced", summary)
+		}
+	})
+
+	t.Run("skips the contents whose mirror is not synchronized", func(t *testing.T) {
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			rows := segmentRows(
+				segment(1, -1, "p", "p", "n", "u", 5432, "cdw", "/data/qddir/gpseg-1"),
+				segment(4, 0, "p", "m", "n", "u", 7000, "sdw2", "/data/mirror/gpseg0"),
+				segment(2, 0, "m", "p", "n", "d", 6000, "sdw1", "/data/primary/gpseg0"),
+			)
+			mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RebalanceCluster(&idl.RebalanceClusterRequest{CoordinatorPort: 5432}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := "Content 0 can not be rebalanced: its mirror is not up and synchronized, run gp recover first"
+		log := stream.Replies[0].GetLog()
+		if log == nil || log.Level != idl.LogMessage_WARNING || log.Message != expected {
+			t.Fatalf("got %+v, want the warning %q", stream.Replies[0], expected)
+		}
+	})
+
+	t.Run("errors out when FTS does not promote the mirrors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockUnbalancedSegmentConfiguration(t, false)
+		defer hub.ResetConnectToCoordinator()
+
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).Return(&idl.StopSegmentReply{}, nil).Times(2)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.RebalanceCluster(&idl.RebalanceClusterRequest{CoordinatorPort: 5432}, &testutils.MockHubStream{})
+
+		expected := "segment 0 with data directory /data/primary/gpseg0 on host sdw1 was not promoted, run gp recover once it acts as primary"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+// ftsProbeQuery has FTS probe the segments right away and waits for it, so
+// that the recovered segments are marked up in gp_segment_configuration
+const ftsProbeQuery = "SELECT gp_request_fts_probe_scan()"
+
+// RecoverCluster recovers the segments marked down from the segments of the
+// same content acting as primary, then starts them. The catalog is only
+// updated by FTS, which is asked to probe the segments once they run.
+func (s *Server) RecoverCluster(in *idl.RecoverClusterRequest, stream idl.Hub_RecoverClusterServer) error {
+	progress := newProgressReporter(stream)
+	port := int(in.CoordinatorPort)
+
+	topology, err := s.RefreshTopology(port, false)
+	if err != nil {
+		return err
+	}
+
+	targets, sources, err := segmentsToRecover(topology, in.ContentIds, progress)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		progress.Summary("No segments to recover")
+		return nil
+	}
+
+	_, err = s.connectAgents(nil, false)
+	if err != nil {
+		return err
+	}
+
+	recovery := "incremental"
+	if in.Full {
+		recovery = "full"
+	}
+	progress.Info("Recovering %d segments on %d hosts using %s recovery", len(targets), len(targets.ByHost()), recovery)
+	err = s.executeOnSegments(targets, func(conn *Connection, seg Segment) error {
+		return recoverSegment(conn, seg, sources[seg.DbID], in.Full, progress)
+	})
+	if err != nil {
+		return err
+	}
+
+	progress.Info("Requesting an FTS probe to mark the recovered segments up")
+	segments, err := s.probeSegments(port)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if _, recovered := sources[seg.DbID]; recovered && !seg.IsUp() {
+			progress.Warn("Segment %d with data directory %s on host %s is still marked down, FTS may mark it up later", seg.ContentID, seg.DataDir, seg.Hostname)
+		}
+	}
+	progress.Summary("Recovered %d segments", len(targets))
+
+	return nil
+}
+
+// segmentsToRecover returns the segments marked down, among the given contents
+// if any, along with the segment to recover each of them from by dbid
+func segmentsToRecover(segments Segments, contentIDs []int32, progress *progressReporter) (Segments, map[int]Segment, error) {
+	wanted := make(map[int]bool, len(contentIDs))
+	for _, contentID := range contentIDs {
+		wanted[int(contentID)] = true
+	}
+
+	targets := segments.Filter(func(seg Segment) bool {
+		return seg.ContentID != CoordinatorContentID && !seg.IsUp() && (len(wanted) == 0 || wanted[seg.ContentID])
+	})
+
+	sources := make(map[int]Segment, len(targets))
+	for _, target := range targets {
+		source := segments.ByContent(target.ContentID).Filter(func(seg Segment) bool {
+			return seg.Role == RolePrimary && seg.IsUp()
+		})
+		if len(source) == 0 {
+			return nil, nil, fmt.Errorf("segment %d with data directory %s on host %s has no running primary to recover from", target.ContentID, target.DataDir, target.Hostname)
+		}
+
+		sources[target.DbID] = source[0]
+		delete(wanted, target.ContentID)
+	}
+
+	for _, contentID := range contentIDs {
+		if wanted[int(contentID)] {
+			progress.Warn("Content %d has no segment marked down, skipping it", contentID)
+			delete(wanted, int(contentID))
+		}
+	}
+
+	return targets, sources, nil
+}
+
+// recoverSegment has the agent rebuild the data directory of the segment from
+// the source, forwarding its output, and starts the segment. The segment is
+// stopped first, as a segment marked down may still run.
+func recoverSegment(conn *Connection, target Segment, source Segment, full bool, progress *progressReporter) error {
+	_, err := conn.AgentClient.StopSegment(context.Background(), &idl.StopSegmentRequest{
+		DataDir:   target.DataDir,
+		ContentId: int32(target.ContentID),
+		Mode:      idl.StopMode_IMMEDIATE,
+		Timeout:   constants.DefaultStopTimeout,
+	})
+	if grpcStatus.Code(err) == codes.FailedPrecondition { // not running
+		err = nil
+	}
+
+	if err == nil {
+		progress.Running(conn.Hostname, fmt.Sprintf("recovering segment %d from %s:%d", target.ContentID, source.Address, source.Port))
+		err = streamRecovery(conn, target, &idl.RecoverSegmentRequest{
+			DataDir:    target.DataDir,
+			ContentId:  int32(target.ContentID),
+			Dbid:       int32(target.DbID),
+			SourceHost: source.Address,
+			SourcePort: int32(source.Port),
+			Full:       full,
+		}, progress)
+	}
+
+	if err == nil {
+		progress.Running(conn.Hostname, fmt.Sprintf("starting segment %d", target.ContentID))
+		_, err = conn.AgentClient.StartSegment(context.Background(), &idl.StartSegmentRequest{
+			DataDir:   target.DataDir,
+			Port:      int32(target.Port),
+			ContentId: int32(target.ContentID),
+			Timeout:   constants.DefaultStartTimeout,
+		})
+	}
+
+	progress.Host(conn.Hostname, fmt.Sprintf("recover segment %d with data directory %s", target.ContentID, target.DataDir), err)
+	if err != nil {
+		return fmt.Errorf("failed to recover segment %d on host %s: %w", target.ContentID, conn.Hostname, err)
+	}
+
+	return nil
+}
+
+// streamRecovery has the agent rebuild the data directory of the segment,
+// forwarding its output until it is done
+func streamRecovery(conn *Connection, seg Segment, request *idl.RecoverSegmentRequest, progress *progressReporter) error {
+	stream, err := conn.AgentClient.RecoverSegment(context.Background(), request)
+	for err == nil {
+		var reply *idl.RecoverSegmentReply
+		reply, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err == nil {
+			progress.Running(conn.Hostname, fmt.Sprintf("segment %d: %s", seg.ContentID, reply.Output))
+		}
+	}
+
+	return err
+}
+
+// probeSegments asks FTS to probe the segments and reloads the topology once
+// it is done
+func (s *Server) probeSegments(port int) (Segments, error) {
+	conn, err := connectToCoordinatorFunc(port, false)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec(ftsProbeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("could not request an FTS probe: %w", err)
+	}
+
+	err = s.Topology.Refresh(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Topology.Segments(), nil
+}
@@ -0,0 +1,285 @@
+package hub_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/idl/mock_idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+)
+
+// mockRecoverSegmentReplies replays the output of the recovery of a segment,
+// followed by Err, or io.EOF when Err is nil
+type mockRecoverSegmentReplies struct {
+	grpc.ClientStream
+	Output []string
+	Err    error
+}
+
+func (s *mockRecoverSegmentReplies) Recv() (*idl.RecoverSegmentReply, error) {
+	if len(s.Output) == 0 {
+		if s.Err != nil {
+			return nil, s.Err
+		}
+
+		return nil, io.EOF
+	}
+
+	output := s.Output[0]
+	s.Output = s.Output[1:]
+
+	return &idl.RecoverSegmentReply{Output: output}, nil
+}
+
+// setMockDownSegmentConfiguration has the mirror of content 0 marked down
+// until FTS is asked to probe the segments, when recovered is set
+func setMockDownSegmentConfiguration(t *testing.T, recovered bool) {
+	t.Helper()
+
+	downSegmentRows := func(mirrorStatus string) *sqlmock.Rows {
+		segments := mirroredSegments()
+		segments[1].Mode = hub.ModeNotSynchronized
+		segments[2].Mode = hub.ModeNotSynchronized
+		segments[2].Status = mirrorStatus
+
+		return segmentRows(segments...)
+	}
+
+	probed := false
+	hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+		conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+
+		if !probed {
+			mock.ExpectQuery("SELECT").WillReturnRows(downSegmentRows("d"))
+			probed = true
+			return conn, nil
+		}
+
+		mock.ExpectExec("SELECT gp_request_fts_probe_scan").WillReturnResult(sqlmock.NewResult(0, 1))
+		if recovered {
+			mock.ExpectQuery("SELECT").WillReturnRows(downSegmentRows("u"))
+		} else {
+			mock.ExpectQuery("SELECT").WillReturnRows(downSegmentRows("d"))
+		}
+
+		return conn, nil
+	})
+}
+
+func TestRecoverCluster(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	hubConfig := testutils.InitializeTestEnv()
+	hubConfig.Hostnames = []string{"sdw1", "sdw2"}
+	hubServer := hub.New(hubConfig, nil)
+
+	hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+		return nil
+	})
+	defer hub.ResetEnsureConnectionsAreReady()
+
+	t.Run("recovers the down segments from their primary and starts them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockDownSegmentConfiguration(t, true)
+		defer hub.ResetConnectToCoordinator()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		stop := sdw2.EXPECT().StopSegment(gomock.Any(), &idl.StopSegmentRequest{
+			DataDir:   "/data/mirror/gpseg0",
+			ContentId: 0,
+			Mode:      idl.StopMode_IMMEDIATE,
+			Timeout:   120,
+		}).Return(nil, status.Errorf(codes.FailedPrecondition, "segment 0 with data directory /data/mirror/gpseg0 is not running"))
+		sdw2.EXPECT().RecoverSegment(gomock.Any(), &idl.RecoverSegmentRequest{
+			DataDir:    "/data/mirror/gpseg0",
+			ContentId:  0,
+			Dbid:       4,
+			SourceHost: "sdw1",
+			SourcePort: 6000,
+			Full:       true,
+		}).Return(&mockRecoverSegmentReplies{Output: []string{"20/20 kB (100%), 1/1 tablespace"}}, nil).After(stop)
+		sdw2.EXPECT().StartSegment(gomock.Any(), &idl.StartSegmentRequest{
+			DataDir:   "/data/mirror/gpseg0",
+			Port:      7000,
+			ContentId: 0,
+			Timeout:   600,
+		}).Return(&idl.StartSegmentReply{}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RecoverCluster(&idl.RecoverClusterRequest{CoordinatorPort: 5432, Full: true}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var output []string
+		for _, reply := range stream.Replies {
+			if progress := reply.GetProgress(); progress != nil {
+				output = append(output, progress.Detail)
+			}
+		}
+		expectedOutput := []string{
+			"recovering segment 0 from sdw1:6000",
+			"segment 0: 20/20 kB (100%), 1/1 tablespace",
+			"starting segment 0",
+			"recover segment 0 with data directory /data/mirror/gpseg0",
+		}
+		if !reflect.DeepEqual(output, expectedOutput) {
+			t.Fatalf("got %q, want %q", output, expectedOutput)
+		}
+
+		summary := stream.Summary()
+		expected := &idl.Summary{Succeeded: 1, Message: "Recovered 1 segments"}
+		if summary.Succeeded != expected.Succeeded || summary.Failed != expected.Failed || summary.Message != expected.Message {
+			t.Fatalf("got %+v, want %+v", summary, expected)
+		}
+
+		mirror := hubServer.Topology.Segments().ByContent(0).Mirrors()[0]
+		if !mirror.IsUp() {
+			t.Fatalf("got %+v, want the topology to have the mirror up", mirror)
+		}
+	})
+
+	t.Run("warns about the segments FTS did not mark up yet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockDownSegmentConfiguration(t, false)
+		defer hub.ResetConnectToCoordinator()
+
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).Return(&idl.StopSegmentReply{}, nil)
+		sdw2.EXPECT().RecoverSegment(gomock.Any(), gomock.Any()).Return(&mockRecoverSegmentReplies{}, nil)
+		sdw2.EXPECT().StartSegment(gomock.Any(), gomock.Any()).Return(&idl.StartSegmentReply{}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RecoverCluster(&idl.RecoverClusterRequest{CoordinatorPort: 5432}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := "Segment 0 with data directory /data/mirror/gpseg0 on host sdw2 is still marked down, FTS may mark it up later"
+		found := false
+		for _, reply := range stream.Replies {
+			if log := reply.GetLog(); log != nil && log.Level == idl.LogMessage_WARNING && log.Message == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("got %+v, want the warning %q", stream.Replies, expected)
+		}
+	})
+
+	t.Run("has nothing to do when no segment of the requested contents is down", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockDownSegmentConfiguration(t, true)
+		defer hub.ResetConnectToCoordinator()
+
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw2"},
+		}
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.RecoverCluster(&idl.RecoverClusterRequest{CoordinatorPort: 5432, ContentIds: []int32{1}}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		summary := stream.Summary()
+		if summary.Message != "No segments to recover" {
+			t.Fatalf("got %+v, want nothing to be recovered", summary)
+		}
+	})
+
+	t.Run("errors out when a down segment has no running primary", func(t *testing.T) {
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			rows := segmentRows(
+				segment(1, -1, "p", "p", "n", "u", 5432, "cdw", "/data/qddir/gpseg-1"),
+				segment(2, 0, "p", "p", "n", "d", 6000, "sdw1", "/data/primary/gpseg0"),
+			)
+			mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		err := hubServer.RecoverCluster(&idl.RecoverClusterRequest{CoordinatorPort: 5432}, &testutils.MockHubStream{})
+
+		expected := "segment 0 with data directory /data/primary/gpseg0 on host sdw1 has no running primary to recover from"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("errors out when the recovery of a segment fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockDownSegmentConfiguration(t, true)
+		defer hub.ResetConnectToCoordinator()
+
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).Return(&idl.StopSegmentReply{}, nil)
+		sdw2.EXPECT().RecoverSegment(gomock.Any(), gomock.Any()).Return(&mockRecoverSegmentReplies{Err: errors.New("error")}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.RecoverCluster(&idl.RecoverClusterRequest{CoordinatorPort: 5432}, &testutils.MockHubStream{})
+
+		expected := "failed to recover segment 0 on host sdw2: error"
+		if err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("does not recover a segment it could not stop", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		setMockDownSegmentConfiguration(t, true)
+		defer hub.ResetConnectToCoordinator()
+
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().StopSegment(gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: mock_idl.NewMockAgentClient(ctrl), Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+
+		err := hubServer.RecoverCluster(&idl.RecoverClusterRequest{CoordinatorPort: 5432}, &testutils.MockHubStream{})
+
+		expected := "failed to recover segment 0 on host sdw2: error"
+		if err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
@@ -7,20 +7,23 @@ import (
 	"net"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/greenplum-db/gpdb/gp/constants"
-
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/audit"
+	"github.com/greenplum-db/gpdb/gp/constants"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/metrics"
+	"github.com/greenplum-db/gpdb/gp/remote"
 	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
 	"github.com/greenplum-db/gpdb/gp/utils"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	grpcStatus "google.golang.org/grpc/status"
 )
@@ -30,6 +33,8 @@ var (
 	DialTimeout                   = 3 * time.Second
 	ensureConnectionsAreReadyFunc = ensureConnectionsAreReady
 	execCommand                   = exec.Command
+	newRemoteExecutor             = remote.NewExecutor
+	hostname                      = os.Hostname
 )
 
 type Dialer func(context.Context, string) (net.Conn, error)
@@ -42,30 +47,72 @@ type Config struct {
 	ServiceName string   `json:"serviceName"`
 	GpHome      string   `json:"gphome"`
 
-	Credentials utils.Credentials
+	Credentials   utils.Credentials
+	Authorization *Authorization `json:"authorization,omitempty"` // every client is allowed everything when not set
+	Metrics       *MetricsConfig `json:"metrics,omitempty"`       // the services export no metrics when not set
+	Events        *EventsConfig  `json:"events,omitempty"`        // the segments are not watched when not set
+	// the hub keeps the topology of the cluster up to date through the
+	// coordinator on this port, instead of only loading it for the cluster
+	// commands, when set
+	CoordinatorPort int `json:"coordinatorPort,omitempty"`
+}
+
+// MetricsConfig sets the ports of the Prometheus /metrics endpoints of the
+// services, which are not served when 0
+type MetricsConfig struct {
+	HubPort   int `json:"hubPort,omitempty"`
+	AgentPort int `json:"agentPort,omitempty"`
+}
+
+// HubMetricsPort returns the port of the metrics endpoint of the hub, or 0
+func (conf *Config) HubMetricsPort() int {
+	if conf.Metrics == nil {
+		return 0
+	}
+
+	return conf.Metrics.HubPort
+}
+
+// AgentMetricsPort returns the port of the metrics endpoint of the agents, or 0
+func (conf *Config) AgentMetricsPort() int {
+	if conf.Metrics == nil {
+		return 0
+	}
+
+	return conf.Metrics.AgentPort
 }
 
 type Server struct {
 	*Config
 	Conns      []*Connection
+	Topology   *Topology
+	Heartbeats *Heartbeats
+	Notifier   *Notifier
+	ConfigFile string // the configuration file the hub was started with
 	grpcDialer Dialer
 
-	mutex      sync.Mutex
-	grpcServer *grpc.Server
-	listener   net.Listener
-	finish     chan struct{}
-}
-
-type Connection struct {
-	Conn          *grpc.ClientConn
-	AgentClient   idl.AgentClient
-	Hostname      string
-	CancelContext func()
+	mutex       sync.Mutex
+	grpcServer  *grpc.Server
+	listener    net.Listener
+	finish      chan struct{}
+	certWatcher *utils.CertificateWatcher
+	health      *health.Server
+	reconnects  map[string]*reconnectState // hosts the hub failed to connect to
+	watches     map[string]*segmentWatch   // by host
+	// set while the cluster is stopped through the hub, so that its segments
+	// going down are not reported
+	clusterStopped bool
+	// set while the agents are stopped through the hub, so that they are
+	// neither reconnected to nor reported unreachable
+	agentsStopped bool
 }
 
 func New(conf *Config, grpcDialer Dialer) *Server {
 	h := &Server{
 		Config:     conf,
+		Topology:   NewTopology(),
+		Heartbeats: NewHeartbeats(),
+		Notifier:   NewNotifier(),
 		grpcDialer: grpcDialer,
 		finish:     make(chan struct{}, 1),
 	}
@@ -81,34 +128,89 @@ func (s *Server) Start() error {
 		return fmt.Errorf("could not listen on port %d: %w", s.Port, err)
 	}
 
-	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		// handle stuff here if needed
-		return handler(ctx, req)
+	credentials, certWatcher, err := utils.LoadWatchedServerCredentials(s.Credentials, utils.HubRole)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	sinks, err := NewEventSinks(s.Config.Events)
+	if err != nil {
+		listener.Close()
+		return err
 	}
+	s.Notifier = NewNotifier(sinks...)
 
-	credentials, err := s.Credentials.LoadServerCredentials()
+	serverMetrics := metrics.New(s.HubMetricsPort(), string(utils.HubRole))
+	serverMetrics.MustRegister(clusterCollector{server: s})
+	err = serverMetrics.Start()
 	if err != nil {
+		listener.Close()
 		return err
 	}
+	defer serverMetrics.Stop()
+
+	// requests are audited before being authorized, to record the denied ones
+	auditLogger := audit.NewLogger(s.LogDir, string(utils.HubRole), isAuditedHubRPC, s.auditTargets)
 	grpcServer := grpc.NewServer(
 		grpc.Creds(credentials),
-		grpc.UnaryInterceptor(interceptor),
+		grpc.ChainUnaryInterceptor(serverMetrics.UnaryInterceptor, auditLogger.UnaryInterceptor, s.authorizeUnary),
+		grpc.ChainStreamInterceptor(serverMetrics.StreamInterceptor, auditLogger.StreamInterceptor, s.authorizeStream),
 	)
 
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(HubHealthService, healthpb.HealthCheckResponse_SERVING)
+
 	s.mutex.Lock()
 	s.grpcServer = grpcServer
 	s.listener = listener
+	s.certWatcher = certWatcher
+	s.health = healthServer
 	s.mutex.Unlock()
 
 	idl.RegisterHubServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
 
+	// the agents are disconnected once the watchers stopped, so that these do
+	// not connect to them again
+	defer s.disconnectAgents()
+	var watchers sync.WaitGroup
+	defer watchers.Wait()
+	watch := func(run func()) {
+		watchers.Add(1)
+		go func() {
+			defer watchers.Done()
+			run()
+		}()
+	}
+
+	if certWatcher != nil {
+		done := make(chan struct{})
+		defer close(done)
+		watch(func() { certWatcher.Watch(constants.CertificateCheckInterval*time.Second, done) })
+	}
+
+	healthDone := make(chan struct{})
+	defer close(healthDone)
+	watch(func() { s.watchAgentsHealth(healthServer, constants.HealthCheckInterval*time.Second, healthDone) })
+	watch(func() { s.watchAgentConnections(constants.AgentReconnectInterval*time.Second, healthDone) })
+	watch(func() { s.watchHeartbeats(constants.HeartbeatInterval*time.Second, healthDone) })
+	watch(func() { s.Notifier.Run(healthDone) })
+	if s.CoordinatorPort != 0 {
+		watch(func() { s.watchTopology(s.CoordinatorPort, constants.TopologyRefreshInterval*time.Second, healthDone) })
+	}
+	if s.Config.Events != nil {
+		watch(func() { s.watchSegments(s.Config.Events, healthDone) })
+	}
+
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
 		<-s.finish
 		gplog.Info("Received stop command, attempting graceful shutdown")
-		s.grpcServer.GracefulStop()
+		healthServer.Shutdown()
+		gracefulStop(s.grpcServer, constants.GracefulStopTimeout*time.Second)
 		gplog.Info("gRPC server has shut down")
 		cancel()
 		wg.Done()
@@ -122,6 +224,39 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// gracefulStop waits for the pending requests to finish, up to the timeout as
+// streams such as health watches only end with their client
+func gracefulStop(grpcServer *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		gplog.Warn("Requests still pending after %s, stopping the gRPC server", timeout)
+		grpcServer.Stop()
+	}
+}
+
+// isAuditedHubRPC returns whether the RPC can change the state of the services
+// or the cluster, i.e. is not available to viewers
+func isAuditedHubRPC(method string) bool {
+	return !ViewerRole.Allows(RequiredRole(method))
+}
+
+// auditTargets returns the hosts an audited RPC acts on, none when it only
+// acts on the hub itself
+func (s *Server) auditTargets(method string) []string {
+	if method == "/idl.Hub/Stop" {
+		return nil
+	}
+
+	return s.Hostnames
+}
+
 func (s *Server) Stop(ctx context.Context, in *idl.StopHubRequest) (*idl.StopHubReply, error) {
 	s.Shutdown()
 	return &idl.StopHubReply{}, nil
@@ -143,87 +278,80 @@ func (s *Server) StartAgents(ctx context.Context, in *idl.StartAgentsRequest) (*
 	}
 
 	// Make sure service has started :
+	s.setAgentsStopped(false)
 	err = s.DialAllAgents()
 	if err != nil {
 		return &idl.StartAgentsReply{}, err
 	}
+	s.refreshAgentsHealth()
+
 	return &idl.StartAgentsReply{}, nil
 }
 
-func (s *Server) StartAllAgents() error {
-	remoteCmd := make([]string, 0)
-	for _, host := range s.Hostnames {
-		remoteCmd = append(remoteCmd, "-h", host)
-	}
-	remoteCmd = append(remoteCmd, platform.GetStartAgentCommandString(s.ServiceName)...)
-	greenplumPathSh := filepath.Join(s.GpHome, "greenplum_path.sh")
-	cmd := execCommand(constants.ShellPath, "-c", fmt.Sprintf("source %s && gpssh %s", greenplumPathSh, strings.Join(remoteCmd, " ")))
-	output, err := cmd.CombinedOutput()
-	strOutput := string(output)
+func (s *Server) StartAgentsStream(in *idl.StartAgentsRequest, stream idl.Hub_StartAgentsStreamServer) error {
+	progress := newProgressReporter(stream)
+
+	progress.Info("Starting agents on %d hosts", len(s.Hostnames))
+	err := s.startAgentsReporting(s.Hostnames, progress)
 	if err != nil {
-		return fmt.Errorf("could not start agents: %s", output)
+		return err
 	}
-	// As command is run through gpssh, even if actual command returns error, gpssh still returns as success.
-	// to overcome this we have added check the command output.
-	if strings.Contains(strOutput, "ERROR") || strings.Contains(strOutput, "No such file or directory") {
-		return fmt.Errorf("could not start agents: %s", strOutput)
+
+	progress.Info("Waiting for agents to accept connections")
+	s.setAgentsStopped(false)
+	_, err = s.connectAgents(progress, false)
+	if err != nil {
+		return err
 	}
+	s.refreshAgentsHealth()
+	progress.Summary("Agents started successfully")
 
 	return nil
 }
 
-func (s *Server) DialAllAgents() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+func (s *Server) StartAllAgents() error {
+	return s.startAgents(s.Hostnames)
+}
 
-	if s.Conns != nil {
-		err := ensureConnectionsAreReadyFunc(s.Conns)
-		if err != nil {
-			return err
-		}
+// startAgents starts the agent service on the given hosts
+func (s *Server) startAgents(hosts []string) error {
+	return s.startAgentsReporting(hosts, nil)
+}
 
-		return nil
+// startAgentsReporting starts the agent service on the given hosts, reporting
+// the hosts on which it failed. The others count once their agent accepts
+// connections.
+func (s *Server) startAgentsReporting(hosts []string, progress *progressReporter) error {
+	executor, err := newRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not start agents: %w", err)
 	}
 
-	for _, host := range s.Hostnames {
-		ctx, cancelFunc := context.WithTimeout(context.Background(), DialTimeout)
-
-		credentials, err := s.Credentials.LoadClientCredentials()
-		if err != nil {
-			cancelFunc()
-			return err
-		}
-
-		address := fmt.Sprintf("%s:%d", host, s.AgentPort)
-		opts := []grpc.DialOption{
-			grpc.WithBlock(),
-			grpc.WithTransportCredentials(credentials),
-			grpc.WithReturnConnectionError(),
+	command := remote.Command(platform.GetStartAgentCommandString(s.ServiceName)...)
+	results := executor.Run(hosts, command)
+	for _, result := range results {
+		if result.Err != nil {
+			progress.Host(result.Hostname, "start agent service", result.Err)
+		} else {
+			progress.Running(result.Hostname, "agent service started")
 		}
-		if s.grpcDialer != nil {
-			opts = append(opts, grpc.WithContextDialer(s.grpcDialer))
-		}
-		conn, err := grpc.DialContext(ctx, address, opts...)
-		if err != nil {
-			cancelFunc()
-			return fmt.Errorf("could not connect to agent on host %s: %w", host, err)
-		}
-		s.Conns = append(s.Conns, &Connection{
-			Conn:          conn,
-			AgentClient:   idl.NewAgentClient(conn),
-			Hostname:      host,
-			CancelContext: cancelFunc,
-		})
 	}
 
-	err := ensureConnectionsAreReadyFunc(s.Conns)
+	err = results.Err()
 	if err != nil {
-		return err
+		return fmt.Errorf("could not start agents: %w", err)
 	}
 
 	return nil
 }
 
+// DialAllAgents connects to the agent of every host, failing when any of them
+// can not be reached
+func (s *Server) DialAllAgents() error {
+	_, err := s.connectAgents(nil, false)
+	return err
+}
+
 func (s *Server) StopAgents(ctx context.Context, in *idl.StopAgentsRequest) (*idl.StopAgentsReply, error) {
 	request := func(conn *Connection) error {
 		_, err := conn.AgentClient.Stop(context.Background(), &idl.StopAgentRequest{})
@@ -239,41 +367,46 @@ func (s *Server) StopAgents(ctx context.Context, in *idl.StopAgentsRequest) (*id
 		return nil
 	}
 
-	err := s.DialAllAgents()
+	conns, err := s.connectAgents(nil, in.SkipUnreachable)
 	if err != nil {
 		return &idl.StopAgentsReply{}, err
 	}
 
-	err = ExecuteRPC(s.Conns, request)
-	s.Conns = nil
+	s.setAgentsStopped(true)
+	err = ExecuteRPC(conns, request)
+	s.disconnectAgents()
+	s.refreshAgentsHealth()
 
 	return &idl.StopAgentsReply{}, err
 }
 
+// setAgentsStopped records whether the agents are stopped through the hub
+func (s *Server) setAgentsStopped(stopped bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.agentsStopped = stopped
+}
+
 func (s *Server) StatusAgents(ctx context.Context, in *idl.StatusAgentsRequest) (*idl.StatusAgentsReply, error) {
-	statusChan := make(chan *idl.ServiceStatus, len(s.Conns))
+	statusChan := make(chan *idl.ServiceStatus, len(s.Hostnames))
 
 	request := func(conn *Connection) error {
-		status, err := conn.AgentClient.Status(context.Background(), &idl.StatusAgentRequest{})
+		status, err := agentStatus(context.Background(), conn)
+		s.recordHeartbeat(conn.Hostname, status, err)
 		if err != nil {
 			return fmt.Errorf("failed to get agent status on host %s", conn.Hostname)
 		}
-		s := idl.ServiceStatus{
-			Host:   conn.Hostname,
-			Status: status.Status,
-			Uptime: status.Uptime,
-			Pid:    status.Pid,
-		}
-		statusChan <- &s
+		statusChan <- status
 
 		return nil
 	}
 
-	err := s.DialAllAgents()
+	conns, err := s.connectAgents(nil, in.SkipUnreachable)
 	if err != nil {
 		return &idl.StatusAgentsReply{}, err
 	}
-	err = ExecuteRPC(s.Conns, request)
+	err = ExecuteRPC(conns, request)
 	if err != nil {
 		return &idl.StatusAgentsReply{}, err
 	}
@@ -283,10 +416,30 @@ func (s *Server) StatusAgents(ctx context.Context, in *idl.StatusAgentsRequest)
 	for status := range statusChan {
 		statuses = append(statuses, status)
 	}
+	for _, host := range unreachableHosts(s.Hostnames, conns) {
+		statuses = append(statuses, &idl.ServiceStatus{Host: host, Status: "unreachable"})
+	}
 
 	return &idl.StatusAgentsReply{Statuses: statuses}, err
 }
 
+// unreachableHosts returns the hosts without a connection among conns
+func unreachableHosts(hostnames []string, conns []*Connection) []string {
+	connected := make(map[string]bool, len(conns))
+	for _, conn := range conns {
+		connected[conn.Hostname] = true
+	}
+
+	hosts := make([]string, 0)
+	for _, host := range hostnames {
+		if !connected[host] {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
 func ensureConnectionsAreReady(conns []*Connection) error {
 	hostnames := []string{}
 	for _, conn := range conns {
@@ -302,30 +455,41 @@ func ensureConnectionsAreReady(conns []*Connection) error {
 	return nil
 }
 
+// ExecuteRPC runs the request against every agent connection in parallel. When
+// the request fails on any host, the returned *HostErrors holds the result of
+// every host.
 func ExecuteRPC(agentConns []*Connection, executeRequest func(conn *Connection) error) error {
 	var wg sync.WaitGroup
-	errs := make(chan error, len(agentConns))
+	results := make(chan HostResult, len(agentConns))
 
 	for _, conn := range agentConns {
 		conn := conn
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			start := time.Now()
 			err := executeRequest(conn)
-			errs <- err
+			results <- HostResult{Hostname: conn.Hostname, Err: err, Duration: time.Since(start)}
 		}()
 	}
 
 	wg.Wait()
-	close(errs)
+	close(results)
+
+	failed := false
+	hostResults := make([]HostResult, 0, len(agentConns))
+	for result := range results {
+		hostResults = append(hostResults, result)
+		if result.Err != nil {
+			failed = true
+		}
+	}
 
-	var err error
-	for e := range errs {
-		err = e
-		break
+	if failed {
+		return NewHostErrors(hostResults)
 	}
 
-	return err
+	return nil
 }
 
 func (conf *Config) Load(ConfigFilePath string) error {
@@ -345,7 +509,17 @@ func (conf *Config) Load(ConfigFilePath string) error {
 }
 
 func (conf *Config) Write(ConfigFilePath string) error {
-	// Updates config to the conf file
+	err := conf.WriteLocal(ConfigFilePath)
+	if err != nil {
+		return err
+	}
+
+	return copyConfigFileToAgents(conf, ConfigFilePath)
+}
+
+// WriteLocal updates the conf file on this host only, leaving the copies on
+// the segment hosts to be updated separately
+func (conf *Config) WriteLocal(ConfigFilePath string) error {
 	configHandle, err := os.OpenFile(ConfigFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return fmt.Errorf("could not create configuration file %s: %w\n", ConfigFilePath, err)
@@ -362,30 +536,27 @@ func (conf *Config) Write(ConfigFilePath string) error {
 	}
 	gplog.Debug("Wrote configuration file to %s", ConfigFilePath)
 
-	err = copyConfigFileToAgents(conf, ConfigFilePath)
-	if err != nil {
-		return err
-	}
-
-	return err
+	return nil
 }
 
 func copyConfigFileToAgents(conf *Config, ConfigFilePath string) error {
-	hostList := make([]string, 0)
+	if len(conf.Hostnames) < 1 {
+		return fmt.Errorf("hostlist should not be empty. No hosts to copy files.")
+	}
 
-	for _, host := range conf.Hostnames {
-		hostList = append(hostList, "-h", host)
+	contents, err := os.ReadFile(ConfigFilePath)
+	if err != nil {
+		return fmt.Errorf("could not read configuration file %s: %w", ConfigFilePath, err)
 	}
-	greenplumPathSh := filepath.Join(conf.GpHome, "greenplum_path.sh")
-	if len(hostList) < 1 {
-		return fmt.Errorf("hostlist should not be empty. No hosts to copy files.")
+
+	executor, err := newRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not copy gp.conf file to segment hosts: %w", err)
 	}
 
-	remoteCmd := append(hostList, ConfigFilePath, fmt.Sprintf("=:%s", ConfigFilePath))
-	cmd := execCommand(constants.ShellPath, "-c", fmt.Sprintf("source %s && gpsync %s", greenplumPathSh, strings.Join(remoteCmd, " ")))
-	output, err := cmd.CombinedOutput()
+	err = executor.Copy(conf.Hostnames, contents, ConfigFilePath, 0644).Err()
 	if err != nil {
-		return fmt.Errorf("could not copy gp.conf file to segment hosts: %w, Command Output: %s", err, string(output))
+		return fmt.Errorf("could not copy gp.conf file to segment hosts: %w", err)
 	}
 
 	return nil
@@ -400,6 +571,14 @@ func ResetEnsureConnectionsAreReady() {
 	ensureConnectionsAreReadyFunc = ensureConnectionsAreReady
 }
 
+func SetNewRemoteExecutor(newExecutor func() (remote.Executor, error)) {
+	newRemoteExecutor = newExecutor
+}
+
+func ResetNewRemoteExecutor() {
+	newRemoteExecutor = remote.NewExecutor
+}
+
 func SetExecCommand(command exectest.Command) {
 	execCommand = command
 }
@@ -407,3 +586,11 @@ func SetExecCommand(command exectest.Command) {
 func ResetExecCommand() {
 	execCommand = exec.Command
 }
+
+func SetHostname(hostnameFunc func() (string, error)) {
+	hostname = hostnameFunc
+}
+
+func ResetHostname() {
+	hostname = os.Hostname
+}
@@ -8,8 +8,10 @@ import (
 	"net"
 	"os"
 	"reflect"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,6 +22,8 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpdb/gp/agent"
@@ -59,6 +63,10 @@ func TestStartServer(t *testing.T) {
 			"gp",
 			gpHome,
 			credentials,
+			nil,
+			nil,
+			nil,
+			0,
 		}
 
 		hubServer := hub.New(hubConfig, nil)
@@ -68,8 +76,6 @@ func TestStartServer(t *testing.T) {
 			errChan <- hubServer.Start()
 		}()
 
-		defer hubServer.Shutdown()
-
 		select {
 		case err := <-errChan:
 			if err != nil {
@@ -77,6 +83,8 @@ func TestStartServer(t *testing.T) {
 			}
 		case <-time.After(1 * time.Second):
 			t.Log("hub server started listening")
+			hubServer.Shutdown()
+			<-errChan
 		}
 
 	})
@@ -95,6 +103,10 @@ func TestStartServer(t *testing.T) {
 			"gp",
 			gpHome,
 			credentials,
+			nil,
+			nil,
+			nil,
+			0,
 		}
 		hubServer := hub.New(hubConfig, nil)
 
@@ -112,6 +124,12 @@ func TestStartServer(t *testing.T) {
 		case <-time.After(1 * time.Second):
 			t.Fatalf("Failed to raise error if load credential fail")
 		}
+
+		listener, err := net.Listen("tcp", "0.0.0.0:1235")
+		if err != nil {
+			t.Fatalf("got %v, want the port to be released", err)
+		}
+		listener.Close()
 	})
 }
 
@@ -139,6 +157,10 @@ func TestStartAgents(t *testing.T) {
 		"gp",
 		"gphome",
 		credentials,
+		nil,
+		nil,
+		nil,
+		0,
 	}
 
 	t.Run("successfully starts the agents from hub", func(t *testing.T) {
@@ -148,13 +170,97 @@ func TestStartAgents(t *testing.T) {
 
 		hubServer := hub.New(hubConfig, dialer)
 
-		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer hub.ResetExecCommand()
+		executor := &testutils.MockExecutor{}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
 
 		_, err := hubServer.StartAgents(context.Background(), &idl.StartAgentsRequest{})
 		if err != nil {
 			t.Fatalf("%v", err)
 		}
+
+		expected := []string{"systemctl --user start gp_agent"}
+		if runtime.GOOS == constants.PlatformDarwin {
+			expected = []string{"launchctl start gp_agent"}
+		}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expected)
+		}
+	})
+
+	t.Run("streams the progress of starting the agents", func(t *testing.T) {
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.Dial()
+		}
+
+		hubServer := hub.New(hubConfig, dialer)
+
+		executor := &testutils.MockExecutor{}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.StartAgentsStream(&idl.StartAgentsRequest{}, stream)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		hosts := []string{}
+		for _, reply := range stream.Replies {
+			if progress := reply.GetProgress(); progress != nil && progress.Status != idl.HostProgress_RUNNING {
+				if progress.Status != idl.HostProgress_SUCCEEDED {
+					t.Fatalf("got status %v for host %s, want %v", progress.Status, progress.Host, idl.HostProgress_SUCCEEDED)
+				}
+				hosts = append(hosts, progress.Host)
+			}
+		}
+		sort.Strings(hosts)
+
+		expectedHosts := []string{"sdw1", "sdw2"}
+		if !reflect.DeepEqual(hosts, expectedHosts) {
+			t.Fatalf("got %+v, want %+v", hosts, expectedHosts)
+		}
+
+		summary := stream.Summary()
+		if summary == nil || summary.Succeeded != 2 || summary.Failed != 0 {
+			t.Fatalf("got summary %+v, want 2 succeeded hosts", summary)
+		}
+	})
+
+	t.Run("errors out without a summary when not able to start the agents", func(t *testing.T) {
+		hubServer := hub.New(hubConfig, nil)
+
+		executor := &testutils.MockExecutor{Err: func(host string, command string) error {
+			if host == "sdw2" {
+				return errors.New("exit status 1")
+			}
+
+			return nil
+		}}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
+
+		stream := &testutils.MockHubStream{}
+		err := hubServer.StartAgentsStream(&idl.StartAgentsRequest{}, stream)
+		expected := "could not start agents: failed on 1 of 2 hosts: host sdw2: exit status 1"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+
+		statuses := map[string]idl.HostProgress_Status{}
+		for _, reply := range stream.Replies {
+			if progress := reply.GetProgress(); progress != nil {
+				statuses[progress.Host] = progress.Status
+			}
+		}
+		expectedStatuses := map[string]idl.HostProgress_Status{"sdw1": idl.HostProgress_RUNNING, "sdw2": idl.HostProgress_FAILED}
+		if !reflect.DeepEqual(statuses, expectedStatuses) {
+			t.Fatalf("got %v, want %v", statuses, expectedStatuses)
+		}
+
+		if summary := stream.Summary(); summary != nil {
+			t.Fatalf("unexpected summary: %+v", summary)
+		}
 	})
 }
 
@@ -167,7 +273,7 @@ func TestDialAllAgents(t *testing.T) {
 	agentServer := grpc.NewServer()
 	defer agentServer.Stop()
 
-	idl.RegisterAgentServer(agentServer, &agent.Server{})
+	idl.RegisterAgentServer(agentServer, &agent.Server{Config: &agent.Config{ServiceName: "gp"}}) // answers the heartbeat of the hub
 	go func() {
 		if err := agentServer.Serve(listener); err != nil {
 			log.Fatalf("server exited with error: %v", err)
@@ -182,9 +288,13 @@ func TestDialAllAgents(t *testing.T) {
 		"gp",
 		"gphome",
 		credentials,
+		nil,
+		nil,
+		nil,
+		0,
 	}
 
-	t.Run("successfully establishes connections to agent hosts and errors out when some of the connections are not ready", func(t *testing.T) {
+	t.Run("successfully establishes connections to agent hosts and replaces the closed ones", func(t *testing.T) {
 
 		dialer := func(ctx context.Context, address string) (net.Conn, error) {
 			return listener.Dial()
@@ -215,16 +325,77 @@ func TestDialAllAgents(t *testing.T) {
 		}
 
 		// close one of the connections
+		var closed *grpc.ClientConn
 		for _, conn := range hubServer.Conns {
 			if conn.Hostname == "sdw2" {
+				closed = conn.Conn
 				conn.Conn.Close()
 			}
 		}
 
+		err = hubServer.DialAllAgents()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		for _, conn := range hubServer.Conns {
+			if conn.Conn == closed || conn.Conn.GetState() != expectedState {
+				t.Fatalf("expected a new ready connection to host %s, got %v", conn.Hostname, conn.Conn.GetState())
+			}
+		}
+		if len(hubServer.Conns) != 2 {
+			t.Fatalf("got %d connections, want 2", len(hubServer.Conns))
+		}
+	})
+
+	t.Run("keeps a single connection to every host when dialed concurrently", func(t *testing.T) {
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.Dial()
+		}
+
+		hubServer := hub.New(hubConfig, dialer)
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		for i := range errs {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = hubServer.DialAllAgents()
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+		if len(hubServer.Conns) != 2 {
+			t.Fatalf("got %d connections, want 2", len(hubServer.Conns))
+		}
+	})
+
+	t.Run("errors out when connections are not ready", func(t *testing.T) {
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			return listener.Dial()
+		}
+
+		hubServer := hub.New(hubConfig, dialer)
+		err := hubServer.DialAllAgents()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		hub.SetEnsureConnectionsAreReady(func(conns []*hub.Connection) error {
+			return errors.New("could not ensure connections were ready: unready hosts: sdw2")
+		})
+		defer hub.ResetEnsureConnectionsAreReady()
+
 		err = hubServer.DialAllAgents()
 		expectedErr := "could not ensure connections were ready: unready hosts: sdw2"
-		if err.Error() != expectedErr {
-			t.Fatalf("got %v, want %v", err.Error(), expectedErr)
+		if err == nil || err.Error() != expectedErr {
+			t.Fatalf("got %v, want %v", err, expectedErr)
 		}
 	})
 
@@ -243,6 +414,50 @@ func TestDialAllAgents(t *testing.T) {
 		if !strings.HasPrefix(err.Error(), expectedErr) {
 			t.Fatalf("got %s, want %s", err.Error(), expectedErr)
 		}
+
+		states := hubServer.AgentStates()
+		if states[0].State != connectivity.Ready.String() || states[0].Failures != 0 {
+			t.Fatalf("got %+v, want a ready connection to sdw1", states[0])
+		}
+		if states[1].State != "NONE" || states[1].Failures != 1 || states[1].NextAttempt.IsZero() || states[1].LastError == nil {
+			t.Fatalf("got %+v, want a pending reconnect to sdw2", states[1])
+		}
+	})
+
+	t.Run("reconnects to the unreachable agents in the background", func(t *testing.T) {
+		var mutex sync.Mutex
+		reachable := false
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if strings.HasPrefix(address, "sdw2") && !reachable {
+				return nil, errors.New("error")
+			}
+
+			return listener.Dial()
+		}
+
+		conf := *hubConfig
+		conf.Port = freePort(t)
+		hubServer := hub.New(&conf, dialer)
+		serveHub(t, hubServer)
+
+		err := hubServer.DialAllAgents()
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		mutex.Lock()
+		reachable = true
+		mutex.Unlock()
+
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			states := hubServer.AgentStates()
+			if states[1].State == connectivity.Ready.String() {
+				return
+			}
+		}
+		t.Fatalf("got %+v, want a ready connection to sdw2", hubServer.AgentStates())
 	})
 }
 
@@ -258,6 +473,10 @@ func TestStatusAgents(t *testing.T) {
 		"gp",
 		"gphome",
 		credentials,
+		nil,
+		nil,
+		nil,
+		0,
 	}
 	hubServer := hub.New(hubConfig, nil)
 
@@ -281,15 +500,26 @@ func TestStatusAgents(t *testing.T) {
 			Pid:    123,
 		}, nil)
 
+		startTime := timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+		cpuTime := durationpb.New(3 * time.Second)
 		sdw2 := mock_idl.NewMockAgentClient(ctrl)
 		sdw2.EXPECT().Status(
 			gomock.Any(),
 			&idl.StatusAgentRequest{},
 			gomock.Any(),
 		).Return(&idl.StatusAgentReply{
-			Status: "running",
-			Uptime: "2H",
-			Pid:    456,
+			Status:         "running",
+			Uptime:         "2H",
+			Pid:            456,
+			StartTime:      startTime,
+			MemoryRss:      1024,
+			CpuTime:        cpuTime,
+			Connections:    2,
+			Version:        "7.1.0",
+			ConfigChecksum: "44136fa3",
+			ActiveState:    "active",
+			SubState:       "running",
+			Result:         "success",
 		}, nil)
 
 		agentConns := []*hub.Connection{
@@ -305,7 +535,21 @@ func TestStatusAgents(t *testing.T) {
 
 		expected := &idl.StatusAgentsReply{
 			Statuses: []*idl.ServiceStatus{
-				{Host: "sdw2", Status: "running", Uptime: "2H", Pid: 456},
+				{
+					Host:           "sdw2",
+					Status:         "running",
+					Uptime:         "2H",
+					Pid:            456,
+					StartTime:      startTime,
+					MemoryRss:      1024,
+					CpuTime:        cpuTime,
+					Connections:    2,
+					Version:        "7.1.0",
+					ConfigChecksum: "44136fa3",
+					ActiveState:    "active",
+					SubState:       "running",
+					Result:         "success",
+				},
 				{Host: "sdw1", Status: "running", Uptime: "5H", Pid: 123},
 			},
 		}
@@ -352,6 +596,47 @@ func TestStatusAgents(t *testing.T) {
 			t.Fatalf("got %v, want %v", err, expectedErr)
 		}
 	})
+
+	t.Run("reports the unreachable agents when asked to skip them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			return nil, errors.New("error")
+		}
+		hubServer := hub.New(hubConfig, dialer)
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().Status(
+			gomock.Any(),
+			&idl.StatusAgentRequest{},
+			gomock.Any(),
+		).Return(&idl.StatusAgentReply{Status: "running"}, nil)
+		hubServer.Conns = []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+		}
+
+		_, err := hubServer.StatusAgents(context.Background(), &idl.StatusAgentsRequest{})
+		expectedErr := "could not connect to agent on host sdw2:"
+		if err == nil || !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+
+		result, err := hubServer.StatusAgents(context.Background(), &idl.StatusAgentsRequest{SkipUnreachable: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := &idl.StatusAgentsReply{
+			Statuses: []*idl.ServiceStatus{
+				{Host: "sdw1", Status: "running"},
+				{Host: "sdw2", Status: "unreachable"},
+			},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
 }
 
 func TestStopAgents(t *testing.T) {
@@ -366,6 +651,10 @@ func TestStopAgents(t *testing.T) {
 		"gp",
 		"gphome",
 		credentials,
+		nil,
+		nil,
+		nil,
+		0,
 	}
 	hubServer := hub.New(hubConfig, nil)
 
@@ -434,6 +723,87 @@ func TestStopAgents(t *testing.T) {
 			t.Fatalf("got %v, want %v", err, expectedErr)
 		}
 	})
+
+	t.Run("reports every host on which the agent could not be stopped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sdw1 := mock_idl.NewMockAgentClient(ctrl)
+		sdw1.EXPECT().Stop(
+			gomock.Any(),
+			&idl.StopAgentRequest{},
+			gomock.Any(),
+		).Return(&idl.StopAgentReply{}, errors.New("error"))
+
+		sdw2 := mock_idl.NewMockAgentClient(ctrl)
+		sdw2.EXPECT().Stop(
+			gomock.Any(),
+			&idl.StopAgentRequest{},
+			gomock.Any(),
+		).Return(&idl.StopAgentReply{}, errors.New("error"))
+
+		agentConns := []*hub.Connection{
+			{AgentClient: sdw1, Hostname: "sdw1"},
+			{AgentClient: sdw2, Hostname: "sdw2"},
+		}
+		hubServer.Conns = agentConns
+
+		_, err := hubServer.StopAgents(context.Background(), &idl.StopAgentsRequest{})
+		expectedErr := "failed on 2 of 2 hosts: failed to stop agent on host sdw1: error; failed to stop agent on host sdw2: error"
+		if err.Error() != expectedErr {
+			t.Fatalf("got %v, want %v", err, expectedErr)
+		}
+
+		var hostErrs *hub.HostErrors
+		if !errors.As(err, &hostErrs) || len(hostErrs.Failed()) != 2 {
+			t.Fatalf("got %#v, want failures for both hosts", err)
+		}
+	})
+
+	t.Run("neither reconnects to nor reports the stopped agents", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		dialed := make(chan string, 10)
+		dialer := func(ctx context.Context, address string) (net.Conn, error) {
+			dialed <- address
+			return nil, errors.New("connection refused")
+		}
+
+		conf := *hubConfig
+		conf.Port = freePort(t)
+		stoppedServer := hub.New(&conf, dialer)
+
+		conns := make([]*hub.Connection, 0)
+		for _, host := range conf.Hostnames {
+			agent := mock_idl.NewMockAgentClient(ctrl)
+			agent.EXPECT().Stop(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, status.Errorf(codes.Unavailable, ""))
+			conns = append(conns, &hub.Connection{AgentClient: agent, Hostname: host})
+			stoppedServer.Heartbeats.Record(host, &idl.ServiceStatus{Host: host, Status: "running"}, nil, time.Now())
+		}
+		stoppedServer.Conns = conns
+
+		_, err := stoppedServer.StopAgents(context.Background(), &idl.StopAgentsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		serveHub(t, stoppedServer)
+
+		// leave time for the first heartbeat and a reconnect attempt
+		time.Sleep((constants.AgentReconnectInterval + 1) * time.Second)
+
+		select {
+		case address := <-dialed:
+			t.Fatalf("unexpected dial of %s", address)
+		default:
+		}
+		for _, host := range conf.Hostnames {
+			if agent := stoppedServer.Heartbeats.Agent(host); agent.State == hub.AgentUnreachable {
+				t.Fatalf("got %+v, want the agent on %s not to be reported unreachable", agent, host)
+			}
+		}
+	})
 }
 
 func TestConfig(t *testing.T) {
@@ -446,8 +816,9 @@ func TestConfig(t *testing.T) {
 		}
 		defer os.Remove(file.Name())
 
-		hub.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer hub.ResetExecCommand()
+		executor := &testutils.MockExecutor{}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
 
 		expectedConfig := hub.Config{
 			Port:        123,
@@ -477,6 +848,10 @@ func TestConfig(t *testing.T) {
 		if !reflect.DeepEqual(resultConfig, expectedConfig) {
 			t.Fatalf("got %+v, want %+v", resultConfig, expectedConfig)
 		}
+
+		if len(executor.Copies) != 1 || executor.Copies[0].Path != file.Name() || !reflect.DeepEqual(executor.Copies[0].Hostnames, expectedConfig.Hostnames) {
+			t.Fatalf("expected the config file to be copied to the segment hosts, got %+v", executor.Copies)
+		}
 	})
 
 	t.Run("returns appropriate error when fails to write config", func(t *testing.T) {
@@ -486,14 +861,15 @@ func TestConfig(t *testing.T) {
 		}
 		defer os.Remove(file.Name())
 
-		hub.SetExecCommand(exectest.NewCommand(exectest.Failure))
-		defer hub.ResetExecCommand()
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("copy", errors.New("exit status 1"))}
+		hub.SetNewRemoteExecutor(executor.NewExecutor())
+		defer hub.ResetNewRemoteExecutor()
 
 		config := hub.Config{
 			Hostnames: []string{"sdw1", "sdw2"},
 		}
 		err = config.Write(file.Name())
-		expectedErrPrefix := "could not copy gp.conf file to segment hosts: exit status 1"
+		expectedErrPrefix := "could not copy gp.conf file to segment hosts: failed on 2 of 2 hosts"
 		if !strings.HasPrefix(err.Error(), expectedErrPrefix) {
 			t.Fatalf("got %v, want %v", err, expectedErrPrefix)
 		}
@@ -0,0 +1,118 @@
+package hub
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+// replyStream is implemented by the server side of every streaming hub RPC
+type replyStream interface {
+	Send(*idl.HubReply) error
+}
+
+// progressReporter sends progress of a long-running operation to the client
+// while logging it on the hub. Messages are also logged when there is no
+// stream, and a nil reporter does nothing at all, so the same code paths serve
+// the unary RPCs. A failure to send does not abort the operation; the client
+// is likely gone but the work still has to finish.
+type progressReporter struct {
+	mutex     sync.Mutex
+	stream    replyStream
+	succeeded int32
+	failed    int32
+}
+
+func newProgressReporter(stream replyStream) *progressReporter {
+	return &progressReporter{stream: stream}
+}
+
+func (p *progressReporter) send(reply *idl.HubReply) {
+	if p.stream == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	err := p.stream.Send(reply)
+	if err != nil {
+		gplog.Debug("could not send progress to client: %v", err)
+	}
+}
+
+func (p *progressReporter) Info(format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	gplog.Info("%s", message)
+	p.send(&idl.HubReply{Message: &idl.HubReply_Log{Log: &idl.LogMessage{Level: idl.LogMessage_INFO, Message: message}}})
+}
+
+func (p *progressReporter) Warn(format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	gplog.Warn("%s", message)
+	p.send(&idl.HubReply{Message: &idl.HubReply_Log{Log: &idl.LogMessage{Level: idl.LogMessage_WARNING, Message: message}}})
+}
+
+// Host reports the outcome of one step on a host. A nil error counts as a
+// success, anything else as a failure.
+func (p *progressReporter) Host(host string, detail string, err error) {
+	if p == nil {
+		return
+	}
+
+	status := idl.HostProgress_SUCCEEDED
+	if err != nil {
+		status = idl.HostProgress_FAILED
+		detail = fmt.Sprintf("%s: %v", detail, err)
+		gplog.Error("%s: %s", host, detail)
+	} else {
+		gplog.Info("%s: %s", host, detail)
+	}
+
+	p.mutex.Lock()
+	if err != nil {
+		p.failed++
+	} else {
+		p.succeeded++
+	}
+	p.mutex.Unlock()
+
+	p.send(&idl.HubReply{Message: &idl.HubReply_Progress{Progress: &idl.HostProgress{Host: host, Status: status, Detail: detail}}})
+}
+
+// Running reports that a step has started on a host
+func (p *progressReporter) Running(host string, detail string) {
+	if p == nil {
+		return
+	}
+
+	gplog.Debug("%s: %s", host, detail)
+	p.send(&idl.HubReply{Message: &idl.HubReply_Progress{Progress: &idl.HostProgress{Host: host, Status: idl.HostProgress_RUNNING, Detail: detail}}})
+}
+
+// Summary sends the final message of the operation, with the counts of the
+// host results reported so far
+func (p *progressReporter) Summary(format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	gplog.Info("%s", message)
+
+	p.mutex.Lock()
+	summary := &idl.Summary{Succeeded: p.succeeded, Failed: p.failed, Message: message}
+	p.mutex.Unlock()
+
+	p.send(&idl.HubReply{Message: &idl.HubReply_Summary{Summary: summary}})
+}
@@ -0,0 +1,286 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+)
+
+var (
+	connectToCoordinatorFunc = connectToCoordinator
+)
+
+const (
+	RolePrimary = "p"
+	RoleMirror  = "m"
+
+	StatusUp   = "u"
+	StatusDown = "d"
+
+	ModeSynchronized    = "s"
+	ModeNotSynchronized = "n"
+
+	CoordinatorContentID = -1
+)
+
+const segmentConfigurationQuery = `
+SELECT
+	dbid,
+	content,
+	role,
+	preferred_role,
+	mode,
+	status,
+	port,
+	hostname,
+	address,
+	datadir
+FROM gp_segment_configuration
+ORDER BY content, role DESC`
+
+// Segment is a single row of gp_segment_configuration
+type Segment struct {
+	DbID          int    `db:"dbid"`
+	ContentID     int    `db:"content"`
+	Role          string `db:"role"`
+	PreferredRole string `db:"preferred_role"`
+	Mode          string `db:"mode"`
+	Status        string `db:"status"`
+	Port          int    `db:"port"`
+	Hostname      string `db:"hostname"`
+	Address       string `db:"address"`
+	DataDir       string `db:"datadir"`
+}
+
+func (seg Segment) IsCoordinator() bool {
+	return seg.ContentID == CoordinatorContentID && seg.Role == RolePrimary
+}
+
+func (seg Segment) IsStandby() bool {
+	return seg.ContentID == CoordinatorContentID && seg.Role == RoleMirror
+}
+
+func (seg Segment) IsPrimary() bool {
+	return seg.ContentID != CoordinatorContentID && seg.Role == RolePrimary
+}
+
+func (seg Segment) IsMirror() bool {
+	return seg.ContentID != CoordinatorContentID && seg.Role == RoleMirror
+}
+
+func (seg Segment) IsUp() bool {
+	return seg.Status == StatusUp
+}
+
+func (seg Segment) InPreferredRole() bool {
+	return seg.Role == seg.PreferredRole
+}
+
+type Segments []Segment
+
+// Filter returns the segments for which keep returns true
+func (segs Segments) Filter(keep func(seg Segment) bool) Segments {
+	result := make(Segments, 0)
+	for _, seg := range segs {
+		if keep(seg) {
+			result = append(result, seg)
+		}
+	}
+
+	return result
+}
+
+func (segs Segments) Primaries() Segments {
+	return segs.Filter(Segment.IsPrimary)
+}
+
+func (segs Segments) Mirrors() Segments {
+	return segs.Filter(Segment.IsMirror)
+}
+
+// ByHost groups the segments by the host they live on
+func (segs Segments) ByHost() map[string]Segments {
+	result := make(map[string]Segments)
+	for _, seg := range segs {
+		result[seg.Hostname] = append(result[seg.Hostname], seg)
+	}
+
+	return result
+}
+
+// ByContent returns the segments with the given content ID, primary first
+func (segs Segments) ByContent(contentID int) Segments {
+	return segs.Filter(func(seg Segment) bool {
+		return seg.ContentID == contentID
+	})
+}
+
+// Hostnames returns the sorted, distinct hosts of the segments
+func (segs Segments) Hostnames() []string {
+	hostnames := make([]string, 0)
+	for host := range segs.ByHost() {
+		hostnames = append(hostnames, host)
+	}
+	sort.Strings(hostnames)
+
+	return hostnames
+}
+
+// Topology caches the segment configuration of the cluster. It is loaded from
+// gp_segment_configuration on the coordinator and only reloaded on Refresh, as
+// the catalog is unavailable while the cluster is stopped.
+type Topology struct {
+	mutex       sync.RWMutex
+	segments    Segments
+	refreshTime time.Time
+}
+
+func NewTopology() *Topology {
+	return &Topology{}
+}
+
+// Segments returns the cached segments, or nil if the topology was never loaded
+func (t *Topology) Segments() Segments {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.segments == nil {
+		return nil
+	}
+
+	return append(Segments{}, t.segments...)
+}
+
+func (t *Topology) IsLoaded() bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.segments != nil
+}
+
+func (t *Topology) RefreshTime() time.Time {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.refreshTime
+}
+
+// Set replaces the cached segments
+func (t *Topology) Set(segments Segments) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.segments = append(Segments{}, segments...)
+	t.refreshTime = time.Now()
+}
+
+// Refresh reloads the segment configuration using the given connection
+func (t *Topology) Refresh(conn *dbconn.DBConn) error {
+	segments := make(Segments, 0)
+	err := conn.Select(&segments, segmentConfigurationQuery)
+	if err != nil {
+		return fmt.Errorf("could not get segment configuration: %w", err)
+	}
+
+	t.Set(segments)
+	gplog.Debug("Loaded %d segments from gp_segment_configuration", len(segments))
+
+	return nil
+}
+
+// RefreshTopology connects to the coordinator on the given port and reloads the
+// cached topology. Utility mode is needed when the coordinator was started
+// without the segments.
+func (s *Server) RefreshTopology(port int, utilityMode bool) (Segments, error) {
+	conn, err := connectToCoordinatorFunc(port, utilityMode)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = s.Topology.Refresh(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Topology.Segments(), nil
+}
+
+// GetTopology returns the cached topology, loading it first if needed
+func (s *Server) GetTopology(port int) (Segments, error) {
+	if s.Topology.IsLoaded() {
+		return s.Topology.Segments(), nil
+	}
+
+	return s.RefreshTopology(port, false)
+}
+
+// ListSegmentHosts returns the hosts with segments in the cached topology,
+// loading it first when the hub knows the port of the coordinator. The hosts
+// are unknown otherwise.
+func (s *Server) ListSegmentHosts(ctx context.Context, in *idl.ListSegmentHostsRequest) (*idl.ListSegmentHostsReply, error) {
+	if !s.Topology.IsLoaded() && s.CoordinatorPort == 0 {
+		return &idl.ListSegmentHostsReply{}, nil
+	}
+
+	segments, err := s.GetTopology(s.CoordinatorPort)
+	if err != nil {
+		return &idl.ListSegmentHostsReply{}, err
+	}
+
+	return &idl.ListSegmentHostsReply{Hosts: segments.Hostnames(), Known: true}, nil
+}
+
+// watchTopology refreshes the cached topology at every interval until done is
+// closed, so that it is loaded once the hub starts and follows the changes made
+// outside of the hub, such as failovers. The cached topology is kept while the
+// coordinator can not be reached.
+func (s *Server) watchTopology(port int, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := s.RefreshTopology(port, false)
+		if err != nil {
+			gplog.Debug("Could not refresh the topology: %s", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func connectToCoordinator(port int, utilityMode bool) (*dbconn.DBConn, error) {
+	currentUser, err := user.Current()
+	if err != nil {
+		return nil, fmt.Errorf("could not get current user: %w", err)
+	}
+
+	conn := dbconn.NewDBConn(constants.DefaultDatabase, currentUser.Username, "localhost", port)
+	err = conn.Connect(1, utilityMode)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to coordinator on port %d: %w", port, err)
+	}
+
+	return conn, nil
+}
+
+// used only for testing
+func SetConnectToCoordinator(customFunc func(port int, utilityMode bool) (*dbconn.DBConn, error)) {
+	connectToCoordinatorFunc = customFunc
+}
+
+func ResetConnectToCoordinator() {
+	connectToCoordinatorFunc = connectToCoordinator
+}
@@ -0,0 +1,239 @@
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/hub"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var testSegments = hub.Segments{
+	{DbID: 1, ContentID: -1, Role: "p", PreferredRole: "p", Status: "u", Port: 5432, Hostname: "cdw", DataDir: "/data/qddir/gpseg-1"},
+	{DbID: 6, ContentID: -1, Role: "m", PreferredRole: "m", Status: "u", Port: 5432, Hostname: "scdw", DataDir: "/data/standby"},
+	{DbID: 2, ContentID: 0, Role: "p", PreferredRole: "p", Status: "u", Port: 6000, Hostname: "sdw1", DataDir: "/data/primary/gpseg0"},
+	{DbID: 4, ContentID: 0, Role: "m", PreferredRole: "m", Status: "u", Port: 7000, Hostname: "sdw2", DataDir: "/data/mirror/gpseg0"},
+	{DbID: 3, ContentID: 1, Role: "m", PreferredRole: "p", Status: "d", Port: 6000, Hostname: "sdw2", DataDir: "/data/primary/gpseg1"},
+	{DbID: 5, ContentID: 1, Role: "p", PreferredRole: "m", Status: "u", Port: 7000, Hostname: "sdw1", DataDir: "/data/mirror/gpseg1"},
+}
+
+func TestSegments(t *testing.T) {
+	t.Run("classifies the segments by role", func(t *testing.T) {
+		if !testSegments[0].IsCoordinator() || !testSegments[1].IsStandby() {
+			t.Fatalf("expected the coordinator and standby to be classified as such")
+		}
+
+		primaries := testSegments.Primaries()
+		expected := hub.Segments{testSegments[2], testSegments[5]}
+		if !reflect.DeepEqual(primaries, expected) {
+			t.Fatalf("got %+v, want %+v", primaries, expected)
+		}
+
+		mirrors := testSegments.Mirrors()
+		expected = hub.Segments{testSegments[3], testSegments[4]}
+		if !reflect.DeepEqual(mirrors, expected) {
+			t.Fatalf("got %+v, want %+v", mirrors, expected)
+		}
+	})
+
+	t.Run("reports the segment state", func(t *testing.T) {
+		if testSegments[4].IsUp() || !testSegments[5].IsUp() {
+			t.Fatalf("unexpected segment status")
+		}
+
+		if testSegments[4].InPreferredRole() || !testSegments[2].InPreferredRole() {
+			t.Fatalf("unexpected preferred role")
+		}
+	})
+
+	t.Run("groups the segments by host and content", func(t *testing.T) {
+		byHost := testSegments.ByHost()
+		expected := hub.Segments{testSegments[2], testSegments[5]}
+		if !reflect.DeepEqual(byHost["sdw1"], expected) {
+			t.Fatalf("got %+v, want %+v", byHost["sdw1"], expected)
+		}
+
+		byContent := testSegments.ByContent(1)
+		expected = hub.Segments{testSegments[4], testSegments[5]}
+		if !reflect.DeepEqual(byContent, expected) {
+			t.Fatalf("got %+v, want %+v", byContent, expected)
+		}
+
+		hostnames := testSegments.Hostnames()
+		expectedHosts := []string{"cdw", "scdw", "sdw1", "sdw2"}
+		if !reflect.DeepEqual(hostnames, expectedHosts) {
+			t.Fatalf("got %+v, want %+v", hostnames, expectedHosts)
+		}
+	})
+}
+
+func TestTopology(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("loads the segments from gp_segment_configuration", func(t *testing.T) {
+		conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+		rows := sqlmock.NewRows([]string{"dbid", "content", "role", "preferred_role", "mode", "status", "port", "hostname", "address", "datadir"}).
+			AddRow(1, -1, "p", "p", "n", "u", 5432, "cdw", "cdw-addr", "/data/qddir/gpseg-1").
+			AddRow(2, 0, "p", "p", "s", "u", 6000, "sdw1", "sdw1-addr", "/data/primary/gpseg0")
+		mock.ExpectQuery("SELECT (.*) FROM gp_segment_configuration").WillReturnRows(rows)
+
+		topology := hub.NewTopology()
+		if topology.IsLoaded() || topology.Segments() != nil {
+			t.Fatalf("expected the topology to be empty")
+		}
+
+		err := topology.Refresh(conn)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := hub.Segments{
+			{DbID: 1, ContentID: -1, Role: "p", PreferredRole: "p", Mode: "n", Status: "u", Port: 5432, Hostname: "cdw", Address: "cdw-addr", DataDir: "/data/qddir/gpseg-1"},
+			{DbID: 2, ContentID: 0, Role: "p", PreferredRole: "p", Mode: "s", Status: "u", Port: 6000, Hostname: "sdw1", Address: "sdw1-addr", DataDir: "/data/primary/gpseg0"},
+		}
+		if !reflect.DeepEqual(topology.Segments(), expected) {
+			t.Fatalf("got %+v, want %+v", topology.Segments(), expected)
+		}
+		if !topology.IsLoaded() || topology.RefreshTime().IsZero() {
+			t.Fatalf("expected the topology to be loaded")
+		}
+	})
+
+	t.Run("errors out when the query fails", func(t *testing.T) {
+		conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+		expected := errors.New("error")
+		mock.ExpectQuery("SELECT").WillReturnError(expected)
+
+		topology := hub.NewTopology()
+		err := topology.Refresh(conn)
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+		if topology.IsLoaded() {
+			t.Fatalf("expected the topology to not be loaded")
+		}
+	})
+
+	t.Run("hub only queries the coordinator when the topology is not cached", func(t *testing.T) {
+		hubServer := hub.New(testutils.InitializeTestEnv(), nil)
+
+		connections := 0
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			connections++
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			mock.ExpectQuery("SELECT").WillReturnRows(segmentRows(segment(2, 0, "p", "p", "s", "u", 6000, "sdw1", "/data/primary/gpseg0")))
+			return conn, nil
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		for i := 0; i < 2; i++ {
+			segments, err := hubServer.GetTopology(5432)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if len(segments) != 1 {
+				t.Fatalf("got %d segments, want 1", len(segments))
+			}
+		}
+		if connections != 1 {
+			t.Fatalf("got %d connections, want 1", connections)
+		}
+
+		_, err := hubServer.RefreshTopology(5432, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if connections != 2 {
+			t.Fatalf("got %d connections, want 2", connections)
+		}
+	})
+
+	t.Run("hub loads the topology once started when it knows the coordinator port", func(t *testing.T) {
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			if port != 5432 {
+				return nil, fmt.Errorf("unexpected port %d", port)
+			}
+
+			conn, mock := testutils.CreateMockDBConn(t, "7.0.0")
+			mock.ExpectQuery("SELECT").WillReturnRows(segmentRows(mirroredSegments()...))
+			return conn, nil
+		})
+		// reset once the hub stopped
+		t.Cleanup(hub.ResetConnectToCoordinator)
+
+		hubServer := startHub(t, &hub.Config{
+			Credentials:     &testutils.MockCredentials{TlsConnection: insecure.NewCredentials()},
+			CoordinatorPort: 5432,
+		})
+
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if hubServer.Topology.IsLoaded() {
+				break
+			}
+		}
+		if !reflect.DeepEqual(hubServer.Topology.Segments(), mirroredSegments()) {
+			t.Fatalf("got %+v, want %+v", hubServer.Topology.Segments(), mirroredSegments())
+		}
+	})
+}
+
+func TestListSegmentHosts(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("returns the hosts with segments in the topology", func(t *testing.T) {
+		setMockSegmentConfiguration(t)
+		defer hub.ResetConnectToCoordinator()
+
+		conf := testutils.InitializeTestEnv()
+		conf.CoordinatorPort = 5432
+		hubServer := hub.New(conf, nil)
+
+		reply, err := hubServer.ListSegmentHosts(context.Background(), &idl.ListSegmentHostsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := &idl.ListSegmentHostsReply{Hosts: []string{"cdw", "sdw1", "sdw2"}, Known: true}
+		if !reflect.DeepEqual(reply, expected) {
+			t.Fatalf("got %+v, want %+v", reply, expected)
+		}
+	})
+
+	t.Run("does not know the hosts when the coordinator port is not configured", func(t *testing.T) {
+		hubServer := hub.New(testutils.InitializeTestEnv(), nil)
+
+		reply, err := hubServer.ListSegmentHosts(context.Background(), &idl.ListSegmentHostsRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if reply.Known || len(reply.Hosts) != 0 {
+			t.Fatalf("got %+v, want unknown hosts", reply)
+		}
+	})
+
+	t.Run("errors out when the topology can not be loaded", func(t *testing.T) {
+		expected := errors.New("error")
+		hub.SetConnectToCoordinator(func(port int, utilityMode bool) (*dbconn.DBConn, error) {
+			return nil, expected
+		})
+		defer hub.ResetConnectToCoordinator()
+
+		conf := testutils.InitializeTestEnv()
+		conf.CoordinatorPort = 5432
+		hubServer := hub.New(conf, nil)
+
+		_, err := hubServer.ListSegmentHosts(context.Background(), &idl.ListSegmentHostsRequest{})
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %#v, want %#v", err, expected)
+		}
+	})
+}
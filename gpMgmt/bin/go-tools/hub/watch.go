@@ -0,0 +1,217 @@
+package hub
+
+import (
+	"context"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc/connectivity"
+)
+
+var segmentEventTypes = map[idl.SegmentEventType]string{
+	idl.SegmentEventType_SEGMENT_UP:       EventSegmentUp,
+	idl.SegmentEventType_SEGMENT_DOWN:     EventSegmentDown,
+	idl.SegmentEventType_DISK_NEARLY_FULL: EventDiskNearlyFull,
+	idl.SegmentEventType_DISK_OK:          EventDiskOK,
+}
+
+// segmentWatch is a WatchSegments call open on the agent of a host
+type segmentWatch struct {
+	segments Segments
+	cancel   func()
+	done     chan struct{} // closed once the call ended
+}
+
+func (w *segmentWatch) ended() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// updateSegmentWatches has the agent of every connected host watch the
+// segments of the cached topology that live there, restarting the watches
+// that ended or whose segments changed. Nothing is watched while the cluster
+// is stopped or before the topology is loaded.
+func (s *Server) updateSegmentWatches(conf *EventsConfig) {
+	byHost := s.Topology.Segments().ByHost()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.clusterStopped {
+		byHost = nil
+	}
+
+	for host, watch := range s.watches {
+		if watch.ended() || !sameWatchedSegments(watch.segments, byHost[host]) || s.getConnection(host) == nil {
+			watch.cancel()
+			delete(s.watches, host)
+		}
+	}
+
+	for host, segments := range byHost {
+		if _, watched := s.watches[host]; watched {
+			continue
+		}
+
+		conn := s.getConnection(host)
+		if conn == nil || (conn.Conn != nil && conn.Conn.GetState() != connectivity.Ready) {
+			continue
+		}
+
+		if s.watches == nil {
+			s.watches = make(map[string]*segmentWatch)
+		}
+		s.watches[host] = s.watchSegmentsOn(conn, segments, conf)
+	}
+}
+
+// sameWatchedSegments compares what the agents watch of the segments, so that
+// a change of role or status does not restart the watch
+func sameWatchedSegments(watched Segments, segments Segments) bool {
+	if len(watched) != len(segments) {
+		return false
+	}
+
+	for i := range watched {
+		if watched[i].DataDir != segments[i].DataDir || watched[i].ContentID != segments[i].ContentID ||
+			watched[i].DbID != segments[i].DbID || watched[i].Port != segments[i].Port {
+			return false
+		}
+	}
+
+	return true
+}
+
+// watchSegmentsOn opens a WatchSegments call on the agent and raises the
+// events it streams, until the call is canceled or fails
+func (s *Server) watchSegmentsOn(conn *Connection, segments Segments, conf *EventsConfig) *segmentWatch {
+	ctx, cancel := context.WithCancel(context.Background())
+	watch := &segmentWatch{segments: segments, cancel: cancel, done: make(chan struct{})}
+
+	request := &idl.WatchSegmentsRequest{
+		Interval:           int32(conf.Interval() / time.Second),
+		DiskUsageThreshold: int32(conf.Threshold()),
+	}
+	for _, seg := range segments {
+		request.Segments = append(request.Segments, &idl.WatchedSegment{
+			DataDir:   seg.DataDir,
+			ContentId: int32(seg.ContentID),
+			Dbid:      int32(seg.DbID),
+			Port:      int32(seg.Port),
+		})
+	}
+
+	go func() {
+		defer close(watch.done)
+
+		stream, err := conn.AgentClient.WatchSegments(ctx, request)
+		if err != nil {
+			gplog.Debug("Could not watch segments on host %s: %s", conn.Hostname, err)
+			return
+		}
+		gplog.Debug("Watching %d segments on host %s", len(segments), conn.Hostname)
+
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					gplog.Debug("Stopped watching segments on host %s: %s", conn.Hostname, err)
+				}
+				return
+			}
+
+			s.Notifier.Notify(newSegmentEvent(conn.Hostname, event))
+		}
+	}()
+
+	return watch
+}
+
+func newSegmentEvent(host string, in *idl.SegmentEvent) Event {
+	event := Event{
+		Type:      segmentEventTypes[in.Type],
+		Host:      host,
+		Message:   in.Message,
+		DiskUsage: in.DiskUsage,
+	}
+	if in.Time != nil {
+		event.Time = in.Time.AsTime()
+	}
+	if in.Segment != nil {
+		event.Segment = &EventSegment{
+			ContentID: int(in.Segment.ContentId),
+			DbID:      int(in.Segment.Dbid),
+			Port:      int(in.Segment.Port),
+			DataDir:   in.Segment.DataDir,
+		}
+	}
+
+	return event
+}
+
+// stopSegmentWatches cancels every watch. When the cluster is being stopped,
+// its segments are not watched again until resumeSegmentWatches is called.
+func (s *Server) stopSegmentWatches(clusterStopped bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for host, watch := range s.watches {
+		watch.cancel()
+		delete(s.watches, host)
+	}
+	if clusterStopped {
+		s.clusterStopped = true
+	}
+}
+
+// resumeSegmentWatches lets the segments be watched again once the cluster is
+// started
+func (s *Server) resumeSegmentWatches() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.clusterStopped = false
+}
+
+// watchSegments keeps the watches of the segments up to date at every
+// interval until done is closed
+func (s *Server) watchSegments(conf *EventsConfig, done <-chan struct{}) {
+	ticker := time.NewTicker(conf.Interval())
+	defer ticker.Stop()
+	defer s.stopSegmentWatches(false)
+
+	for {
+		s.updateSegmentWatches(conf)
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordHeartbeat records the outcome of a status request to the agent of the
+// host, raising an event when the agent becomes unreachable or reachable again.
+// Failures are ignored while the agents are stopped through the hub.
+func (s *Server) recordHeartbeat(host string, status *idl.ServiceStatus, err error) {
+	s.mutex.Lock()
+	stopped := s.agentsStopped
+	s.mutex.Unlock()
+	if stopped && err != nil { // in flight when the agents were stopped
+		return
+	}
+
+	previous := s.Heartbeats.Record(host, status, err, time.Now())
+
+	if err != nil && previous != AgentUnreachable {
+		s.Notifier.Notify(Event{Type: EventAgentUnreachable, Host: host, Message: err.Error()})
+	} else if err == nil && previous == AgentUnreachable {
+		s.Notifier.Notify(Event{Type: EventAgentReachable, Host: host, Message: "agent is reachable again"})
+	}
+}
@@ -13,6 +13,8 @@ import (
 	status "google.golang.org/grpc/status"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -24,14 +26,172 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type StopHubRequest struct {
+type MirroringStrategy int32
+
+const (
+	MirroringStrategy_NO_MIRRORS MirroringStrategy = 0
+	MirroringStrategy_GROUP      MirroringStrategy = 1 // the mirrors of the primaries of a host all go to the next host
+	MirroringStrategy_SPREAD     MirroringStrategy = 2 // the mirrors of the primaries of a host each go to a different host
+)
+
+// Enum value maps for MirroringStrategy.
+var (
+	MirroringStrategy_name = map[int32]string{
+		0: "NO_MIRRORS",
+		1: "GROUP",
+		2: "SPREAD",
+	}
+	MirroringStrategy_value = map[string]int32{
+		"NO_MIRRORS": 0,
+		"GROUP":      1,
+		"SPREAD":     2,
+	}
+)
+
+func (x MirroringStrategy) Enum() *MirroringStrategy {
+	p := new(MirroringStrategy)
+	*p = x
+	return p
+}
+
+func (x MirroringStrategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MirroringStrategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_hub_proto_enumTypes[0].Descriptor()
+}
+
+func (MirroringStrategy) Type() protoreflect.EnumType {
+	return &file_hub_proto_enumTypes[0]
+}
+
+func (x MirroringStrategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MirroringStrategy.Descriptor instead.
+func (MirroringStrategy) EnumDescriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{0}
+}
+
+type HostProgress_Status int32
+
+const (
+	HostProgress_RUNNING   HostProgress_Status = 0
+	HostProgress_SUCCEEDED HostProgress_Status = 1
+	HostProgress_FAILED    HostProgress_Status = 2
+)
+
+// Enum value maps for HostProgress_Status.
+var (
+	HostProgress_Status_name = map[int32]string{
+		0: "RUNNING",
+		1: "SUCCEEDED",
+		2: "FAILED",
+	}
+	HostProgress_Status_value = map[string]int32{
+		"RUNNING":   0,
+		"SUCCEEDED": 1,
+		"FAILED":    2,
+	}
+)
+
+func (x HostProgress_Status) Enum() *HostProgress_Status {
+	p := new(HostProgress_Status)
+	*p = x
+	return p
+}
+
+func (x HostProgress_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HostProgress_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_hub_proto_enumTypes[1].Descriptor()
+}
+
+func (HostProgress_Status) Type() protoreflect.EnumType {
+	return &file_hub_proto_enumTypes[1]
+}
+
+func (x HostProgress_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HostProgress_Status.Descriptor instead.
+func (HostProgress_Status) EnumDescriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type LogMessage_Level int32
+
+const (
+	LogMessage_INFO    LogMessage_Level = 0
+	LogMessage_WARNING LogMessage_Level = 1
+	LogMessage_ERROR   LogMessage_Level = 2
+	LogMessage_DEBUG   LogMessage_Level = 3
+)
+
+// Enum value maps for LogMessage_Level.
+var (
+	LogMessage_Level_name = map[int32]string{
+		0: "INFO",
+		1: "WARNING",
+		2: "ERROR",
+		3: "DEBUG",
+	}
+	LogMessage_Level_value = map[string]int32{
+		"INFO":    0,
+		"WARNING": 1,
+		"ERROR":   2,
+		"DEBUG":   3,
+	}
+)
+
+func (x LogMessage_Level) Enum() *LogMessage_Level {
+	p := new(LogMessage_Level)
+	*p = x
+	return p
+}
+
+func (x LogMessage_Level) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogMessage_Level) Descriptor() protoreflect.EnumDescriptor {
+	return file_hub_proto_enumTypes[2].Descriptor()
+}
+
+func (LogMessage_Level) Type() protoreflect.EnumType {
+	return &file_hub_proto_enumTypes[2]
+}
+
+func (x LogMessage_Level) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogMessage_Level.Descriptor instead.
+func (LogMessage_Level) EnumDescriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{2, 0}
+}
+
+// HubReply is sent by the streaming RPCs of long-running operations. Any
+// number of progress and log messages are followed by a single summary.
+type HubReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Message:
+	//	*HubReply_Progress
+	//	*HubReply_Log
+	//	*HubReply_Summary
+	Message isHubReply_Message `protobuf_oneof:"message"`
 }
 
-func (x *StopHubRequest) Reset() {
-	*x = StopHubRequest{}
+func (x *HubReply) Reset() {
+	*x = HubReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[0]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -39,13 +199,13 @@ func (x *StopHubRequest) Reset() {
 	}
 }
 
-func (x *StopHubRequest) String() string {
+func (x *HubReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopHubRequest) ProtoMessage() {}
+func (*HubReply) ProtoMessage() {}
 
-func (x *StopHubRequest) ProtoReflect() protoreflect.Message {
+func (x *HubReply) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[0]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -57,19 +217,73 @@ func (x *StopHubRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopHubRequest.ProtoReflect.Descriptor instead.
-func (*StopHubRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use HubReply.ProtoReflect.Descriptor instead.
+func (*HubReply) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{0}
 }
 
-type StopHubReply struct {
+func (m *HubReply) GetMessage() isHubReply_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *HubReply) GetProgress() *HostProgress {
+	if x, ok := x.GetMessage().(*HubReply_Progress); ok {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *HubReply) GetLog() *LogMessage {
+	if x, ok := x.GetMessage().(*HubReply_Log); ok {
+		return x.Log
+	}
+	return nil
+}
+
+func (x *HubReply) GetSummary() *Summary {
+	if x, ok := x.GetMessage().(*HubReply_Summary); ok {
+		return x.Summary
+	}
+	return nil
+}
+
+type isHubReply_Message interface {
+	isHubReply_Message()
+}
+
+type HubReply_Progress struct {
+	Progress *HostProgress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type HubReply_Log struct {
+	Log *LogMessage `protobuf:"bytes,2,opt,name=log,proto3,oneof"`
+}
+
+type HubReply_Summary struct {
+	Summary *Summary `protobuf:"bytes,3,opt,name=summary,proto3,oneof"`
+}
+
+func (*HubReply_Progress) isHubReply_Message() {}
+
+func (*HubReply_Log) isHubReply_Message() {}
+
+func (*HubReply_Summary) isHubReply_Message() {}
+
+type HostProgress struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Host   string              `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Status HostProgress_Status `protobuf:"varint,2,opt,name=status,proto3,enum=idl.HostProgress_Status" json:"status,omitempty"`
+	Detail string              `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
 }
 
-func (x *StopHubReply) Reset() {
-	*x = StopHubReply{}
+func (x *HostProgress) Reset() {
+	*x = HostProgress{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -77,13 +291,13 @@ func (x *StopHubReply) Reset() {
 	}
 }
 
-func (x *StopHubReply) String() string {
+func (x *HostProgress) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopHubReply) ProtoMessage() {}
+func (*HostProgress) ProtoMessage() {}
 
-func (x *StopHubReply) ProtoReflect() protoreflect.Message {
+func (x *HostProgress) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -95,19 +309,43 @@ func (x *StopHubReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopHubReply.ProtoReflect.Descriptor instead.
-func (*StopHubReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use HostProgress.ProtoReflect.Descriptor instead.
+func (*HostProgress) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{1}
 }
 
-type StartAgentsRequest struct {
+func (x *HostProgress) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *HostProgress) GetStatus() HostProgress_Status {
+	if x != nil {
+		return x.Status
+	}
+	return HostProgress_RUNNING
+}
+
+func (x *HostProgress) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type LogMessage struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Level   LogMessage_Level `protobuf:"varint,1,opt,name=level,proto3,enum=idl.LogMessage_Level" json:"level,omitempty"`
+	Message string           `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *StartAgentsRequest) Reset() {
-	*x = StartAgentsRequest{}
+func (x *LogMessage) Reset() {
+	*x = LogMessage{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -115,13 +353,13 @@ func (x *StartAgentsRequest) Reset() {
 	}
 }
 
-func (x *StartAgentsRequest) String() string {
+func (x *LogMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartAgentsRequest) ProtoMessage() {}
+func (*LogMessage) ProtoMessage() {}
 
-func (x *StartAgentsRequest) ProtoReflect() protoreflect.Message {
+func (x *LogMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -133,19 +371,37 @@ func (x *StartAgentsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartAgentsRequest.ProtoReflect.Descriptor instead.
-func (*StartAgentsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LogMessage.ProtoReflect.Descriptor instead.
+func (*LogMessage) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{2}
 }
 
-type StartAgentsReply struct {
+func (x *LogMessage) GetLevel() LogMessage_Level {
+	if x != nil {
+		return x.Level
+	}
+	return LogMessage_INFO
+}
+
+func (x *LogMessage) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type Summary struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Succeeded int32  `protobuf:"varint,1,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed    int32  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Message   string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *StartAgentsReply) Reset() {
-	*x = StartAgentsReply{}
+func (x *Summary) Reset() {
+	*x = Summary{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -153,13 +409,13 @@ func (x *StartAgentsReply) Reset() {
 	}
 }
 
-func (x *StartAgentsReply) String() string {
+func (x *Summary) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StartAgentsReply) ProtoMessage() {}
+func (*Summary) ProtoMessage() {}
 
-func (x *StartAgentsReply) ProtoReflect() protoreflect.Message {
+func (x *Summary) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -171,19 +427,47 @@ func (x *StartAgentsReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StartAgentsReply.ProtoReflect.Descriptor instead.
-func (*StartAgentsReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use Summary.ProtoReflect.Descriptor instead.
+func (*Summary) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{3}
 }
 
-type StatusAgentsRequest struct {
+func (x *Summary) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *Summary) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *Summary) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// HostResult is the outcome of a request on a single host. RPCs running a
+// request on many hosts attach one for every host to the gRPC status details
+// when any of them fails.
+type HostResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Hostname string               `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Error    string               `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Duration *durationpb.Duration `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
 }
 
-func (x *StatusAgentsRequest) Reset() {
-	*x = StatusAgentsRequest{}
+func (x *HostResult) Reset() {
+	*x = HostResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -191,13 +475,13 @@ func (x *StatusAgentsRequest) Reset() {
 	}
 }
 
-func (x *StatusAgentsRequest) String() string {
+func (x *HostResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatusAgentsRequest) ProtoMessage() {}
+func (*HostResult) ProtoMessage() {}
 
-func (x *StatusAgentsRequest) ProtoReflect() protoreflect.Message {
+func (x *HostResult) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -209,24 +493,40 @@ func (x *StatusAgentsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatusAgentsRequest.ProtoReflect.Descriptor instead.
-func (*StatusAgentsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use HostResult.ProtoReflect.Descriptor instead.
+func (*HostResult) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{4}
 }
 
-type ServiceStatus struct {
+func (x *HostResult) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *HostResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *HostResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type StopHubRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Host   string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
-	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Uptime string `protobuf:"bytes,3,opt,name=uptime,proto3" json:"uptime,omitempty"`
-	Pid    uint32 `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
 }
 
-func (x *ServiceStatus) Reset() {
-	*x = ServiceStatus{}
+func (x *StopHubRequest) Reset() {
+	*x = StopHubRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -234,13 +534,13 @@ func (x *ServiceStatus) Reset() {
 	}
 }
 
-func (x *ServiceStatus) String() string {
+func (x *StopHubRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceStatus) ProtoMessage() {}
+func (*StopHubRequest) ProtoMessage() {}
 
-func (x *ServiceStatus) ProtoReflect() protoreflect.Message {
+func (x *StopHubRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -252,49 +552,19 @@ func (x *ServiceStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceStatus.ProtoReflect.Descriptor instead.
-func (*ServiceStatus) Descriptor() ([]byte, []int) {
+// Deprecated: Use StopHubRequest.ProtoReflect.Descriptor instead.
+func (*StopHubRequest) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *ServiceStatus) GetHost() string {
-	if x != nil {
-		return x.Host
-	}
-	return ""
-}
-
-func (x *ServiceStatus) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
-}
-
-func (x *ServiceStatus) GetUptime() string {
-	if x != nil {
-		return x.Uptime
-	}
-	return ""
-}
-
-func (x *ServiceStatus) GetPid() uint32 {
-	if x != nil {
-		return x.Pid
-	}
-	return 0
-}
-
-type StatusAgentsReply struct {
+type StopHubReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Statuses []*ServiceStatus `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
 }
 
-func (x *StatusAgentsReply) Reset() {
-	*x = StatusAgentsReply{}
+func (x *StopHubReply) Reset() {
+	*x = StopHubReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_hub_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -302,13 +572,13 @@ func (x *StatusAgentsReply) Reset() {
 	}
 }
 
-func (x *StatusAgentsReply) String() string {
+func (x *StopHubReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatusAgentsReply) ProtoMessage() {}
+func (*StopHubReply) ProtoMessage() {}
 
-func (x *StatusAgentsReply) ProtoReflect() protoreflect.Message {
+func (x *StopHubReply) ProtoReflect() protoreflect.Message {
 	mi := &file_hub_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -320,186 +590,2201 @@ func (x *StatusAgentsReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatusAgentsReply.ProtoReflect.Descriptor instead.
-func (*StatusAgentsReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use StopHubReply.ProtoReflect.Descriptor instead.
+func (*StopHubReply) Descriptor() ([]byte, []int) {
 	return file_hub_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *StatusAgentsReply) GetStatuses() []*ServiceStatus {
-	if x != nil {
-		return x.Statuses
+type StartAgentsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StartAgentsRequest) Reset() {
+	*x = StartAgentsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartAgentsRequest) ProtoMessage() {}
+
+func (x *StartAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartAgentsRequest.ProtoReflect.Descriptor instead.
+func (*StartAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{7}
+}
+
+type StartAgentsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StartAgentsReply) Reset() {
+	*x = StartAgentsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartAgentsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartAgentsReply) ProtoMessage() {}
+
+func (x *StartAgentsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartAgentsReply.ProtoReflect.Descriptor instead.
+func (*StartAgentsReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{8}
+}
+
+type StatusAgentsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SkipUnreachable bool `protobuf:"varint,1,opt,name=skip_unreachable,json=skipUnreachable,proto3" json:"skip_unreachable,omitempty"` // report unreachable agents instead of failing
+}
+
+func (x *StatusAgentsRequest) Reset() {
+	*x = StatusAgentsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatusAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusAgentsRequest) ProtoMessage() {}
+
+func (x *StatusAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusAgentsRequest.ProtoReflect.Descriptor instead.
+func (*StatusAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StatusAgentsRequest) GetSkipUnreachable() bool {
+	if x != nil {
+		return x.SkipUnreachable
+	}
+	return false
+}
+
+type ServiceStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Host           string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Status         string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Uptime         string                 `protobuf:"bytes,3,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Pid            uint32                 `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
+	Certificates   []*Certificate         `protobuf:"bytes,5,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	StartTime      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	MemoryRss      uint64                 `protobuf:"varint,7,opt,name=memory_rss,json=memoryRss,proto3" json:"memory_rss,omitempty"` // bytes
+	CpuTime        *durationpb.Duration   `protobuf:"bytes,8,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`        // user and system time used since the start
+	Connections    uint32                 `protobuf:"varint,9,opt,name=connections,proto3" json:"connections,omitempty"`              // open gRPC connections to the service
+	Version        string                 `protobuf:"bytes,10,opt,name=version,proto3" json:"version,omitempty"`
+	ConfigChecksum string                 `protobuf:"bytes,11,opt,name=config_checksum,json=configChecksum,proto3" json:"config_checksum,omitempty"` // sha256 of the configuration file loaded by the service
+	ActiveState    string                 `protobuf:"bytes,12,opt,name=active_state,json=activeState,proto3" json:"active_state,omitempty"`          // as reported by the service manager
+	SubState       string                 `protobuf:"bytes,13,opt,name=sub_state,json=subState,proto3" json:"sub_state,omitempty"`
+	Result         string                 `protobuf:"bytes,14,opt,name=result,proto3" json:"result,omitempty"`
+	LastSeen       *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"` // set from the heartbeat of the hub
+}
+
+func (x *ServiceStatus) Reset() {
+	*x = ServiceStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServiceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceStatus) ProtoMessage() {}
+
+func (x *ServiceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceStatus.ProtoReflect.Descriptor instead.
+func (*ServiceStatus) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ServiceStatus) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetUptime() string {
+	if x != nil {
+		return x.Uptime
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetPid() uint32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetCertificates() []*Certificate {
+	if x != nil {
+		return x.Certificates
 	}
 	return nil
 }
 
-type StopAgentsRequest struct {
+func (x *ServiceStatus) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ServiceStatus) GetMemoryRss() uint64 {
+	if x != nil {
+		return x.MemoryRss
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetCpuTime() *durationpb.Duration {
+	if x != nil {
+		return x.CpuTime
+	}
+	return nil
+}
+
+func (x *ServiceStatus) GetConnections() uint32 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetConfigChecksum() string {
+	if x != nil {
+		return x.ConfigChecksum
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetActiveState() string {
+	if x != nil {
+		return x.ActiveState
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetSubState() string {
+	if x != nil {
+		return x.SubState
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+type StatusAgentsReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Statuses []*ServiceStatus `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
 }
 
-func (x *StopAgentsRequest) Reset() {
-	*x = StopAgentsRequest{}
+func (x *StatusAgentsReply) Reset() {
+	*x = StatusAgentsReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_hub_proto_msgTypes[7]
+		mi := &file_hub_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopAgentsRequest) String() string {
+func (x *StatusAgentsReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopAgentsRequest) ProtoMessage() {}
+func (*StatusAgentsReply) ProtoMessage() {}
 
-func (x *StopAgentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hub_proto_msgTypes[7]
+func (x *StatusAgentsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use StopAgentsRequest.ProtoReflect.Descriptor instead.
-func (*StopAgentsRequest) Descriptor() ([]byte, []int) {
-	return file_hub_proto_rawDescGZIP(), []int{7}
-}
-
-type StopAgentsReply struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-}
-
-func (x *StopAgentsReply) Reset() {
-	*x = StopAgentsReply{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_hub_proto_msgTypes[8]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
-}
-
-func (x *StopAgentsReply) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*StopAgentsReply) ProtoMessage() {}
-
-func (x *StopAgentsReply) ProtoReflect() protoreflect.Message {
-	mi := &file_hub_proto_msgTypes[8]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusAgentsReply.ProtoReflect.Descriptor instead.
+func (*StatusAgentsReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StatusAgentsReply) GetStatuses() []*ServiceStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+type StopAgentsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SkipUnreachable bool `protobuf:"varint,1,opt,name=skip_unreachable,json=skipUnreachable,proto3" json:"skip_unreachable,omitempty"` // stop the reachable agents instead of failing
+}
+
+func (x *StopAgentsRequest) Reset() {
+	*x = StopAgentsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopAgentsRequest) ProtoMessage() {}
+
+func (x *StopAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopAgentsRequest.ProtoReflect.Descriptor instead.
+func (*StopAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StopAgentsRequest) GetSkipUnreachable() bool {
+	if x != nil {
+		return x.SkipUnreachable
+	}
+	return false
+}
+
+type StopAgentsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StopAgentsReply) Reset() {
+	*x = StopAgentsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopAgentsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopAgentsReply) ProtoMessage() {}
+
+func (x *StopAgentsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopAgentsReply.ProtoReflect.Descriptor instead.
+func (*StopAgentsReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{13}
+}
+
+// ListAgentsRequest asks the hub what its heartbeat knows of every agent,
+// without contacting them
+type ListAgentsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListAgentsRequest) Reset() {
+	*x = ListAgentsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsRequest) ProtoMessage() {}
+
+func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{14}
+}
+
+type ListAgentsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Agents []*AgentInfo `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+}
+
+func (x *ListAgentsReply) Reset() {
+	*x = ListAgentsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAgentsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsReply) ProtoMessage() {}
+
+func (x *ListAgentsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsReply.ProtoReflect.Descriptor instead.
+func (*ListAgentsReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListAgentsReply) GetAgents() []*AgentInfo {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+type AgentInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Host            string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	State           string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`                       // alive, unreachable, or unknown until the first heartbeat
+	LastSeen        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"` // unset when the agent never answered
+	LastCheck       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_check,json=lastCheck,proto3" json:"last_check,omitempty"`
+	Error           string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                                             // why the last heartbeat failed
+	Status          *ServiceStatus         `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`                                           // as reported by the agent when last seen
+	ConnectionState string                 `protobuf:"bytes,7,opt,name=connection_state,json=connectionState,proto3" json:"connection_state,omitempty"`  // of the connection of the hub to the agent
+	ConnectFailures int32                  `protobuf:"varint,8,opt,name=connect_failures,json=connectFailures,proto3" json:"connect_failures,omitempty"` // consecutive failed attempts to connect
+	History         []*AgentStateChange    `protobuf:"bytes,9,rep,name=history,proto3" json:"history,omitempty"`                                         // oldest first
+}
+
+func (x *AgentInfo) Reset() {
+	*x = AgentInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AgentInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentInfo) ProtoMessage() {}
+
+func (x *AgentInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentInfo.ProtoReflect.Descriptor instead.
+func (*AgentInfo) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AgentInfo) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *AgentInfo) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *AgentInfo) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *AgentInfo) GetLastCheck() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastCheck
+	}
+	return nil
+}
+
+func (x *AgentInfo) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AgentInfo) GetStatus() *ServiceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *AgentInfo) GetConnectionState() string {
+	if x != nil {
+		return x.ConnectionState
+	}
+	return ""
+}
+
+func (x *AgentInfo) GetConnectFailures() int32 {
+	if x != nil {
+		return x.ConnectFailures
+	}
+	return 0
+}
+
+func (x *AgentInfo) GetHistory() []*AgentStateChange {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+type AgentStateChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Error string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *AgentStateChange) Reset() {
+	*x = AgentStateChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AgentStateChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentStateChange) ProtoMessage() {}
+
+func (x *AgentStateChange) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentStateChange.ProtoReflect.Descriptor instead.
+func (*AgentStateChange) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AgentStateChange) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *AgentStateChange) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AgentStateChange) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// DistributeConfigRequest asks the hub to push the configuration file it was
+// started with to the same path on every agent host
+// ListSegmentHostsRequest asks the hub for the hosts with segments in the
+// topology it cached, loaded first through the configured coordinator port if
+// needed
+type ListSegmentHostsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListSegmentHostsRequest) Reset() {
+	*x = ListSegmentHostsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSegmentHostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSegmentHostsRequest) ProtoMessage() {}
+
+func (x *ListSegmentHostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSegmentHostsRequest.ProtoReflect.Descriptor instead.
+func (*ListSegmentHostsRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{18}
+}
+
+type ListSegmentHostsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hosts []string `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Known bool     `protobuf:"varint,2,opt,name=known,proto3" json:"known,omitempty"` // whether the hub knows the topology
+}
+
+func (x *ListSegmentHostsReply) Reset() {
+	*x = ListSegmentHostsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSegmentHostsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSegmentHostsReply) ProtoMessage() {}
+
+func (x *ListSegmentHostsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSegmentHostsReply.ProtoReflect.Descriptor instead.
+func (*ListSegmentHostsReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListSegmentHostsReply) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *ListSegmentHostsReply) GetKnown() bool {
+	if x != nil {
+		return x.Known
+	}
+	return false
+}
+
+type DistributeConfigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DistributeConfigRequest) Reset() {
+	*x = DistributeConfigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DistributeConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DistributeConfigRequest) ProtoMessage() {}
+
+func (x *DistributeConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DistributeConfigRequest.ProtoReflect.Descriptor instead.
+func (*DistributeConfigRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{20}
+}
+
+type DistributeConfigReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DistributeConfigReply) Reset() {
+	*x = DistributeConfigReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DistributeConfigReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DistributeConfigReply) ProtoMessage() {}
+
+func (x *DistributeConfigReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DistributeConfigReply.ProtoReflect.Descriptor instead.
+func (*DistributeConfigReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{21}
+}
+
+type StartClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CoordinatorDataDir string `protobuf:"bytes,1,opt,name=coordinator_data_dir,json=coordinatorDataDir,proto3" json:"coordinator_data_dir,omitempty"`
+	CoordinatorPort    int32  `protobuf:"varint,2,opt,name=coordinator_port,json=coordinatorPort,proto3" json:"coordinator_port,omitempty"`
+	SkipUnreachable    bool   `protobuf:"varint,3,opt,name=skip_unreachable,json=skipUnreachable,proto3" json:"skip_unreachable,omitempty"` // leave out the segments of hosts whose agent is unreachable instead of failing
+}
+
+func (x *StartClusterRequest) Reset() {
+	*x = StartClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartClusterRequest) ProtoMessage() {}
+
+func (x *StartClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartClusterRequest.ProtoReflect.Descriptor instead.
+func (*StartClusterRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StartClusterRequest) GetCoordinatorDataDir() string {
+	if x != nil {
+		return x.CoordinatorDataDir
+	}
+	return ""
+}
+
+func (x *StartClusterRequest) GetCoordinatorPort() int32 {
+	if x != nil {
+		return x.CoordinatorPort
+	}
+	return 0
+}
+
+func (x *StartClusterRequest) GetSkipUnreachable() bool {
+	if x != nil {
+		return x.SkipUnreachable
+	}
+	return false
+}
+
+type StopClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CoordinatorDataDir string   `protobuf:"bytes,1,opt,name=coordinator_data_dir,json=coordinatorDataDir,proto3" json:"coordinator_data_dir,omitempty"`
+	CoordinatorPort    int32    `protobuf:"varint,2,opt,name=coordinator_port,json=coordinatorPort,proto3" json:"coordinator_port,omitempty"`
+	Mode               StopMode `protobuf:"varint,3,opt,name=mode,proto3,enum=idl.StopMode" json:"mode,omitempty"`
+	SkipUnreachable    bool     `protobuf:"varint,4,opt,name=skip_unreachable,json=skipUnreachable,proto3" json:"skip_unreachable,omitempty"` // leave out the segments of hosts whose agent is unreachable instead of failing
+}
+
+func (x *StopClusterRequest) Reset() {
+	*x = StopClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopClusterRequest) ProtoMessage() {}
+
+func (x *StopClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopClusterRequest.ProtoReflect.Descriptor instead.
+func (*StopClusterRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StopClusterRequest) GetCoordinatorDataDir() string {
+	if x != nil {
+		return x.CoordinatorDataDir
+	}
+	return ""
+}
+
+func (x *StopClusterRequest) GetCoordinatorPort() int32 {
+	if x != nil {
+		return x.CoordinatorPort
+	}
+	return 0
+}
+
+func (x *StopClusterRequest) GetMode() StopMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StopMode_SMART
+}
+
+func (x *StopClusterRequest) GetSkipUnreachable() bool {
+	if x != nil {
+		return x.SkipUnreachable
+	}
+	return false
+}
+
+// RecoverClusterRequest asks the hub to recover the segments marked down in
+// gp_segment_configuration from the segments acting as their primary
+type RecoverClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CoordinatorPort int32   `protobuf:"varint,1,opt,name=coordinator_port,json=coordinatorPort,proto3" json:"coordinator_port,omitempty"`
+	Full            bool    `protobuf:"varint,2,opt,name=full,proto3" json:"full,omitempty"`                                      // copy the data directories instead of rewinding them
+	ContentIds      []int32 `protobuf:"varint,3,rep,packed,name=content_ids,json=contentIds,proto3" json:"content_ids,omitempty"` // recover only the segments of these contents, every down segment when empty
+}
+
+func (x *RecoverClusterRequest) Reset() {
+	*x = RecoverClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecoverClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecoverClusterRequest) ProtoMessage() {}
+
+func (x *RecoverClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecoverClusterRequest.ProtoReflect.Descriptor instead.
+func (*RecoverClusterRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RecoverClusterRequest) GetCoordinatorPort() int32 {
+	if x != nil {
+		return x.CoordinatorPort
+	}
+	return 0
+}
+
+func (x *RecoverClusterRequest) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *RecoverClusterRequest) GetContentIds() []int32 {
+	if x != nil {
+		return x.ContentIds
+	}
+	return nil
+}
+
+// RebalanceClusterRequest asks the hub to have the segments that are not in
+// their preferred role swap roles with the other segment of their content
+type RebalanceClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CoordinatorPort int32 `protobuf:"varint,1,opt,name=coordinator_port,json=coordinatorPort,proto3" json:"coordinator_port,omitempty"`
+	DryRun          bool  `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`          // only report the plan
+	BatchSize       int32 `protobuf:"varint,3,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"` // segments worked on at once on every host, no limit when 0
+}
+
+func (x *RebalanceClusterRequest) Reset() {
+	*x = RebalanceClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RebalanceClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebalanceClusterRequest) ProtoMessage() {}
+
+func (x *RebalanceClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebalanceClusterRequest.ProtoReflect.Descriptor instead.
+func (*RebalanceClusterRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RebalanceClusterRequest) GetCoordinatorPort() int32 {
+	if x != nil {
+		return x.CoordinatorPort
+	}
+	return 0
+}
+
+func (x *RebalanceClusterRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *RebalanceClusterRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+// InitClusterRequest declares a new cluster for the hub to initialize. The data
+// directory of every segment is <directory>/<prefix><content ID>.
+type InitClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Coordinator *CoordinatorSpec `protobuf:"bytes,1,opt,name=coordinator,proto3" json:"coordinator,omitempty"`
+	Segments    *SegmentsSpec    `protobuf:"bytes,2,opt,name=segments,proto3" json:"segments,omitempty"`
+	Locale      string           `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"` // initdb default when empty
+	Encoding    string           `protobuf:"bytes,4,opt,name=encoding,proto3" json:"encoding,omitempty"`
+}
+
+func (x *InitClusterRequest) Reset() {
+	*x = InitClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InitClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitClusterRequest) ProtoMessage() {}
+
+func (x *InitClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitClusterRequest.ProtoReflect.Descriptor instead.
+func (*InitClusterRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *InitClusterRequest) GetCoordinator() *CoordinatorSpec {
+	if x != nil {
+		return x.Coordinator
+	}
+	return nil
+}
+
+func (x *InitClusterRequest) GetSegments() *SegmentsSpec {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+func (x *InitClusterRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *InitClusterRequest) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+type CoordinatorSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hostname  string `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"` // host of the hub
+	Directory string `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	Port      int32  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+}
+
+func (x *CoordinatorSpec) Reset() {
+	*x = CoordinatorSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CoordinatorSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoordinatorSpec) ProtoMessage() {}
+
+func (x *CoordinatorSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoordinatorSpec.ProtoReflect.Descriptor instead.
+func (*CoordinatorSpec) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CoordinatorSpec) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *CoordinatorSpec) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *CoordinatorSpec) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+type SegmentsSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hosts              []string          `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	PrimaryDirectories []string          `protobuf:"bytes,2,rep,name=primary_directories,json=primaryDirectories,proto3" json:"primary_directories,omitempty"` // one primary per directory on every host
+	PrimaryBasePort    int32             `protobuf:"varint,3,opt,name=primary_base_port,json=primaryBasePort,proto3" json:"primary_base_port,omitempty"`
+	MirrorDirectories  []string          `protobuf:"bytes,4,rep,name=mirror_directories,json=mirrorDirectories,proto3" json:"mirror_directories,omitempty"` // as many as primary_directories when mirrored
+	MirrorBasePort     int32             `protobuf:"varint,5,opt,name=mirror_base_port,json=mirrorBasePort,proto3" json:"mirror_base_port,omitempty"`
+	Mirroring          MirroringStrategy `protobuf:"varint,6,opt,name=mirroring,proto3,enum=idl.MirroringStrategy" json:"mirroring,omitempty"`
+	Prefix             string            `protobuf:"bytes,7,opt,name=prefix,proto3" json:"prefix,omitempty"`
+}
+
+func (x *SegmentsSpec) Reset() {
+	*x = SegmentsSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SegmentsSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentsSpec) ProtoMessage() {}
+
+func (x *SegmentsSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentsSpec.ProtoReflect.Descriptor instead.
+func (*SegmentsSpec) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SegmentsSpec) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *SegmentsSpec) GetPrimaryDirectories() []string {
+	if x != nil {
+		return x.PrimaryDirectories
+	}
+	return nil
+}
+
+func (x *SegmentsSpec) GetPrimaryBasePort() int32 {
+	if x != nil {
+		return x.PrimaryBasePort
+	}
+	return 0
+}
+
+func (x *SegmentsSpec) GetMirrorDirectories() []string {
+	if x != nil {
+		return x.MirrorDirectories
+	}
+	return nil
+}
+
+func (x *SegmentsSpec) GetMirrorBasePort() int32 {
+	if x != nil {
+		return x.MirrorBasePort
+	}
+	return 0
+}
+
+func (x *SegmentsSpec) GetMirroring() MirroringStrategy {
+	if x != nil {
+		return x.Mirroring
+	}
+	return MirroringStrategy_NO_MIRRORS
+}
+
+func (x *SegmentsSpec) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+// ExpandClusterRequest asks the hub to add segments to the cluster on new
+// hosts, laid out as on the existing hosts, or to resume such an expansion
+type ExpandClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CoordinatorPort int32    `protobuf:"varint,1,opt,name=coordinator_port,json=coordinatorPort,proto3" json:"coordinator_port,omitempty"`
+	Hosts           []string `protobuf:"bytes,2,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	DryRun          bool     `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // only check and report the plan
+}
+
+func (x *ExpandClusterRequest) Reset() {
+	*x = ExpandClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExpandClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandClusterRequest) ProtoMessage() {}
+
+func (x *ExpandClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandClusterRequest.ProtoReflect.Descriptor instead.
+func (*ExpandClusterRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ExpandClusterRequest) GetCoordinatorPort() int32 {
+	if x != nil {
+		return x.CoordinatorPort
+	}
+	return 0
+}
+
+func (x *ExpandClusterRequest) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *ExpandClusterRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// ReloadAllCredentialsRequest asks the hub to reload its certificates and
+// those of every agent
+type ReloadAllCredentialsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReloadAllCredentialsRequest) Reset() {
+	*x = ReloadAllCredentialsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReloadAllCredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadAllCredentialsRequest) ProtoMessage() {}
+
+func (x *ReloadAllCredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadAllCredentialsRequest.ProtoReflect.Descriptor instead.
+func (*ReloadAllCredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{30}
+}
+
+type ReloadAllCredentialsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HubCertificates []*Certificate   `protobuf:"bytes,1,rep,name=hub_certificates,json=hubCertificates,proto3" json:"hub_certificates,omitempty"`
+	Agents          []*ServiceStatus `protobuf:"bytes,2,rep,name=agents,proto3" json:"agents,omitempty"` // only host and certificates are set
+}
+
+func (x *ReloadAllCredentialsReply) Reset() {
+	*x = ReloadAllCredentialsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_hub_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReloadAllCredentialsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadAllCredentialsReply) ProtoMessage() {}
+
+func (x *ReloadAllCredentialsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_hub_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadAllCredentialsReply.ProtoReflect.Descriptor instead.
+func (*ReloadAllCredentialsReply) Descriptor() ([]byte, []int) {
+	return file_hub_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ReloadAllCredentialsReply) GetHubCertificates() []*Certificate {
+	if x != nil {
+		return x.HubCertificates
+	}
+	return nil
+}
+
+func (x *ReloadAllCredentialsReply) GetAgents() []*ServiceStatus {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+var File_hub_proto protoreflect.FileDescriptor
+
+var file_hub_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x68, 0x75, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x69, 0x64, 0x6c,
+	0x1a, 0x0b, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x95,
+	0x01, 0x0a, 0x08, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2f, 0x0a, 0x08, 0x70,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x69, 0x64, 0x6c, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x23, 0x0a, 0x03,
+	0x6c, 0x6f, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x69, 0x64, 0x6c, 0x2e,
+	0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f,
+	0x67, 0x12, 0x28, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x48, 0x00, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x42, 0x09, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x9e, 0x01, 0x0a, 0x0c, 0x48, 0x6f, 0x73, 0x74, 0x50,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x69, 0x64,
+	0x6c, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x65, 0x74, 0x61, 0x69, 0x6c, 0x22, 0x30, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09,
+	0x53, 0x55, 0x43, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46,
+	0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x02, 0x22, 0x89, 0x01, 0x0a, 0x0a, 0x4c, 0x6f, 0x67, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x4c, 0x6f, 0x67, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x05, 0x6c, 0x65,
+	0x76, 0x65, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x34, 0x0a,
+	0x05, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x00,
+	0x12, 0x0b, 0x0a, 0x07, 0x57, 0x41, 0x52, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x09, 0x0a,
+	0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x44, 0x45, 0x42, 0x55,
+	0x47, 0x10, 0x03, 0x22, 0x59, 0x0a, 0x07, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x09, 0x73, 0x75, 0x63, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x66, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x75,
+	0x0a, 0x0a, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1a, 0x0a, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x35,
+	0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x10, 0x0a, 0x0e, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x75, 0x62,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x70, 0x48,
+	0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x14, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x12, 0x0a,
+	0x10, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x40, 0x0a, 0x13, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41, 0x67, 0x65, 0x6e, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x6b, 0x69, 0x70,
+	0x5f, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x73, 0x6b, 0x69, 0x70, 0x55, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61,
+	0x62, 0x6c, 0x65, 0x22, 0xa1, 0x04, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x34, 0x0a, 0x0c, 0x63,
+	0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x52, 0x0c, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x72, 0x73, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x52, 0x73, 0x73, 0x12, 0x34, 0x0a, 0x08, 0x63,
+	0x70, 0x75, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x63, 0x70, 0x75, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a,
+	0x0f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65,
+	0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63,
+	0x74, 0x69, 0x76, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x75, 0x62,
+	0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x75,
+	0x62, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x37,
+	0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6c,
+	0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x22, 0x43, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x08,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0x3e, 0x0a, 0x11,
+	0x53, 0x74, 0x6f, 0x70, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x63,
+	0x68, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73, 0x6b, 0x69,
+	0x70, 0x55, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x11, 0x0a, 0x0f,
+	0x53, 0x74, 0x6f, 0x70, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x13, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x39, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x67, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x26, 0x0a, 0x06, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x41, 0x67,
+	0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x06, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x22,
+	0xf2, 0x02, 0x0a, 0x09, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a,
+	0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x73, 0x65, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e,
+	0x12, 0x39, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x12, 0x2a, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x29, 0x0a,
+	0x10, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x46, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x09,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x41, 0x67, 0x65, 0x6e, 0x74,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x68, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x22, 0x6e, 0x0a, 0x10, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2e,
+	0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x22, 0x19, 0x0a, 0x17, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x67, 0x6d,
+	0x65, 0x6e, 0x74, 0x48, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0x43, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x6f,
+	0x73, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x6b,
+	0x6e, 0x6f, 0x77, 0x6e, 0x22, 0x1f, 0x0a, 0x17, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4a,
+	0x04, 0x08, 0x01, 0x10, 0x02, 0x22, 0x17, 0x0a, 0x15, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62,
+	0x75, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x9d,
+	0x01, 0x0a, 0x13, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x14, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f,
+	0x72, 0x44, 0x61, 0x74, 0x61, 0x44, 0x69, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x50,
+	0x6f, 0x72, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x75, 0x6e, 0x72, 0x65,
+	0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73,
+	0x6b, 0x69, 0x70, 0x55, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x22, 0xbf,
+	0x01, 0x0a, 0x12, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x14, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e,
+	0x61, 0x74, 0x6f, 0x72, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x12, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72,
+	0x44, 0x61, 0x74, 0x61, 0x44, 0x69, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6f, 0x72, 0x64,
+	0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f,
+	0x72, 0x74, 0x12, 0x21, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0d, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x75, 0x6e,
+	0x72, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x73, 0x6b, 0x69, 0x70, 0x55, 0x6e, 0x72, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65,
+	0x22, 0x77, 0x0a, 0x15, 0x52, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6f,
+	0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72,
+	0x50, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x75, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x04, 0x66, 0x75, 0x6c, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05, 0x52, 0x0a, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x73, 0x22, 0x7c, 0x0a, 0x17, 0x52, 0x65, 0x62,
+	0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61,
+	0x74, 0x6f, 0x72, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f,
+	0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f, 0x72, 0x74, 0x12,
+	0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62, 0x61,
+	0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x22, 0xaf, 0x01, 0x0a, 0x12, 0x49, 0x6e, 0x69, 0x74,
+	0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x36,
+	0x0a, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69,
+	0x6e, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x70, 0x65, 0x63, 0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64,
+	0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x2d, 0x0a, 0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53,
+	0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x70, 0x65, 0x63, 0x52, 0x08, 0x73, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x22, 0x5f, 0x0a, 0x0f, 0x43, 0x6f, 0x6f,
+	0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x70, 0x65, 0x63, 0x12, 0x1a, 0x0a, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x22, 0xa8, 0x02, 0x0a, 0x0c, 0x53,
+	0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x53, 0x70, 0x65, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x68,
+	0x6f, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74,
+	0x73, 0x12, 0x2f, 0x0a, 0x13, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12,
+	0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x69,
+	0x65, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x62, 0x61,
+	0x73, 0x65, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x70,
+	0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x42, 0x61, 0x73, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x2d,
+	0x0a, 0x12, 0x6d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x6d, 0x69, 0x72, 0x72,
+	0x6f, 0x72, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x12, 0x28, 0x0a,
+	0x10, 0x6d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x70, 0x6f, 0x72,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x6d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x42,
+	0x61, 0x73, 0x65, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x34, 0x0a, 0x09, 0x6d, 0x69, 0x72, 0x72, 0x6f,
+	0x72, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x69, 0x64, 0x6c,
+	0x2e, 0x4d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x67, 0x79, 0x52, 0x09, 0x6d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x22, 0x70, 0x0a, 0x14, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x43,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a,
+	0x10, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x6f, 0x72,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e,
+	0x61, 0x74, 0x6f, 0x72, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x17,
+	0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22, 0x1d, 0x0a, 0x1b, 0x52, 0x65, 0x6c, 0x6f, 0x61,
+	0x64, 0x41, 0x6c, 0x6c, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x84, 0x01, 0x0a, 0x19, 0x52, 0x65, 0x6c, 0x6f, 0x61,
+	0x64, 0x41, 0x6c, 0x6c, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x73, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x3b, 0x0a, 0x10, 0x68, 0x75, 0x62, 0x5f, 0x63, 0x65, 0x72, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x52, 0x0f, 0x68, 0x75, 0x62, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x2a, 0x0a, 0x06, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x2a, 0x3a, 0x0a,
+	0x11, 0x4d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x67, 0x79, 0x12, 0x0e, 0x0a, 0x0a, 0x4e, 0x4f, 0x5f, 0x4d, 0x49, 0x52, 0x52, 0x4f, 0x52, 0x53,
+	0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x10, 0x01, 0x12, 0x0a, 0x0a,
+	0x06, 0x53, 0x50, 0x52, 0x45, 0x41, 0x44, 0x10, 0x02, 0x32, 0xea, 0x07, 0x0a, 0x03, 0x48, 0x75,
+	0x62, 0x12, 0x30, 0x0a, 0x04, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x13, 0x2e, 0x69, 0x64, 0x6c, 0x2e,
+	0x53, 0x74, 0x6f, 0x70, 0x48, 0x75, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11,
+	0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x3f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e,
+	0x74, 0x73, 0x12, 0x17, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67,
+	0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x69, 0x64,
+	0x6c, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41, 0x67,
+	0x65, 0x6e, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
+	0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41, 0x67, 0x65, 0x6e, 0x74,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x0a, 0x53, 0x74, 0x6f, 0x70,
+	0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f,
+	0x70, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3b, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x18, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0d, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x30, 0x01, 0x12, 0x39, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x12, 0x17, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x69, 0x64,
+	0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x3f,
+	0x0a, 0x0e, 0x52, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x12, 0x1a, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x69,
+	0x64, 0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12,
+	0x43, 0x0a, 0x10, 0x52, 0x65, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x43, 0x6c, 0x75, 0x73,
+	0x74, 0x65, 0x72, 0x12, 0x1c, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x52, 0x65, 0x62, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x0d, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x30, 0x01, 0x12, 0x39, 0x0a, 0x0b, 0x49, 0x6e, 0x69, 0x74, 0x43, 0x6c, 0x75, 0x73,
+	0x74, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x49, 0x6e, 0x69, 0x74, 0x43, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x69,
+	0x64, 0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12,
+	0x3d, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x12, 0x19, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x43, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x69, 0x64,
+	0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x4e,
+	0x0a, 0x10, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x1c, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62,
+	0x75, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1a, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x57,
+	0x0a, 0x11, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x61, 0x6c, 0x73, 0x12, 0x20, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64,
+	0x41, 0x6c, 0x6c, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x52, 0x65, 0x6c, 0x6f,
+	0x61, 0x64, 0x41, 0x6c, 0x6c, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x41,
+	0x67, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
+	0x69, 0x64, 0x6c, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x48, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x1c, 0x2e, 0x69, 0x64, 0x6c, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x6f, 0x73, 0x74, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x6f, 0x73, 0x74, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3f, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67,
+	0x65, 0x6e, 0x74, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x17, 0x2e, 0x69, 0x64, 0x6c,
+	0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x2e, 0x2f, 0x69, 0x64, 0x6c,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_hub_proto_rawDescOnce sync.Once
+	file_hub_proto_rawDescData = file_hub_proto_rawDesc
+)
+
+func file_hub_proto_rawDescGZIP() []byte {
+	file_hub_proto_rawDescOnce.Do(func() {
+		file_hub_proto_rawDescData = protoimpl.X.CompressGZIP(file_hub_proto_rawDescData)
+	})
+	return file_hub_proto_rawDescData
+}
+
+var file_hub_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_hub_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
+var file_hub_proto_goTypes = []interface{}{
+	(MirroringStrategy)(0),              // 0: idl.MirroringStrategy
+	(HostProgress_Status)(0),            // 1: idl.HostProgress.Status
+	(LogMessage_Level)(0),               // 2: idl.LogMessage.Level
+	(*HubReply)(nil),                    // 3: idl.HubReply
+	(*HostProgress)(nil),                // 4: idl.HostProgress
+	(*LogMessage)(nil),                  // 5: idl.LogMessage
+	(*Summary)(nil),                     // 6: idl.Summary
+	(*HostResult)(nil),                  // 7: idl.HostResult
+	(*StopHubRequest)(nil),              // 8: idl.StopHubRequest
+	(*StopHubReply)(nil),                // 9: idl.StopHubReply
+	(*StartAgentsRequest)(nil),          // 10: idl.StartAgentsRequest
+	(*StartAgentsReply)(nil),            // 11: idl.StartAgentsReply
+	(*StatusAgentsRequest)(nil),         // 12: idl.StatusAgentsRequest
+	(*ServiceStatus)(nil),               // 13: idl.ServiceStatus
+	(*StatusAgentsReply)(nil),           // 14: idl.StatusAgentsReply
+	(*StopAgentsRequest)(nil),           // 15: idl.StopAgentsRequest
+	(*StopAgentsReply)(nil),             // 16: idl.StopAgentsReply
+	(*ListAgentsRequest)(nil),           // 17: idl.ListAgentsRequest
+	(*ListAgentsReply)(nil),             // 18: idl.ListAgentsReply
+	(*AgentInfo)(nil),                   // 19: idl.AgentInfo
+	(*AgentStateChange)(nil),            // 20: idl.AgentStateChange
+	(*ListSegmentHostsRequest)(nil),     // 21: idl.ListSegmentHostsRequest
+	(*ListSegmentHostsReply)(nil),       // 22: idl.ListSegmentHostsReply
+	(*DistributeConfigRequest)(nil),     // 23: idl.DistributeConfigRequest
+	(*DistributeConfigReply)(nil),       // 24: idl.DistributeConfigReply
+	(*StartClusterRequest)(nil),         // 25: idl.StartClusterRequest
+	(*StopClusterRequest)(nil),          // 26: idl.StopClusterRequest
+	(*RecoverClusterRequest)(nil),       // 27: idl.RecoverClusterRequest
+	(*RebalanceClusterRequest)(nil),     // 28: idl.RebalanceClusterRequest
+	(*InitClusterRequest)(nil),          // 29: idl.InitClusterRequest
+	(*CoordinatorSpec)(nil),             // 30: idl.CoordinatorSpec
+	(*SegmentsSpec)(nil),                // 31: idl.SegmentsSpec
+	(*ExpandClusterRequest)(nil),        // 32: idl.ExpandClusterRequest
+	(*ReloadAllCredentialsRequest)(nil), // 33: idl.ReloadAllCredentialsRequest
+	(*ReloadAllCredentialsReply)(nil),   // 34: idl.ReloadAllCredentialsReply
+	(*durationpb.Duration)(nil),         // 35: google.protobuf.Duration
+	(*Certificate)(nil),                 // 36: idl.Certificate
+	(*timestamppb.Timestamp)(nil),       // 37: google.protobuf.Timestamp
+	(StopMode)(0),                       // 38: idl.StopMode
+}
+var file_hub_proto_depIdxs = []int32{
+	4,  // 0: idl.HubReply.progress:type_name -> idl.HostProgress
+	5,  // 1: idl.HubReply.log:type_name -> idl.LogMessage
+	6,  // 2: idl.HubReply.summary:type_name -> idl.Summary
+	1,  // 3: idl.HostProgress.status:type_name -> idl.HostProgress.Status
+	2,  // 4: idl.LogMessage.level:type_name -> idl.LogMessage.Level
+	35, // 5: idl.HostResult.duration:type_name -> google.protobuf.Duration
+	36, // 6: idl.ServiceStatus.certificates:type_name -> idl.Certificate
+	37, // 7: idl.ServiceStatus.start_time:type_name -> google.protobuf.Timestamp
+	35, // 8: idl.ServiceStatus.cpu_time:type_name -> google.protobuf.Duration
+	37, // 9: idl.ServiceStatus.last_seen:type_name -> google.protobuf.Timestamp
+	13, // 10: idl.StatusAgentsReply.statuses:type_name -> idl.ServiceStatus
+	19, // 11: idl.ListAgentsReply.agents:type_name -> idl.AgentInfo
+	37, // 12: idl.AgentInfo.last_seen:type_name -> google.protobuf.Timestamp
+	37, // 13: idl.AgentInfo.last_check:type_name -> google.protobuf.Timestamp
+	13, // 14: idl.AgentInfo.status:type_name -> idl.ServiceStatus
+	20, // 15: idl.AgentInfo.history:type_name -> idl.AgentStateChange
+	37, // 16: idl.AgentStateChange.time:type_name -> google.protobuf.Timestamp
+	38, // 17: idl.StopClusterRequest.mode:type_name -> idl.StopMode
+	30, // 18: idl.InitClusterRequest.coordinator:type_name -> idl.CoordinatorSpec
+	31, // 19: idl.InitClusterRequest.segments:type_name -> idl.SegmentsSpec
+	0,  // 20: idl.SegmentsSpec.mirroring:type_name -> idl.MirroringStrategy
+	36, // 21: idl.ReloadAllCredentialsReply.hub_certificates:type_name -> idl.Certificate
+	13, // 22: idl.ReloadAllCredentialsReply.agents:type_name -> idl.ServiceStatus
+	8,  // 23: idl.Hub.Stop:input_type -> idl.StopHubRequest
+	10, // 24: idl.Hub.StartAgents:input_type -> idl.StartAgentsRequest
+	12, // 25: idl.Hub.StatusAgents:input_type -> idl.StatusAgentsRequest
+	15, // 26: idl.Hub.StopAgents:input_type -> idl.StopAgentsRequest
+	25, // 27: idl.Hub.StartCluster:input_type -> idl.StartClusterRequest
+	26, // 28: idl.Hub.StopCluster:input_type -> idl.StopClusterRequest
+	27, // 29: idl.Hub.RecoverCluster:input_type -> idl.RecoverClusterRequest
+	28, // 30: idl.Hub.RebalanceCluster:input_type -> idl.RebalanceClusterRequest
+	29, // 31: idl.Hub.InitCluster:input_type -> idl.InitClusterRequest
+	32, // 32: idl.Hub.ExpandCluster:input_type -> idl.ExpandClusterRequest
+	23, // 33: idl.Hub.DistributeConfig:input_type -> idl.DistributeConfigRequest
+	33, // 34: idl.Hub.ReloadCredentials:input_type -> idl.ReloadAllCredentialsRequest
+	17, // 35: idl.Hub.ListAgents:input_type -> idl.ListAgentsRequest
+	21, // 36: idl.Hub.ListSegmentHosts:input_type -> idl.ListSegmentHostsRequest
+	10, // 37: idl.Hub.StartAgentsStream:input_type -> idl.StartAgentsRequest
+	9,  // 38: idl.Hub.Stop:output_type -> idl.StopHubReply
+	11, // 39: idl.Hub.StartAgents:output_type -> idl.StartAgentsReply
+	14, // 40: idl.Hub.StatusAgents:output_type -> idl.StatusAgentsReply
+	16, // 41: idl.Hub.StopAgents:output_type -> idl.StopAgentsReply
+	3,  // 42: idl.Hub.StartCluster:output_type -> idl.HubReply
+	3,  // 43: idl.Hub.StopCluster:output_type -> idl.HubReply
+	3,  // 44: idl.Hub.RecoverCluster:output_type -> idl.HubReply
+	3,  // 45: idl.Hub.RebalanceCluster:output_type -> idl.HubReply
+	3,  // 46: idl.Hub.InitCluster:output_type -> idl.HubReply
+	3,  // 47: idl.Hub.ExpandCluster:output_type -> idl.HubReply
+	24, // 48: idl.Hub.DistributeConfig:output_type -> idl.DistributeConfigReply
+	34, // 49: idl.Hub.ReloadCredentials:output_type -> idl.ReloadAllCredentialsReply
+	18, // 50: idl.Hub.ListAgents:output_type -> idl.ListAgentsReply
+	22, // 51: idl.Hub.ListSegmentHosts:output_type -> idl.ListSegmentHostsReply
+	3,  // 52: idl.Hub.StartAgentsStream:output_type -> idl.HubReply
+	38, // [38:53] is the sub-list for method output_type
+	23, // [23:38] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_hub_proto_init() }
+func file_hub_proto_init() {
+	if File_hub_proto != nil {
+		return
+	}
+	file_agent_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_hub_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HubReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostProgress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Summary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HostResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopHubRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopHubReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartAgentsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartAgentsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusAgentsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServiceStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusAgentsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopAgentsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopAgentsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListAgentsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListAgentsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AgentInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AgentStateChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSegmentHostsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSegmentHostsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DistributeConfigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use StopAgentsReply.ProtoReflect.Descriptor instead.
-func (*StopAgentsReply) Descriptor() ([]byte, []int) {
-	return file_hub_proto_rawDescGZIP(), []int{8}
-}
-
-var File_hub_proto protoreflect.FileDescriptor
-
-var file_hub_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x68, 0x75, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x69, 0x64, 0x6c,
-	0x22, 0x10, 0x0a, 0x0e, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x75, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x14, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x12, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x72,
-	0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x15, 0x0a, 0x13,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x22, 0x65, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x06, 0x75, 0x70, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x70, 0x69, 0x64, 0x22, 0x43, 0x0a, 0x11, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
-	0x2e, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x12, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22,
-	0x13, 0x0a, 0x11, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x22, 0x11, 0x0a, 0x0f, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x67, 0x65, 0x6e,
-	0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x32, 0xfa, 0x01, 0x0a, 0x03, 0x48, 0x75, 0x62, 0x12,
-	0x30, 0x0a, 0x04, 0x53, 0x74, 0x6f, 0x70, 0x12, 0x13, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74,
-	0x6f, 0x70, 0x48, 0x75, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x69,
-	0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x48, 0x75, 0x62, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
-	0x00, 0x12, 0x3f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73,
-	0x12, 0x17, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e,
-	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x69, 0x64, 0x6c, 0x2e,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x22, 0x00, 0x12, 0x42, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41, 0x67, 0x65, 0x6e,
-	0x74, 0x73, 0x12, 0x18, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41,
-	0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x69,
-	0x64, 0x6c, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x0a, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x67,
-	0x65, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x69, 0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x41,
-	0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x69,
-	0x64, 0x6c, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x00, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x2e, 0x2f, 0x69, 0x64, 0x6c, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
-
-var (
-	file_hub_proto_rawDescOnce sync.Once
-	file_hub_proto_rawDescData = file_hub_proto_rawDesc
-)
-
-func file_hub_proto_rawDescGZIP() []byte {
-	file_hub_proto_rawDescOnce.Do(func() {
-		file_hub_proto_rawDescData = protoimpl.X.CompressGZIP(file_hub_proto_rawDescData)
-	})
-	return file_hub_proto_rawDescData
-}
-
-var file_hub_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
-var file_hub_proto_goTypes = []interface{}{
-	(*StopHubRequest)(nil),      // 0: idl.StopHubRequest
-	(*StopHubReply)(nil),        // 1: idl.StopHubReply
-	(*StartAgentsRequest)(nil),  // 2: idl.StartAgentsRequest
-	(*StartAgentsReply)(nil),    // 3: idl.StartAgentsReply
-	(*StatusAgentsRequest)(nil), // 4: idl.StatusAgentsRequest
-	(*ServiceStatus)(nil),       // 5: idl.ServiceStatus
-	(*StatusAgentsReply)(nil),   // 6: idl.StatusAgentsReply
-	(*StopAgentsRequest)(nil),   // 7: idl.StopAgentsRequest
-	(*StopAgentsReply)(nil),     // 8: idl.StopAgentsReply
-}
-var file_hub_proto_depIdxs = []int32{
-	5, // 0: idl.StatusAgentsReply.statuses:type_name -> idl.ServiceStatus
-	0, // 1: idl.Hub.Stop:input_type -> idl.StopHubRequest
-	2, // 2: idl.Hub.StartAgents:input_type -> idl.StartAgentsRequest
-	4, // 3: idl.Hub.StatusAgents:input_type -> idl.StatusAgentsRequest
-	7, // 4: idl.Hub.StopAgents:input_type -> idl.StopAgentsRequest
-	1, // 5: idl.Hub.Stop:output_type -> idl.StopHubReply
-	3, // 6: idl.Hub.StartAgents:output_type -> idl.StartAgentsReply
-	6, // 7: idl.Hub.StatusAgents:output_type -> idl.StatusAgentsReply
-	8, // 8: idl.Hub.StopAgents:output_type -> idl.StopAgentsReply
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
-}
-
-func init() { file_hub_proto_init() }
-func file_hub_proto_init() {
-	if File_hub_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_hub_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopHubRequest); i {
+		file_hub_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DistributeConfigReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -510,8 +2795,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopHubReply); i {
+		file_hub_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartClusterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -522,8 +2807,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StartAgentsRequest); i {
+		file_hub_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopClusterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -534,8 +2819,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StartAgentsReply); i {
+		file_hub_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecoverClusterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -546,8 +2831,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StatusAgentsRequest); i {
+		file_hub_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RebalanceClusterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -558,8 +2843,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ServiceStatus); i {
+		file_hub_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InitClusterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -570,8 +2855,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StatusAgentsReply); i {
+		file_hub_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CoordinatorSpec); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -582,8 +2867,8 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopAgentsRequest); i {
+		file_hub_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SegmentsSpec); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -594,8 +2879,32 @@ func file_hub_proto_init() {
 				return nil
 			}
 		}
-		file_hub_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopAgentsReply); i {
+		file_hub_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExpandClusterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReloadAllCredentialsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_hub_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReloadAllCredentialsReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -607,94 +2916,384 @@ func file_hub_proto_init() {
 			}
 		}
 	}
-	type x struct{}
-	out := protoimpl.TypeBuilder{
-		File: protoimpl.DescBuilder{
-			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_hub_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   9,
-			NumExtensions: 0,
-			NumServices:   1,
-		},
-		GoTypes:           file_hub_proto_goTypes,
-		DependencyIndexes: file_hub_proto_depIdxs,
-		MessageInfos:      file_hub_proto_msgTypes,
-	}.Build()
-	File_hub_proto = out.File
-	file_hub_proto_rawDesc = nil
-	file_hub_proto_goTypes = nil
-	file_hub_proto_depIdxs = nil
+	file_hub_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*HubReply_Progress)(nil),
+		(*HubReply_Log)(nil),
+		(*HubReply_Summary)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_hub_proto_rawDesc,
+			NumEnums:      3,
+			NumMessages:   32,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_hub_proto_goTypes,
+		DependencyIndexes: file_hub_proto_depIdxs,
+		EnumInfos:         file_hub_proto_enumTypes,
+		MessageInfos:      file_hub_proto_msgTypes,
+	}.Build()
+	File_hub_proto = out.File
+	file_hub_proto_rawDesc = nil
+	file_hub_proto_goTypes = nil
+	file_hub_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// HubClient is the client API for Hub service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type HubClient interface {
+	Stop(ctx context.Context, in *StopHubRequest, opts ...grpc.CallOption) (*StopHubReply, error)
+	StartAgents(ctx context.Context, in *StartAgentsRequest, opts ...grpc.CallOption) (*StartAgentsReply, error)
+	StatusAgents(ctx context.Context, in *StatusAgentsRequest, opts ...grpc.CallOption) (*StatusAgentsReply, error)
+	StopAgents(ctx context.Context, in *StopAgentsRequest, opts ...grpc.CallOption) (*StopAgentsReply, error)
+	StartCluster(ctx context.Context, in *StartClusterRequest, opts ...grpc.CallOption) (Hub_StartClusterClient, error)
+	StopCluster(ctx context.Context, in *StopClusterRequest, opts ...grpc.CallOption) (Hub_StopClusterClient, error)
+	RecoverCluster(ctx context.Context, in *RecoverClusterRequest, opts ...grpc.CallOption) (Hub_RecoverClusterClient, error)
+	RebalanceCluster(ctx context.Context, in *RebalanceClusterRequest, opts ...grpc.CallOption) (Hub_RebalanceClusterClient, error)
+	InitCluster(ctx context.Context, in *InitClusterRequest, opts ...grpc.CallOption) (Hub_InitClusterClient, error)
+	ExpandCluster(ctx context.Context, in *ExpandClusterRequest, opts ...grpc.CallOption) (Hub_ExpandClusterClient, error)
+	DistributeConfig(ctx context.Context, in *DistributeConfigRequest, opts ...grpc.CallOption) (*DistributeConfigReply, error)
+	ReloadCredentials(ctx context.Context, in *ReloadAllCredentialsRequest, opts ...grpc.CallOption) (*ReloadAllCredentialsReply, error)
+	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsReply, error)
+	ListSegmentHosts(ctx context.Context, in *ListSegmentHostsRequest, opts ...grpc.CallOption) (*ListSegmentHostsReply, error)
+	// Streaming variants of the above, reporting progress as each host is done
+	StartAgentsStream(ctx context.Context, in *StartAgentsRequest, opts ...grpc.CallOption) (Hub_StartAgentsStreamClient, error)
+}
+
+type hubClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHubClient(cc grpc.ClientConnInterface) HubClient {
+	return &hubClient{cc}
+}
+
+func (c *hubClient) Stop(ctx context.Context, in *StopHubRequest, opts ...grpc.CallOption) (*StopHubReply, error) {
+	out := new(StopHubReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubClient) StartAgents(ctx context.Context, in *StartAgentsRequest, opts ...grpc.CallOption) (*StartAgentsReply, error) {
+	out := new(StartAgentsReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/StartAgents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubClient) StatusAgents(ctx context.Context, in *StatusAgentsRequest, opts ...grpc.CallOption) (*StatusAgentsReply, error) {
+	out := new(StatusAgentsReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/StatusAgents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubClient) StopAgents(ctx context.Context, in *StopAgentsRequest, opts ...grpc.CallOption) (*StopAgentsReply, error) {
+	out := new(StopAgentsReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/StopAgents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hubClient) StartCluster(ctx context.Context, in *StartClusterRequest, opts ...grpc.CallOption) (Hub_StartClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[0], "/idl.Hub/StartCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubStartClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Hub_StartClusterClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
+
+type hubStartClusterClient struct {
+	grpc.ClientStream
+}
+
+func (x *hubStartClusterClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hubClient) StopCluster(ctx context.Context, in *StopClusterRequest, opts ...grpc.CallOption) (Hub_StopClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[1], "/idl.Hub/StopCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubStopClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Hub_StopClusterClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
+
+type hubStopClusterClient struct {
+	grpc.ClientStream
+}
+
+func (x *hubStopClusterClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hubClient) RecoverCluster(ctx context.Context, in *RecoverClusterRequest, opts ...grpc.CallOption) (Hub_RecoverClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[2], "/idl.Hub/RecoverCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubRecoverClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Hub_RecoverClusterClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
+
+type hubRecoverClusterClient struct {
+	grpc.ClientStream
+}
+
+func (x *hubRecoverClusterClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConnInterface
+func (c *hubClient) RebalanceCluster(ctx context.Context, in *RebalanceClusterRequest, opts ...grpc.CallOption) (Hub_RebalanceClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[3], "/idl.Hub/RebalanceCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubRebalanceClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion6
+type Hub_RebalanceClusterClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
 
-// HubClient is the client API for Hub service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type HubClient interface {
-	Stop(ctx context.Context, in *StopHubRequest, opts ...grpc.CallOption) (*StopHubReply, error)
-	StartAgents(ctx context.Context, in *StartAgentsRequest, opts ...grpc.CallOption) (*StartAgentsReply, error)
-	StatusAgents(ctx context.Context, in *StatusAgentsRequest, opts ...grpc.CallOption) (*StatusAgentsReply, error)
-	StopAgents(ctx context.Context, in *StopAgentsRequest, opts ...grpc.CallOption) (*StopAgentsReply, error)
+type hubRebalanceClusterClient struct {
+	grpc.ClientStream
 }
 
-type hubClient struct {
-	cc grpc.ClientConnInterface
+func (x *hubRebalanceClusterClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func NewHubClient(cc grpc.ClientConnInterface) HubClient {
-	return &hubClient{cc}
+func (c *hubClient) InitCluster(ctx context.Context, in *InitClusterRequest, opts ...grpc.CallOption) (Hub_InitClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[4], "/idl.Hub/InitCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubInitClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *hubClient) Stop(ctx context.Context, in *StopHubRequest, opts ...grpc.CallOption) (*StopHubReply, error) {
-	out := new(StopHubReply)
-	err := c.cc.Invoke(ctx, "/idl.Hub/Stop", in, out, opts...)
+type Hub_InitClusterClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
+
+type hubInitClusterClient struct {
+	grpc.ClientStream
+}
+
+func (x *hubInitClusterClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hubClient) ExpandCluster(ctx context.Context, in *ExpandClusterRequest, opts ...grpc.CallOption) (Hub_ExpandClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[5], "/idl.Hub/ExpandCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubExpandClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Hub_ExpandClusterClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
+
+type hubExpandClusterClient struct {
+	grpc.ClientStream
+}
+
+func (x *hubExpandClusterClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hubClient) DistributeConfig(ctx context.Context, in *DistributeConfigRequest, opts ...grpc.CallOption) (*DistributeConfigReply, error) {
+	out := new(DistributeConfigReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/DistributeConfig", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *hubClient) StartAgents(ctx context.Context, in *StartAgentsRequest, opts ...grpc.CallOption) (*StartAgentsReply, error) {
-	out := new(StartAgentsReply)
-	err := c.cc.Invoke(ctx, "/idl.Hub/StartAgents", in, out, opts...)
+func (c *hubClient) ReloadCredentials(ctx context.Context, in *ReloadAllCredentialsRequest, opts ...grpc.CallOption) (*ReloadAllCredentialsReply, error) {
+	out := new(ReloadAllCredentialsReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/ReloadCredentials", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *hubClient) StatusAgents(ctx context.Context, in *StatusAgentsRequest, opts ...grpc.CallOption) (*StatusAgentsReply, error) {
-	out := new(StatusAgentsReply)
-	err := c.cc.Invoke(ctx, "/idl.Hub/StatusAgents", in, out, opts...)
+func (c *hubClient) ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsReply, error) {
+	out := new(ListAgentsReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/ListAgents", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *hubClient) StopAgents(ctx context.Context, in *StopAgentsRequest, opts ...grpc.CallOption) (*StopAgentsReply, error) {
-	out := new(StopAgentsReply)
-	err := c.cc.Invoke(ctx, "/idl.Hub/StopAgents", in, out, opts...)
+func (c *hubClient) ListSegmentHosts(ctx context.Context, in *ListSegmentHostsRequest, opts ...grpc.CallOption) (*ListSegmentHostsReply, error) {
+	out := new(ListSegmentHostsReply)
+	err := c.cc.Invoke(ctx, "/idl.Hub/ListSegmentHosts", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
+func (c *hubClient) StartAgentsStream(ctx context.Context, in *StartAgentsRequest, opts ...grpc.CallOption) (Hub_StartAgentsStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Hub_serviceDesc.Streams[6], "/idl.Hub/StartAgentsStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hubStartAgentsStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Hub_StartAgentsStreamClient interface {
+	Recv() (*HubReply, error)
+	grpc.ClientStream
+}
+
+type hubStartAgentsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *hubStartAgentsStreamClient) Recv() (*HubReply, error) {
+	m := new(HubReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // HubServer is the server API for Hub service.
 type HubServer interface {
 	Stop(context.Context, *StopHubRequest) (*StopHubReply, error)
 	StartAgents(context.Context, *StartAgentsRequest) (*StartAgentsReply, error)
 	StatusAgents(context.Context, *StatusAgentsRequest) (*StatusAgentsReply, error)
 	StopAgents(context.Context, *StopAgentsRequest) (*StopAgentsReply, error)
+	StartCluster(*StartClusterRequest, Hub_StartClusterServer) error
+	StopCluster(*StopClusterRequest, Hub_StopClusterServer) error
+	RecoverCluster(*RecoverClusterRequest, Hub_RecoverClusterServer) error
+	RebalanceCluster(*RebalanceClusterRequest, Hub_RebalanceClusterServer) error
+	InitCluster(*InitClusterRequest, Hub_InitClusterServer) error
+	ExpandCluster(*ExpandClusterRequest, Hub_ExpandClusterServer) error
+	DistributeConfig(context.Context, *DistributeConfigRequest) (*DistributeConfigReply, error)
+	ReloadCredentials(context.Context, *ReloadAllCredentialsRequest) (*ReloadAllCredentialsReply, error)
+	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsReply, error)
+	ListSegmentHosts(context.Context, *ListSegmentHostsRequest) (*ListSegmentHostsReply, error)
+	// Streaming variants of the above, reporting progress as each host is done
+	StartAgentsStream(*StartAgentsRequest, Hub_StartAgentsStreamServer) error
 }
 
 // UnimplementedHubServer can be embedded to have forward compatible implementations.
@@ -713,6 +3312,39 @@ func (*UnimplementedHubServer) StatusAgents(context.Context, *StatusAgentsReques
 func (*UnimplementedHubServer) StopAgents(context.Context, *StopAgentsRequest) (*StopAgentsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StopAgents not implemented")
 }
+func (*UnimplementedHubServer) StartCluster(*StartClusterRequest, Hub_StartClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method StartCluster not implemented")
+}
+func (*UnimplementedHubServer) StopCluster(*StopClusterRequest, Hub_StopClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method StopCluster not implemented")
+}
+func (*UnimplementedHubServer) RecoverCluster(*RecoverClusterRequest, Hub_RecoverClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method RecoverCluster not implemented")
+}
+func (*UnimplementedHubServer) RebalanceCluster(*RebalanceClusterRequest, Hub_RebalanceClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method RebalanceCluster not implemented")
+}
+func (*UnimplementedHubServer) InitCluster(*InitClusterRequest, Hub_InitClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method InitCluster not implemented")
+}
+func (*UnimplementedHubServer) ExpandCluster(*ExpandClusterRequest, Hub_ExpandClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExpandCluster not implemented")
+}
+func (*UnimplementedHubServer) DistributeConfig(context.Context, *DistributeConfigRequest) (*DistributeConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DistributeConfig not implemented")
+}
+func (*UnimplementedHubServer) ReloadCredentials(context.Context, *ReloadAllCredentialsRequest) (*ReloadAllCredentialsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadCredentials not implemented")
+}
+func (*UnimplementedHubServer) ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (*UnimplementedHubServer) ListSegmentHosts(context.Context, *ListSegmentHostsRequest) (*ListSegmentHostsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSegmentHosts not implemented")
+}
+func (*UnimplementedHubServer) StartAgentsStream(*StartAgentsRequest, Hub_StartAgentsStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method StartAgentsStream not implemented")
+}
 
 func RegisterHubServer(s *grpc.Server, srv HubServer) {
 	s.RegisterService(&_Hub_serviceDesc, srv)
@@ -790,6 +3422,225 @@ func _Hub_StopAgents_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Hub_StartCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StartClusterRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).StartCluster(m, &hubStartClusterServer{stream})
+}
+
+type Hub_StartClusterServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubStartClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubStartClusterServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Hub_StopCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StopClusterRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).StopCluster(m, &hubStopClusterServer{stream})
+}
+
+type Hub_StopClusterServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubStopClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubStopClusterServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Hub_RecoverCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RecoverClusterRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).RecoverCluster(m, &hubRecoverClusterServer{stream})
+}
+
+type Hub_RecoverClusterServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubRecoverClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubRecoverClusterServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Hub_RebalanceCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RebalanceClusterRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).RebalanceCluster(m, &hubRebalanceClusterServer{stream})
+}
+
+type Hub_RebalanceClusterServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubRebalanceClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubRebalanceClusterServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Hub_InitCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InitClusterRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).InitCluster(m, &hubInitClusterServer{stream})
+}
+
+type Hub_InitClusterServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubInitClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubInitClusterServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Hub_ExpandCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExpandClusterRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).ExpandCluster(m, &hubExpandClusterServer{stream})
+}
+
+type Hub_ExpandClusterServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubExpandClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubExpandClusterServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Hub_DistributeConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DistributeConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubServer).DistributeConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Hub/DistributeConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubServer).DistributeConfig(ctx, req.(*DistributeConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hub_ReloadCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadAllCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubServer).ReloadCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Hub/ReloadCredentials",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubServer).ReloadCredentials(ctx, req.(*ReloadAllCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hub_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Hub/ListAgents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubServer).ListAgents(ctx, req.(*ListAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hub_ListSegmentHosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSegmentHostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HubServer).ListSegmentHosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/idl.Hub/ListSegmentHosts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HubServer).ListSegmentHosts(ctx, req.(*ListSegmentHostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hub_StartAgentsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StartAgentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HubServer).StartAgentsStream(m, &hubStartAgentsStreamServer{stream})
+}
+
+type Hub_StartAgentsStreamServer interface {
+	Send(*HubReply) error
+	grpc.ServerStream
+}
+
+type hubStartAgentsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *hubStartAgentsStreamServer) Send(m *HubReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Hub_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "idl.Hub",
 	HandlerType: (*HubServer)(nil),
@@ -810,7 +3661,59 @@ var _Hub_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopAgents",
 			Handler:    _Hub_StopAgents_Handler,
 		},
+		{
+			MethodName: "DistributeConfig",
+			Handler:    _Hub_DistributeConfig_Handler,
+		},
+		{
+			MethodName: "ReloadCredentials",
+			Handler:    _Hub_ReloadCredentials_Handler,
+		},
+		{
+			MethodName: "ListAgents",
+			Handler:    _Hub_ListAgents_Handler,
+		},
+		{
+			MethodName: "ListSegmentHosts",
+			Handler:    _Hub_ListSegmentHosts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StartCluster",
+			Handler:       _Hub_StartCluster_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StopCluster",
+			Handler:       _Hub_StopCluster_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RecoverCluster",
+			Handler:       _Hub_RecoverCluster_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RebalanceCluster",
+			Handler:       _Hub_RebalanceCluster_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "InitCluster",
+			Handler:       _Hub_InitCluster_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExpandCluster",
+			Handler:       _Hub_ExpandCluster_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StartAgentsStream",
+			Handler:       _Hub_StartAgentsStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "hub.proto",
 }
@@ -11,8 +11,44 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	idl "github.com/greenplum-db/gpdb/gp/idl"
 	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
 )
 
+// MockisPushFileRequest_Request is a mock of isPushFileRequest_Request interface.
+type MockisPushFileRequest_Request struct {
+	ctrl     *gomock.Controller
+	recorder *MockisPushFileRequest_RequestMockRecorder
+}
+
+// MockisPushFileRequest_RequestMockRecorder is the mock recorder for MockisPushFileRequest_Request.
+type MockisPushFileRequest_RequestMockRecorder struct {
+	mock *MockisPushFileRequest_Request
+}
+
+// NewMockisPushFileRequest_Request creates a new mock instance.
+func NewMockisPushFileRequest_Request(ctrl *gomock.Controller) *MockisPushFileRequest_Request {
+	mock := &MockisPushFileRequest_Request{ctrl: ctrl}
+	mock.recorder = &MockisPushFileRequest_RequestMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockisPushFileRequest_Request) EXPECT() *MockisPushFileRequest_RequestMockRecorder {
+	return m.recorder
+}
+
+// isPushFileRequest_Request mocks base method.
+func (m *MockisPushFileRequest_Request) isPushFileRequest_Request() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "isPushFileRequest_Request")
+}
+
+// isPushFileRequest_Request indicates an expected call of isPushFileRequest_Request.
+func (mr *MockisPushFileRequest_RequestMockRecorder) isPushFileRequest_Request() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "isPushFileRequest_Request", reflect.TypeOf((*MockisPushFileRequest_Request)(nil).isPushFileRequest_Request))
+}
+
 // MockAgentClient is a mock of AgentClient interface.
 type MockAgentClient struct {
 	ctrl     *gomock.Controller
@@ -36,6 +72,126 @@ func (m *MockAgentClient) EXPECT() *MockAgentClientMockRecorder {
 	return m.recorder
 }
 
+// InitSegment mocks base method.
+func (m *MockAgentClient) InitSegment(ctx context.Context, in *idl.InitSegmentRequest, opts ...grpc.CallOption) (*idl.InitSegmentReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InitSegment", varargs...)
+	ret0, _ := ret[0].(*idl.InitSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InitSegment indicates an expected call of InitSegment.
+func (mr *MockAgentClientMockRecorder) InitSegment(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitSegment", reflect.TypeOf((*MockAgentClient)(nil).InitSegment), varargs...)
+}
+
+// PushFile mocks base method.
+func (m *MockAgentClient) PushFile(ctx context.Context, opts ...grpc.CallOption) (idl.Agent_PushFileClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PushFile", varargs...)
+	ret0, _ := ret[0].(idl.Agent_PushFileClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushFile indicates an expected call of PushFile.
+func (mr *MockAgentClientMockRecorder) PushFile(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushFile", reflect.TypeOf((*MockAgentClient)(nil).PushFile), varargs...)
+}
+
+// RecoverSegment mocks base method.
+func (m *MockAgentClient) RecoverSegment(ctx context.Context, in *idl.RecoverSegmentRequest, opts ...grpc.CallOption) (idl.Agent_RecoverSegmentClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RecoverSegment", varargs...)
+	ret0, _ := ret[0].(idl.Agent_RecoverSegmentClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecoverSegment indicates an expected call of RecoverSegment.
+func (mr *MockAgentClientMockRecorder) RecoverSegment(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverSegment", reflect.TypeOf((*MockAgentClient)(nil).RecoverSegment), varargs...)
+}
+
+// ReloadCredentials mocks base method.
+func (m *MockAgentClient) ReloadCredentials(ctx context.Context, in *idl.ReloadCredentialsRequest, opts ...grpc.CallOption) (*idl.ReloadCredentialsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReloadCredentials", varargs...)
+	ret0, _ := ret[0].(*idl.ReloadCredentialsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadCredentials indicates an expected call of ReloadCredentials.
+func (mr *MockAgentClientMockRecorder) ReloadCredentials(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadCredentials", reflect.TypeOf((*MockAgentClient)(nil).ReloadCredentials), varargs...)
+}
+
+// RemoveSegment mocks base method.
+func (m *MockAgentClient) RemoveSegment(ctx context.Context, in *idl.RemoveSegmentRequest, opts ...grpc.CallOption) (*idl.RemoveSegmentReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveSegment", varargs...)
+	ret0, _ := ret[0].(*idl.RemoveSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveSegment indicates an expected call of RemoveSegment.
+func (mr *MockAgentClientMockRecorder) RemoveSegment(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSegment", reflect.TypeOf((*MockAgentClient)(nil).RemoveSegment), varargs...)
+}
+
+// StartSegment mocks base method.
+func (m *MockAgentClient) StartSegment(ctx context.Context, in *idl.StartSegmentRequest, opts ...grpc.CallOption) (*idl.StartSegmentReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StartSegment", varargs...)
+	ret0, _ := ret[0].(*idl.StartSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSegment indicates an expected call of StartSegment.
+func (mr *MockAgentClientMockRecorder) StartSegment(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSegment", reflect.TypeOf((*MockAgentClient)(nil).StartSegment), varargs...)
+}
+
 // Status mocks base method.
 func (m *MockAgentClient) Status(ctx context.Context, in *idl.StatusAgentRequest, opts ...grpc.CallOption) (*idl.StatusAgentReply, error) {
 	m.ctrl.T.Helper()
@@ -76,55 +232,967 @@ func (mr *MockAgentClientMockRecorder) Stop(ctx, in interface{}, opts ...interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockAgentClient)(nil).Stop), varargs...)
 }
 
-// MockAgentServer is a mock of AgentServer interface.
-type MockAgentServer struct {
+// StopSegment mocks base method.
+func (m *MockAgentClient) StopSegment(ctx context.Context, in *idl.StopSegmentRequest, opts ...grpc.CallOption) (*idl.StopSegmentReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StopSegment", varargs...)
+	ret0, _ := ret[0].(*idl.StopSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopSegment indicates an expected call of StopSegment.
+func (mr *MockAgentClientMockRecorder) StopSegment(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSegment", reflect.TypeOf((*MockAgentClient)(nil).StopSegment), varargs...)
+}
+
+// WatchSegments mocks base method.
+func (m *MockAgentClient) WatchSegments(ctx context.Context, in *idl.WatchSegmentsRequest, opts ...grpc.CallOption) (idl.Agent_WatchSegmentsClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WatchSegments", varargs...)
+	ret0, _ := ret[0].(idl.Agent_WatchSegmentsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchSegments indicates an expected call of WatchSegments.
+func (mr *MockAgentClientMockRecorder) WatchSegments(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchSegments", reflect.TypeOf((*MockAgentClient)(nil).WatchSegments), varargs...)
+}
+
+// MockAgent_PushFileClient is a mock of Agent_PushFileClient interface.
+type MockAgent_PushFileClient struct {
 	ctrl     *gomock.Controller
-	recorder *MockAgentServerMockRecorder
+	recorder *MockAgent_PushFileClientMockRecorder
 }
 
-// MockAgentServerMockRecorder is the mock recorder for MockAgentServer.
-type MockAgentServerMockRecorder struct {
-	mock *MockAgentServer
+// MockAgent_PushFileClientMockRecorder is the mock recorder for MockAgent_PushFileClient.
+type MockAgent_PushFileClientMockRecorder struct {
+	mock *MockAgent_PushFileClient
 }
 
-// NewMockAgentServer creates a new mock instance.
-func NewMockAgentServer(ctrl *gomock.Controller) *MockAgentServer {
-	mock := &MockAgentServer{ctrl: ctrl}
-	mock.recorder = &MockAgentServerMockRecorder{mock}
+// NewMockAgent_PushFileClient creates a new mock instance.
+func NewMockAgent_PushFileClient(ctrl *gomock.Controller) *MockAgent_PushFileClient {
+	mock := &MockAgent_PushFileClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_PushFileClientMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockAgentServer) EXPECT() *MockAgentServerMockRecorder {
+func (m *MockAgent_PushFileClient) EXPECT() *MockAgent_PushFileClientMockRecorder {
 	return m.recorder
 }
 
-// Status mocks base method.
-func (m *MockAgentServer) Status(arg0 context.Context, arg1 *idl.StatusAgentRequest) (*idl.StatusAgentReply, error) {
+// CloseAndRecv mocks base method.
+func (m *MockAgent_PushFileClient) CloseAndRecv() (*idl.PushFileReply, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Status", arg0, arg1)
-	ret0, _ := ret[0].(*idl.StatusAgentReply)
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*idl.PushFileReply)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Status indicates an expected call of Status.
-func (mr *MockAgentServerMockRecorder) Status(arg0, arg1 interface{}) *gomock.Call {
+// CloseAndRecv indicates an expected call of CloseAndRecv.
+func (mr *MockAgent_PushFileClientMockRecorder) CloseAndRecv() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockAgentServer)(nil).Status), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockAgent_PushFileClient)(nil).CloseAndRecv))
 }
 
-// Stop mocks base method.
-func (m *MockAgentServer) Stop(arg0 context.Context, arg1 *idl.StopAgentRequest) (*idl.StopAgentReply, error) {
+// CloseSend mocks base method.
+func (m *MockAgent_PushFileClient) CloseSend() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Stop", arg0, arg1)
-	ret0, _ := ret[0].(*idl.StopAgentReply)
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAgent_PushFileClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_PushFileClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAgent_PushFileClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgent_PushFileClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_PushFileClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAgent_PushFileClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Stop indicates an expected call of Stop.
-func (mr *MockAgentServerMockRecorder) Stop(arg0, arg1 interface{}) *gomock.Call {
+// Header indicates an expected call of Header.
+func (mr *MockAgent_PushFileClientMockRecorder) Header() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockAgentServer)(nil).Stop), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_PushFileClient)(nil).Header))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgent_PushFileClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgent_PushFileClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_PushFileClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAgent_PushFileClient) Send(arg0 *idl.PushFileRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAgent_PushFileClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_PushFileClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgent_PushFileClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgent_PushFileClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_PushFileClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAgent_PushFileClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAgent_PushFileClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_PushFileClient)(nil).Trailer))
+}
+
+// MockAgent_WatchSegmentsClient is a mock of Agent_WatchSegmentsClient interface.
+type MockAgent_WatchSegmentsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_WatchSegmentsClientMockRecorder
+}
+
+// MockAgent_WatchSegmentsClientMockRecorder is the mock recorder for MockAgent_WatchSegmentsClient.
+type MockAgent_WatchSegmentsClientMockRecorder struct {
+	mock *MockAgent_WatchSegmentsClient
+}
+
+// NewMockAgent_WatchSegmentsClient creates a new mock instance.
+func NewMockAgent_WatchSegmentsClient(ctrl *gomock.Controller) *MockAgent_WatchSegmentsClient {
+	mock := &MockAgent_WatchSegmentsClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_WatchSegmentsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgent_WatchSegmentsClient) EXPECT() *MockAgent_WatchSegmentsClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockAgent_WatchSegmentsClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAgent_WatchSegmentsClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAgent_WatchSegmentsClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockAgent_WatchSegmentsClient) Recv() (*idl.SegmentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.SegmentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgent_WatchSegmentsClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgent_WatchSegmentsClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAgent_WatchSegmentsClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAgent_WatchSegmentsClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_WatchSegmentsClient)(nil).Trailer))
+}
+
+// MockAgent_RecoverSegmentClient is a mock of Agent_RecoverSegmentClient interface.
+type MockAgent_RecoverSegmentClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_RecoverSegmentClientMockRecorder
+}
+
+// MockAgent_RecoverSegmentClientMockRecorder is the mock recorder for MockAgent_RecoverSegmentClient.
+type MockAgent_RecoverSegmentClientMockRecorder struct {
+	mock *MockAgent_RecoverSegmentClient
+}
+
+// NewMockAgent_RecoverSegmentClient creates a new mock instance.
+func NewMockAgent_RecoverSegmentClient(ctrl *gomock.Controller) *MockAgent_RecoverSegmentClient {
+	mock := &MockAgent_RecoverSegmentClient{ctrl: ctrl}
+	mock.recorder = &MockAgent_RecoverSegmentClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgent_RecoverSegmentClient) EXPECT() *MockAgent_RecoverSegmentClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockAgent_RecoverSegmentClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockAgent_RecoverSegmentClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockAgent_RecoverSegmentClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockAgent_RecoverSegmentClient) Recv() (*idl.RecoverSegmentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.RecoverSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgent_RecoverSegmentClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgent_RecoverSegmentClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockAgent_RecoverSegmentClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockAgent_RecoverSegmentClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockAgent_RecoverSegmentClient)(nil).Trailer))
+}
+
+// MockAgentServer is a mock of AgentServer interface.
+type MockAgentServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentServerMockRecorder
+}
+
+// MockAgentServerMockRecorder is the mock recorder for MockAgentServer.
+type MockAgentServerMockRecorder struct {
+	mock *MockAgentServer
+}
+
+// NewMockAgentServer creates a new mock instance.
+func NewMockAgentServer(ctrl *gomock.Controller) *MockAgentServer {
+	mock := &MockAgentServer{ctrl: ctrl}
+	mock.recorder = &MockAgentServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgentServer) EXPECT() *MockAgentServerMockRecorder {
+	return m.recorder
+}
+
+// InitSegment mocks base method.
+func (m *MockAgentServer) InitSegment(arg0 context.Context, arg1 *idl.InitSegmentRequest) (*idl.InitSegmentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitSegment", arg0, arg1)
+	ret0, _ := ret[0].(*idl.InitSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InitSegment indicates an expected call of InitSegment.
+func (mr *MockAgentServerMockRecorder) InitSegment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitSegment", reflect.TypeOf((*MockAgentServer)(nil).InitSegment), arg0, arg1)
+}
+
+// PushFile mocks base method.
+func (m *MockAgentServer) PushFile(arg0 idl.Agent_PushFileServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushFile", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PushFile indicates an expected call of PushFile.
+func (mr *MockAgentServerMockRecorder) PushFile(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushFile", reflect.TypeOf((*MockAgentServer)(nil).PushFile), arg0)
+}
+
+// RecoverSegment mocks base method.
+func (m *MockAgentServer) RecoverSegment(arg0 *idl.RecoverSegmentRequest, arg1 idl.Agent_RecoverSegmentServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverSegment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverSegment indicates an expected call of RecoverSegment.
+func (mr *MockAgentServerMockRecorder) RecoverSegment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverSegment", reflect.TypeOf((*MockAgentServer)(nil).RecoverSegment), arg0, arg1)
+}
+
+// ReloadCredentials mocks base method.
+func (m *MockAgentServer) ReloadCredentials(arg0 context.Context, arg1 *idl.ReloadCredentialsRequest) (*idl.ReloadCredentialsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadCredentials", arg0, arg1)
+	ret0, _ := ret[0].(*idl.ReloadCredentialsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadCredentials indicates an expected call of ReloadCredentials.
+func (mr *MockAgentServerMockRecorder) ReloadCredentials(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadCredentials", reflect.TypeOf((*MockAgentServer)(nil).ReloadCredentials), arg0, arg1)
+}
+
+// RemoveSegment mocks base method.
+func (m *MockAgentServer) RemoveSegment(arg0 context.Context, arg1 *idl.RemoveSegmentRequest) (*idl.RemoveSegmentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSegment", arg0, arg1)
+	ret0, _ := ret[0].(*idl.RemoveSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveSegment indicates an expected call of RemoveSegment.
+func (mr *MockAgentServerMockRecorder) RemoveSegment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSegment", reflect.TypeOf((*MockAgentServer)(nil).RemoveSegment), arg0, arg1)
+}
+
+// StartSegment mocks base method.
+func (m *MockAgentServer) StartSegment(arg0 context.Context, arg1 *idl.StartSegmentRequest) (*idl.StartSegmentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSegment", arg0, arg1)
+	ret0, _ := ret[0].(*idl.StartSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSegment indicates an expected call of StartSegment.
+func (mr *MockAgentServerMockRecorder) StartSegment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSegment", reflect.TypeOf((*MockAgentServer)(nil).StartSegment), arg0, arg1)
+}
+
+// Status mocks base method.
+func (m *MockAgentServer) Status(arg0 context.Context, arg1 *idl.StatusAgentRequest) (*idl.StatusAgentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", arg0, arg1)
+	ret0, _ := ret[0].(*idl.StatusAgentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockAgentServerMockRecorder) Status(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockAgentServer)(nil).Status), arg0, arg1)
+}
+
+// Stop mocks base method.
+func (m *MockAgentServer) Stop(arg0 context.Context, arg1 *idl.StopAgentRequest) (*idl.StopAgentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", arg0, arg1)
+	ret0, _ := ret[0].(*idl.StopAgentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockAgentServerMockRecorder) Stop(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockAgentServer)(nil).Stop), arg0, arg1)
+}
+
+// StopSegment mocks base method.
+func (m *MockAgentServer) StopSegment(arg0 context.Context, arg1 *idl.StopSegmentRequest) (*idl.StopSegmentReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopSegment", arg0, arg1)
+	ret0, _ := ret[0].(*idl.StopSegmentReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopSegment indicates an expected call of StopSegment.
+func (mr *MockAgentServerMockRecorder) StopSegment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSegment", reflect.TypeOf((*MockAgentServer)(nil).StopSegment), arg0, arg1)
+}
+
+// WatchSegments mocks base method.
+func (m *MockAgentServer) WatchSegments(arg0 *idl.WatchSegmentsRequest, arg1 idl.Agent_WatchSegmentsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchSegments", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchSegments indicates an expected call of WatchSegments.
+func (mr *MockAgentServerMockRecorder) WatchSegments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchSegments", reflect.TypeOf((*MockAgentServer)(nil).WatchSegments), arg0, arg1)
+}
+
+// MockAgent_PushFileServer is a mock of Agent_PushFileServer interface.
+type MockAgent_PushFileServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_PushFileServerMockRecorder
+}
+
+// MockAgent_PushFileServerMockRecorder is the mock recorder for MockAgent_PushFileServer.
+type MockAgent_PushFileServerMockRecorder struct {
+	mock *MockAgent_PushFileServer
+}
+
+// NewMockAgent_PushFileServer creates a new mock instance.
+func NewMockAgent_PushFileServer(ctrl *gomock.Controller) *MockAgent_PushFileServer {
+	mock := &MockAgent_PushFileServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_PushFileServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgent_PushFileServer) EXPECT() *MockAgent_PushFileServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAgent_PushFileServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgent_PushFileServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_PushFileServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockAgent_PushFileServer) Recv() (*idl.PushFileRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*idl.PushFileRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockAgent_PushFileServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockAgent_PushFileServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgent_PushFileServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgent_PushFileServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_PushFileServer)(nil).RecvMsg), m)
+}
+
+// SendAndClose mocks base method.
+func (m *MockAgent_PushFileServer) SendAndClose(arg0 *idl.PushFileReply) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose.
+func (mr *MockAgent_PushFileServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockAgent_PushFileServer)(nil).SendAndClose), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAgent_PushFileServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAgent_PushFileServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_PushFileServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgent_PushFileServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgent_PushFileServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_PushFileServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAgent_PushFileServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAgent_PushFileServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_PushFileServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAgent_PushFileServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAgent_PushFileServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_PushFileServer)(nil).SetTrailer), arg0)
+}
+
+// MockAgent_WatchSegmentsServer is a mock of Agent_WatchSegmentsServer interface.
+type MockAgent_WatchSegmentsServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_WatchSegmentsServerMockRecorder
+}
+
+// MockAgent_WatchSegmentsServerMockRecorder is the mock recorder for MockAgent_WatchSegmentsServer.
+type MockAgent_WatchSegmentsServerMockRecorder struct {
+	mock *MockAgent_WatchSegmentsServer
+}
+
+// NewMockAgent_WatchSegmentsServer creates a new mock instance.
+func NewMockAgent_WatchSegmentsServer(ctrl *gomock.Controller) *MockAgent_WatchSegmentsServer {
+	mock := &MockAgent_WatchSegmentsServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_WatchSegmentsServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgent_WatchSegmentsServer) EXPECT() *MockAgent_WatchSegmentsServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAgent_WatchSegmentsServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgent_WatchSegmentsServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAgent_WatchSegmentsServer) Send(arg0 *idl.SegmentEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAgent_WatchSegmentsServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgent_WatchSegmentsServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAgent_WatchSegmentsServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAgent_WatchSegmentsServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAgent_WatchSegmentsServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_WatchSegmentsServer)(nil).SetTrailer), arg0)
+}
+
+// MockAgent_RecoverSegmentServer is a mock of Agent_RecoverSegmentServer interface.
+type MockAgent_RecoverSegmentServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgent_RecoverSegmentServerMockRecorder
+}
+
+// MockAgent_RecoverSegmentServerMockRecorder is the mock recorder for MockAgent_RecoverSegmentServer.
+type MockAgent_RecoverSegmentServerMockRecorder struct {
+	mock *MockAgent_RecoverSegmentServer
+}
+
+// NewMockAgent_RecoverSegmentServer creates a new mock instance.
+func NewMockAgent_RecoverSegmentServer(ctrl *gomock.Controller) *MockAgent_RecoverSegmentServer {
+	mock := &MockAgent_RecoverSegmentServer{ctrl: ctrl}
+	mock.recorder = &MockAgent_RecoverSegmentServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgent_RecoverSegmentServer) EXPECT() *MockAgent_RecoverSegmentServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockAgent_RecoverSegmentServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockAgent_RecoverSegmentServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockAgent_RecoverSegmentServer) Send(arg0 *idl.RecoverSegmentReply) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockAgent_RecoverSegmentServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockAgent_RecoverSegmentServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockAgent_RecoverSegmentServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockAgent_RecoverSegmentServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockAgent_RecoverSegmentServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockAgent_RecoverSegmentServer)(nil).SetTrailer), arg0)
 }
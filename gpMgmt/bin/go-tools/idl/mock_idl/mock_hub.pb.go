@@ -36,6 +36,166 @@ func (m *MockHubClient) EXPECT() *MockHubClientMockRecorder {
 	return m.recorder
 }
 
+// DistributeConfig mocks base method.
+func (m *MockHubClient) DistributeConfig(arg0 context.Context, arg1 *idl.DistributeConfigRequest, arg2 ...grpc.CallOption) (*idl.DistributeConfigReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeConfig", varargs...)
+	ret0, _ := ret[0].(*idl.DistributeConfigReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DistributeConfig indicates an expected call of DistributeConfig.
+func (mr *MockHubClientMockRecorder) DistributeConfig(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeConfig", reflect.TypeOf((*MockHubClient)(nil).DistributeConfig), varargs...)
+}
+
+// ExpandCluster mocks base method.
+func (m *MockHubClient) ExpandCluster(arg0 context.Context, arg1 *idl.ExpandClusterRequest, arg2 ...grpc.CallOption) (idl.Hub_ExpandClusterClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExpandCluster", varargs...)
+	ret0, _ := ret[0].(idl.Hub_ExpandClusterClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpandCluster indicates an expected call of ExpandCluster.
+func (mr *MockHubClientMockRecorder) ExpandCluster(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpandCluster", reflect.TypeOf((*MockHubClient)(nil).ExpandCluster), varargs...)
+}
+
+// InitCluster mocks base method.
+func (m *MockHubClient) InitCluster(arg0 context.Context, arg1 *idl.InitClusterRequest, arg2 ...grpc.CallOption) (idl.Hub_InitClusterClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InitCluster", varargs...)
+	ret0, _ := ret[0].(idl.Hub_InitClusterClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InitCluster indicates an expected call of InitCluster.
+func (mr *MockHubClientMockRecorder) InitCluster(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitCluster", reflect.TypeOf((*MockHubClient)(nil).InitCluster), varargs...)
+}
+
+// ListAgents mocks base method.
+func (m *MockHubClient) ListAgents(arg0 context.Context, arg1 *idl.ListAgentsRequest, arg2 ...grpc.CallOption) (*idl.ListAgentsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListAgents", varargs...)
+	ret0, _ := ret[0].(*idl.ListAgentsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAgents indicates an expected call of ListAgents.
+func (mr *MockHubClientMockRecorder) ListAgents(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAgents", reflect.TypeOf((*MockHubClient)(nil).ListAgents), varargs...)
+}
+
+// ListSegmentHosts mocks base method.
+func (m *MockHubClient) ListSegmentHosts(arg0 context.Context, arg1 *idl.ListSegmentHostsRequest, arg2 ...grpc.CallOption) (*idl.ListSegmentHostsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListSegmentHosts", varargs...)
+	ret0, _ := ret[0].(*idl.ListSegmentHostsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSegmentHosts indicates an expected call of ListSegmentHosts.
+func (mr *MockHubClientMockRecorder) ListSegmentHosts(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSegmentHosts", reflect.TypeOf((*MockHubClient)(nil).ListSegmentHosts), varargs...)
+}
+
+// RebalanceCluster mocks base method.
+func (m *MockHubClient) RebalanceCluster(arg0 context.Context, arg1 *idl.RebalanceClusterRequest, arg2 ...grpc.CallOption) (idl.Hub_RebalanceClusterClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RebalanceCluster", varargs...)
+	ret0, _ := ret[0].(idl.Hub_RebalanceClusterClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RebalanceCluster indicates an expected call of RebalanceCluster.
+func (mr *MockHubClientMockRecorder) RebalanceCluster(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebalanceCluster", reflect.TypeOf((*MockHubClient)(nil).RebalanceCluster), varargs...)
+}
+
+// RecoverCluster mocks base method.
+func (m *MockHubClient) RecoverCluster(arg0 context.Context, arg1 *idl.RecoverClusterRequest, arg2 ...grpc.CallOption) (idl.Hub_RecoverClusterClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RecoverCluster", varargs...)
+	ret0, _ := ret[0].(idl.Hub_RecoverClusterClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecoverCluster indicates an expected call of RecoverCluster.
+func (mr *MockHubClientMockRecorder) RecoverCluster(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverCluster", reflect.TypeOf((*MockHubClient)(nil).RecoverCluster), varargs...)
+}
+
+// ReloadCredentials mocks base method.
+func (m *MockHubClient) ReloadCredentials(arg0 context.Context, arg1 *idl.ReloadAllCredentialsRequest, arg2 ...grpc.CallOption) (*idl.ReloadAllCredentialsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReloadCredentials", varargs...)
+	ret0, _ := ret[0].(*idl.ReloadAllCredentialsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadCredentials indicates an expected call of ReloadCredentials.
+func (mr *MockHubClientMockRecorder) ReloadCredentials(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadCredentials", reflect.TypeOf((*MockHubClient)(nil).ReloadCredentials), varargs...)
+}
+
 // StartAgents mocks base method.
 func (m *MockHubClient) StartAgents(arg0 context.Context, arg1 *idl.StartAgentsRequest, arg2 ...grpc.CallOption) (*idl.StartAgentsReply, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +216,46 @@ func (mr *MockHubClientMockRecorder) StartAgents(arg0, arg1 interface{}, arg2 ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAgents", reflect.TypeOf((*MockHubClient)(nil).StartAgents), varargs...)
 }
 
+// StartAgentsStream mocks base method.
+func (m *MockHubClient) StartAgentsStream(arg0 context.Context, arg1 *idl.StartAgentsRequest, arg2 ...grpc.CallOption) (idl.Hub_StartAgentsStreamClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StartAgentsStream", varargs...)
+	ret0, _ := ret[0].(idl.Hub_StartAgentsStreamClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartAgentsStream indicates an expected call of StartAgentsStream.
+func (mr *MockHubClientMockRecorder) StartAgentsStream(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAgentsStream", reflect.TypeOf((*MockHubClient)(nil).StartAgentsStream), varargs...)
+}
+
+// StartCluster mocks base method.
+func (m *MockHubClient) StartCluster(arg0 context.Context, arg1 *idl.StartClusterRequest, arg2 ...grpc.CallOption) (idl.Hub_StartClusterClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StartCluster", varargs...)
+	ret0, _ := ret[0].(idl.Hub_StartClusterClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartCluster indicates an expected call of StartCluster.
+func (mr *MockHubClientMockRecorder) StartCluster(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartCluster", reflect.TypeOf((*MockHubClient)(nil).StartCluster), varargs...)
+}
+
 // StatusAgents mocks base method.
 func (m *MockHubClient) StatusAgents(arg0 context.Context, arg1 *idl.StatusAgentsRequest, arg2 ...grpc.CallOption) (*idl.StatusAgentsReply, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +316,26 @@ func (mr *MockHubClientMockRecorder) StopAgents(arg0, arg1 interface{}, arg2 ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopAgents", reflect.TypeOf((*MockHubClient)(nil).StopAgents), varargs...)
 }
 
+// StopCluster mocks base method.
+func (m *MockHubClient) StopCluster(arg0 context.Context, arg1 *idl.StopClusterRequest, arg2 ...grpc.CallOption) (idl.Hub_StopClusterClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StopCluster", varargs...)
+	ret0, _ := ret[0].(idl.Hub_StopClusterClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopCluster indicates an expected call of StopCluster.
+func (mr *MockHubClientMockRecorder) StopCluster(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopCluster", reflect.TypeOf((*MockHubClient)(nil).StopCluster), varargs...)
+}
+
 // MockHubServer is a mock of HubServer interface.
 type MockHubServer struct {
 	ctrl     *gomock.Controller
@@ -139,6 +359,122 @@ func (m *MockHubServer) EXPECT() *MockHubServerMockRecorder {
 	return m.recorder
 }
 
+// DistributeConfig mocks base method.
+func (m *MockHubServer) DistributeConfig(arg0 context.Context, arg1 *idl.DistributeConfigRequest) (*idl.DistributeConfigReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DistributeConfig", arg0, arg1)
+	ret0, _ := ret[0].(*idl.DistributeConfigReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DistributeConfig indicates an expected call of DistributeConfig.
+func (mr *MockHubServerMockRecorder) DistributeConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeConfig", reflect.TypeOf((*MockHubServer)(nil).DistributeConfig), arg0, arg1)
+}
+
+// ExpandCluster mocks base method.
+func (m *MockHubServer) ExpandCluster(arg0 *idl.ExpandClusterRequest, arg1 idl.Hub_ExpandClusterServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpandCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpandCluster indicates an expected call of ExpandCluster.
+func (mr *MockHubServerMockRecorder) ExpandCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpandCluster", reflect.TypeOf((*MockHubServer)(nil).ExpandCluster), arg0, arg1)
+}
+
+// InitCluster mocks base method.
+func (m *MockHubServer) InitCluster(arg0 *idl.InitClusterRequest, arg1 idl.Hub_InitClusterServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitCluster indicates an expected call of InitCluster.
+func (mr *MockHubServerMockRecorder) InitCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitCluster", reflect.TypeOf((*MockHubServer)(nil).InitCluster), arg0, arg1)
+}
+
+// ListAgents mocks base method.
+func (m *MockHubServer) ListAgents(arg0 context.Context, arg1 *idl.ListAgentsRequest) (*idl.ListAgentsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAgents", arg0, arg1)
+	ret0, _ := ret[0].(*idl.ListAgentsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAgents indicates an expected call of ListAgents.
+func (mr *MockHubServerMockRecorder) ListAgents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAgents", reflect.TypeOf((*MockHubServer)(nil).ListAgents), arg0, arg1)
+}
+
+// ListSegmentHosts mocks base method.
+func (m *MockHubServer) ListSegmentHosts(arg0 context.Context, arg1 *idl.ListSegmentHostsRequest) (*idl.ListSegmentHostsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSegmentHosts", arg0, arg1)
+	ret0, _ := ret[0].(*idl.ListSegmentHostsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSegmentHosts indicates an expected call of ListSegmentHosts.
+func (mr *MockHubServerMockRecorder) ListSegmentHosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSegmentHosts", reflect.TypeOf((*MockHubServer)(nil).ListSegmentHosts), arg0, arg1)
+}
+
+// RebalanceCluster mocks base method.
+func (m *MockHubServer) RebalanceCluster(arg0 *idl.RebalanceClusterRequest, arg1 idl.Hub_RebalanceClusterServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebalanceCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebalanceCluster indicates an expected call of RebalanceCluster.
+func (mr *MockHubServerMockRecorder) RebalanceCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebalanceCluster", reflect.TypeOf((*MockHubServer)(nil).RebalanceCluster), arg0, arg1)
+}
+
+// RecoverCluster mocks base method.
+func (m *MockHubServer) RecoverCluster(arg0 *idl.RecoverClusterRequest, arg1 idl.Hub_RecoverClusterServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverCluster indicates an expected call of RecoverCluster.
+func (mr *MockHubServerMockRecorder) RecoverCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverCluster", reflect.TypeOf((*MockHubServer)(nil).RecoverCluster), arg0, arg1)
+}
+
+// ReloadCredentials mocks base method.
+func (m *MockHubServer) ReloadCredentials(arg0 context.Context, arg1 *idl.ReloadAllCredentialsRequest) (*idl.ReloadAllCredentialsReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadCredentials", arg0, arg1)
+	ret0, _ := ret[0].(*idl.ReloadAllCredentialsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadCredentials indicates an expected call of ReloadCredentials.
+func (mr *MockHubServerMockRecorder) ReloadCredentials(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadCredentials", reflect.TypeOf((*MockHubServer)(nil).ReloadCredentials), arg0, arg1)
+}
+
 // StartAgents mocks base method.
 func (m *MockHubServer) StartAgents(arg0 context.Context, arg1 *idl.StartAgentsRequest) (*idl.StartAgentsReply, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +490,34 @@ func (mr *MockHubServerMockRecorder) StartAgents(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAgents", reflect.TypeOf((*MockHubServer)(nil).StartAgents), arg0, arg1)
 }
 
+// StartAgentsStream mocks base method.
+func (m *MockHubServer) StartAgentsStream(arg0 *idl.StartAgentsRequest, arg1 idl.Hub_StartAgentsStreamServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartAgentsStream", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartAgentsStream indicates an expected call of StartAgentsStream.
+func (mr *MockHubServerMockRecorder) StartAgentsStream(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAgentsStream", reflect.TypeOf((*MockHubServer)(nil).StartAgentsStream), arg0, arg1)
+}
+
+// StartCluster mocks base method.
+func (m *MockHubServer) StartCluster(arg0 *idl.StartClusterRequest, arg1 idl.Hub_StartClusterServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartCluster indicates an expected call of StartCluster.
+func (mr *MockHubServerMockRecorder) StartCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartCluster", reflect.TypeOf((*MockHubServer)(nil).StartCluster), arg0, arg1)
+}
+
 // StatusAgents mocks base method.
 func (m *MockHubServer) StatusAgents(arg0 context.Context, arg1 *idl.StatusAgentsRequest) (*idl.StatusAgentsReply, error) {
 	m.ctrl.T.Helper()
@@ -198,3 +562,17 @@ func (mr *MockHubServerMockRecorder) StopAgents(arg0, arg1 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopAgents", reflect.TypeOf((*MockHubServer)(nil).StopAgents), arg0, arg1)
 }
+
+// StopCluster mocks base method.
+func (m *MockHubServer) StopCluster(arg0 *idl.StopClusterRequest, arg1 idl.Hub_StopClusterServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopCluster indicates an expected call of StopCluster.
+func (mr *MockHubServerMockRecorder) StopCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopCluster", reflect.TypeOf((*MockHubServer)(nil).StopCluster), arg0, arg1)
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	grpcStatus "google.golang.org/grpc/status"
+)
+
+const Namespace = "gp"
+
+// Metrics exports the metrics of a service on an HTTP /metrics endpoint for
+// Prometheus to scrape
+type Metrics struct {
+	port      int
+	registry  *prometheus.Registry
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+
+	mutex    sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// New returns the metrics of the service, or nil when the port is 0 so that
+// the service exports none. The Go runtime and process metrics are included.
+func New(port int, service string) *Metrics {
+	if port == 0 {
+		return nil
+	}
+
+	labels := prometheus.Labels{"service": service}
+	m := &Metrics{
+		port:     port,
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   Namespace,
+			Name:        "grpc_requests_total",
+			Help:        "Number of gRPC requests handled, by method and status code.",
+			ConstLabels: labels,
+		}, []string{"method", "code"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   Namespace,
+			Name:        "grpc_request_duration_seconds",
+			Help:        "Time taken to handle the gRPC requests, by method.",
+			ConstLabels: labels,
+			Buckets:     []float64{0.005, 0.025, 0.1, 0.5, 1, 5, 30, 120, 600},
+		}, []string{"method"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.durations,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// MustRegister adds collectors of service specific metrics
+func (m *Metrics) MustRegister(collectors ...prometheus.Collector) {
+	if m == nil {
+		return
+	}
+
+	m.registry.MustRegister(collectors...)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Start listens on the metrics port and serves /metrics in the background
+// until Stop is called
+func (m *Metrics) Start() error {
+	if m == nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", m.port))
+	if err != nil {
+		return fmt.Errorf("could not listen on metrics port %d: %w", m.port, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	m.mutex.Lock()
+	m.server = server
+	m.listener = listener
+	m.mutex.Unlock()
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			gplog.Warn("Metrics server stopped: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop closes the metrics listener
+func (m *Metrics) Stop() {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.server != nil {
+		m.server.Close()
+	}
+}
+
+// Addr returns the address the metrics are served on, once started
+func (m *Metrics) Addr() net.Addr {
+	if m == nil {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.listener == nil {
+		return nil
+	}
+
+	return m.listener.Addr()
+}
+
+func (m *Metrics) observe(method string, start time.Time, err error) {
+	m.requests.WithLabelValues(method, grpcStatus.Code(err).String()).Inc()
+	m.durations.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// UnaryInterceptor counts and times the unary requests
+func (m *Metrics) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if m == nil {
+		return handler(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observe(info.FullMethod, start, err)
+
+	return resp, err
+}
+
+// StreamInterceptor counts and times the streaming requests, from the start
+// to the end of the stream
+func (m *Metrics) StreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if m == nil {
+		return handler(srv, stream)
+	}
+
+	start := time.Now()
+	err := handler(srv, stream)
+	m.observe(info.FullMethod, start, err)
+
+	return err
+}
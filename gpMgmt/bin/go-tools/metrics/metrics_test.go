@@ -0,0 +1,133 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+
+	"github.com/greenplum-db/gpdb/gp/metrics"
+)
+
+// render returns the metrics in the Prometheus text format
+func render(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	return recorder.Body.String()
+}
+
+func TestInterceptors(t *testing.T) {
+	t.Run("counts and times the requests by method and code", func(t *testing.T) {
+		m := metrics.New(1234, "agent")
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/idl.Agent/Stop"}
+		for _, err := range []error{nil, nil, grpcStatus.Error(codes.PermissionDenied, "not allowed")} {
+			err := err
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, err
+			}
+			_, result := m.UnaryInterceptor(context.Background(), nil, info, handler)
+			if result != err {
+				t.Fatalf("got %v, want %v", result, err)
+			}
+		}
+
+		handler := func(srv interface{}, stream grpc.ServerStream) error {
+			return errors.New("stream failed")
+		}
+		_ = m.StreamInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/idl.Agent/PushFile"}, handler)
+
+		output := render(t, m)
+		for _, expected := range []string{
+			`gp_grpc_requests_total{code="OK",method="/idl.Agent/Stop",service="agent"} 2`,
+			`gp_grpc_requests_total{code="PermissionDenied",method="/idl.Agent/Stop",service="agent"} 1`,
+			`gp_grpc_requests_total{code="Unknown",method="/idl.Agent/PushFile",service="agent"} 1`,
+			`gp_grpc_request_duration_seconds_count{method="/idl.Agent/Stop",service="agent"} 3`,
+			`go_goroutines `,
+		} {
+			if !strings.Contains(output, expected) {
+				t.Fatalf("expected %q in the metrics:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("only calls the handler when the metrics are disabled", func(t *testing.T) {
+		m := metrics.New(0, "agent")
+		if m != nil {
+			t.Fatalf("expected no metrics")
+		}
+
+		called := false
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		}
+		_, err := m.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+		if err != nil || !called {
+			t.Fatalf("expected the handler to be called, got %v", err)
+		}
+
+		err = m.Start()
+		if err != nil || m.Addr() != nil {
+			t.Fatalf("expected nothing to be served, got %v", err)
+		}
+		m.Stop()
+	})
+}
+
+func TestStart(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	t.Run("errors out when the port is in use", func(t *testing.T) {
+		m := metrics.New(port, "hub")
+
+		err := m.Start()
+		if err == nil || !strings.HasPrefix(err.Error(), "could not listen on metrics port") {
+			t.Fatalf("got %v, want a listen error", err)
+		}
+	})
+
+	t.Run("serves the metrics until stopped", func(t *testing.T) {
+		listener.Close()
+		m := metrics.New(port, "hub")
+
+		err := m.Start()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		resp, err := http.Get("http://" + m.Addr().String() + "/metrics")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "process_start_time_seconds") {
+			t.Fatalf("got %d %s, want the process metrics", resp.StatusCode, body)
+		}
+
+		m.Stop()
+		_, err = http.Get("http://" + m.Addr().String() + "/metrics")
+		if err == nil {
+			t.Fatalf("expected the metrics to no longer be served")
+		}
+	})
+}
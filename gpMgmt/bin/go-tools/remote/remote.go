@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Executor runs commands and copies files on a set of hosts in parallel
+type Executor interface {
+	// Run runs the command through the login shell of every host
+	Run(hostnames []string, command string) Results
+	// Copy writes contents to path with the given mode on every host. The file
+	// is written next to path first and then renamed, so that a failed copy
+	// never leaves a partial file behind.
+	Copy(hostnames []string, contents []byte, path string, mode os.FileMode) Results
+}
+
+// Result is the outcome of a command on a single host. Err is set when the
+// command could not be run or exited with a non-zero status, in which case
+// ExitCode is the exit status, or -1 if there was none.
+type Result struct {
+	Hostname string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Err      error
+	Duration time.Duration
+}
+
+type Results []Result
+
+// Failed returns the results of the hosts on which the command failed
+func (results Results) Failed() Results {
+	failed := make(Results, 0)
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Err returns an error naming every host on which the command failed, or nil
+// if it succeeded everywhere
+func (results Results) Err() error {
+	failed := results.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(failed))
+	for _, result := range failed {
+		messages = append(messages, fmt.Sprintf("host %s: %v", result.Hostname, result.Err))
+	}
+
+	return fmt.Errorf("failed on %d of %d hosts: %s", len(failed), len(results), strings.Join(messages, "; "))
+}
+
+// Command joins the arguments into a command line for the remote shell,
+// quoting each of them
+func Command(args ...string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, Quote(arg))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// Quote quotes s for a POSIX shell
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@%+,") == "" {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
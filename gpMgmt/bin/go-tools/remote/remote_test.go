@@ -0,0 +1,17 @@
+package remote_test
+
+import (
+	"testing"
+
+	"github.com/greenplum-db/gpdb/gp/remote"
+)
+
+func TestCommand(t *testing.T) {
+	t.Run("quotes only the arguments that need it", func(t *testing.T) {
+		result := remote.Command("systemctl", "--user", "start", "gp_agent", "/path/with space", "it's", "")
+		expected := `systemctl --user start gp_agent '/path/with space' 'it'\''s' ''`
+		if result != expected {
+			t.Fatalf("got %s, want %s", result, expected)
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Config describes how to reach the hosts over SSH
+type Config struct {
+	User            string
+	Port            int
+	Timeout         time.Duration // for each command, including connecting to the host
+	Auth            []ssh.AuthMethod
+	HostKeyCallback ssh.HostKeyCallback
+}
+
+// DefaultConfig connects as the current user with the keys found in ~/.ssh and
+// the SSH agent, if any, verifying the hosts against ~/.ssh/known_hosts. This
+// is the same setup gpssh relies on, as made by gpssh-exkeys.
+func DefaultConfig() (Config, error) {
+	currentUser, err := user.Current()
+	if err != nil {
+		return Config{}, fmt.Errorf("could not get current user: %w", err)
+	}
+	sshDir := filepath.Join(currentUser.HomeDir, ".ssh")
+
+	auth := make([]ssh.AuthMethod, 0)
+	signers := make([]ssh.Signer, 0)
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		key, err := os.ReadFile(filepath.Join(sshDir, name))
+		if err != nil {
+			continue
+		}
+
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			continue // most likely protected by a passphrase, leave it to the agent
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		_, err := sshAgent(socket)
+		if err == nil {
+			auth = append(auth, ssh.PublicKeysCallback(SSHAgentSigners(socket)))
+		}
+	}
+
+	if len(auth) == 0 {
+		return Config{}, fmt.Errorf("no usable SSH keys found in %s or the SSH agent", sshDir)
+	}
+
+	hostKeyCallback, err := knownhosts.New(filepath.Join(sshDir, "known_hosts"))
+	if err != nil {
+		return Config{}, fmt.Errorf("could not load known hosts: %w", err)
+	}
+
+	return Config{
+		User:            currentUser.Username,
+		Port:            constants.DefaultSSHPort,
+		Timeout:         constants.DefaultSSHTimeout * time.Second,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+var (
+	agentMutex  sync.Mutex
+	agentSocket string
+	agentConn   net.Conn
+	agentClient agent.ExtendedAgent
+)
+
+// sshAgent returns a client of the SSH agent listening on socket. The
+// connection is dialed once and shared by every executor, as the agent signs
+// with it for as long as the executor is used, and the hub creates executors
+// over and over.
+func sshAgent(socket string) (agent.ExtendedAgent, error) {
+	agentMutex.Lock()
+	defer agentMutex.Unlock()
+
+	if agentClient != nil && agentSocket == socket {
+		return agentClient, nil
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+
+	if agentConn != nil {
+		agentConn.Close()
+	}
+	agentSocket, agentConn, agentClient = socket, conn, agent.NewClient(conn)
+
+	return agentClient, nil
+}
+
+// dropSSHAgent closes the shared connection to the SSH agent once a call on
+// client failed, so that the next one dials the agent again. The connection
+// is left alone when another caller already replaced it.
+func dropSSHAgent(client agent.ExtendedAgent) {
+	agentMutex.Lock()
+	defer agentMutex.Unlock()
+
+	if agentClient != client {
+		return
+	}
+
+	agentConn.Close()
+	agentSocket, agentConn, agentClient = "", nil, nil
+}
+
+// SSHAgentSigners returns a function listing the keys of the SSH agent
+// listening on socket over the shared connection, which is dialed again when
+// it no longer works, e.g. once the agent was restarted.
+func SSHAgentSigners(socket string) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		client, err := sshAgent(socket)
+		if err != nil {
+			return nil, err
+		}
+
+		signers, err := client.Signers()
+		if err == nil {
+			return signers, nil
+		}
+
+		dropSSHAgent(client)
+		client, err = sshAgent(socket)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Signers()
+	}
+}
+
+// SSHExecutor is an Executor running commands over SSH, with one connection
+// per host and command
+type SSHExecutor struct {
+	config       Config
+	clientConfig *ssh.ClientConfig
+}
+
+func NewSSHExecutor(config Config) *SSHExecutor {
+	return &SSHExecutor{
+		config: config,
+		clientConfig: &ssh.ClientConfig{
+			User:            config.User,
+			Auth:            config.Auth,
+			HostKeyCallback: config.HostKeyCallback,
+			Timeout:         config.Timeout,
+		},
+	}
+}
+
+// NewExecutor returns an SSHExecutor using the DefaultConfig
+func NewExecutor() (Executor, error) {
+	config, err := DefaultConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSSHExecutor(config), nil
+}
+
+func (e *SSHExecutor) Run(hostnames []string, command string) Results {
+	return e.runOnHosts(hostnames, command, nil)
+}
+
+func (e *SSHExecutor) Copy(hostnames []string, contents []byte, path string, mode os.FileMode) Results {
+	tempPath := fmt.Sprintf("%s.%d.tmp", path, time.Now().UnixNano())
+	command := fmt.Sprintf("cat > %[1]s && chmod %[2]o %[1]s && mv -f %[1]s %[3]s || { rm -f %[1]s; exit 1; }",
+		Quote(tempPath), mode.Perm(), Quote(path))
+
+	return e.runOnHosts(hostnames, command, contents)
+}
+
+func (e *SSHExecutor) runOnHosts(hostnames []string, command string, stdin []byte) Results {
+	var wg sync.WaitGroup
+	results := make(Results, len(hostnames))
+
+	for i, host := range hostnames {
+		i, host := i, host
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = e.runOnHost(host, command, stdin)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (e *SSHExecutor) runOnHost(host string, command string, stdin []byte) Result {
+	start := time.Now()
+	result := Result{Hostname: host, ExitCode: -1}
+
+	err := e.run(host, command, stdin, &result)
+	if err != nil {
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitStatus()
+			err = fmt.Errorf("command exited with status %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+		}
+		result.Err = err
+	} else {
+		result.ExitCode = 0
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+func (e *SSHExecutor) run(host string, command string, stdin []byte, result *Result) error {
+	address := net.JoinHostPort(host, strconv.Itoa(e.config.Port))
+	client, err := ssh.Dial("tcp", address, e.clientConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to %s: %w", address, err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("could not open SSH session: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if stdin != nil {
+		session.Stdin = bytes.NewReader(stdin)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	var timeout <-chan time.Time
+	if e.config.Timeout > 0 {
+		timer := time.NewTimer(e.config.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case err = <-done:
+	case <-timeout:
+		// Closing the connection makes Run return, and the remote sshd
+		// hangs up on the command.
+		client.Close()
+		<-done
+		err = fmt.Errorf("command timed out after %s", e.config.Timeout)
+	}
+
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	if errors.Is(err, io.EOF) {
+		err = errors.New("connection closed before the command finished")
+	}
+
+	return err
+}
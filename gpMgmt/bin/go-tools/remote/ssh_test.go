@@ -0,0 +1,368 @@
+package remote_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// testServer is an in-process SSH server running the commands it is sent
+// through the local shell
+type testServer struct {
+	port         int
+	hostKey      ssh.PublicKey
+	clientSigner ssh.Signer
+}
+
+func startTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	hostSigner := newSigner(t)
+	clientSigner := newSigner(t)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+
+			return nil, errors.New("unknown public key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+
+	return &testServer{
+		port:         listener.Addr().(*net.TCPAddr).Port,
+		hostKey:      hostSigner.PublicKey(),
+		clientSigner: clientSigner,
+	}
+}
+
+func (s *testServer) config() remote.Config {
+	return remote.Config{
+		Port:            s.port,
+		Timeout:         5 * time.Second,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(s.clientSigner)},
+		HostKeyCallback: ssh.FixedHostKey(s.hostKey),
+	}
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return signer
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go serveSession(channel, requests)
+	}
+}
+
+func serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for request := range requests {
+		if request.Type != "exec" {
+			_ = request.Reply(false, nil)
+			continue
+		}
+		_ = request.Reply(true, nil)
+
+		// the payload is the length prefixed command
+		command := string(request.Payload[4:])
+		cmd := exec.Command("/bin/sh", "-c", command)
+		cmd.Stdin = channel
+		cmd.Stdout = channel
+		cmd.Stderr = channel.Stderr()
+
+		exitCode := 0
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			exitCode = 127
+		}
+
+		status := make([]byte, 4)
+		binary.BigEndian.PutUint32(status, uint32(exitCode))
+		_, _ = channel.SendRequest("exit-status", false, status)
+
+		return
+	}
+}
+
+func TestSSHExecutorRun(t *testing.T) {
+	server := startTestServer(t)
+	hosts := []string{"127.0.0.1", "localhost"}
+
+	t.Run("runs the command on every host", func(t *testing.T) {
+		executor := remote.NewSSHExecutor(server.config())
+
+		results := executor.Run(hosts, "echo hello; echo world >&2")
+		if err := results.Err(); err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		for i, result := range results {
+			if result.Hostname != hosts[i] {
+				t.Fatalf("got host %s, want %s", result.Hostname, hosts[i])
+			}
+			if result.ExitCode != 0 || result.Stdout != "hello\n" || result.Stderr != "world\n" {
+				t.Fatalf("unexpected result %+v", result)
+			}
+		}
+	})
+
+	t.Run("returns the exit code and output of a failed command", func(t *testing.T) {
+		executor := remote.NewSSHExecutor(server.config())
+
+		results := executor.Run(hosts[:1], remote.Command("sh", "-c", "echo 'it failed' >&2; exit 3"))
+		result := results[0]
+		if result.ExitCode != 3 || result.Stderr != "it failed\n" {
+			t.Fatalf("unexpected result %+v", result)
+		}
+
+		expected := "failed on 1 of 1 hosts: host 127.0.0.1: command exited with status 3: it failed"
+		if err := results.Err(); err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
+	})
+
+	t.Run("stops waiting for a command once the timeout expires", func(t *testing.T) {
+		config := server.config()
+		config.Timeout = 200 * time.Millisecond
+		executor := remote.NewSSHExecutor(config)
+
+		results := executor.Run(hosts[:1], "sleep 5")
+		result := results[0]
+		expected := "command timed out after 200ms"
+		if result.Err == nil || result.Err.Error() != expected {
+			t.Fatalf("got %v, want %v", result.Err, expected)
+		}
+		if result.ExitCode != -1 || result.Duration > 2*time.Second {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	})
+
+	t.Run("errors out when the host key does not match", func(t *testing.T) {
+		config := server.config()
+		config.HostKeyCallback = ssh.FixedHostKey(newSigner(t).PublicKey())
+		executor := remote.NewSSHExecutor(config)
+
+		result := executor.Run(hosts[:1], "true")[0]
+		expected := "could not connect to 127.0.0.1:"
+		if result.Err == nil || !strings.HasPrefix(result.Err.Error(), expected) {
+			t.Fatalf("got %v, want %v", result.Err, expected)
+		}
+	})
+
+	t.Run("errors out when not able to connect to the host", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		config := server.config()
+		config.Port = listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+		executor := remote.NewSSHExecutor(config)
+
+		results := executor.Run([]string{"127.0.0.1"}, "true")
+		if results[0].Err == nil || results[0].ExitCode != -1 {
+			t.Fatalf("unexpected result %+v", results[0])
+		}
+	})
+}
+
+func TestSSHExecutorCopy(t *testing.T) {
+	server := startTestServer(t)
+
+	t.Run("copies the contents to the file on every host", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "gp.conf")
+		executor := remote.NewSSHExecutor(server.config())
+
+		results := executor.Copy([]string{"127.0.0.1"}, []byte("contents"), path, 0640)
+		if err := results.Err(); err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if string(contents) != "contents" {
+			t.Fatalf("got %q, want %q", contents, "contents")
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Fatalf("got mode %o, want %o", info.Mode().Perm(), 0640)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected only the copied file in %s, got %d entries", dir, len(entries))
+		}
+	})
+
+	t.Run("errors out when not able to write the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "gp.conf")
+		executor := remote.NewSSHExecutor(server.config())
+
+		results := executor.Copy([]string{"127.0.0.1"}, []byte("contents"), path, 0644)
+		if results.Err() == nil || results[0].ExitCode != 1 {
+			t.Fatalf("unexpected result %+v", results[0])
+		}
+	})
+}
+
+func TestDefaultConfig(t *testing.T) {
+	t.Run("shares the connection to the SSH agent", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "agent.sock")
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		t.Cleanup(func() {
+			listener.Close()
+		})
+
+		connections := make(chan struct{}, 10)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				connections <- struct{}{}
+				go agent.ServeAgent(agent.NewKeyring(), conn) //nolint
+			}
+		}()
+		t.Setenv("SSH_AUTH_SOCK", socket)
+
+		// The known hosts may be missing, which does not matter here
+		_, _ = remote.DefaultConfig()
+		_, _ = remote.DefaultConfig()
+
+		time.Sleep(100 * time.Millisecond)
+		if len(connections) != 1 {
+			t.Fatalf("got %d connections to the SSH agent, want 1", len(connections))
+		}
+	})
+}
+
+func TestSSHAgentSigners(t *testing.T) {
+	t.Run("dials the SSH agent again when the shared connection fails", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "agent.sock")
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		t.Cleanup(func() {
+			listener.Close()
+		})
+
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		keyring := agent.NewKeyring()
+		err = keyring.Add(agent.AddedKey{PrivateKey: key})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		connections := make(chan struct{}, 10)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				connections <- struct{}{}
+				if len(connections) == 1 {
+					conn.Close() // as if the agent was restarted
+					continue
+				}
+				go agent.ServeAgent(keyring, conn) //nolint
+			}
+		}()
+
+		signers, err := remote.SSHAgentSigners(socket)()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if len(signers) != 1 {
+			t.Fatalf("got %d signers, want 1", len(signers))
+		}
+		if len(connections) != 2 {
+			t.Fatalf("got %d connections to the SSH agent, want 2", len(connections))
+		}
+	})
+}
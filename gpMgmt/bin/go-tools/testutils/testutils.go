@@ -1,14 +1,34 @@
 package testutils
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"io"
+	"math/big"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/greenplum-db/gp-common-go-libs/dbconn"
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpdb/gp/constants"
 	"github.com/greenplum-db/gpdb/gp/hub"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
 
@@ -20,6 +40,7 @@ type MockPlatform struct {
 	DefServiceDir        string
 	StartCmd             *exec.Cmd
 	ConfigFileData       []byte
+	ProcessUsage         *utils.ProcessUsage
 }
 
 func InitializeTestEnv() *hub.Config {
@@ -37,7 +58,29 @@ func InitializeTestEnv() *hub.Config {
 	}
 	return conf
 }
-func (p *MockPlatform) CreateServiceDir(hostnames []string, serviceDir string, gphome string) error {
+
+// CreateMockDBConn returns a connected DBConn backed by sqlmock, reporting
+// the given database version
+func CreateMockDBConn(t *testing.T, version string) (*dbconn.DBConn, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("could not create mock database connection: %#v", err)
+	}
+
+	conn := dbconn.NewDBConn("testdb", "testrole", "testhost", 5432)
+	conn.Driver = &testhelper.TestDriver{DB: sqlx.NewDb(db, "sqlmock"), DBName: "testdb", User: "testrole"}
+	testhelper.ExpectVersionQuery(mock, version)
+	err = conn.Connect(1)
+	if err != nil {
+		t.Fatalf("could not connect to mock database: %#v", err)
+	}
+
+	return conn, mock
+}
+
+func (p *MockPlatform) CreateServiceDir(hostnames []string, serviceDir string) error {
 	return nil
 }
 func (p *MockPlatform) GetServiceStatusMessage(serviceName string) (string, error) {
@@ -52,7 +95,7 @@ func (p *MockPlatform) GetDefaultServiceDir() string {
 func (p *MockPlatform) ReloadHubService(servicePath string) error {
 	return p.Err
 }
-func (p *MockPlatform) ReloadAgentService(gphome string, hostList []string, servicePath string) error {
+func (p *MockPlatform) ReloadAgentService(hostnames []string, servicePath string) error {
 	return p.Err
 }
 func (p *MockPlatform) CreateAndInstallHubServiceFile(gphome string, serviceDir string, serviceName string) error {
@@ -61,6 +104,15 @@ func (p *MockPlatform) CreateAndInstallHubServiceFile(gphome string, serviceDir
 func (p *MockPlatform) CreateAndInstallAgentServiceFile(hostnames []string, gphome string, serviceDir string, serviceName string) error {
 	return p.Err
 }
+func (p *MockPlatform) RemoveHubService(serviceDir string, serviceName string) error {
+	return p.Err
+}
+func (p *MockPlatform) DisableUserLingering(executor remote.Executor, hostnames []string, serviceUser string) error {
+	return p.Err
+}
+func (p *MockPlatform) RemoveAgentService(executor remote.Executor, hostnames []string, serviceDir string, serviceName string) error {
+	return p.Err
+}
 func (p *MockPlatform) GetStartHubCommand(serviceName string) *exec.Cmd {
 	return p.StartCmd
 }
@@ -70,9 +122,13 @@ func (p *MockPlatform) GetStartAgentCommandString(serviceName string) []string {
 func (p *MockPlatform) ParseServiceStatusMessage(message string) idl.ServiceStatus {
 	return idl.ServiceStatus{Status: p.RetStatus.Status, Pid: p.RetStatus.Pid, Uptime: p.RetStatus.Uptime}
 }
-func (p *MockPlatform) DisplayServiceStatus(outfile io.Writer, serviceName string, statuses []*idl.ServiceStatus, skipHeader bool) {
+func (p *MockPlatform) GetProcessUsage(pid uint32) (*utils.ProcessUsage, error) {
+	if p.ProcessUsage == nil {
+		return nil, errors.New("no such process")
+	}
+	return p.ProcessUsage, nil
 }
-func (p *MockPlatform) EnableUserLingering(hostnames []string, gphome string, serviceUser string) error {
+func (p *MockPlatform) EnableUserLingering(hostnames []string, serviceUser string) error {
 	return nil
 }
 func (p *MockPlatform) ReadFile(configFilePath string) (config *hub.Config, err error) {
@@ -84,10 +140,11 @@ func (p *MockPlatform) SetServiceFileContent(content string) {
 
 type MockCredentials struct {
 	TlsConnection credentials.TransportCredentials
+	Certs         []*idl.Certificate
 	Err           error
 }
 
-func (s *MockCredentials) LoadServerCredentials() (credentials.TransportCredentials, error) {
+func (s *MockCredentials) LoadServerCredentials(role utils.Role) (credentials.TransportCredentials, error) {
 	return s.TlsConnection, s.Err
 }
 
@@ -101,3 +158,199 @@ func (s *MockCredentials) SetCredsError(errMsg string) {
 func (s *MockCredentials) ResetCredsError() {
 	s.Err = nil
 }
+
+func (s *MockCredentials) Certificates() ([]*idl.Certificate, error) {
+	return s.Certs, s.Err
+}
+
+// MockHubStream collects the replies sent by the streaming hub RPCs
+type MockHubStream struct {
+	grpc.ServerStream
+	mutex   sync.Mutex
+	Replies []*idl.HubReply
+	Err     error
+}
+
+func (s *MockHubStream) Send(reply *idl.HubReply) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Replies = append(s.Replies, reply)
+	return s.Err
+}
+
+// Summary returns the summary sent by the RPC, or nil if there was none
+func (s *MockHubStream) Summary() *idl.Summary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, reply := range s.Replies {
+		if summary := reply.GetSummary(); summary != nil {
+			return summary
+		}
+	}
+
+	return nil
+}
+
+// MockHubReplies replays the replies to the client of a streaming hub RPC,
+// followed by Err, or io.EOF when Err is nil
+type MockHubReplies struct {
+	grpc.ClientStream
+	Replies []*idl.HubReply
+	Err     error
+}
+
+func (s *MockHubReplies) Recv() (*idl.HubReply, error) {
+	if len(s.Replies) == 0 {
+		if s.Err != nil {
+			return nil, s.Err
+		}
+
+		return nil, io.EOF
+	}
+
+	reply := s.Replies[0]
+	s.Replies = s.Replies[1:]
+
+	return reply, nil
+}
+
+// MockExecutor records the commands and copies it is asked to run. Err, when
+// set, returns the error of the command on a host; copies are passed to it as
+// "copy <path>".
+type MockExecutor struct {
+	mutex    sync.Mutex
+	Commands []string
+	Copies   []MockCopy
+	Err      func(host string, command string) error
+	Stdout   func(host string, command string) string
+}
+
+type MockCopy struct {
+	Hostnames []string
+	Contents  string
+	Path      string
+	Mode      os.FileMode
+}
+
+func (e *MockExecutor) Run(hostnames []string, command string) remote.Results {
+	e.mutex.Lock()
+	e.Commands = append(e.Commands, command)
+	e.mutex.Unlock()
+
+	return e.results(hostnames, command)
+}
+
+func (e *MockExecutor) Copy(hostnames []string, contents []byte, path string, mode os.FileMode) remote.Results {
+	e.mutex.Lock()
+	e.Copies = append(e.Copies, MockCopy{Hostnames: hostnames, Contents: string(contents), Path: path, Mode: mode})
+	e.mutex.Unlock()
+
+	return e.results(hostnames, "copy "+path)
+}
+
+func (e *MockExecutor) results(hostnames []string, command string) remote.Results {
+	results := make(remote.Results, 0, len(hostnames))
+	for _, host := range hostnames {
+		result := remote.Result{Hostname: host}
+		if e.Stdout != nil {
+			result.Stdout = e.Stdout(host, command)
+		}
+		if e.Err != nil {
+			result.Err = e.Err(host, command)
+		}
+		if result.Err != nil {
+			result.ExitCode = 1
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// NewExecutor returns a function creating the executor, for the
+// SetNewRemoteExecutor functions
+func (e *MockExecutor) NewExecutor() func() (remote.Executor, error) {
+	return func() (remote.Executor, error) {
+		return e, nil
+	}
+}
+
+// FailOn returns an Err function failing the commands containing substr
+func FailOn(substr string, err error) func(host string, command string) error {
+	return func(host string, command string) error {
+		if strings.Contains(command, substr) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// CreateCertificates writes a new CA, and a certificate for localhost with the
+// given common name signed by it, to dir, replacing any previous ones. The
+// returned credentials use the certificate as server and client certificate.
+func CreateCertificates(t *testing.T, dir string, commonName string) *utils.GpCredentials {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate CA key: %#v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName + "-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create CA certificate: %#v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %#v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(12 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create certificate: %#v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %#v", err)
+	}
+
+	creds := &utils.GpCredentials{
+		CACertPath:     filepath.Join(dir, "ca-cert.pem"),
+		ServerCertPath: filepath.Join(dir, "server-cert.pem"),
+		ServerKeyPath:  filepath.Join(dir, "server-key.pem"),
+	}
+	files := map[string]*pem.Block{
+		creds.CACertPath:     {Type: "CERTIFICATE", Bytes: caDER},
+		creds.ServerCertPath: {Type: "CERTIFICATE", Bytes: der},
+		creds.ServerKeyPath:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	}
+	for path, block := range files {
+		err = os.WriteFile(path, pem.EncodeToMemory(block), 0600)
+		if err != nil {
+			t.Fatalf("could not write %s: %#v", path, err)
+		}
+	}
+
+	return creds
+}
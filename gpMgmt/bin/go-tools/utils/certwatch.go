@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CertificateWatcher serves the server credentials of a role, reloading the
+// certificate, key and CA files when they change or when asked to, so that
+// certificates can be rotated without restarting the hub or the agents.
+// Connections already established keep the certificates they were opened with.
+type CertificateWatcher struct {
+	credentials GpCredentials
+	role        Role
+
+	mutex        sync.RWMutex
+	config       *tls.Config
+	checksum     string
+	certificates []*idl.Certificate
+}
+
+// WatchServerCredentials loads the server credentials of the role once, to be
+// served through TransportCredentials and kept up to date by Watch or Reload
+func (c GpCredentials) WatchServerCredentials(role Role) (*CertificateWatcher, error) {
+	w := &CertificateWatcher{credentials: c, role: role}
+
+	err := w.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// LoadWatchedServerCredentials returns the server credentials of the role,
+// along with their watcher when the credentials can be reloaded, nil otherwise
+func LoadWatchedServerCredentials(c Credentials, role Role) (credentials.TransportCredentials, *CertificateWatcher, error) {
+	watched, ok := c.(interface {
+		WatchServerCredentials(role Role) (*CertificateWatcher, error)
+	})
+	if !ok {
+		creds, err := c.LoadServerCredentials(role)
+		return creds, nil, err
+	}
+
+	watcher, err := watched.WatchServerCredentials(role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return watcher.TransportCredentials(), watcher, nil
+}
+
+// TransportCredentials hands out the latest configuration to each new client
+func (w *CertificateWatcher) TransportCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(&tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			w.mutex.RLock()
+			defer w.mutex.RUnlock()
+
+			return w.config, nil
+		},
+	})
+}
+
+// Reload reads the files again. The previous credentials are kept if the new
+// ones can not be loaded, e.g. when only the certificate has been replaced yet.
+func (w *CertificateWatcher) Reload() error {
+	checksum, err := w.credentials.checksum()
+	if err != nil {
+		return fmt.Errorf("could not reload credentials: %w", err)
+	}
+
+	config, err := w.credentials.serverTLSConfig(w.role)
+	if err != nil {
+		return err
+	}
+	config.NextProtos = []string{"h2"} // not added by gRPC to the configuration for each client
+
+	certificates, err := w.credentials.Certificates()
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.config = config
+	w.checksum = checksum
+	w.certificates = certificates
+
+	return nil
+}
+
+// Watch reloads the credentials whenever their files change, checking them
+// at the given interval until done is closed
+func (w *CertificateWatcher) Watch(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		checksum, err := w.credentials.checksum()
+		if err != nil {
+			gplog.Warn("Could not check the %s credentials for changes: %s", w.role, err)
+			continue
+		}
+
+		w.mutex.RLock()
+		changed := checksum != w.checksum
+		w.mutex.RUnlock()
+		if !changed {
+			continue
+		}
+
+		err = w.Reload()
+		if err != nil {
+			gplog.Warn("Could not reload the %s credentials, keeping the previous ones: %s", w.role, err)
+			continue
+		}
+		gplog.Info("Reloaded the %s credentials", w.role)
+	}
+}
+
+// Certificates returns the certificates currently served
+func (w *CertificateWatcher) Certificates() []*idl.Certificate {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	return w.certificates
+}
+
+// Certificates returns the server, client and CA certificates configured
+func (c GpCredentials) Certificates() ([]*idl.Certificate, error) {
+	paths := []string{c.ServerCertPath}
+	if c.ClientCertPath != "" {
+		paths = append(paths, c.ClientCertPath)
+	}
+	paths = append(paths, c.CACertPath)
+
+	certificates := make([]*idl.Certificate, 0)
+	for _, path := range paths {
+		cert, err := readCertificate(path)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, &idl.Certificate{
+			Path:     path,
+			Subject:  cert.Subject.String(),
+			NotAfter: timestamppb.New(cert.NotAfter),
+		})
+	}
+
+	return certificates, nil
+}
+
+// checksum covers the contents of every file the server credentials use
+func (c GpCredentials) checksum() (string, error) {
+	hash := sha256.New()
+	for _, path := range []string{c.ServerCertPath, c.ServerKeyPath, c.ClientCertPath, c.CACertPath} {
+		if path == "" {
+			continue
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(contents)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readCertificate parses the first certificate of the PEM file
+func readCertificate(path string) (*x509.Certificate, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificate: %w", err)
+	}
+
+	cert, err := ParseCertificate(contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate %s: %w", path, err)
+	}
+
+	return cert, nil
+}
+
+// EarliestExpiry returns the certificate expiring first, or nil if there are none
+func EarliestExpiry(certificates []*idl.Certificate) *idl.Certificate {
+	var earliest *idl.Certificate
+	for _, cert := range certificates {
+		if earliest == nil || cert.NotAfter.AsTime().Before(earliest.NotAfter.AsTime()) {
+			earliest = cert
+		}
+	}
+
+	return earliest
+}
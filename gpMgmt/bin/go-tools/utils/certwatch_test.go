@@ -0,0 +1,174 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/grpc/credentials"
+)
+
+// servedCommonName connects to a server using the watcher and returns the
+// common name of the certificate it presented
+func servedCommonName(t *testing.T, watcher *utils.CertificateWatcher, client *utils.GpCredentials) string {
+	t.Helper()
+
+	clientCreds, err := client.LoadClientCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		serverConn, _, err := watcher.TransportCredentials().ServerHandshake(conn)
+		if err == nil {
+			serverConn.Close()
+		}
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer conn.Close()
+
+	_, authInfo, err := clientCreds.ClientHandshake(context.Background(), "localhost", conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return authInfo.(credentials.TLSInfo).State.PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertificateWatcher(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("serves the new certificates once reloaded", func(t *testing.T) {
+		dir := t.TempDir()
+		creds := testutils.CreateCertificates(t, dir, "first")
+
+		watcher, err := creds.WatchServerCredentials(utils.AgentRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if name := servedCommonName(t, watcher, creds); name != "first" {
+			t.Fatalf("got %s, want first", name)
+		}
+
+		testutils.CreateCertificates(t, dir, "second")
+		if subject := watcher.Certificates()[0].Subject; subject != "CN=first" {
+			t.Fatalf("got %s before reloading, want CN=first", subject)
+		}
+
+		err = watcher.Reload()
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if name := servedCommonName(t, watcher, creds); name != "second" {
+			t.Fatalf("got %s, want second", name)
+		}
+
+		certificates := watcher.Certificates()
+		if len(certificates) != 2 || certificates[0].Subject != "CN=second" || certificates[1].Subject != "CN=second-ca" {
+			t.Fatalf("unexpected certificates %+v", certificates)
+		}
+	})
+
+	t.Run("keeps the previous certificates when the new ones can not be loaded", func(t *testing.T) {
+		dir := t.TempDir()
+		creds := testutils.CreateCertificates(t, dir, "first")
+
+		watcher, err := creds.WatchServerCredentials(utils.AgentRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// only the certificate has been replaced, not its key yet
+		previousKey, err := os.ReadFile(creds.ServerKeyPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		testutils.CreateCertificates(t, dir, "second")
+		err = os.WriteFile(creds.ServerKeyPath, previousKey, 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		err = watcher.Reload()
+		expected := "could not load server credentials"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+
+		certificates := watcher.Certificates()
+		if certificates[0].Subject != "CN=first" {
+			t.Fatalf("got %s, want CN=first", certificates[0].Subject)
+		}
+	})
+
+	t.Run("reloads the certificates when their files change", func(t *testing.T) {
+		dir := t.TempDir()
+		creds := testutils.CreateCertificates(t, dir, "first")
+
+		watcher, err := creds.WatchServerCredentials(utils.AgentRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			watcher.Watch(10*time.Millisecond, done)
+			close(stopped)
+		}()
+		defer func() {
+			close(done)
+			<-stopped
+		}()
+
+		testutils.CreateCertificates(t, dir, "second")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for watcher.Certificates()[0].Subject != "CN=second" {
+			if time.Now().After(deadline) {
+				t.Fatalf("certificates were not reloaded: %+v", watcher.Certificates())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if name := servedCommonName(t, watcher, creds); name != "second" {
+			t.Fatalf("got %s, want second", name)
+		}
+	})
+
+	t.Run("errors out when not able to load the certificates", func(t *testing.T) {
+		creds := utils.GpCredentials{
+			CACertPath:     "/does/not/exist/ca-cert.pem",
+			ServerCertPath: "/does/not/exist/server-cert.pem",
+			ServerKeyPath:  "/does/not/exist/server-key.pem",
+		}
+
+		_, err := creds.WatchServerCredentials(utils.HubRole)
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("got %v, want a missing file error", err)
+		}
+	})
+}
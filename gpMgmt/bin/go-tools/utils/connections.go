@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc/stats"
+)
+
+// ConnectionCounter is a gRPC stats handler keeping count of the open
+// connections to a server
+type ConnectionCounter struct {
+	open int64
+}
+
+func (c *ConnectionCounter) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (c *ConnectionCounter) HandleRPC(ctx context.Context, s stats.RPCStats) {}
+
+func (c *ConnectionCounter) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (c *ConnectionCounter) HandleConn(ctx context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		atomic.AddInt64(&c.open, 1)
+	case *stats.ConnEnd:
+		atomic.AddInt64(&c.open, -1)
+	}
+}
+
+// Count returns the number of open connections
+func (c *ConnectionCounter) Count() uint32 {
+	return uint32(atomic.LoadInt64(&c.open))
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIdentity is what the hub and the agents know of the client of a
+// request, from the bearer token it sends and its TLS certificate
+type ClientIdentity struct {
+	TokenHash string   // HashToken of the bearer token, empty when none is sent
+	Names     []string // CertificateNames of the client certificate
+	Name      string   // CN of the certificate, or its first SAN when it has none
+	Verified  bool     // whether the certificate was verified against the CA
+}
+
+// HashToken returns the form in which a bearer token is stored in the configuration
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IncomingClientIdentity returns the identity of the client of the request
+func IncomingClientIdentity(ctx context.Context) ClientIdentity {
+	var identity ClientIdentity
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if strings.HasPrefix(value, "Bearer ") {
+				identity.TokenHash = HashToken(strings.TrimPrefix(value, "Bearer "))
+				break
+			}
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return identity
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return identity
+	}
+
+	var cert *x509.Certificate
+	if len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+		cert, identity.Verified = tlsInfo.State.VerifiedChains[0][0], true
+	} else if len(tlsInfo.State.PeerCertificates) > 0 {
+		cert = tlsInfo.State.PeerCertificates[0]
+	}
+	if cert != nil {
+		identity.Names = CertificateNames(cert)
+		// the CN names users, the SANs name hosts
+		identity.Name = cert.Subject.CommonName
+		if identity.Name == "" && len(identity.Names) > 0 {
+			identity.Name = identity.Names[0]
+		}
+	}
+
+	return identity
+}
+
+// String describes the client by the fingerprint of its token, or else by the
+// name of its certificate
+func (c ClientIdentity) String() string {
+	switch {
+	case c.TokenHash != "":
+		return "token " + c.TokenHash[:8]
+	case len(c.Names) == 0:
+		return "unidentified client"
+	case !c.Verified:
+		return "unverified client " + c.Name
+	default:
+		return "client " + c.Name
+	}
+}
@@ -0,0 +1,78 @@
+package utils_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestIncomingClientIdentity(t *testing.T) {
+	user := &x509.Certificate{Subject: pkix.Name{CommonName: "dba"}, DNSNames: []string{"cdw"}}
+	host := &x509.Certificate{DNSNames: []string{"sdw1", "localhost"}}
+
+	withCertificate := func(state tls.ConnectionState) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	cases := []struct {
+		name        string
+		ctx         context.Context
+		names       []string
+		verified    bool
+		description string
+	}{
+		{
+			name:        "names verified clients by the CN of their certificate",
+			ctx:         withCertificate(tls.ConnectionState{PeerCertificates: []*x509.Certificate{user}, VerifiedChains: [][]*x509.Certificate{{user}}}),
+			names:       []string{"cdw", "dba"},
+			verified:    true,
+			description: "client dba",
+		},
+		{
+			name:        "names clients by the first SAN of certificates without CN",
+			ctx:         withCertificate(tls.ConnectionState{PeerCertificates: []*x509.Certificate{host}, VerifiedChains: [][]*x509.Certificate{{host}}}),
+			names:       []string{"sdw1", "localhost"},
+			verified:    true,
+			description: "client sdw1",
+		},
+		{
+			name:        "tells apart the unverified clients",
+			ctx:         withCertificate(tls.ConnectionState{PeerCertificates: []*x509.Certificate{user}}),
+			names:       []string{"cdw", "dba"},
+			description: "unverified client dba",
+		},
+		{
+			name:        "prefers the token over the certificate",
+			ctx:         metadata.NewIncomingContext(withCertificate(tls.ConnectionState{PeerCertificates: []*x509.Certificate{user}}), metadata.Pairs("authorization", "Bearer secret")),
+			names:       []string{"cdw", "dba"},
+			description: "token 2bb80d53",
+		},
+		{
+			name:        "does not identify clients without TLS",
+			ctx:         context.Background(),
+			description: "unidentified client",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			identity := utils.IncomingClientIdentity(tc.ctx)
+			if !reflect.DeepEqual(identity.Names, tc.names) || identity.Verified != tc.verified {
+				t.Fatalf("got %+v, want names %q verified %t", identity, tc.names, tc.verified)
+			}
+			if identity.String() != tc.description {
+				t.Fatalf("got %q, want %q", identity.String(), tc.description)
+			}
+		})
+	}
+}
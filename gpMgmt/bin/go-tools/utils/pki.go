@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// KeyPair is a certificate with its private key, in PEM as written to disk
+type KeyPair struct {
+	Cert    *x509.Certificate
+	Key     crypto.Signer
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// GenerateCA creates a self-signed CA to issue the certificates of the cluster
+func GenerateCA(commonName string, validity time.Duration) (*KeyPair, error) {
+	template, err := certificateTemplate(commonName, validity)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	return createKeyPair(template, nil)
+}
+
+// IssueCertificate creates a certificate signed by the CA, valid for both
+// serving and connecting as a client from any of the hosts, given as
+// hostnames or IP addresses
+func (ca *KeyPair) IssueCertificate(commonName string, hosts []string, validity time.Duration) (*KeyPair, error) {
+	template, err := certificateTemplate(commonName, validity)
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	if template.NotAfter.After(ca.Cert.NotAfter) {
+		template.NotAfter = ca.Cert.NotAfter // not valid past its CA anyway
+	}
+
+	return createKeyPair(template, ca)
+}
+
+// LoadKeyPair reads a certificate and its private key written by GenerateCA
+// or IssueCertificate
+func LoadKeyPair(certPEM []byte, keyPEM []byte) (*KeyPair, error) {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no private key found")
+	}
+	key, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{Cert: cert, Key: key, CertPEM: certPEM, KeyPEM: keyPEM}, nil
+}
+
+// parsePrivateKey parses a private key in PKCS#8, as written by GenerateCA,
+// or in the PKCS#1 and SEC1 formats older versions of OpenSSL write
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key can not sign certificates")
+	}
+
+	return key, nil
+}
+
+// ParseCertificate parses the first certificate of the PEM contents
+func ParseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no certificate found")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificate: %w", err)
+	}
+
+	return cert, nil
+}
+
+// CheckCertificate verifies that the certificate chains to the CA, is valid
+// for the host and is not expired at the given time. The returned warning is
+// set when it expires within warnBefore.
+func CheckCertificate(cert *x509.Certificate, ca *x509.Certificate, host string, now time.Time, warnBefore time.Duration) (warning string, err error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:     host,
+		Roots:       roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if expiresIn := cert.NotAfter.Sub(now); expiresIn < warnBefore {
+		return fmt.Sprintf("expires in %d days", int(expiresIn.Hours()/24)), nil
+	}
+
+	return "", nil
+}
+
+func certificateTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("could not generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Greenplum"}},
+		NotBefore:    now.Add(-5 * time.Minute), // tolerate clock skew between the hosts
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+// createKeyPair signs the template with a new key, using the CA if any or
+// the new key itself otherwise
+func createKeyPair(template *x509.Certificate, ca *KeyPair) (*KeyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate private key: %w", err)
+	}
+
+	parent, signer := template, crypto.Signer(key)
+	if ca != nil {
+		parent, signer = ca.Cert, ca.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("could not create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal private key: %w", err)
+	}
+
+	return &KeyPair{
+		Cert:    cert,
+		Key:     key,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
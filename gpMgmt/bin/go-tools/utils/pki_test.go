@@ -0,0 +1,214 @@
+package utils_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestIssueCertificate(t *testing.T) {
+	t.Run("issues certificates valid for the hosts and signed by the CA", func(t *testing.T) {
+		ca, err := utils.GenerateCA("test CA", 48*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		cert, err := ca.IssueCertificate("sdw1", []string{"sdw1", "localhost", "127.0.0.1"}, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		for _, host := range []string{"sdw1", "localhost", "127.0.0.1"} {
+			warning, err := utils.CheckCertificate(cert.Cert, ca.Cert, host, time.Now(), time.Hour)
+			if err != nil {
+				t.Fatalf("unexpected error for %s: %#v", host, err)
+			}
+			if warning != "" {
+				t.Fatalf("unexpected warning for %s: %s", host, warning)
+			}
+		}
+
+		_, err = cert.Cert.Verify(x509.VerifyOptions{
+			Roots:     poolOf(ca.Cert),
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		if err != nil {
+			t.Fatalf("expected the certificate to be usable by clients: %#v", err)
+		}
+	})
+
+	t.Run("does not issue certificates outliving the CA", func(t *testing.T) {
+		ca, err := utils.GenerateCA("test CA", time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		cert, err := ca.IssueCertificate("sdw1", []string{"sdw1"}, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if !cert.Cert.NotAfter.Equal(ca.Cert.NotAfter) {
+			t.Fatalf("got %s, want %s", cert.Cert.NotAfter, ca.Cert.NotAfter)
+		}
+	})
+
+	t.Run("loads the key pairs it writes", func(t *testing.T) {
+		ca, err := utils.GenerateCA("test CA", time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		loaded, err := utils.LoadKeyPair(ca.CertPEM, ca.KeyPEM)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		cert, err := loaded.IssueCertificate("sdw1", []string{"sdw1"}, time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		_, err = utils.CheckCertificate(cert.Cert, ca.Cert, "sdw1", time.Now(), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("loads CA keys in PKCS#1 format", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		certPEM := selfSignedCA(t, key)
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+		expectIssues(t, certPEM, keyPEM)
+	})
+
+	t.Run("loads CA keys in SEC1 format", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		certPEM := selfSignedCA(t, key)
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+		expectIssues(t, certPEM, keyPEM)
+	})
+}
+
+// selfSignedCA creates a CA certificate for the key, as openssl req -x509 does
+func selfSignedCA(t *testing.T, key crypto.Signer) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// expectIssues loads the CA key pair and checks that it issues certificates
+func expectIssues(t *testing.T, certPEM []byte, keyPEM []byte) {
+	t.Helper()
+
+	ca, err := utils.LoadKeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	cert, err := ca.IssueCertificate("sdw1", []string{"sdw1"}, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	_, err = utils.CheckCertificate(cert.Cert, ca.Cert, "sdw1", time.Now(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func TestCheckCertificate(t *testing.T) {
+	ca, err := utils.GenerateCA("test CA", 48*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	cert, err := ca.IssueCertificate("sdw1", []string{"sdw1"}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	t.Run("warns about certificates about to expire", func(t *testing.T) {
+		warning, err := utils.CheckCertificate(cert.Cert, ca.Cert, "sdw1", time.Now(), 30*24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := "expires in 0 days"
+		if warning != expected {
+			t.Fatalf("got %q, want %q", warning, expected)
+		}
+	})
+
+	t.Run("errors out when the certificate is not valid for the host", func(t *testing.T) {
+		_, err := utils.CheckCertificate(cert.Cert, ca.Cert, "sdw2", time.Now(), 0)
+		expected := "certificate is valid for sdw1, not sdw2"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("errors out when the certificate has expired", func(t *testing.T) {
+		_, err := utils.CheckCertificate(cert.Cert, ca.Cert, "sdw1", time.Now().Add(25*time.Hour), 0)
+		expected := "certificate has expired"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("errors out when the certificate is not signed by the CA", func(t *testing.T) {
+		otherCA, err := utils.GenerateCA("other CA", time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		_, err = utils.CheckCertificate(cert.Cert, otherCA.Cert, "sdw1", time.Now(), 0)
+		expected := "certificate signed by unknown authority"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
+
+func poolOf(certs ...*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+
+	return pool
+}
@@ -3,27 +3,30 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
-	"text/tabwriter"
+	"time"
 
 	"github.com/greenplum-db/gpdb/gp/constants"
 
 	"github.com/greenplum-db/gp-common-go-libs/gplog"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
 	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
+	"golang.org/x/sys/unix"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
 	platform             Platform
 	execCommand          = exec.Command
+	bootTime             = monotonicBootTime
 	writeServiceFileFunc = WriteServiceFile
-	GpsyncCommand        = exec.Command
+	newRemoteExecutor    = remote.NewExecutor
 	LoadServiceCommand   = exec.Command
 	UnloadServiceCommand = exec.Command
 )
@@ -62,19 +65,22 @@ func NewPlatform(os string) (Platform, error) {
 }
 
 type Platform interface {
-	CreateServiceDir(hostnames []string, serviceDir string, gphome string) error
+	CreateServiceDir(hostnames []string, serviceDir string) error
 	GenerateServiceFileContents(process string, gphome string, serviceName string) string
 	GetDefaultServiceDir() string
 	ReloadHubService(servicePath string) error
-	ReloadAgentService(gphome string, hostList []string, servicePath string) error
+	ReloadAgentService(hostnames []string, servicePath string) error
 	CreateAndInstallHubServiceFile(gphome string, serviceDir string, serviceName string) error
+	RemoveHubService(serviceDir string, serviceName string) error
 	CreateAndInstallAgentServiceFile(hostnames []string, gphome string, serviceDir string, serviceName string) error
+	RemoveAgentService(executor remote.Executor, hostnames []string, serviceDir string, serviceName string) error
 	GetStartHubCommand(serviceName string) *exec.Cmd
 	GetStartAgentCommandString(serviceName string) []string
 	GetServiceStatusMessage(serviceName string) (string, error)
 	ParseServiceStatusMessage(message string) idl.ServiceStatus
-	DisplayServiceStatus(outfile io.Writer, serviceName string, statuses []*idl.ServiceStatus, skipHeader bool)
-	EnableUserLingering(hostnames []string, gphome string, serviceUser string) error
+	GetProcessUsage(pid uint32) (*ProcessUsage, error)
+	EnableUserLingering(hostnames []string, serviceUser string) error
+	DisableUserLingering(executor remote.Executor, hostnames []string, serviceUser string) error
 }
 
 func GetPlatform() Platform {
@@ -91,16 +97,9 @@ func GetPlatform() Platform {
 	return platform
 }
 
-func (p GpPlatform) CreateServiceDir(hostnames []string, serviceDir string, gphome string) error {
-	hostList := make([]string, 0)
-	for _, host := range hostnames {
-		hostList = append(hostList, "-h", host)
-	}
-
+func (p GpPlatform) CreateServiceDir(hostnames []string, serviceDir string) error {
 	// Create service directory if it does not exist
-	args := append(hostList, "mkdir", "-p", serviceDir)
-	utility := filepath.Join(gphome, "bin", constants.GpSSH)
-	err := execCommand(utility, args...).Run()
+	err := runOnHosts(hostnames, "mkdir", "-p", serviceDir)
 	if err != nil {
 		return fmt.Errorf("could not create service directory %s on hosts: %w", serviceDir, err)
 	}
@@ -203,6 +202,40 @@ func (p GpPlatform) CreateAndInstallHubServiceFile(gphome string, serviceDir str
 	return nil
 }
 
+// RemoveHubService stops the hub service and removes its service file. It does
+// nothing once the service file is gone.
+func (p GpPlatform) RemoveHubService(serviceDir string, serviceName string) error {
+	hubServiceFilePath := filepath.Join(serviceDir, fmt.Sprintf("%s_hub.%s", serviceName, p.ServiceExt))
+	_, err := os.Stat(hubServiceFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if p.OS == constants.PlatformDarwin {
+		err = UnloadServiceCommand(p.ServiceCmd, "unload", hubServiceFilePath).Run()
+	} else {
+		err = execCommand(p.ServiceCmd, p.UserArg, "stop", fmt.Sprintf("%s_hub", serviceName)).Run()
+	}
+	if err != nil {
+		return fmt.Errorf("could not stop hub service: %w", err)
+	}
+
+	err = os.Remove(hubServiceFilePath)
+	if err != nil {
+		return fmt.Errorf("could not remove hub service file %s: %w", hubServiceFilePath, err)
+	}
+
+	if p.OS != constants.PlatformDarwin {
+		err = p.ReloadHubService(hubServiceFilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	gplog.Info("Removed hub service file %s on coordinator host", hubServiceFilePath)
+	return nil
+}
+
 func (p GpPlatform) ReloadHubService(servicePath string) error {
 	if p.OS == constants.PlatformDarwin {
 		// launchctl does not have a single reload command. Hence unload and load the file to update the configuration.
@@ -227,17 +260,24 @@ func (p GpPlatform) ReloadHubService(servicePath string) error {
 	return nil
 }
 
-func (p GpPlatform) ReloadAgentService(gphome string, hostList []string, servicePath string) error {
-	args := append(hostList, p.ServiceCmd)
+func (p GpPlatform) ReloadAgentService(hostnames []string, servicePath string) error {
+	executor, err := newRemoteExecutor()
+	if err != nil {
+		return err
+	}
 
+	return p.reloadAgentService(executor, hostnames, servicePath)
+}
+
+func (p GpPlatform) reloadAgentService(executor remote.Executor, hostnames []string, servicePath string) error {
 	if p.OS == constants.PlatformDarwin { // launchctl reloads a specific service, not all of them
 		// launchctl does not have a single reload command. Hence unload and load the file to update the configuration.
-		err := UnloadServiceCommand(fmt.Sprintf("%s/bin/gpssh", gphome), append(args, "unload", servicePath)...).Run()
+		err := executor.Run(hostnames, remote.Command(p.ServiceCmd, "unload", servicePath)).Err()
 		if err != nil {
 			return fmt.Errorf("could not unload agent service file %s on segment hosts: %w", servicePath, err)
 		}
 
-		err = LoadServiceCommand(fmt.Sprintf("%s/bin/gpssh", gphome), append(args, "load", servicePath)...).Run()
+		err = executor.Run(hostnames, remote.Command(p.ServiceCmd, "load", servicePath)).Err()
 		if err != nil {
 			return fmt.Errorf("could not load agent service file %s on segment hosts: %w", servicePath, err)
 		}
@@ -245,7 +285,7 @@ func (p GpPlatform) ReloadAgentService(gphome string, hostList []string, service
 		return nil
 	}
 
-	err := execCommand(fmt.Sprintf("%s/bin/gpssh", gphome), append(args, p.UserArg, "daemon-reload")...).Run()
+	err := executor.Run(hostnames, remote.Command(p.ServiceCmd, p.UserArg, "daemon-reload")).Err()
 	if err != nil {
 		return fmt.Errorf("could not reload agent service file %s on segment hosts: %w", servicePath, err)
 	}
@@ -255,27 +295,20 @@ func (p GpPlatform) ReloadAgentService(gphome string, hostList []string, service
 
 func (p GpPlatform) CreateAndInstallAgentServiceFile(hostnames []string, gphome string, serviceDir string, serviceName string) error {
 	agentServiceContents := p.GenerateServiceFileContents("agent", gphome, serviceName)
-	localAgentServiceFilePath := fmt.Sprintf("./%s_agent.%s", serviceName, p.ServiceExt)
-	err := writeServiceFileFunc(localAgentServiceFilePath, agentServiceContents)
-	if err != nil {
-		return err
-	}
-	defer os.Remove(localAgentServiceFilePath)
-
 	remoteAgentServiceFilePath := fmt.Sprintf("%s/%s_agent.%s", serviceDir, serviceName, p.ServiceExt)
-	hostList := make([]string, 0)
-	for _, host := range hostnames {
-		hostList = append(hostList, "-h", host)
-	}
 
 	// Copy the file to segment host service directories
-	args := append(hostList, localAgentServiceFilePath, fmt.Sprintf("=:%s", remoteAgentServiceFilePath))
-	err = GpsyncCommand(fmt.Sprintf("%s/bin/gpsync", gphome), args...).Run()
+	executor, err := newRemoteExecutor()
+	if err != nil {
+		return fmt.Errorf("could not copy agent service files to segment hosts: %w", err)
+	}
+
+	err = executor.Copy(hostnames, []byte(agentServiceContents), remoteAgentServiceFilePath, 0644).Err()
 	if err != nil {
 		return fmt.Errorf("could not copy agent service files to segment hosts: %w", err)
 	}
 
-	err = p.ReloadAgentService(gphome, hostList, remoteAgentServiceFilePath)
+	err = p.ReloadAgentService(hostnames, remoteAgentServiceFilePath)
 	if err != nil {
 		return err
 	}
@@ -284,6 +317,37 @@ func (p GpPlatform) CreateAndInstallAgentServiceFile(hostnames []string, gphome
 	return nil
 }
 
+// RemoveAgentService stops the agent service on the segment hosts and removes
+// its service file. The service is only stopped on the hosts that still have
+// the file, so that removing it again succeeds.
+func (p GpPlatform) RemoveAgentService(executor remote.Executor, hostnames []string, serviceDir string, serviceName string) error {
+	remoteAgentServiceFilePath := fmt.Sprintf("%s/%s_agent.%s", serviceDir, serviceName, p.ServiceExt)
+
+	stopCommand := remote.Command(p.ServiceCmd, p.UserArg, "stop", fmt.Sprintf("%s_agent", serviceName))
+	if p.OS == constants.PlatformDarwin {
+		stopCommand = remote.Command(p.ServiceCmd, "unload", remoteAgentServiceFilePath)
+	}
+	err := executor.Run(hostnames, fmt.Sprintf("test ! -f %s || %s", remote.Quote(remoteAgentServiceFilePath), stopCommand)).Err()
+	if err != nil {
+		return fmt.Errorf("could not stop agent service on segment hosts: %w", err)
+	}
+
+	err = executor.Run(hostnames, remote.Command("rm", "-f", remoteAgentServiceFilePath)).Err()
+	if err != nil {
+		return fmt.Errorf("could not remove agent service file %s on segment hosts: %w", remoteAgentServiceFilePath, err)
+	}
+
+	if p.OS != constants.PlatformDarwin {
+		err = p.reloadAgentService(executor, hostnames, remoteAgentServiceFilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	gplog.Debug("Removed agent service file %s on segment hosts", remoteAgentServiceFilePath)
+	return nil
+}
+
 func (p GpPlatform) GetStartHubCommand(serviceName string) *exec.Cmd {
 	args := []string{p.UserArg, "start", fmt.Sprintf("%s_hub", serviceName)}
 
@@ -295,7 +359,13 @@ func (p GpPlatform) GetStartHubCommand(serviceName string) *exec.Cmd {
 }
 
 func (p GpPlatform) GetStartAgentCommandString(serviceName string) []string {
-	return []string{p.ServiceCmd, p.UserArg, "start", fmt.Sprintf("%s_agent", serviceName)}
+	args := []string{p.ServiceCmd, p.UserArg, "start", fmt.Sprintf("%s_agent", serviceName)}
+
+	if p.OS == constants.PlatformDarwin { // empty strings are also treated as arguments
+		args = append(args[:1], args[2:]...)
+	}
+
+	return args
 }
 
 func (p GpPlatform) GetServiceStatusMessage(serviceName string) (string, error) {
@@ -320,12 +390,17 @@ Example service status output
 
 Linux:
 ExecMainStartTimestamp=Sun 2023-08-20 14:43:35 UTC
+ExecMainStartTimestampMonotonic=286453245
 ExecMainPID=83008
 ExecMainCode=0
 ExecMainStatus=0
+Result=success
+ActiveState=active
+SubState=running
 
 Darwin:
 {
+	"LastExitStatus" = 0;
 	"PID" = 19909;
 	"Program" = "/usr/local/gpdb/bin/gp";
 	"ProgramArguments" = (
@@ -335,7 +410,7 @@ Darwin:
 };
 */
 func (p GpPlatform) ParseServiceStatusMessage(message string) idl.ServiceStatus {
-	var status, uptime string
+	var activeState, subState, result, startTimestamp string
 	var pid int
 
 	lines := strings.Split(message, "\n")
@@ -346,60 +421,204 @@ func (p GpPlatform) ParseServiceStatusMessage(message string) idl.ServiceStatus
 			results := strings.Split(line, " = ")
 			pid, _ = strconv.Atoi(results[1])
 
-		case strings.HasPrefix(line, "ExecMainPID="): // for linux
-			results := strings.Split(line, "=")
-			pid, _ = strconv.Atoi(results[1])
+		case strings.HasPrefix(line, "\"LastExitStatus\" ="): // for darwin
+			results := strings.Split(line, " = ")
+			result = "success"
+			if results[1] != "0" {
+				result = "exit-code"
+			}
+
+		default: // for linux
+			key, value, _ := strings.Cut(line, "=")
+			switch key {
+			case "ExecMainPID":
+				pid, _ = strconv.Atoi(value)
+			case "ExecMainStartTimestampMonotonic":
+				startTimestamp = value
+			case "ActiveState":
+				activeState = value
+			case "SubState":
+				subState = value
+			case "Result":
+				result = value
+			}
+		}
+	}
 
-		case strings.HasPrefix(line, "ExecMainStartTimestamp="): // for linux
-			result := strings.Split(line, "=")
-			uptime = result[1]
+	// systemd keeps the start time of the last run once the service stopped.
+	// The monotonic one is used, as the other is formatted in the local time
+	// zone whose abbreviation does not tell its offset.
+	var startTime *timestamppb.Timestamp
+	if pid > 0 && startTimestamp != "" {
+		started, err := monotonicTime(startTimestamp)
+		if err != nil {
+			gplog.Debug("could not parse the service start time %q: %s", startTimestamp, err)
+		} else {
+			startTime = timestamppb.New(started)
 		}
 	}
 
-	if pid > 0 {
-		status = "running"
-	} else {
-		status = "not running"
+	return idl.ServiceStatus{
+		Status:      serviceState(activeState, pid),
+		Pid:         uint32(pid),
+		StartTime:   startTime,
+		ActiveState: activeState,
+		SubState:    subState,
+		Result:      result,
+	}
+}
+
+// monotonicTime converts the microseconds since boot that systemd reports into
+// a time
+func monotonicTime(usec string) (time.Time, error) {
+	elapsed, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	boot, err := bootTime()
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	return idl.ServiceStatus{Status: status, Uptime: uptime, Pid: uint32(pid)}
+	return boot.Add(time.Duration(elapsed) * time.Microsecond).Truncate(time.Second), nil
 }
 
-func (p GpPlatform) DisplayServiceStatus(outfile io.Writer, serviceName string, statuses []*idl.ServiceStatus, skipHeader bool) {
-	w := new(tabwriter.Writer)
-	w.Init(outfile, 0, 8, 2, '\t', 0)
+// monotonicBootTime returns when the host booted, in terms of the
+// CLOCK_MONOTONIC systemd timestamps its units with
+func monotonicBootTime() (time.Time, error) {
+	var ts unix.Timespec
+	err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not read the monotonic clock: %w", err)
+	}
 
-	if !skipHeader {
-		fmt.Fprintln(w, "ROLE\tHOST\tSTATUS\tPID\tUPTIME")
+	return time.Now().Add(-time.Duration(ts.Nano())), nil
+}
+
+// serviceState returns the state shown to the user for the systemd active
+// state of the service. launchctl has no such state, so a service is running
+// when it has a PID.
+func serviceState(activeState string, pid int) string {
+	switch activeState {
+	case "":
+		if pid > 0 {
+			return "running"
+		}
+		return "not running"
+	case "active", "reloading":
+		return "running"
+	case "activating":
+		return "starting"
+	case "deactivating":
+		return "stopping"
+	case "failed":
+		return "failed"
+	default:
+		return "not running"
 	}
+}
+
+// ServiceStatusRecord is the status of a service as output by the status commands
+type ServiceStatusRecord struct {
+	Role              string     `json:"role" yaml:"role"`
+	Host              string     `json:"host" yaml:"host"`
+	Status            string     `json:"status" yaml:"status"`
+	Pid               uint32     `json:"pid" yaml:"pid"`
+	Uptime            string     `json:"uptime" yaml:"uptime"`
+	CertificateExpiry *time.Time `json:"certificateExpiry,omitempty" yaml:"certificateExpiry,omitempty"` // of the certificate expiring first
+	StartTime         *time.Time `json:"startTime,omitempty" yaml:"startTime,omitempty"`
+	MemoryRSS         uint64     `json:"memoryRss,omitempty" yaml:"memoryRss,omitempty"` // bytes
+	CPUSeconds        float64    `json:"cpuSeconds,omitempty" yaml:"cpuSeconds,omitempty"`
+	Connections       uint32     `json:"connections,omitempty" yaml:"connections,omitempty"`
+	Version           string     `json:"version,omitempty" yaml:"version,omitempty"`
+	ConfigChecksum    string     `json:"configChecksum,omitempty" yaml:"configChecksum,omitempty"`
+	ActiveState       string     `json:"activeState,omitempty" yaml:"activeState,omitempty"`
+	SubState          string     `json:"subState,omitempty" yaml:"subState,omitempty"`
+	Result            string     `json:"result,omitempty" yaml:"result,omitempty"`
+	LastSeen          *time.Time `json:"lastSeen,omitempty" yaml:"lastSeen,omitempty"` // by the heartbeat of the hub
+}
+
+// ServiceStatusReport returns the statuses of the services with the given role
+func ServiceStatusReport(serviceName string, statuses []*idl.ServiceStatus) *Report {
+	report := NewReport("ROLE", "HOST", "STATUS", "PID", "UPTIME", "CERT EXPIRY")
 
 	for _, s := range statuses {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", serviceName, s.Host, s.Status, s.Pid, s.Uptime)
+		record := ServiceStatusRecord{
+			Role:           serviceName,
+			Host:           s.Host,
+			Status:         s.Status,
+			Pid:            s.Pid,
+			Uptime:         s.Uptime,
+			MemoryRSS:      s.MemoryRss,
+			CPUSeconds:     s.CpuTime.AsDuration().Seconds(),
+			Connections:    s.Connections,
+			Version:        s.Version,
+			ConfigChecksum: s.ConfigChecksum,
+			ActiveState:    s.ActiveState,
+			SubState:       s.SubState,
+			Result:         s.Result,
+		}
+		if s.StartTime != nil {
+			startTime := s.StartTime.AsTime().UTC()
+			record.StartTime = &startTime
+		}
+		if s.LastSeen != nil {
+			lastSeen := s.LastSeen.AsTime().UTC()
+			record.LastSeen = &lastSeen
+		}
+		certExpiry := "-"
+		if cert := EarliestExpiry(s.Certificates); cert != nil {
+			notAfter := cert.NotAfter.AsTime().UTC()
+			record.CertificateExpiry = &notAfter
+			certExpiry = notAfter.Format(time.RFC3339)
+		}
+
+		report.Add(record, serviceName, s.Host, s.Status, strconv.FormatUint(uint64(s.Pid), 10), s.Uptime, certExpiry)
 	}
-	w.Flush()
+
+	return report
 }
 
 // Allow systemd services to run on startup and be started/stopped without root access
 // This is a no-op on Mac, as launchctl lacks the concept of user lingering
-func (p GpPlatform) EnableUserLingering(hostnames []string, gphome string, serviceUser string) error {
+func (p GpPlatform) EnableUserLingering(hostnames []string, serviceUser string) error {
 	if p.OS != "linux" {
 		return nil
 	}
 
-	hostList := make([]string, 0)
-	for _, host := range hostnames {
-		hostList = append(hostList, "-h", host)
+	err := runOnHosts(hostnames, "loginctl", "enable-linger", serviceUser)
+	if err != nil {
+		return fmt.Errorf("could not enable user lingering: %w", err)
+	}
+
+	return nil
+}
+
+// DisableUserLingering undoes EnableUserLingering. This is a no-op on Mac.
+func (p GpPlatform) DisableUserLingering(executor remote.Executor, hostnames []string, serviceUser string) error {
+	if p.OS != "linux" {
+		return nil
 	}
 
-	remoteCmd := append(hostList, "loginctl enable-linger ", serviceUser)
-	err := execCommand(fmt.Sprintf("%s/bin/gpssh", gphome), remoteCmd...).Run()
+	err := executor.Run(hostnames, remote.Command("loginctl", "disable-linger", serviceUser)).Err()
 	if err != nil {
-		return fmt.Errorf("could not enable user lingering: %w", err)
+		return fmt.Errorf("could not disable user lingering: %w", err)
 	}
 
 	return nil
 }
 
+// runOnHosts runs the command given by args on every host over SSH
+func runOnHosts(hostnames []string, args ...string) error {
+	executor, err := newRemoteExecutor()
+	if err != nil {
+		return err
+	}
+
+	return executor.Run(hostnames, remote.Command(args...)).Err()
+}
+
 func SetExecCommand(command exectest.Command) {
 	execCommand = command
 }
@@ -408,6 +627,14 @@ func ResetExecCommand() {
 	execCommand = exec.Command
 }
 
+func SetBootTime(bootTimeFunc func() (time.Time, error)) {
+	bootTime = bootTimeFunc
+}
+
+func ResetBootTime() {
+	bootTime = monotonicBootTime
+}
+
 func SetWriteServiceFileFunc(writeFunc func(filename string, contents string) error) {
 	writeServiceFileFunc = writeFunc
 }
@@ -415,3 +642,11 @@ func SetWriteServiceFileFunc(writeFunc func(filename string, contents string) er
 func ResetWriteServiceFileFunc() {
 	writeServiceFileFunc = WriteServiceFile
 }
+
+func SetNewRemoteExecutor(newExecutor func() (remote.Executor, error)) {
+	newRemoteExecutor = newExecutor
+}
+
+func ResetNewRemoteExecutor() {
+	newRemoteExecutor = remote.NewExecutor
+}
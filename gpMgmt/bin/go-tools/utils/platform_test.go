@@ -1,21 +1,26 @@
 package utils_test
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/greenplum-db/gpdb/gp/constants"
 
 	"github.com/greenplum-db/gp-common-go-libs/testhelper"
 	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/remote"
+	"github.com/greenplum-db/gpdb/gp/testutils"
 	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
 	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func init() {
@@ -31,13 +36,11 @@ func TestMain(m *testing.M) {
 }
 
 func setMocks(t *testing.T) {
-	utils.GpsyncCommand = nil
 	utils.LoadServiceCommand = nil
 	utils.UnloadServiceCommand = nil
 }
 
 func resetMocks(t *testing.T) {
-	utils.GpsyncCommand = exec.Command
 	utils.LoadServiceCommand = exec.Command
 	utils.UnloadServiceCommand = exec.Command
 }
@@ -48,11 +51,12 @@ func TestCreateServiceDir(t *testing.T) {
 	t.Run("CreateServiceDir returns error", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
 
-		utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
-		defer utils.ResetExecCommand()
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("mkdir", errors.New("exit status 1"))}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
-		err := platform.CreateServiceDir([]string{"host1"}, "path/to/serviceDir", "gphome")
-		if err.Error() != "could not create service directory path/to/serviceDir on hosts: exit status 1" {
+		err := platform.CreateServiceDir([]string{"host1"}, "path/to/serviceDir")
+		if err.Error() != "could not create service directory path/to/serviceDir on hosts: failed on 1 of 1 hosts: host host1: exit status 1" {
 			t.Fatalf("unexpected error: %#v", err)
 		}
 	})
@@ -60,13 +64,34 @@ func TestCreateServiceDir(t *testing.T) {
 	t.Run("CreateServiceDir runs successfully", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
 
-		utils.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer utils.ResetExecCommand()
+		executor := &testutils.MockExecutor{}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
-		err := platform.CreateServiceDir([]string{"host1"}, "path/to/serviceDir", "gphome")
+		err := platform.CreateServiceDir([]string{"host1"}, "path/to/serviceDir")
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
+
+		expected := []string{"mkdir -p path/to/serviceDir"}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expected)
+		}
+	})
+
+	t.Run("CreateServiceDir errors when not able to connect to the hosts", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+
+		expected := errors.New("error")
+		utils.SetNewRemoteExecutor(func() (remote.Executor, error) {
+			return nil, expected
+		})
+		defer utils.ResetNewRemoteExecutor()
+
+		err := platform.CreateServiceDir([]string{"host1"}, "path/to/serviceDir")
+		if !errors.Is(err, expected) {
+			t.Fatalf("got %v, want %v", err, expected)
+		}
 	})
 }
 
@@ -226,10 +251,14 @@ func TestReloadServices(t *testing.T) {
 			utils.SetExecCommand(exectest.NewCommand(exectest.Success))
 			defer utils.ResetExecCommand()
 
+			executor := &testutils.MockExecutor{}
+			utils.SetNewRemoteExecutor(executor.NewExecutor())
+			defer utils.ResetNewRemoteExecutor()
+
 			if tc.service == "hub" {
 				err = platform.ReloadHubService("/path/to/service/file")
 			} else {
-				err = platform.ReloadAgentService("gphome", []string{"host1"}, "/path/to/service/file")
+				err = platform.ReloadAgentService([]string{"host1"}, "/path/to/service/file")
 			}
 
 			if err != nil {
@@ -240,7 +269,7 @@ func TestReloadServices(t *testing.T) {
 
 	failure_tests_darwin := []test{
 		{os: "darwin", service: "hub"},
-		{os: "darwin", service: "agent", errSuffix: " on segment hosts"},
+		{os: "darwin", service: "agent", errSuffix: " on segment hosts: failed on 1 of 1 hosts: host host1"},
 	}
 	for _, tc := range failure_tests_darwin {
 		t.Run(fmt.Sprintf("reloading of %s service returns error when not able to unload the file on darwin", tc.service), func(t *testing.T) {
@@ -252,10 +281,14 @@ func TestReloadServices(t *testing.T) {
 			utils.UnloadServiceCommand = exectest.NewCommand(exectest.Failure)
 			utils.LoadServiceCommand = exectest.NewCommand(exectest.Success)
 
+			executor := &testutils.MockExecutor{Err: testutils.FailOn("launchctl unload", errors.New("exit status 1"))}
+			utils.SetNewRemoteExecutor(executor.NewExecutor())
+			defer utils.ResetNewRemoteExecutor()
+
 			if tc.service == "hub" {
 				err = platform.ReloadHubService("/path/to/service/file")
 			} else {
-				err = platform.ReloadAgentService("gphome", []string{"host1"}, "/path/to/service/file")
+				err = platform.ReloadAgentService([]string{"host1"}, "/path/to/service/file")
 			}
 
 			expectedErr := fmt.Sprintf("could not unload %s service file /path/to/service/file%s: exit status 1", tc.service, tc.errSuffix)
@@ -273,10 +306,14 @@ func TestReloadServices(t *testing.T) {
 			utils.UnloadServiceCommand = exectest.NewCommand(exectest.Success)
 			utils.LoadServiceCommand = exectest.NewCommand(exectest.Failure)
 
+			executor := &testutils.MockExecutor{Err: testutils.FailOn("launchctl load", errors.New("exit status 1"))}
+			utils.SetNewRemoteExecutor(executor.NewExecutor())
+			defer utils.ResetNewRemoteExecutor()
+
 			if tc.service == "hub" {
 				err = platform.ReloadHubService("/path/to/service/file")
 			} else {
-				err = platform.ReloadAgentService("gphome", []string{"host1"}, "/path/to/service/file")
+				err = platform.ReloadAgentService([]string{"host1"}, "/path/to/service/file")
 			}
 
 			expectedErr := fmt.Sprintf("could not load %s service file /path/to/service/file%s: exit status 1", tc.service, tc.errSuffix)
@@ -289,7 +326,7 @@ func TestReloadServices(t *testing.T) {
 
 	failure_tests_linux := []test{
 		{os: constants.PlatformLinux, service: "hub"},
-		{os: constants.PlatformLinux, service: "agent", errSuffix: " on segment hosts"},
+		{os: constants.PlatformLinux, service: "agent", errSuffix: " on segment hosts: failed on 1 of 1 hosts: host host1"},
 	}
 	for _, tc := range failure_tests_linux {
 		t.Run(fmt.Sprintf("reloading of %s service returns error when not able to reload the file on linux", tc.service), func(t *testing.T) {
@@ -299,10 +336,14 @@ func TestReloadServices(t *testing.T) {
 			utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
 			defer utils.ResetExecCommand()
 
+			executor := &testutils.MockExecutor{Err: testutils.FailOn("daemon-reload", errors.New("exit status 1"))}
+			utils.SetNewRemoteExecutor(executor.NewExecutor())
+			defer utils.ResetNewRemoteExecutor()
+
 			if tc.service == "hub" {
 				err = platform.ReloadHubService("/path/to/service/file")
 			} else {
-				err = platform.ReloadAgentService("gphome", []string{"host1"}, "/path/to/service/file")
+				err = platform.ReloadAgentService([]string{"host1"}, "/path/to/service/file")
 			}
 
 			expectedErr := fmt.Sprintf("could not reload %s service file /path/to/service/file%s: exit status 1", tc.service, tc.errSuffix)
@@ -376,82 +417,47 @@ func TestCreateAndInstallAgentServiceFile(t *testing.T) {
 	t.Run("CreateAndInstallAgentServiceFile runs successfully", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
 
-		utils.SetWriteServiceFileFunc(func(filename, contents string) error {
-			return nil
-		})
-		defer utils.ResetWriteServiceFileFunc()
-
-		setMocks(t)
-		defer resetMocks(t)
-		utils.GpsyncCommand = exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
-			if !strings.HasSuffix(utility, "gpsync") {
-				t.Fatalf("got %q, want gpsync", utility)
-			}
-
-			expectedArgs := []string{"-h", "host1", "-h", "host2", "./gptest_agent.service", "=:testdir/gptest_agent.service"}
-			if !reflect.DeepEqual(args, expectedArgs) {
-				t.Fatalf("got %+v, want %+v", args, expectedArgs)
-			}
-		})
-
-		utils.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer utils.ResetExecCommand()
+		executor := &testutils.MockExecutor{}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
 		err := platform.CreateAndInstallAgentServiceFile([]string{"host1", "host2"}, "gphome", "testdir", "gptest")
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
-	})
-
-	t.Run("CreateAndInstallAgentServiceFile errors when gpsync fails", func(t *testing.T) {
-		platform := GetPlatform(constants.PlatformLinux, t)
-
-		utils.SetWriteServiceFileFunc(func(filename, contents string) error {
-			return nil
-		})
-		defer utils.ResetWriteServiceFileFunc()
 
-		setMocks(t)
-		defer resetMocks(t)
-		utils.GpsyncCommand = exectest.NewCommandWithVerifier(exectest.Failure, func(utility string, args ...string) {
-			if !strings.HasSuffix(utility, "gpsync") {
-				t.Fatalf("got %q, want gpsync", utility)
-			}
-
-			expectedArgs := []string{"-h", "host1", "-h", "host2", "./gptest_agent.service", "=:testdir/gptest_agent.service"}
-			if !reflect.DeepEqual(args, expectedArgs) {
-				t.Fatalf("got %+v, want %+v", args, expectedArgs)
-			}
-		})
-
-		utils.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer utils.ResetExecCommand()
+		expectedCopies := []testutils.MockCopy{{
+			Hostnames: []string{"host1", "host2"},
+			Contents:  platform.GenerateServiceFileContents("agent", "gphome", "gptest"),
+			Path:      "testdir/gptest_agent.service",
+			Mode:      0644,
+		}}
+		if !reflect.DeepEqual(executor.Copies, expectedCopies) {
+			t.Fatalf("got %+v, want %+v", executor.Copies, expectedCopies)
+		}
 
-		err := platform.CreateAndInstallAgentServiceFile([]string{"host1", "host2"}, "gphome", "testdir", "gptest")
-		expectedErr := "could not copy agent service files to segment hosts: exit status 1"
-		if err.Error() != expectedErr {
-			t.Fatalf("got %q, want %q", err, expectedErr)
+		expectedCommands := []string{"systemctl --user daemon-reload"}
+		if !reflect.DeepEqual(executor.Commands, expectedCommands) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expectedCommands)
 		}
 	})
 
-	t.Run("CreateAndInstallAgentServiceFile errors when not able to write to a file", func(t *testing.T) {
+	t.Run("CreateAndInstallAgentServiceFile errors when the copy fails", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
 
-		utils.SetWriteServiceFileFunc(func(filename, contents string) error {
-			return os.ErrPermission
-		})
-		defer utils.ResetWriteServiceFileFunc()
-
-		setMocks(t)
-		defer resetMocks(t)
-		utils.GpsyncCommand = exectest.NewCommand(exectest.Success)
+		executor := &testutils.MockExecutor{Err: func(host string, command string) error {
+			if host == "host2" && strings.HasPrefix(command, "copy") {
+				return errors.New("exit status 1")
+			}
 
-		utils.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer utils.ResetExecCommand()
+			return nil
+		}}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
 		err := platform.CreateAndInstallAgentServiceFile([]string{"host1", "host2"}, "gphome", "testdir", "gptest")
-		expectedErr := os.ErrPermission
-		if !errors.Is(err, expectedErr) {
+		expectedErr := "could not copy agent service files to segment hosts: failed on 1 of 2 hosts: host host2: exit status 1"
+		if err.Error() != expectedErr {
 			t.Fatalf("got %q, want %q", err, expectedErr)
 		}
 	})
@@ -459,20 +465,12 @@ func TestCreateAndInstallAgentServiceFile(t *testing.T) {
 	t.Run("CreateAndInstallAgentServiceFile errors when not able to reload the service", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
 
-		utils.SetWriteServiceFileFunc(func(filename, contents string) error {
-			return nil
-		})
-		defer utils.ResetWriteServiceFileFunc()
-
-		setMocks(t)
-		defer resetMocks(t)
-		utils.GpsyncCommand = exectest.NewCommand(exectest.Success)
-
-		utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
-		defer utils.ResetExecCommand()
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("daemon-reload", errors.New("exit status 1"))}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
 		err := platform.CreateAndInstallAgentServiceFile([]string{"host1", "host2"}, "gphome", "testdir", "gptest")
-		expectedErr := "could not reload agent service file testdir/gptest_agent.service on segment hosts: exit status 1"
+		expectedErr := "could not reload agent service file testdir/gptest_agent.service on segment hosts: failed on 2 of 2 hosts: host host1: exit status 1; host host2: exit status 1"
 		if err.Error() != expectedErr {
 			t.Fatalf("got %q, want %q", err, expectedErr)
 		}
@@ -520,7 +518,7 @@ func TestGetStartAgentCommandString(t *testing.T) {
 		platform := GetPlatform(constants.PlatformDarwin, t)
 
 		result := platform.GetStartAgentCommandString("gptest")
-		expected := []string{"launchctl", "", "start", "gptest_agent"}
+		expected := []string{"launchctl", "start", "gptest_agent"}
 		if !reflect.DeepEqual(result, expected) {
 			t.Fatalf("got %+v, want %+v", result, expected)
 		}
@@ -607,6 +605,12 @@ func TestGetServiceStatusMessage(t *testing.T) {
 func TestParseServiceStatusMessage(t *testing.T) {
 	testhelper.SetupTestLogger()
 
+	// the monotonic start time of the examples is 14:43:35 UTC
+	utils.SetBootTime(func() (time.Time, error) {
+		return time.Date(2023, 8, 20, 14, 38, 48, 546755000, time.UTC), nil
+	})
+	defer utils.ResetBootTime()
+
 	cases := []struct {
 		name     string
 		os       string
@@ -632,7 +636,7 @@ func TestParseServiceStatusMessage(t *testing.T) {
 				);
 			};
 			`,
-			expected: &idl.ServiceStatus{Status: "running", Pid: uint32(19909)},
+			expected: &idl.ServiceStatus{Status: "running", Pid: uint32(19909), Result: "success"},
 		},
 		{
 			name: "ParseServiceStatusMessage gets status for darwin when service is not running",
@@ -652,20 +656,20 @@ func TestParseServiceStatusMessage(t *testing.T) {
 				);
 			};
 			`,
-			expected: &idl.ServiceStatus{Status: "not running"},
+			expected: &idl.ServiceStatus{Status: "not running", Result: "success"},
 		},
 		{
 			name: "ParseServiceStatusMessage gets status for linux when service is running",
 			os:   constants.PlatformLinux,
 			message: `
-			ExecMainStartTimestamp=Sun 2023-08-20 14:43:35 UTC
+			ExecMainStartTimestamp=Sun 2023-08-20 16:43:35 CEST
 			ExecMainStartTimestampMonotonic=286453245
 			ExecMainExitTimestampMonotonic=0
 			ExecMainPID=83008
 			ExecMainCode=0
 			ExecMainStatus=0
 			`,
-			expected: &idl.ServiceStatus{Status: "running", Uptime: "Sun 2023-08-20 14:43:35 UTC", Pid: uint32(83008)},
+			expected: &idl.ServiceStatus{Status: "running", StartTime: timestamppb.New(time.Date(2023, 8, 20, 14, 43, 35, 0, time.UTC)), Pid: uint32(83008)},
 		},
 		{
 			name: "ParseServiceStatusMessage gets status for linux when service is not running",
@@ -679,6 +683,49 @@ func TestParseServiceStatusMessage(t *testing.T) {
 			`,
 			expected: &idl.ServiceStatus{Status: "not running", Pid: uint32(0)},
 		},
+		{
+			name: "ParseServiceStatusMessage gets the state of the unit for linux when service is running",
+			os:   constants.PlatformLinux,
+			message: `
+			ExecMainStartTimestamp=Sun 2023-08-20 14:43:35 UTC
+			ExecMainStartTimestampMonotonic=286453245
+			ExecMainPID=83008
+			Result=success
+			ActiveState=active
+			SubState=running
+			`,
+			expected: &idl.ServiceStatus{
+				Status:      "running",
+				StartTime:   timestamppb.New(time.Date(2023, 8, 20, 14, 43, 35, 0, time.UTC)),
+				Pid:         uint32(83008),
+				ActiveState: "active",
+				SubState:    "running",
+				Result:      "success",
+			},
+		},
+		{
+			name: "ParseServiceStatusMessage gets the state of the unit for linux when service has failed",
+			os:   constants.PlatformLinux,
+			message: `
+			ExecMainStartTimestamp=Sun 2023-08-20 14:43:35 UTC
+			ExecMainPID=0
+			Result=exit-code
+			ActiveState=failed
+			SubState=failed
+			`,
+			expected: &idl.ServiceStatus{Status: "failed", ActiveState: "failed", SubState: "failed", Result: "exit-code"},
+		},
+		{
+			name: "ParseServiceStatusMessage gets the state of the unit for linux when service is starting",
+			os:   constants.PlatformLinux,
+			message: `
+			ExecMainPID=0
+			Result=success
+			ActiveState=activating
+			SubState=auto-restart
+			`,
+			expected: &idl.ServiceStatus{Status: "starting", ActiveState: "activating", SubState: "auto-restart", Result: "success"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -686,53 +733,121 @@ func TestParseServiceStatusMessage(t *testing.T) {
 			platform := GetPlatform(constants.PlatformDarwin, t)
 
 			result := platform.ParseServiceStatusMessage(tc.message)
-			if !reflect.DeepEqual(&result, tc.expected) {
+			if !proto.Equal(&result, tc.expected) {
 				t.Fatalf("got %+v, want %+v", &result, tc.expected)
 			}
 		})
 	}
 }
 
-func TestDisplayServiceStatus(t *testing.T) {
+func TestRemoveAgentService(t *testing.T) {
 	testhelper.SetupTestLogger()
 
-	t.Run("DisplayServiceStatus displays the service message", func(t *testing.T) {
-		var output bytes.Buffer
+	t.Run("RemoveAgentService stops the service and removes its file", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
-		statuses := []*idl.ServiceStatus{
-			{
-				Host:   "sdw1",
-				Status: "running",
-				Pid:    1234,
-				Uptime: "5H",
-			},
+
+		executor := &testutils.MockExecutor{}
+		err := platform.RemoveAgentService(executor, []string{"host1", "host2"}, "testdir", "gptest")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{
+			"test ! -f testdir/gptest_agent.service || systemctl --user stop gptest_agent",
+			"rm -f testdir/gptest_agent.service",
+			"systemctl --user daemon-reload",
+		}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expected)
+		}
+	})
+
+	t.Run("RemoveAgentService unloads the service on other platforms", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformDarwin, t)
+
+		executor := &testutils.MockExecutor{}
+		err := platform.RemoveAgentService(executor, []string{"host1"}, "testdir", "gptest")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
 		}
 
-		platform.DisplayServiceStatus(&output, "hub", statuses, true)
+		expected := []string{"test ! -f testdir/gptest_agent.plist || launchctl unload testdir/gptest_agent.plist", "rm -f testdir/gptest_agent.plist"}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expected)
+		}
+	})
 
-		expected := "hub\tsdw1\trunning\t\t1234\t5H\n"
-		if output.String() != expected {
-			t.Fatalf("got %q, want %q", output.String(), expected)
+	t.Run("RemoveAgentService returns error when the service can not be stopped", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("stop", errors.New("exit status 1"))}
+
+		err := platform.RemoveAgentService(executor, []string{"host1"}, "testdir", "gptest")
+		expected := "could not stop agent service on segment hosts: failed on 1 of 1 hosts: host host1: exit status 1"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
 		}
 	})
+}
 
-	t.Run("DisplayServiceStatus displays the service message with header", func(t *testing.T) {
-		var output bytes.Buffer
+func TestRemoveHubService(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("RemoveHubService stops the service and removes its file", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
-		statuses := []*idl.ServiceStatus{
-			{
-				Host:   "sdw1",
-				Status: "running",
-				Pid:    1234,
-				Uptime: "5H",
-			},
+		serviceDir := t.TempDir()
+		serviceFile := filepath.Join(serviceDir, "gptest_hub.service")
+		err := os.WriteFile(serviceFile, []byte{}, 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
 		}
 
-		platform.DisplayServiceStatus(&output, "hub", statuses, false)
+		var calls []string
+		utils.SetExecCommand(exectest.NewCommandWithVerifier(exectest.Success, func(utility string, args ...string) {
+			calls = append(calls, strings.Join(append([]string{utility}, args...), " "))
+		}))
+		defer utils.ResetExecCommand()
 
-		expected := "ROLE\tHOST\tSTATUS\t\tPID\tUPTIME\nhub\tsdw1\trunning\t\t1234\t5H\n"
-		if output.String() != expected {
-			t.Fatalf("got %q, want %q", output.String(), expected)
+		err = platform.RemoveHubService(serviceDir, "gptest")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"systemctl --user stop gptest_hub", "systemctl --user daemon-reload"}
+		if !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("got %+v, want %+v", calls, expected)
+		}
+		if _, err := os.Stat(serviceFile); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected %s to be removed, got %v", serviceFile, err)
+		}
+	})
+
+	t.Run("RemoveHubService does nothing when the service file is gone", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+
+		utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer utils.ResetExecCommand()
+
+		err := platform.RemoveHubService(t.TempDir(), "gptest")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("RemoveHubService returns error when the service can not be stopped", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+		serviceDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(serviceDir, "gptest_hub.service"), []byte{}, 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer utils.ResetExecCommand()
+
+		err = platform.RemoveHubService(serviceDir, "gptest")
+		expected := "could not stop hub service: exit status 1"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
 		}
 	})
 }
@@ -743,19 +858,25 @@ func TestEnableUserLingering(t *testing.T) {
 	t.Run("EnableUserLingering run successfully for linux", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
 
-		utils.SetExecCommand(exectest.NewCommand(exectest.Success))
-		defer utils.ResetExecCommand()
+		executor := &testutils.MockExecutor{}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
-		err := platform.EnableUserLingering([]string{"host1", "host2"}, "/path/to/gphome", "serviceUser")
+		err := platform.EnableUserLingering([]string{"host1", "host2"}, "serviceUser")
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
+
+		expected := []string{"loginctl enable-linger serviceUser"}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expected)
+		}
 	})
 
 	t.Run("EnableUserLingering runs successfully for other platforms", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformDarwin, t)
 
-		err := platform.EnableUserLingering([]string{"host1", "host2"}, "path/to/gphome", "serviceUser")
+		err := platform.EnableUserLingering([]string{"host1", "host2"}, "serviceUser")
 		if err != nil {
 			t.Fatalf("unexpected error: %#v", err)
 		}
@@ -763,17 +884,48 @@ func TestEnableUserLingering(t *testing.T) {
 
 	t.Run("EnableUserLingering returns error on failure", func(t *testing.T) {
 		platform := GetPlatform(constants.PlatformLinux, t)
-		utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
-		defer utils.ResetExecCommand()
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("loginctl", errors.New("exit status 1"))}
+		utils.SetNewRemoteExecutor(executor.NewExecutor())
+		defer utils.ResetNewRemoteExecutor()
 
-		err := platform.EnableUserLingering([]string{"host1", "host2"}, "path/to/gphome", "serviceUser")
-		expected := "could not enable user lingering: exit status 1"
+		err := platform.EnableUserLingering([]string{"host1", "host2"}, "serviceUser")
+		expected := "could not enable user lingering: failed on 2 of 2 hosts: host host1: exit status 1; host host2: exit status 1"
 		if err.Error() != expected {
 			t.Fatalf("got %q, want %q", err, expected)
 		}
 	})
 }
 
+func TestDisableUserLingering(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("DisableUserLingering runs successfully for linux", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+
+		executor := &testutils.MockExecutor{}
+		err := platform.DisableUserLingering(executor, []string{"host1", "host2"}, "serviceUser")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := []string{"loginctl disable-linger serviceUser"}
+		if !reflect.DeepEqual(executor.Commands, expected) {
+			t.Fatalf("got %+v, want %+v", executor.Commands, expected)
+		}
+	})
+
+	t.Run("DisableUserLingering returns error on failure", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+		executor := &testutils.MockExecutor{Err: testutils.FailOn("loginctl", errors.New("exit status 1"))}
+
+		err := platform.DisableUserLingering(executor, []string{"host1"}, "serviceUser")
+		expected := "could not disable user lingering: failed on 1 of 1 hosts: host host1: exit status 1"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %q", err, expected)
+		}
+	})
+}
+
 func ServiceStatusOutput() {
 	os.Stdout.WriteString("got status of the service")
 	os.Exit(0)
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	GpRoleDispatch = "dispatch"
+	GpRoleExecute  = "execute"
+	GpRoleUtility  = "utility"
+
+	StopModeSmart     = "smart"
+	StopModeFast      = "fast"
+	StopModeImmediate = "immediate"
+
+	// ReplicationSlotName is the slot of the acting primary its mirror streams from
+	ReplicationSlotName = "internal_wal_replication_slot"
+)
+
+// PgCtlPath returns the path to the pg_ctl binary of the given installation
+func PgCtlPath(gphome string) string {
+	return filepath.Join(gphome, "bin", "pg_ctl")
+}
+
+// PgRewindPath returns the path to the pg_rewind binary of the given installation
+func PgRewindPath(gphome string) string {
+	return filepath.Join(gphome, "bin", "pg_rewind")
+}
+
+// InitdbPath returns the path to the initdb binary of the given installation
+func InitdbPath(gphome string) string {
+	return filepath.Join(gphome, "bin", "initdb")
+}
+
+// PgBasebackupPath returns the path to the pg_basebackup binary of the given installation
+func PgBasebackupPath(gphome string) string {
+	return filepath.Join(gphome, "bin", "pg_basebackup")
+}
+
+/*
+PgCtlStartArgs returns the arguments used to start a postmaster with pg_ctl,
+mirroring the command built by gpstart, e.g.
+
+	-D /data/primary/gpseg0 -l /data/primary/gpseg0/log/startup.log -w -t 600 -o "-p 6000 -c gp_role=execute" start
+
+A timeout of 0 leaves the pg_ctl default in place.
+*/
+func PgCtlStartArgs(dataDir string, port int, gpRole string, timeout int) []string {
+	args := []string{"-D", dataDir, "-l", filepath.Join(dataDir, "log", "startup.log"), "-w"}
+	if timeout > 0 {
+		args = append(args, "-t", fmt.Sprint(timeout))
+	}
+
+	options := []string{fmt.Sprintf("-p %d", port)}
+	if gpRole != "" {
+		options = append(options, fmt.Sprintf("-c gp_role=%s", gpRole))
+	}
+
+	return append(args, "-o", strings.Join(options, " "), "start")
+}
+
+/*
+PgCtlStopArgs returns the arguments used to stop a postmaster with pg_ctl, e.g.
+
+	-D /data/primary/gpseg0 -m fast -w -t 600 stop
+*/
+func PgCtlStopArgs(dataDir string, mode string, timeout int) []string {
+	args := []string{"-D", dataDir, "-m", mode, "-w"}
+	if timeout > 0 {
+		args = append(args, "-t", fmt.Sprint(timeout))
+	}
+
+	return append(args, "stop")
+}
+
+/*
+PgRewindArgs returns the arguments used to rewind the data directory of a
+segment from its acting primary, mirroring the command built by gprecoverseg
+for an incremental recovery, e.g.
+
+	--write-recovery-conf --slot=internal_wal_replication_slot --source-server="host=sdw1 port=6000 dbname=template1" --target-pgdata=/data/mirror/gpseg0 --progress
+*/
+func PgRewindArgs(dataDir string, sourceHost string, sourcePort int) []string {
+	return []string{
+		"--write-recovery-conf",
+		fmt.Sprintf("--slot=%s", ReplicationSlotName),
+		fmt.Sprintf("--source-server=host=%s port=%d dbname=template1", sourceHost, sourcePort),
+		fmt.Sprintf("--target-pgdata=%s", dataDir),
+		"--progress",
+	}
+}
+
+/*
+PgBasebackupArgs returns the arguments used to copy the data directory of a
+segment from its acting primary, mirroring the command built by gprecoverseg
+for a full recovery, e.g.
+
+	-c fast -D /data/mirror/gpseg0 -h sdw1 -p 6000 --slot internal_wal_replication_slot --wal-method stream --force-overwrite --write-recovery-conf --target-gp-dbid 4 -E ./db_dumps -E ./promote -E ./db_analyze --progress --verbose
+
+The replication slot is created first when createSlot is set, as for a new
+mirror.
+*/
+func PgBasebackupArgs(dataDir string, sourceHost string, sourcePort int, dbid int, createSlot bool) []string {
+	args := []string{
+		"-c", "fast",
+		"-D", dataDir,
+		"-h", sourceHost,
+		"-p", fmt.Sprint(sourcePort),
+	}
+	if createSlot {
+		args = append(args, "--create-slot")
+	}
+
+	return append(args,
+		"--slot", ReplicationSlotName,
+		"--wal-method", "stream",
+		"--force-overwrite",
+		"--write-recovery-conf",
+		"--target-gp-dbid", fmt.Sprint(dbid),
+		"-E", "./db_dumps",
+		"-E", "./promote",
+		"-E", "./db_analyze",
+		"--progress",
+		"--verbose",
+	)
+}
+
+/*
+PgBasebackupCoordinatorArgs returns the arguments used to copy the data
+directory of the coordinator for a new primary segment, mirroring the copy
+gpexpand builds the new segments from, e.g.
+
+	-c fast -D /data/primary/gpseg2 -h cdw -p 5432 --wal-method stream --target-gp-dbid 6 -E ./db_dumps -E ./promote -E ./db_analyze -E ./gpperfmon/data -E ./gpperfmon/logs -E ./log --progress --verbose
+
+No recovery configuration is written, as the copy runs as a primary.
+*/
+func PgBasebackupCoordinatorArgs(dataDir string, sourceHost string, sourcePort int, dbid int) []string {
+	return []string{
+		"-c", "fast",
+		"-D", dataDir,
+		"-h", sourceHost,
+		"-p", fmt.Sprint(sourcePort),
+		"--wal-method", "stream",
+		"--target-gp-dbid", fmt.Sprint(dbid),
+		"-E", "./db_dumps",
+		"-E", "./promote",
+		"-E", "./db_analyze",
+		"-E", "./gpperfmon/data",
+		"-E", "./gpperfmon/logs",
+		"-E", "./log",
+		"--progress",
+		"--verbose",
+	}
+}
+
+/*
+InitdbArgs returns the arguments used to create the data directory of a new
+segment, mirroring the command built by gpinitsystem, e.g.
+
+	-D /data/primary/gpseg0 -E UTF-8 --locale=en_US.utf8 --data-checksums
+
+An empty encoding or locale leaves the initdb default in place.
+*/
+func InitdbArgs(dataDir string, encoding string, locale string) []string {
+	args := []string{"-D", dataDir}
+	if encoding != "" {
+		args = append(args, "-E", encoding)
+	}
+	if locale != "" {
+		args = append(args, fmt.Sprintf("--locale=%s", locale))
+	}
+
+	return append(args, "--data-checksums")
+}
+
+/*
+WriteSegmentConfig configures the data directory of a new segment created by
+initdb as gpinitsystem does: postgresql.conf gets the port and content ID,
+internal.auto.conf the dbid, and pg_hba.conf trusts the user from every host of
+the cluster, for both regular and replication connections. The settings are
+appended, so they also override those of a data directory copied from the
+coordinator.
+*/
+func WriteSegmentConfig(dataDir string, port int, contentID int, dbid int, user string, hbaHostnames []string) error {
+	settings := fmt.Sprintf("port=%d\nlisten_addresses='*'\ngp_contentid=%d\n", port, contentID)
+	err := appendToFile(filepath.Join(dataDir, "postgresql.conf"), settings)
+	if err != nil {
+		return err
+	}
+
+	err = appendToFile(filepath.Join(dataDir, "internal.auto.conf"), fmt.Sprintf("gp_dbid=%d\n", dbid))
+	if err != nil {
+		return err
+	}
+
+	return TrustHosts(dataDir, user, hbaHostnames)
+}
+
+// TrustHosts adds the hosts to pg_hba.conf of the data directory, trusting the
+// user from them for both regular and replication connections
+func TrustHosts(dataDir string, user string, hostnames []string) error {
+	var hba strings.Builder
+	for _, host := range hostnames {
+		fmt.Fprintf(&hba, "host\tall\t%s\t%s\ttrust\n", user, host)
+		fmt.Fprintf(&hba, "host\treplication\t%s\t%s\ttrust\n", user, host)
+	}
+
+	return appendToFile(filepath.Join(dataDir, "pg_hba.conf"), hba.String())
+}
+
+func appendToFile(path string, contents string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(contents)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,129 @@
+package utils_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/greenplum-db/gpdb/gp/utils"
+)
+
+func TestPgCtlStartArgs(t *testing.T) {
+	t.Run("builds the start arguments with a timeout", func(t *testing.T) {
+		result := utils.PgCtlStartArgs("/data/qddir/gpseg-1", 7000, utils.GpRoleUtility, 60)
+		expected := []string{"-D", "/data/qddir/gpseg-1", "-l", "/data/qddir/gpseg-1/log/startup.log", "-w", "-t", "60", "-o", "-p 7000 -c gp_role=utility", "start"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("omits the role and timeout when not given", func(t *testing.T) {
+		result := utils.PgCtlStartArgs("/data/qddir/gpseg-1", 7000, "", 0)
+		expected := []string{"-D", "/data/qddir/gpseg-1", "-l", "/data/qddir/gpseg-1/log/startup.log", "-w", "-o", "-p 7000", "start"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestPgCtlStopArgs(t *testing.T) {
+	t.Run("builds the stop arguments", func(t *testing.T) {
+		result := utils.PgCtlStopArgs("/data/qddir/gpseg-1", utils.StopModeFast, 120)
+		expected := []string{"-D", "/data/qddir/gpseg-1", "-m", "fast", "-w", "-t", "120", "stop"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestPgRewindArgs(t *testing.T) {
+	t.Run("builds the rewind arguments", func(t *testing.T) {
+		result := utils.PgRewindArgs("/data/mirror/gpseg0", "sdw1", 6000)
+		expected := []string{"--write-recovery-conf", "--slot=internal_wal_replication_slot", "--source-server=host=sdw1 port=6000 dbname=template1", "--target-pgdata=/data/mirror/gpseg0", "--progress"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestPgBasebackupArgs(t *testing.T) {
+	t.Run("builds the base backup arguments", func(t *testing.T) {
+		result := utils.PgBasebackupArgs("/data/mirror/gpseg0", "sdw1", 6000, 4, false)
+		expected := []string{"-c", "fast", "-D", "/data/mirror/gpseg0", "-h", "sdw1", "-p", "6000", "--slot", "internal_wal_replication_slot", "--wal-method", "stream", "--force-overwrite", "--write-recovery-conf", "--target-gp-dbid", "4", "-E", "./db_dumps", "-E", "./promote", "-E", "./db_analyze", "--progress", "--verbose"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+	t.Run("creates the replication slot for a new mirror", func(t *testing.T) {
+		result := utils.PgBasebackupArgs("/data/mirror/gpseg0", "sdw1", 6000, 4, true)
+		expected := []string{"-c", "fast", "-D", "/data/mirror/gpseg0", "-h", "sdw1", "-p", "6000", "--create-slot", "--slot", "internal_wal_replication_slot", "--wal-method", "stream", "--force-overwrite", "--write-recovery-conf", "--target-gp-dbid", "4", "-E", "./db_dumps", "-E", "./promote", "-E", "./db_analyze", "--progress", "--verbose"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestPgBasebackupCoordinatorArgs(t *testing.T) {
+	t.Run("copies the coordinator without its logs and recovery configuration", func(t *testing.T) {
+		result := utils.PgBasebackupCoordinatorArgs("/data/primary/gpseg2", "cdw", 5432, 6)
+		expected := []string{"-c", "fast", "-D", "/data/primary/gpseg2", "-h", "cdw", "-p", "5432", "--wal-method", "stream", "--target-gp-dbid", "6", "-E", "./db_dumps", "-E", "./promote", "-E", "./db_analyze", "-E", "./gpperfmon/data", "-E", "./gpperfmon/logs", "-E", "./log", "--progress", "--verbose"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestInitdbArgs(t *testing.T) {
+	t.Run("builds the initdb arguments", func(t *testing.T) {
+		result := utils.InitdbArgs("/data/primary/gpseg0", "UTF-8", "en_US.utf8")
+		expected := []string{"-D", "/data/primary/gpseg0", "-E", "UTF-8", "--locale=en_US.utf8", "--data-checksums"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+	t.Run("leaves out the encoding and locale when not given", func(t *testing.T) {
+		result := utils.InitdbArgs("/data/primary/gpseg0", "", "")
+		expected := []string{"-D", "/data/primary/gpseg0", "--data-checksums"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+}
+
+func TestWriteSegmentConfig(t *testing.T) {
+	t.Run("configures the data directory of the segment", func(t *testing.T) {
+		dataDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dataDir, "postgresql.conf"), []byte("max_connections = 100\n"), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		err = utils.WriteSegmentConfig(dataDir, 6000, 0, 2, "gpadmin", []string{"cdw", "sdw1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := map[string]string{
+			"postgresql.conf":    "max_connections = 100\nport=6000\nlisten_addresses='*'\ngp_contentid=0\n",
+			"internal.auto.conf": "gp_dbid=2\n",
+			"pg_hba.conf":        "host\tall\tgpadmin\tcdw\ttrust\nhost\treplication\tgpadmin\tcdw\ttrust\nhost\tall\tgpadmin\tsdw1\ttrust\nhost\treplication\tgpadmin\tsdw1\ttrust\n",
+		}
+		for name, contents := range expected {
+			result, err := os.ReadFile(filepath.Join(dataDir, name))
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if string(result) != contents {
+				t.Fatalf("got %q in %s, want %q", result, name, contents)
+			}
+		}
+	})
+	t.Run("errors out when the data directory does not exist", func(t *testing.T) {
+		err := utils.WriteSegmentConfig(filepath.Join(t.TempDir(), "missing"), 6000, 0, 2, "gpadmin", nil)
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("got %v, want %v", err, os.ErrNotExist)
+		}
+	})
+}
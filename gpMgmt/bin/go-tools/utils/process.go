@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/gplog"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProcessUsage is the resource usage of a running process
+type ProcessUsage struct {
+	Elapsed   time.Duration // since the process started
+	CPUTime   time.Duration // user and system
+	MemoryRSS uint64        // bytes
+}
+
+// GetProcessUsage returns the resource usage of a process as reported by ps,
+// whose output fields are the same on Linux and Darwin
+func (p GpPlatform) GetProcessUsage(pid uint32) (*ProcessUsage, error) {
+	output, err := execCommand("ps", "-o", "etime=,time=,rss=", "-p", strconv.FormatUint(uint64(pid), 10)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the usage of process %d: %w", pid, err)
+	}
+
+	return ParseProcessUsage(string(output))
+}
+
+/*
+Example ps output, for the elapsed time, the CPU time and the RSS in KiB
+
+Linux:
+
+	2-03:04:05 00:01:02 31560
+
+Darwin:
+
+	03:04:05   0:01.02  31560
+*/
+func ParseProcessUsage(output string) (*ProcessUsage, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected process usage %q", strings.TrimSpace(output))
+	}
+
+	elapsed, err := parseProcessTime(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	cpuTime, err := parseProcessTime(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	rss, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid process memory %q: %w", fields[2], err)
+	}
+
+	return &ProcessUsage{Elapsed: elapsed, CPUTime: cpuTime, MemoryRSS: rss * 1024}, nil
+}
+
+// parseProcessTime parses the [[dd-]hh:]mm:ss[.ss] times output by ps
+func parseProcessTime(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid process time %q", value)
+
+	var days int
+	clock := value
+	if d, rest, found := strings.Cut(value, "-"); found {
+		var err error
+		days, err = strconv.Atoi(d)
+		if err != nil {
+			return 0, invalid
+		}
+		clock = rest
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, invalid
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, invalid
+	}
+	total := time.Duration(seconds*float64(time.Second)) + time.Duration(days)*24*time.Hour
+
+	units := []time.Duration{time.Minute, time.Hour}
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, invalid
+		}
+		total += time.Duration(n) * units[len(parts)-2-i]
+	}
+
+	return total, nil
+}
+
+// AddProcessUsage fills in the start time, uptime and resource usage of a
+// running service. The usage is left out when it can not be read, as the
+// service may have stopped in the meantime.
+func AddProcessUsage(platform Platform, status *idl.ServiceStatus) {
+	if status.Pid == 0 {
+		return
+	}
+
+	usage, err := platform.GetProcessUsage(status.Pid)
+	if err != nil {
+		gplog.Debug("%s", err)
+	} else {
+		status.MemoryRss = usage.MemoryRSS
+		status.CpuTime = durationpb.New(usage.CPUTime)
+		if status.StartTime == nil { // launchctl does not report the start time
+			status.StartTime = timestamppb.New(time.Now().Add(-usage.Elapsed).Truncate(time.Second))
+		}
+	}
+
+	if status.StartTime != nil {
+		status.Uptime = FormatUptime(time.Since(status.StartTime.AsTime()))
+	}
+}
+
+// FormatUptime returns the uptime rounded down to the second, e.g. 50h3m2s
+func FormatUptime(uptime time.Duration) string {
+	if uptime < 0 {
+		uptime = 0
+	}
+
+	return uptime.Truncate(time.Second).String()
+}
+
+// FileChecksum returns the hex encoded sha256 of the contents of the file
+func FileChecksum(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not compute the checksum of %s: %w", path, err)
+	}
+	sum := sha256.Sum256(contents)
+
+	return hex.EncodeToString(sum[:]), nil
+}
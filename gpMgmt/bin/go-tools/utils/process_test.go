@@ -0,0 +1,179 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gp-common-go-libs/testhelper"
+	"github.com/greenplum-db/gpdb/gp/constants"
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/testutils"
+	"github.com/greenplum-db/gpdb/gp/testutils/exectest"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	exectest.RegisterMains(
+		ProcessUsageOutput,
+	)
+}
+
+func ProcessUsageOutput() {
+	os.Stdout.WriteString(" 1-02:03:04 00:00:05 2048\n")
+	os.Exit(0)
+}
+
+func TestGetProcessUsage(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	t.Run("gets the usage of the process from ps", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+
+		utils.SetExecCommand(exectest.NewCommandWithVerifier(ProcessUsageOutput, func(utility string, args ...string) {
+			expectedArgs := []string{"-o", "etime=,time=,rss=", "-p", "1234"}
+			if utility != "ps" || !reflect.DeepEqual(args, expectedArgs) {
+				t.Fatalf("got %s %+v, want ps %+v", utility, args, expectedArgs)
+			}
+		}))
+		defer utils.ResetExecCommand()
+
+		result, err := platform.GetProcessUsage(1234)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		expected := &utils.ProcessUsage{Elapsed: 26*time.Hour + 3*time.Minute + 4*time.Second, CPUTime: 5 * time.Second, MemoryRSS: 2048 * 1024}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("got %+v, want %+v", result, expected)
+		}
+	})
+
+	t.Run("errors out when the process does not exist", func(t *testing.T) {
+		platform := GetPlatform(constants.PlatformLinux, t)
+
+		utils.SetExecCommand(exectest.NewCommand(exectest.Failure))
+		defer utils.ResetExecCommand()
+
+		_, err := platform.GetProcessUsage(1234)
+		expected := "could not get the usage of process 1234: exit status 1"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+}
+
+func TestParseProcessUsage(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		expected *utils.ProcessUsage
+	}{
+		{
+			name:     "linux",
+			output:   "  2-03:04:05 00:01:02 31560\n",
+			expected: &utils.ProcessUsage{Elapsed: 51*time.Hour + 4*time.Minute + 5*time.Second, CPUTime: 62 * time.Second, MemoryRSS: 31560 * 1024},
+		},
+		{
+			name:     "darwin",
+			output:   "    03:04:05   0:01.50  31560\n",
+			expected: &utils.ProcessUsage{Elapsed: 3*time.Hour + 4*time.Minute + 5*time.Second, CPUTime: 1500 * time.Millisecond, MemoryRSS: 31560 * 1024},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := utils.ParseProcessUsage(tc.output)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Fatalf("got %+v, want %+v", result, tc.expected)
+			}
+		})
+	}
+
+	for _, output := range []string{"", "03:04 0:01", "1:2:3:4 0:01 100", "03:04 x:01 100", "03:04 0:01 -1"} {
+		_, err := utils.ParseProcessUsage(output)
+		if err == nil {
+			t.Fatalf("expected an error for %q", output)
+		}
+	}
+}
+
+func TestAddProcessUsage(t *testing.T) {
+	testhelper.SetupTestLogger()
+
+	usage := &utils.ProcessUsage{Elapsed: 2 * time.Hour, CPUTime: 3 * time.Second, MemoryRSS: 4096}
+
+	t.Run("computes the start time and uptime from the elapsed time", func(t *testing.T) {
+		status := &idl.ServiceStatus{Status: "running", Pid: 1234}
+		utils.AddProcessUsage(&testutils.MockPlatform{ProcessUsage: usage}, status)
+
+		if status.MemoryRss != 4096 || status.CpuTime.AsDuration() != 3*time.Second {
+			t.Fatalf("unexpected usage %+v", status)
+		}
+		if !strings.HasPrefix(status.Uptime, "2h0m") {
+			t.Fatalf("got uptime %q, want 2h0m", status.Uptime)
+		}
+		if elapsed := time.Since(status.StartTime.AsTime()); elapsed < 2*time.Hour || elapsed > 2*time.Hour+time.Minute {
+			t.Fatalf("got start time %s, want two hours ago", status.StartTime.AsTime())
+		}
+	})
+
+	t.Run("keeps the start time reported by the service manager", func(t *testing.T) {
+		startTime := time.Now().Add(-5 * time.Minute)
+		status := &idl.ServiceStatus{Status: "running", Pid: 1234, StartTime: timestamppb.New(startTime)}
+		utils.AddProcessUsage(&testutils.MockPlatform{ProcessUsage: usage}, status)
+
+		if !status.StartTime.AsTime().Equal(startTime) || !strings.HasPrefix(status.Uptime, "5m") {
+			t.Fatalf("got %s and %q, want %s and 5m", status.StartTime.AsTime(), status.Uptime, startTime)
+		}
+	})
+
+	t.Run("computes the uptime when the usage can not be read", func(t *testing.T) {
+		status := &idl.ServiceStatus{Status: "running", Pid: 1234, StartTime: timestamppb.New(time.Now().Add(-time.Hour))}
+		utils.AddProcessUsage(&testutils.MockPlatform{}, status)
+
+		if status.MemoryRss != 0 || status.CpuTime != nil || !strings.HasPrefix(status.Uptime, "1h0m") {
+			t.Fatalf("unexpected status %+v", status)
+		}
+	})
+
+	t.Run("adds nothing when the service is not running", func(t *testing.T) {
+		status := &idl.ServiceStatus{Status: "not running"}
+		utils.AddProcessUsage(&testutils.MockPlatform{ProcessUsage: usage}, status)
+
+		if status.MemoryRss != 0 || status.Uptime != "" || status.StartTime != nil {
+			t.Fatalf("unexpected status %+v", status)
+		}
+	})
+}
+
+func TestFileChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gp.conf")
+	err := os.WriteFile(path, []byte("{}"), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	result, err := utils.FileChecksum(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	expected := "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+	if result != expected {
+		t.Fatalf("got %s, want %s", result, expected)
+	}
+
+	_, err = utils.FileChecksum(filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Report is the output of a command, as columns for the table and CSV
+// formats, and as records for the structured formats. Each record is encoded
+// with its json and yaml struct tags.
+type Report struct {
+	Header  []string
+	Rows    [][]string
+	Records []interface{}
+}
+
+func NewReport(header ...string) *Report {
+	return &Report{Header: header, Rows: [][]string{}, Records: []interface{}{}}
+}
+
+// Add appends a row along with the record it shows
+func (r *Report) Add(record interface{}, row ...string) {
+	r.Rows = append(r.Rows, row)
+	r.Records = append(r.Records, record)
+}
+
+// Append adds the rows and records of another report with the same header
+func (r *Report) Append(other *Report) *Report {
+	r.Rows = append(r.Rows, other.Rows...)
+	r.Records = append(r.Records, other.Records...)
+
+	return r
+}
+
+// Renderer writes a report in an output format
+type Renderer interface {
+	Render(w io.Writer, report *Report) error
+}
+
+var renderers = map[string]Renderer{
+	"table": TableRenderer{},
+	"json":  JSONRenderer{},
+	"yaml":  YAMLRenderer{},
+	"csv":   CSVRenderer{},
+}
+
+// RegisterRenderer makes an output format available to the commands
+func RegisterRenderer(format string, renderer Renderer) {
+	renderers[format] = renderer
+}
+
+// NewRenderer returns the renderer of the output format
+func NewRenderer(format string) (Renderer, error) {
+	renderer, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid output format %q, expected one of %s", format, strings.Join(OutputFormats(), ", "))
+	}
+
+	return renderer, nil
+}
+
+// OutputFormats returns the names of the available output formats
+func OutputFormats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// TableRenderer aligns the columns for humans to read
+type TableRenderer struct {
+	SkipHeader bool
+}
+
+func (r TableRenderer) Render(w io.Writer, report *Report) error {
+	tw := new(tabwriter.Writer)
+	tw.Init(w, 0, 8, 2, '\t', 0)
+
+	if !r.SkipHeader {
+		fmt.Fprintln(tw, strings.Join(report.Header, "\t"))
+	}
+	for _, row := range report.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// JSONRenderer writes the records as an indented JSON array
+type JSONRenderer struct{}
+
+func (r JSONRenderer) Render(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(report.Records)
+	if err != nil {
+		return fmt.Errorf("could not render JSON: %w", err)
+	}
+
+	return nil
+}
+
+// YAMLRenderer writes the records as a YAML sequence
+type YAMLRenderer struct{}
+
+func (r YAMLRenderer) Render(w io.Writer, report *Report) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(report.Records)
+	if err != nil {
+		return fmt.Errorf("could not render YAML: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+// CSVRenderer writes the header and rows as comma-separated values
+type CSVRenderer struct{}
+
+func (r CSVRenderer) Render(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(report.Header)
+	if err != nil {
+		return fmt.Errorf("could not render CSV: %w", err)
+	}
+	err = writer.WriteAll(report.Rows)
+	if err != nil {
+		return fmt.Errorf("could not render CSV: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package utils_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/greenplum-db/gpdb/gp/idl"
+	"github.com/greenplum-db/gpdb/gp/utils"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type firstColumnRenderer struct{}
+
+func (r firstColumnRenderer) Render(w io.Writer, report *utils.Report) error {
+	_, err := io.WriteString(w, report.Header[0])
+	return err
+}
+
+func TestRenderers(t *testing.T) {
+	report := utils.ServiceStatusReport("Hub", []*idl.ServiceStatus{
+		{
+			Host:   "cdw",
+			Status: "Running",
+			Pid:    1234,
+			Uptime: "5H",
+			Certificates: []*idl.Certificate{
+				{Path: "/certs/server-cert.pem", NotAfter: timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))},
+			},
+		},
+	}).Append(utils.ServiceStatusReport("Agent", []*idl.ServiceStatus{
+		{Host: "sdw1, rack 2", Status: "Unknown"},
+	}))
+
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "table",
+			expected: "ROLE\tHOST\t\tSTATUS\t\tPID\tUPTIME\tCERT EXPIRY\n" +
+				"Hub\tcdw\t\tRunning\t\t1234\t5H\t2024-03-01T00:00:00Z\n" +
+				"Agent\tsdw1, rack 2\tUnknown\t\t0\t\t-\n",
+		},
+		{
+			format: "json",
+			expected: `[
+  {
+    "role": "Hub",
+    "host": "cdw",
+    "status": "Running",
+    "pid": 1234,
+    "uptime": "5H",
+    "certificateExpiry": "2024-03-01T00:00:00Z"
+  },
+  {
+    "role": "Agent",
+    "host": "sdw1, rack 2",
+    "status": "Unknown",
+    "pid": 0,
+    "uptime": ""
+  }
+]
+`,
+		},
+		{
+			format: "yaml",
+			expected: `- role: Hub
+  host: cdw
+  status: Running
+  pid: 1234
+  uptime: 5H
+  certificateExpiry: 2024-03-01T00:00:00Z
+- role: Agent
+  host: sdw1, rack 2
+  status: Unknown
+  pid: 0
+  uptime: ""
+`,
+		},
+		{
+			format: "csv",
+			expected: "ROLE,HOST,STATUS,PID,UPTIME,CERT EXPIRY\n" +
+				"Hub,cdw,Running,1234,5H,2024-03-01T00:00:00Z\n" +
+				"Agent,\"sdw1, rack 2\",Unknown,0,,-\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			renderer, err := utils.NewRenderer(tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+
+			var output bytes.Buffer
+			err = renderer.Render(&output, report)
+			if err != nil {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+
+			if output.String() != tc.expected {
+				t.Fatalf("got %q, want %q", output.String(), tc.expected)
+			}
+		})
+	}
+
+	t.Run("renders an empty list of records", func(t *testing.T) {
+		var output bytes.Buffer
+		err := utils.JSONRenderer{}.Render(&output, utils.ServiceStatusReport("Agent", nil))
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if output.String() != "[]\n" {
+			t.Fatalf("got %q, want %q", output.String(), "[]\n")
+		}
+	})
+
+	t.Run("errors out on unknown formats", func(t *testing.T) {
+		_, err := utils.NewRenderer("xml")
+		expected := `invalid output format "xml", expected one of csv, json, table, yaml`
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("renders the formats registered", func(t *testing.T) {
+		utils.RegisterRenderer("first-column", firstColumnRenderer{})
+
+		renderer, err := utils.NewRenderer("first-column")
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		var output bytes.Buffer
+		err = renderer.Render(&output, report)
+		if err != nil || output.String() != "ROLE" {
+			t.Fatalf("got %q, %v, want ROLE", output.String(), err)
+		}
+	})
+}
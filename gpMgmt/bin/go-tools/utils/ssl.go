@@ -3,15 +3,27 @@ package utils
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/greenplum-db/gpdb/gp/idl"
 	"google.golang.org/grpc/credentials"
 )
 
+// Role is the gp service accepting the TLS connections
+type Role string
+
+const (
+	HubRole   Role = "hub"   // connected to by the CLI
+	AgentRole Role = "agent" // connected to by the hub
+)
+
 type Credentials interface {
-	LoadServerCredentials() (credentials.TransportCredentials, error)
+	LoadServerCredentials(role Role) (credentials.TransportCredentials, error)
 	LoadClientCredentials() (credentials.TransportCredentials, error)
+	Certificates() ([]*idl.Certificate, error)
 }
 
 type GpCredentials struct {
@@ -19,9 +31,26 @@ type GpCredentials struct {
 	CAKeyPath      string `json:"caKey"`
 	ServerCertPath string `json:"serverCert"`
 	ServerKeyPath  string `json:"serverKey"`
+	// The client certificate defaults to the server certificate when not set
+	ClientCertPath string `json:"clientCert,omitempty"`
+	ClientKeyPath  string `json:"clientKey,omitempty"`
+	// In strict mode client certificates must be signed by the CA, and match
+	// one of the allowed names of the role, if any, by SAN or CN
+	VerifyClientCerts   bool     `json:"verifyClientCerts,omitempty"`
+	AllowedHubClients   []string `json:"allowedHubClients,omitempty"`
+	AllowedAgentClients []string `json:"allowedAgentClients,omitempty"`
+}
+
+func (c GpCredentials) LoadServerCredentials(role Role) (credentials.TransportCredentials, error) {
+	config, err := c.serverTLSConfig(role)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(config), nil
 }
 
-func (c GpCredentials) LoadServerCredentials() (credentials.TransportCredentials, error) {
+func (c GpCredentials) serverTLSConfig(role Role) (*tls.Config, error) {
 	serverCert, err := tls.LoadX509KeyPair(c.ServerCertPath, c.ServerKeyPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not load server credentials: %w", err)
@@ -31,23 +60,39 @@ func (c GpCredentials) LoadServerCredentials() (credentials.TransportCredentials
 		Certificates: []tls.Certificate{serverCert},
 		ClientAuth:   tls.RequireAnyClientCert,
 	}
-	return credentials.NewTLS(config), nil
 
+	if c.VerifyClientCerts {
+		certPool, err := loadCertPool(c.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client CA: %w", err)
+		}
+
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = certPool
+
+		allowed := c.AllowedClients(role)
+		if len(allowed) > 0 {
+			config.VerifyPeerCertificate = verifyClientName(role, allowed)
+		}
+	}
+
+	return config, nil
 }
 
 func (c GpCredentials) LoadClientCredentials() (credentials.TransportCredentials, error) {
-	caCert, err := os.ReadFile(c.CACertPath)
+	certPool, err := loadCertPool(c.CACertPath)
 	if err != nil {
 		return nil, err
 	}
-	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("failed to add server CA's certificate")
+
+	certPath, keyPath := c.ServerCertPath, c.ServerKeyPath
+	if c.ClientCertPath != "" {
+		certPath, keyPath = c.ClientCertPath, c.ClientKeyPath
 	}
 
-	clientCert, err := tls.LoadX509KeyPair(c.ServerCertPath, c.ServerKeyPath)
+	clientCert, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
-		return nil, fmt.Errorf("error while loading server certificate: %v", err)
+		return nil, fmt.Errorf("error while loading client certificate: %v", err)
 	}
 
 	config := &tls.Config{
@@ -57,3 +102,69 @@ func (c GpCredentials) LoadClientCredentials() (credentials.TransportCredentials
 
 	return credentials.NewTLS(config), nil
 }
+
+// AllowedClients returns the names the client certificates connecting to the
+// role may have. An empty list allows any certificate signed by the CA.
+func (c GpCredentials) AllowedClients(role Role) []string {
+	switch role {
+	case HubRole:
+		return c.AllowedHubClients
+	case AgentRole:
+		return c.AllowedAgentClients
+	}
+
+	return nil
+}
+
+func loadCertPool(caCertPath string) (*x509.CertPool, error) {
+	caCert, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to add server CA's certificate")
+	}
+
+	return certPool, nil
+}
+
+// verifyClientName runs after the chain of the client certificate has been
+// verified against the CA, and checks its names against the allowed ones
+func verifyClientName(role Role, allowed []string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+			return errors.New("no verified client certificate")
+		}
+
+		names := CertificateNames(verifiedChains[0][0])
+		for _, name := range names {
+			for _, allowedName := range allowed {
+				if name == allowedName {
+					return nil
+				}
+			}
+		}
+
+		return fmt.Errorf("client certificate with names [%s] is not allowed to connect to the %s", strings.Join(names, ", "), role)
+	}
+}
+
+// CertificateNames returns the SANs of the certificate followed by its CN
+func CertificateNames(cert *x509.Certificate) []string {
+	names := make([]string, 0)
+	names = append(names, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	names = append(names, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	return names
+}
@@ -1,8 +1,11 @@
 package utils_test
 
 import (
+	"context"
+	"net"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 
 	"github.com/greenplum-db/gpdb/gp/constants"
@@ -23,7 +26,7 @@ func TestLoadServerCredentials(t *testing.T) {
 			ServerCertPath: "./certificates/server-cert.pem",
 			ServerKeyPath:  "./certificates/server-key.pem",
 		}
-		_, err := creds.LoadServerCredentials()
+		_, err := creds.LoadServerCredentials(utils.HubRole)
 		if err != nil {
 			t.Errorf("unexpected error %v", err)
 		}
@@ -37,7 +40,7 @@ func TestLoadServerCredentials(t *testing.T) {
 			ServerKeyPath:  "./certificates/server-key.pem",
 		}
 		creds.ServerCertPath = "/dev/null"
-		_, err := creds.LoadServerCredentials()
+		_, err := creds.LoadServerCredentials(utils.HubRole)
 		if err == nil {
 			t.Fatalf("expected TLS error, did not receive one")
 		}
@@ -93,3 +96,160 @@ func TestLoadClientCredentials(t *testing.T) {
 		t.Fatalf("Cannot remove test certificates: %v", err)
 	}
 }
+
+// handshake connects the client to the server and returns the error of the
+// server, which is the side verifying the client certificate
+func handshake(t *testing.T, server, client *utils.GpCredentials, role utils.Role) error {
+	t.Helper()
+
+	serverCreds, err := server.LoadServerCredentials(role)
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	clientCreds, err := client.LoadClientCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	defer listener.Close()
+
+	clientDone := make(chan struct{})
+	go func() {
+		defer close(clientDone)
+		clientConn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			return
+		}
+		defer clientConn.Close()
+
+		conn, _, err := clientCreds.ClientHandshake(context.Background(), "localhost", clientConn)
+		if err == nil {
+			// wait for the server to verify the client certificate
+			_, _ = conn.Read(make([]byte, 1))
+		}
+	}()
+
+	serverConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	conn, _, err := serverCreds.ServerHandshake(serverConn)
+	if err == nil {
+		conn.Close()
+	}
+	serverConn.Close()
+	<-clientDone
+
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	err := exec.Command(constants.ShellPath, "-c", "../generate_test_tls_certificates.sh `hostname`").Run()
+	if err != nil {
+		t.Fatalf("Cannot generate test certificates: %v", err)
+	}
+
+	newCredentials := func() *utils.GpCredentials {
+		return &utils.GpCredentials{
+			CACertPath:     "./certificates/ca-cert.pem",
+			CAKeyPath:      "./certificates/ca-key.pem",
+			ServerCertPath: "./certificates/server-cert.pem",
+			ServerKeyPath:  "./certificates/server-key.pem",
+			ClientCertPath: "./certificates/client-cert.pem",
+			ClientKeyPath:  "./certificates/client-key.pem",
+		}
+	}
+	untrusted := newCredentials()
+	untrusted.ClientCertPath = "./certificates/untrusted-cert.pem"
+	untrusted.ClientKeyPath = "./certificates/untrusted-key.pem"
+
+	t.Run("accepts any client certificate when not verifying them", func(t *testing.T) {
+		err := handshake(t, newCredentials(), untrusted, utils.HubRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("accepts client certificates signed by the CA in strict mode", func(t *testing.T) {
+		server := newCredentials()
+		server.VerifyClientCerts = true
+
+		err := handshake(t, server, newCredentials(), utils.HubRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("rejects client certificates not signed by the CA in strict mode", func(t *testing.T) {
+		server := newCredentials()
+		server.VerifyClientCerts = true
+
+		err := handshake(t, server, untrusted, utils.HubRole)
+		// clients do not send certificates the CA would not accept
+		expected := "client didn't provide a certificate"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("accepts client certificates matching an allowed name of the role", func(t *testing.T) {
+		server := newCredentials()
+		server.VerifyClientCerts = true
+		server.AllowedHubClients = []string{"gp-cli", "gp-client"}
+		server.AllowedAgentClients = []string{"gp-hub"}
+
+		err := handshake(t, server, newCredentials(), utils.HubRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("rejects client certificates not matching the allowed names of the role", func(t *testing.T) {
+		server := newCredentials()
+		server.VerifyClientCerts = true
+		server.AllowedHubClients = []string{"gp-client"}
+		server.AllowedAgentClients = []string{"gp-hub"}
+
+		err := handshake(t, server, newCredentials(), utils.AgentRole)
+		expected := "client certificate with names [gp-client, gp-client] is not allowed to connect to the agent"
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	t.Run("uses the server certificate as client certificate when none is set", func(t *testing.T) {
+		server := newCredentials()
+		server.VerifyClientCerts = true
+		server.AllowedHubClients = []string{"localhost"}
+		client := newCredentials()
+		client.ClientCertPath = ""
+		client.ClientKeyPath = ""
+
+		err := handshake(t, server, client, utils.HubRole)
+		if err != nil {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("errors out in strict mode when not able to load the CA", func(t *testing.T) {
+		server := newCredentials()
+		server.VerifyClientCerts = true
+		server.CACertPath = "/dev/null"
+
+		_, err := server.LoadServerCredentials(utils.HubRole)
+		expected := "could not load client CA: failed to add server CA's certificate"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("got %v, want %s", err, expected)
+		}
+	})
+
+	err = os.RemoveAll("./certificates")
+	if err != nil {
+		t.Fatalf("Cannot remove test certificates: %v", err)
+	}
+}